@@ -0,0 +1,136 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Config represent what the admin server exposes
+type Config struct {
+	// Token must be sent as "Authorization: Bearer <token>" when it is set
+	Token string
+	// Settings is the effective configuration served on /config, already redacted
+	Settings interface{}
+	// Router is the public router whose routes are listed on /routes
+	Router *mux.Router
+	// Vars are served on /debug/vars next to the expvar variables
+	Vars map[string]func() interface{}
+}
+
+// Route represent a route registered on the public router
+type Route struct {
+	Path string `json:"path"`
+	// Methods is empty when the route matches every method
+	Methods []string `json:"methods"`
+}
+
+// NewHandler will create the handler of the admin server. It must only be
+// served on its own listener, never next to the public API.
+func NewHandler(c Config) http.Handler {
+	r := mux.NewRouter()
+	r.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	r.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	r.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	r.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	// Index also serves the named profiles, e.g. /debug/pprof/heap
+	r.PathPrefix("/debug/pprof/").HandlerFunc(pprof.Index)
+	r.HandleFunc("/debug/vars", c.vars).Methods("GET")
+	r.HandleFunc("/config", c.settings).Methods("GET")
+	r.HandleFunc("/routes", c.routes).Methods("GET")
+
+	if c.Token == "" {
+		return r
+	}
+	return c.authorize(r)
+}
+
+func (c Config) authorize(next http.Handler) http.Handler {
+	expected := []byte("Bearer " + c.Token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "a valid admin token is required"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// vars writes the expvar variables, such as memstats, with the runtime and extra variables
+func (c Config) vars(w http.ResponseWriter, r *http.Request) {
+	values := map[string]json.RawMessage{}
+	expvar.Do(func(kv expvar.KeyValue) {
+		values[kv.Key] = json.RawMessage(kv.Value.String())
+	})
+	extra := map[string]func() interface{}{
+		"goroutines": func() interface{} { return runtime.NumGoroutine() },
+		"go_version": func() interface{} { return runtime.Version() },
+	}
+	for name, fn := range c.Vars {
+		extra[name] = fn
+	}
+	for name, fn := range extra {
+		b, err := json.Marshal(fn())
+		if err != nil {
+			b, _ = json.Marshal(fmt.Sprintf("error: %v", err))
+		}
+		values[name] = b
+	}
+	writeJSON(w, http.StatusOK, values)
+}
+
+func (c Config) settings(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, c.Settings)
+}
+
+func (c Config) routes(w http.ResponseWriter, r *http.Request) {
+	routes, err := Routes(c.Router)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"message": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, routes)
+}
+
+// Routes lists the routes of router sorted by path
+func Routes(router *mux.Router) ([]Route, error) {
+	routes := []Route{}
+	if router == nil {
+		return routes, nil
+	}
+	err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			// routes matching on something else than the path
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			methods = []string{}
+		}
+		routes = append(routes, Route{Path: path, Methods: methods})
+		return nil
+	})
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return strings.Join(routes[i].Methods, ",") < strings.Join(routes[j].Methods, ",")
+	})
+	return routes, err
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
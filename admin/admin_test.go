@@ -0,0 +1,86 @@
+package admin_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/admin"
+)
+
+func newRouter() *mux.Router {
+	router := mux.NewRouter()
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+	router.HandleFunc("/articles", noop).Methods("GET")
+	router.HandleFunc("/articles", noop).Methods("POST")
+	router.HandleFunc("/articles/{id}", noop).Methods("GET", "PUT")
+	router.HandleFunc("/healthz", noop)
+	return router
+}
+
+func get(h http.Handler, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestToken(t *testing.T) {
+	h := admin.NewHandler(admin.Config{Token: "s3cret", Router: newRouter()})
+
+	assert.Equal(t, http.StatusUnauthorized, get(h, "/routes", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, get(h, "/debug/pprof/", "wrong").Code)
+	assert.Equal(t, http.StatusOK, get(h, "/routes", "s3cret").Code)
+
+	// no token is required when none is configured
+	h = admin.NewHandler(admin.Config{})
+	assert.Equal(t, http.StatusOK, get(h, "/debug/pprof/", "").Code)
+}
+
+func TestRoutes(t *testing.T) {
+	h := admin.NewHandler(admin.Config{Router: newRouter()})
+
+	rec := get(h, "/routes", "")
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var routes []admin.Route
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &routes))
+	assert.Equal(t, []admin.Route{
+		{Path: "/articles", Methods: []string{"GET"}},
+		{Path: "/articles", Methods: []string{"POST"}},
+		{Path: "/articles/{id}", Methods: []string{"GET", "PUT"}},
+		{Path: "/healthz", Methods: []string{}},
+	}, routes)
+}
+
+func TestVarsAndConfig(t *testing.T) {
+	h := admin.NewHandler(admin.Config{
+		Settings: map[string]string{"pass": "*****"},
+		Vars: map[string]func() interface{}{
+			"cache": func() interface{} { return map[string]int{"hits": 3} },
+		},
+	})
+
+	rec := get(h, "/debug/vars", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var vars map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &vars))
+	assert.Contains(t, vars, "memstats")
+	assert.Contains(t, vars, "goroutines")
+	assert.JSONEq(t, `{"hits": 3}`, string(vars["cache"]))
+
+	rec = get(h, "/config", "")
+	assert.JSONEq(t, `{"pass": "*****"}`, rec.Body.String())
+
+	rec = get(h, "/debug/pprof/heap?debug=1", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "heap profile")
+}
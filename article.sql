@@ -1,136 +0,0 @@
-CREATE DATABASE  IF NOT EXISTS `article` /*!40100 DEFAULT CHARACTER SET utf8 COLLATE utf8_unicode_ci */;
-USE `article`;
--- MySQL dump 10.13  Distrib 5.7.17, for macos10.12 (x86_64)
---
--- Host: localhost    Database: article
--- ------------------------------------------------------
--- Server version	5.7.18
-
-/*!40101 SET @OLD_CHARACTER_SET_CLIENT=@@CHARACTER_SET_CLIENT */;
-/*!40101 SET @OLD_CHARACTER_SET_RESULTS=@@CHARACTER_SET_RESULTS */;
-/*!40101 SET @OLD_COLLATION_CONNECTION=@@COLLATION_CONNECTION */;
-/*!40101 SET NAMES utf8 */;
-/*!40103 SET @OLD_TIME_ZONE=@@TIME_ZONE */;
-/*!40103 SET TIME_ZONE='+00:00' */;
-/*!40014 SET @OLD_UNIQUE_CHECKS=@@UNIQUE_CHECKS, UNIQUE_CHECKS=0 */;
-/*!40014 SET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0 */;
-/*!40101 SET @OLD_SQL_MODE=@@SQL_MODE, SQL_MODE='NO_AUTO_VALUE_ON_ZERO' */;
-/*!40111 SET @OLD_SQL_NOTES=@@SQL_NOTES, SQL_NOTES=0 */;
-
---
--- Table structure for table `article`
---
-
-DROP TABLE IF EXISTS `article`;
-/*!40101 SET @saved_cs_client     = @@character_set_client */;
-/*!40101 SET character_set_client = utf8 */;
-CREATE TABLE `article` (
-  `id` int(11) NOT NULL AUTO_INCREMENT,
-  `title` varchar(45) COLLATE utf8_unicode_ci NOT NULL,
-  `content` longtext COLLATE utf8_unicode_ci NOT NULL,
-  `author_id` int(11) DEFAULT '0',
-  `updated_at` datetime DEFAULT NULL,
-  `created_at` datetime DEFAULT NULL,
-  PRIMARY KEY (`id`)
-) ENGINE=InnoDB AUTO_INCREMENT=7 DEFAULT CHARSET=utf8 COLLATE=utf8_unicode_ci;
-/*!40101 SET character_set_client = @saved_cs_client */;
-
---
--- Dumping data for table `article`
---
-
-LOCK TABLES `article` WRITE;
-/*!40000 ALTER TABLE `article` DISABLE KEYS */;
-INSERT INTO `article` VALUES (1,'Makan Ayam','<p>But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness. No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful.</p>\n\n<p>Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure. To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it? But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure?</p>\n\n<p>On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain. These cases are perfectly simple and easy to distinguish.</p>\n\n<p>In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided. But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains.</p>\n\n<p>But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness. No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful.</p>\n\n<p>Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure. To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it? But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure?</p>\n\n<p>On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain. These cases are perfectly simple and easy to distinguish.</p>\n\n<p>In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided. But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains.</p>\n\n<p>But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness. No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful.</p>\n\n<p>Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure. To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it? But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure?</p>\n\n<p>On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain. These cases are perfectly simple and easy to distinguish.</p>\n\n<p>In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided. But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains.</p>\n\n<p>But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness. No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful.</p>\n\n<p>Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure. To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it? But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure?</p>\n\n<p>On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain. These cases are perfectly simple and easy to distinguish.</p>\n\n<p>In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided. But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains.</p>\n\n<p>But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness. No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful.</p>\n\n<p>Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure. To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it? But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure?</p>\n\n<p>On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain. These cases are perfectly simple and easy to distinguish.</p>\n\n<p>In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided. But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains.</p>\n\n<p>But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness. No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful.</p>\n\n<p>Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure. To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it? But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure?</p>\n\n<p>On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain. These cases are perfectly simple and easy to distinguish.</p>\n\n<p>In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided. But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains.</p>\n\n<p>But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness. No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful.</p>\n\n<p>Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure. To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it? But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure?</p>\n\n<p>On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain. These cases are perfectly simple and easy to distinguish.</p>\n\n<p>In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided. But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains.</p>\n\n<p>But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness. No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful.</p>\n\n<p>Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure. To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it? But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure?</p>\n\n<p>On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain. These cases are perfectly simple and easy to distinguish.</p>\n\n<p>In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided. But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains.</p>\n\n<p>But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness. No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful.</p>\n\n<p>Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure. To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it? But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure?</p>\n\n<p>On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain. These cases are perfectly simple and easy to distinguish. In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided.</p>\n\n<p>But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains. But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness.</p>\n\n<p>No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful. Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure. To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it?</p>\n\n<p>But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure? On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain. These cases are perfectly simple and easy to distinguish.</p>\n\n<p>In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided. But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains.</p>\n\n<p>But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness. No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful.</p>\n\n<p>Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure. To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it? But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure?</p>\n\n<p>On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain. These cases are perfectly simple and easy to distinguish. In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided.</p>\n\n<p>But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains. But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness.</p>\n\n<p>No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful. Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure. To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it?</p>\n\n<p>But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure? On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain. These cases are perfectly simple and easy to distinguish.</p>\n\n<p>In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided. But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains.</p>\n\n<p>But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness. No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful.</p>\n\n<p>Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure. To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it? But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure?</p>\n\n<p>On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain. These cases are perfectly simple and easy to distinguish. In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided.</p>\n\n<p>But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains. But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness.</p>\n\n<p>No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful. Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure. To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it?</p>\n\n<p>But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure? On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain. These cases are perfectly simple and easy to distinguish.</p>\n\n<p>In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided. But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains.</p>\n\n<p>But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness. No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful.</p>\n\n<p>Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure. To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it? But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure?</p>\n\n<p>On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain. These cases are perfectly simple and easy to distinguish. In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided.</p>\n\n<p>But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains. But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness.</p>\n\n<p>No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful. Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure. To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it?</p>\n\n<p>But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure? On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain. These cases are perfectly simple and easy to distinguish.</p>\n\n<p>In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided. But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains.</p>\n\n<p>But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness. No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful. Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure.</p>\n\n<p>To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it? But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure?</p>\n\n<p>On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain. These cases are perfectly simple and easy to distinguish. In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided.</p>\n\n<p>But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains. But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness.</p>\n\n<p>No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful. Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure. To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it?</p>\n\n<p>But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure? On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain. These cases are perfectly simple and easy to distinguish.</p>\n\n<p>In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided. But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains.</p>\n\n<p>But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness. No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful. Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure.</p>\n\n<p>To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it? But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure? On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain.</p>\n\n<p>These cases are perfectly simple and easy to distinguish. In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided. But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains.</p>\n\n<p>But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness. No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful. Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure.</p>\n\n<p>To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it? But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure? On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain.</p>\n\n<p>These cases are perfectly simple and easy to distinguish. In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided. But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains.</p>\n\n<p>But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness. No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful. Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure.</p>\n\n<p>To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it? But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure? On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain.</p>\n\n<p>These cases are perfectly simple and easy to distinguish. In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided. But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains.</p>\n\n<p>But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness. No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful. Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure.</p>\n\n<p>To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it? But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure? On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain.</p>\n\n<p>These cases are perfectly simple and easy to distinguish. In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided. But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains.</p>\n\n<p>But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness. No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful. Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure.</p>\n\n<p>To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it? But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure? On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain.</p>\n\n<p>These cases are perfectly simple and easy to distinguish. In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided. But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains.</p>\n\n<p>But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness. No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful. Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure.</p>\n\n<p>To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it? But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure? On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain. These cases are perfectly simple and easy to distinguish.</p>\n\n<p>In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided. But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains.</p>\n\n<p>But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness. No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful. Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure.</p>\n\n<p>To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it? But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure? On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain. These cases are perfectly simple and easy to distinguish.</p>\n\n<p>In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided. But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains.</p>\n\n<p>But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness. No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful. Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure.</p>\n\n<p>To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it? But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure? On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain. These cases are perfectly simple and easy to distinguish.</p>\n\n<p>In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided. But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains. But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness.</p>\n\n<p>No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful. Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure. To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it? But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure?</p>\n\n<p>On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain. These cases are perfectly simple and easy to distinguish. In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided. But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted.</p>\n\n<p>The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains. But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness. No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful.</p>\n\n<p>Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure. To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it? But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure?</p>\n\n<p>On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain. These cases are perfectly simple and easy to distinguish. In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided. But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted.</p>\n\n<p>The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains. But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness. No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful. Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure.</p>\n\n<p>To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it? But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure? On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain. These cases are perfectly simple and easy to distinguish. In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided.</p>\n\n<p>But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains. But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness. No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful. Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure. To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it? But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure? On the</p>\n\n',1,'2017-05-18 13:50:19','2017-05-18 13:50:19'),(2,'Makan Ikan','<h1>Odio Mollis Turpis Dictumst</h1>\n\n<p><em>Ut</em> arcu tempor auctor pellentesque vitae lacinia potenti amet tellus sagittis molestie aliquam <strong>est</strong> mi facilisi amet, pretium <strong>torquent</strong> platea curabitur dolor pretium ultricies semper, phasellus commodo montes ut metus neque commodo platea a platea. Urna luctus cubilia faucibus class dolor nonummy orci dictumst amet ligula posuere hendrerit feugiat. Cursus dignissim ligula ultricies <em>leo</em> curae; nibh.</p>\n\n<p>Auctor sodales non euismod eros sodales rhoncus justo sit. Tristique primis <em>montes</em> condimentum <em>luctus</em> sagittis pretium Fringilla ligula sociosqu nibh.</p>\n\n<p>Mus Hymenaeos ultricies primis lacus pretium id. Ullamcorper dapibus magnis tellus maecenas eget purus magna maecenas sollicitudin sagittis convallis senectus maecenas <strong>sociis</strong> purus orci mollis ridiculus velit tristique nulla enim sodales cubilia eleifend.</p>\n\n<p><em>Risus</em> quam lacus sociosqu Malesuada. Mattis pretium etiam egestas. Interdum ultrices <em>luctus</em> luctus rutrum pellentesque amet, tincidunt.</p>\n\n<p>Accumsan at sociis dolor Fusce lacus lorem imperdiet tristique. Est sed. Sapien proin <em>in</em> vivamus sociosqu tempus. Risus. Feugiat. Et nam dapibus <strong>tristique</strong> donec id, mollis euismod. Lorem, nisi.</p>\n\n<p>Ut torquent curabitur blandit sociis nam sollicitudin tristique convallis aptent accumsan aliquam dictum imperdiet lacus imperdiet fermentum cum at urna neque sem curabitur facilisi hymenaeos dapibus. Diam vehicula. Urna hendrerit duis.</p>\n\n<p>Eget Convallis non senectus justo varius, sociis semper ullamcorper donec, molestie curae; metus ut sagittis. Mattis feugiat consectetuer inceptos ac.</p>\n\n<p>Natoque libero egestas vitae egestas aenean viverra nostra ornare. Per. <em>Aenean</em> cum elit ridiculus per.</p>\n\n<p>Massa hymenaeos Gravida parturient Cubilia laoreet, morbi duis interdum neque. Eu natoque elementum placerat sagittis Tincidunt facilisi sollicitudin tristique auctor donec arcu. Purus libero netus.</p>\n\n<p>Curae; erat eget fames sociosqu, egestas auctor est orci luctus. Nibh elit non aenean pulvinar elementum rutrum eleifend habitasse dictum dapibus velit urna cras. Massa elit ac, nascetur. <strong>Ut</strong> vestibulum montes. Lorem a.</p>\n\n<p>Ultricies varius. Dapibus nam sagittis porta augue per. Hac velit. Elementum penatibus. Condimentum velit. Amet integer litora tempor mus eros curabitur Libero.</p>\n\n<p>Dapibus senectus magna. Arcu, dignissim tempor nascetur lobortis conubia ornare netus vivamus. Nascetur ad habitasse elementum rutrum parturient sapien pretium penatibus. Posuere etiam massa nisi. Imperdiet et sem habitasse.</p>\n\n<p>Lorem lectus natoque fames molestie fermentum at leo. Cubilia, fringilla nibh libero tempus. <strong>Hac</strong> platea, volutpat Pretium ultrices dictum. Malesuada ut integer senectus eros phasellus congue nam sociosqu Suspendisse a, a commodo commodo scelerisque.</p>\n\n<p>Convallis sollicitudin non dui elit cubilia quis ullamcorper praesent tincidunt viverra mauris <em>integer</em> nostra gravida enim pellentesque faucibus sociosqu dapibus erat cursus.</p>\n\n<p>Interdum id cras mauris class Cubilia sagittis faucibus consectetuer Per ante lacus. Eget donec nec phasellus. Eu metus tempor suscipit eleifend. Fames at.</p>\n\n<p>Ullamcorper. Tempus convallis ultricies lorem mauris facilisi nisl accumsan. Velit lobortis varius enim Placerat quisque porttitor laoreet vestibulum.</p>\n\n<p>Dui nulla ultrices nostra primis. Parturient lacus nulla cras magna, consectetuer. Hendrerit curabitur hymenaeos dapibus cubilia <em>mauris</em> litora <strong>iaculis</strong> morbi montes.</p>\n\n<p>Orci morbi morbi vestibulum. Malesuada fermentum sed consectetuer quis iaculis lectus. Libero dignissim. Netus litora augue a. Magnis. Primis etiam tellus vestibulum aptent.</p>\n\n<p>Fermentum elit dolor cubilia suscipit, fermentum aliquet massa dapibus condimentum eu adipiscing <em>volutpat</em> at habitant inceptos tempus tempus habitasse fames praesent egestas fusce donec porttitor tempus eget viverra rutrum elementum commodo volutpat.</p>\n\n<p>Neque mattis cras cum. Accumsan primis, torquent nullam pulvinar, <strong>curae;</strong> egestas quis phasellus, hendrerit, blandit venenatis sed fermentum curabitur pede aliquet lectus egestas. Viverra.</p>\n\n<p>Placerat nullam duis felis. Curae; vel aliquet erat nisi laoreet justo nisl duis, tincidunt, mus inceptos. Nisi in conubia lorem neque eleifend potenti ante euismod rutrum orci. Lectus sem sit sagittis venenatis metus.</p>\n\n<p>Neque felis, ad. Nonummy augue vel aliquet scelerisque, urna at risus turpis in augue. Rutrum dis netus <em>sagittis</em> eleifend suscipit nam risus hymenaeos lobortis class lacus. Pede posuere et ultricies, lorem ullamcorper vivamus nec.</p>\n\n<p>Dictum nam lorem in sed fringilla magnis Rhoncus, facilisis mattis sagittis posuere. Porttitor inceptos amet et convallis feugiat iaculis.</p>\n\n<p>Ultrices <em>euismod</em> eros purus convallis iaculis felis pede a libero id <strong>leo</strong> tortor inceptos Lorem. Pulvinar, leo aliquet velit, aliquam rutrum.</p>\n\n<p>Sed imperdiet imperdiet. Tempor viverra pretium auctor eros pharetra tristique euismod felis. Natoque, phasellus tortor hac consequat molestie, commodo urna ipsum tempus litora cum accumsan potenti. Sed tortor nonummy. Nisi mauris.</p>\n\n<p>Molestie vitae. Sodales tellus pretium quis consequat malesuada. A eget lorem sem vehicula class fermentum justo euismod penatibus. Hendrerit augue. Et, natoque interdum fermentum leo dictum in vestibulum. Nibh.</p>\n\n<p>Est ipsum fringilla platea fames posuere, amet primis pellentesque potenti eget bibendum fermentum pede.</p>\n\n<p>Inceptos tortor consequat, iaculis vivamus tortor faucibus ligula habitasse tristique risus dignissim ridiculus <strong>aliquet</strong> aenean risus <em>felis</em> elementum tempor nonummy dapibus convallis tristique. Augue hendrerit.</p>\n\n<p>Habitant natoque nunc nisl dis nostra, bibendum magna bibendum urna eu fringilla tellus turpis gravida rutrum eros integer justo <strong>varius</strong> arcu sed. Ligula convallis ac. Fames metus fringilla lacinia Euismod quam <em>ornare</em> vehicula gravida accumsan.</p>\n\n<p>Purus scelerisque. Habitasse Accumsan varius dapibus. Hendrerit orci. Pede neque venenatis nulla porta enim facilisis <em>viverra</em> class, pulvinar molestie sociosqu penatibus urna quam aptent.</p>\n\n<p>Pede. Tempor ad consectetuer. Vitae gravida in nullam quam eu, quisque vehicula dis blandit. Congue. Tempor integer fringilla consectetuer ornare urna ut leo non cras netus aptent.</p>\n\n<p>Dictumst quam blandit <strong>metus</strong> tortor, condimentum ornare. Proin suscipit. Luctus semper nisl libero.</p>\n\n<p>Dolor dictumst. Curabitur ultricies pulvinar hymenaeos sociosqu sociosqu fringilla accumsan mauris justo <em>nulla</em> mauris Inceptos. Suspendisse sem.</p>\n\n<p>Habitasse morbi fermentum eu sollicitudin augue neque aenean integer parturient, nunc rhoncus enim parturient aptent.</p>\n\n<p>Dolor sapien vel dolor condimentum dapibus ultrices nulla nullam magnis vivamus porttitor sodales molestie dapibus, senectus imperdiet integer nostra.</p>\n\n<p>Pede phasellus risus id, nibh cursus, magna interdum Vestibulum praesent inceptos malesuada interdum platea nostra non velit facilisis mattis. Dui tempor volutpat <em>tempor</em> tellus viverra facilisi <em>quam</em> vivamus, nascetur.</p>\n\n<p>Fringilla mollis egestas, molestie pulvinar praesent. Lacus. Ac aliquet litora luctus ac nibh <strong>vulputate</strong> montes metus sagittis. Aenean etiam Litora lorem est tortor augue elit condimentum. Potenti.</p>\n\n<p>Eleifend amet orci vel enim eros pede ut fringilla. Iaculis laoreet justo lectus. Sodales. Sem a aenean aliquet nam mus sociis dapibus tempor tellus ligula commodo <em>magnis</em> fusce ultrices.</p>\n\n<p>Faucibus natoque cras ridiculus neque. Cum accumsan sit iaculis nam cras <strong>hymenaeos</strong> <strong>nam</strong> <em>fames</em> ultricies molestie <em>litora</em> habitasse risus vestibulum cursus quis cum.</p>\n\n<p>Venenatis ligula placerat mauris velit euismod, sociosqu libero ut laoreet felis. Suscipit. Aliquam Felis <strong>vulputate</strong> porta <strong>proin</strong> hymenaeos quam. Viverra molestie aenean, pharetra <em>phasellus</em> nunc sollicitudin lectus.</p>\n\n<p>Suscipit nullam ultrices congue. Mus mattis praesent eros lectus pede. Leo <em>dictum</em> ac gravida aliquet <strong>congue</strong> iaculis molestie habitasse mattis netus consectetuer. Quam. Amet.</p>\n\n<p>Ultricies amet scelerisque consequat consequat Arcu ornare. Feugiat vel varius laoreet nulla senectus.</p>\n\n<p>Quis <em>pharetra</em> lacus. Rutrum nascetur diam porttitor, nulla dictum cras turpis quam sociis per leo imperdiet commodo. Lacinia hendrerit, sed. Magnis.</p>\n\n<p>Tortor convallis aliquet, id mauris aliquam praesent pulvinar hendrerit velit quam, curabitur.</p>\n\n<p>Elementum justo sollicitudin accumsan hac tristique magna. Cras mauris cubilia platea, aenean dui. Nisi. Ullamcorper. Interdum tempor inceptos enim. Netus ridiculus euismod. Pellentesque. Viverra aenean.</p>\n\n<p>Curae; natoque. Aenean. <em>Molestie</em> eros potenti, nam ultricies curae; nec at nullam quis arcu in. Sit. Nullam tempus hymenaeos donec mollis quisque. Gravida consectetuer torquent. Pede, proin <strong>leo</strong> platea lobortis nam.</p>\n\n<p>Ullamcorper cras. Malesuada molestie pharetra. Odio duis luctus pharetra. Diam tempus ipsum vitae morbi.</p>\n\n<p>Vestibulum vivamus ipsum potenti et rhoncus Aptent risus tortor cum. Laoreet auctor inceptos curabitur fusce mollis lacus sollicitudin, ligula taciti integer. Nullam pulvinar, facilisi consectetuer lacinia hac vivamus <em>tempus</em> habitant.</p>\n\n<p>Pharetra gravida volutpat justo lacus urna penatibus <strong>hac</strong> dui. Per velit sagittis, phasellus nam aliquet ultrices imperdiet. Orci est mi <strong>imperdiet</strong> nec pede magna metus.</p>\n\n<p>Ut sit varius, hymenaeos nonummy Feugiat scelerisque scelerisque. Primis dis gravida magnis pretium, orci aptent hendrerit lectus torquent semper magna penatibus. Molestie <strong>praesent</strong> posuere sodales dolor fusce cursus euismod auctor dictum.</p>\n\n<p>Curabitur pulvinar platea eleifend turpis. Lacinia taciti taciti gravida accumsan. Massa suscipit.</p>\n\n<p>Mi ullamcorper aliquam praesent vitae sed nibh molestie dignissim, est. Diam velit integer non pulvinar nostra fusce molestie class vestibulum platea aenean vestibulum egestas tempus rutrum suscipit.</p>\n\n<p>Odio, curae; magnis. In pellentesque facilisis penatibus mi. Habitant ligula conubia senectus nascetur, ut.</p>\n\n<p><strong>Non</strong> condimentum porttitor sapien senectus suspendisse interdum fames quis. Interdum sociis egestas cras litora lorem. Nec. Posuere. Primis euismod litora odio fusce. Condimentum mus interdum. Nisl ullamcorper.</p>\n\n<p>Sollicitudin hymenaeos morbi. Nam aenean <em>in</em> commodo quisque curabitur platea molestie etiam tincidunt dictumst torquent Quis nam. Molestie litora augue. Iaculis nunc lectus euismod.</p>\n\n<p>Nullam a nunc consectetuer varius. Nonummy vivamus odio quis. Tempus. Accumsan tempor. Ac integer tempus litora mus justo curabitur pharetra donec vestibulum ullamcorper tincidunt potenti praesent.</p>\n\n<p><strong>Tellus</strong> tincidunt euismod quam sit ante adipiscing blandit magna sodales convallis. Felis purus mollis <strong>pretium</strong> iaculis pharetra quisque viverra est. Felis porta Eleifend suscipit nec. Rutrum amet nisi suspendisse ipsum platea platea.</p>\n\n<p>Sagittis magnis. Dis morbi fames <em>nascetur</em> libero fames. Congue pede <strong>dui</strong> sociosqu arcu viverra. Ipsum vitae sagittis euismod justo.</p>\n\n<p>Ante taciti dui fusce elementum sociosqu. Pellentesque imperdiet torquent euismod praesent at nunc turpis habitasse <em>gravida</em> nunc sagittis libero. Fames luctus. Cubilia penatibus suscipit ut cursus, sociosqu sociosqu vivamus phasellus. Eu.</p>\n\n<p>Conubia. Etiam sed parturient donec. Auctor, netus quisque habitasse mattis hac nullam sed varius taciti elementum vivamus. Facilisis tortor varius sociosqu pretium elementum hymenaeos felis. Ac iaculis cum accumsan litora aliquam cubilia sollicitudin class ad dictumst.</p>\n\n<h2>Justo Dolor Dui Hendrerit Elit Tincidunt Sagittis In</h2>\n<p>Consequat conubia. Nascetur lectus facilisi et lacus et nam rutrum arcu congue etiam.</p>\n\n<p><strong>Donec</strong> adipiscing dui, mauris sagittis neque convallis vulputate posuere. Volutpat primis etiam. Tortor praesent accumsan, morbi augue ut curabitur.</p>\n\n<p>Aptent hendrerit feugiat. Vehicula hymenaeos mi dictumst sociis duis. Ridiculus nonummy quisque ornare duis massa duis. Ornare.</p>\n\n<p>Felis neque, euismod fames metus. Ipsum. Magnis. Et suspendisse suspendisse porta nibh, at orci ut amet nonummy vitae Posuere dignissim, donec vestibulum facilisis felis eget aliquet tristique nascetur porttitor viverra fringilla. Semper nibh nisl amet maecenas.</p>\n\n<p>Nullam aliquam duis, ridiculus dignissim, fermentum diam ridiculus. Nam conubia vulputate adipiscing <strong>porta</strong> vulputate sociosqu sociis torquent hendrerit metus metus placerat ultrices justo lobortis. Phasellus phasellus hendrerit. Consectetuer ridiculus tincidunt.</p>\n\n<p>Conubia placerat <em>metus</em> velit blandit quis <em>hac</em> <strong>praesent</strong> <em>neque</em> ut gravida auctor lobortis pede proin pede. Libero libero morbi integer. Sociis aliquam magnis turpis mattis tristique class consectetuer laoreet, sociosqu.</p>\n\n<p>Commodo mollis Ullamcorper. Posuere sodales varius at pulvinar mollis erat velit eget est. Hymenaeos potenti facilisi nec accumsan. Porta leo.</p>\n\n<p>Consectetuer nunc a hymenaeos. Diam dui elit. Justo iaculis aliquet vivamus rutrum fames class facilisi per nostra, diam nonummy. Dapibus.</p>\n\n<p>Id tincidunt pede quis dignissim vehicula. Feugiat ridiculus fusce. Condimentum placerat ultricies Vulputate scelerisque bibendum lacus montes semper <em>rutrum</em> laoreet vulputate. Nec praesent fermentum. Sed <em>pellentesque</em> ac. Congue. Consequat.</p>\n\n<p>Morbi cum nulla faucibus dictumst suspendisse mus curabitur egestas sit. Faucibus tempor magnis interdum blandit adipiscing sit. Integer venenatis <strong>mus</strong> elementum.</p>\n\n<p>Litora blandit malesuada. Consectetuer inceptos fringilla leo quis. Laoreet sed sapien nulla malesuada. Diam elit tempus mauris. Mus platea luctus ullamcorper.</p>\n\n<p>In consectetuer erat praesent lobortis inceptos pretium hac ligula. Scelerisque mi in dolor vestibulum. Facilisis Urna donec scelerisque condimentum cras massa vestibulum. Ultricies libero, mus. Tortor.</p>\n\n<p>Habitant nascetur. <strong>Elit</strong> pede nullam venenatis, accumsan a. Dapibus diam dictumst. Curabitur condimentum. Massa dignissim vestibulum viverra nam, sed ullamcorper molestie penatibus pretium, suspendisse et sem Felis sem nonummy quis commodo auctor. Eu.</p>\n\n<p>Odio id maecenas. Et, consectetuer quis lobortis ante fames netus. Egestas ornare purus, <strong>senectus</strong> pede nonummy inceptos lectus nec orci, pretium sed.</p>\n\n<p>Parturient interdum, faucibus ac sollicitudin semper libero lacus mauris malesuada ridiculus eros viverra sapien odio rhoncus taciti ultrices et <strong>habitasse</strong> molestie proin <em>inceptos</em> ac rutrum dis consectetuer posuere.</p>\n\n<p>Facilisi vestibulum phasellus quis ante, dis class metus scelerisque vivamus natoque imperdiet justo ante a senectus vehicula.</p>\n\n<p>Odio consectetuer senectus. Ullamcorper dapibus duis mattis proin lobortis inceptos sagittis integer laoreet. Curabitur donec. Ipsum Litora nullam fames nunc a primis.</p>\n\n<p>Fames elit habitant <em>dolor</em> suscipit hendrerit consectetuer accumsan imperdiet viverra magnis mus. Ligula habitant pharetra hendrerit lacinia ante Facilisi consectetuer etiam enim lacus eros, quisque malesuada porta. Sed.</p>\n\n<p>Sodales magnis erat porttitor. Magna netus natoque taciti nostra hac. Vehicula vitae ligula fermentum suscipit. Suspendisse <em>non</em> libero magna per tellus mi adipiscing nascetur taciti.</p>\n\n<p>Urna, integer est tellus enim nam torquent magna penatibus dapibus tempor cubilia nascetur tristique nulla nulla.</p>\n\n<p>Neque laoreet, cubilia Phasellus vestibulum nam eget consequat porttitor ultrices Fames cum accumsan vel mi Lobortis sit vitae <em>vulputate</em> morbi eget.</p>\n\n<p>Hac felis fames facilisi viverra vitae proin cras diam primis molestie senectus nunc. Integer. <em>Mattis</em> volutpat. Augue pharetra arcu hac. Ullamcorper fringilla.</p>\n\n<p>Ornare dignissim nisi. Lacus mus. Conubia condimentum. Venenatis nisi erat erat <strong>augue</strong> tristique odio, magna praesent congue torquent. Nostra aenean dolor ridiculus sit penatibus sed penatibus. Pulvinar.</p>\n\n<p>Iaculis nulla posuere consequat aenean Potenti <em>consectetuer</em> potenti eleifend. Curae; massa taciti vivamus. Senectus sollicitudin montes morbi dolor suspendisse sollicitudin <em>facilisis</em> ultricies pede risus vivamus Ultrices primis ante ultrices mollis quis vel ornare mus cum, leo.</p>\n\n<p>Ipsum, egestas fusce urna cras aliquet <em>rhoncus</em> sociis sociis vestibulum dis quam fermentum placerat, neque hac. Ligula <em>potenti</em> natoque tellus, suspendisse sem ultricies justo <em>porttitor</em> nam. Aenean sociosqu at. Maecenas.</p>\n\n<p>Est vivamus ullamcorper nascetur. Sollicitudin. Sem luctus elementum <strong>vestibulum</strong> congue vehicula luctus, quis. Lorem. Nostra id urna taciti quis purus donec praesent arcu facilisi sociosqu diam. Neque tempor ultricies nunc sociis nullam, condimentum fames volutpat mollis.</p>\n\n<p>Fringilla cum accumsan facilisi vestibulum Curabitur suspendisse mi <em>mauris</em> nisi dictumst purus sagittis. Sit amet nec justo. Fermentum leo. Bibendum nisi pede hymenaeos sapien nunc dictumst. Velit.</p>\n\n<h2>Orci Id Duis Lobortis Nibh Conubia Senectus</h2>\n<p>Aliquam vestibulum. Taciti sem rhoncus sagittis magnis, pretium parturient mi sed vitae blandit auctor quam.</p>\n\n<p>Porta. Adipiscing vel mauris auctor. Elementum dis aenean hymenaeos mauris, nam. Placerat inceptos, ante <strong>dictum</strong> ac consequat molestie pharetra sem nulla interdum risus ad venenatis pede.</p>\n\n<p>Per habitant suspendisse cum nunc <em>mi</em> pede commodo class id pulvinar <em>cras</em> imperdiet gravida aliquam mus posuere nostra blandit posuere magna egestas fames non suspendisse. Commodo.</p>\n\n<p>Interdum ultrices, varius ante. Dapibus pulvinar. Laoreet aenean molestie eget vehicula rutrum ipsum justo fringilla tellus id cursus duis mollis vehicula auctor. Mattis bibendum <em>faucibus</em> nullam. Porta.</p>\n\n<p>Pede neque mollis. Per netus interdum mus eleifend <em>massa</em> aliquet etiam feugiat eget penatibus dapibus cras penatibus ac. Dictum elementum fermentum fermentum. In netus dictumst.</p>\n\n<p>Lacus habitant lobortis. Potenti. Vulputate enim habitasse, tellus <em>parturient</em> litora a orci sociis tellus. Vel cursus nec dolor. Orci lectus tristique augue ad, aenean fringilla volutpat natoque ante. Pretium hymenaeos ridiculus penatibus nisi. Curae;.</p>\n\n<p>Mus. Aenean potenti sit nisi, dui. Consequat. Porta pellentesque lorem, dignissim nibh Diam in pretium venenatis. Quisque molestie.</p>\n\n<p>Vitae felis cum non torquent. Condimentum magna vitae erat diam. Sed duis pharetra dictum a facilisi euismod nullam, dis, risus tellus hac aliquam.</p>\n\n<p>Tellus. Nunc <strong>neque</strong> proin libero <em>praesent</em> nisl torquent integer torquent feugiat urna metus taciti montes enim. Torquent Laoreet, suscipit magna litora cras mattis suspendisse per.</p>\n\n<p>Diam et. Dui purus congue <strong>a</strong> senectus arcu adipiscing netus hendrerit ridiculus cubilia non. Viverra morbi augue luctus ipsum scelerisque habitasse eleifend egestas <em>tempor</em> diam sociosqu imperdiet penatibus <strong>vehicula</strong> placerat eu.</p>\n\n<p>Fusce leo ligula scelerisque malesuada purus adipiscing vehicula praesent, lorem fames massa adipiscing condimentum magna rhoncus purus mattis sem, fringilla natoque potenti pharetra eu nisi est.</p>\n\n<p>Metus mauris luctus sit fermentum cras facilisis. Dapibus augue lobortis sem fames sed quisque sollicitudin risus etiam. Lacus. Leo. Congue eros <em>nam</em> ultrices feugiat. Ante condimentum mus. <em>Curabitur</em> porttitor. Ante varius nullam ullamcorper <strong>gravida</strong> egestas.</p>\n\n<p>Iaculis hymenaeos Phasellus nulla at primis Dis commodo semper ornare turpis amet nulla. Morbi Consectetuer cum a facilisi metus quam interdum imperdiet netus ante urna.</p>',1,'2017-05-18 13:50:19','2017-05-18 13:50:19'),(3,'Makan Sayur','Lorem ipsum dolor sit amet, consectetur adipiscing elit. Morbi id odio tortor. Pellentesque in efficitur velit. Aenean nec iaculis turpis. Ut eget lorem et velit lacinia mollis finibus vel felis. Sed ut elit leo. Curabitur eu ultrices ligula. Integer pulvinar nisl vitae lacinia porttitor. Maecenas mollis lacus quis turpis semper consequat.\n\nNullam sit amet augue non erat consectetur faucibus vitae eu nisi. Suspendisse non consectetur justo. Duis sed feugiat risus. Pellentesque euismod tellus pellentesque quam condimentum mollis. Phasellus est metus, tempus sit amet viverra tincidunt, lacinia at est. Aenean quis lacus nunc. Suspendisse accumsan nisl sit amet vestibulum molestie. Praesent quis justo congue, condimentum odio non, sollicitudin diam. Sed aliquam risus et urna pulvinar imperdiet. Praesent ac est velit. Sed sit amet volutpat enim, vehicula posuere diam.\n\nNunc sodales, arcu sed euismod sollicitudin, risus nisl fringilla nibh, nec venenatis dolor mi et lorem. Donec dapibus tempus porttitor. Suspendisse et tincidunt dolor. Suspendisse rhoncus faucibus tortor, in condimentum lacus gravida ac. Mauris eleifend blandit erat in interdum. Proin elementum nisi posuere quam scelerisque laoreet. Sed rutrum urna ante, vitae molestie diam lacinia a. In pretium mauris quam. Praesent vehicula odio dui, at sagittis orci bibendum quis.\n\nMauris a euismod ligula. Pellentesque sollicitudin vitae ante eget commodo. Etiam quis interdum lorem. Lorem ipsum dolor sit amet, consectetur adipiscing elit. Praesent a sapien eros. Nam varius quis lorem id ultrices. Etiam posuere tortor nec aliquam convallis. Praesent id tincidunt velit. Cras commodo ex a orci pellentesque bibendum. Duis at ex eu diam tincidunt placerat. Duis odio ante, rutrum ac laoreet eget, fringilla id metus. Vivamus non nisi vestibulum, lacinia elit in, consequat dui. Proin mattis felis metus, ut dignissim tellus finibus eget. Curabitur auctor leo mattis est blandit, eu consectetur sem maximus.\n\nClass aptent taciti sociosqu ad litora torquent per conubia nostra, per inceptos himenaeos. Cras imperdiet magna lacus, vel luctus quam pulvinar a. In massa turpis, vestibulum vel tortor laoreet, malesuada porttitor nisi. Sed faucibus vulputate nunc, ac semper dui auctor in. Nunc convallis efficitur malesuada. Nulla facilisi. In et tristique est, vel aliquam massa. Donec iaculis, urna rhoncus pharetra tincidunt, arcu risus consequat lacus, sed dapibus nisi elit luctus tellus. Phasellus pellentesque elit ac dolor pellentesque, et imperdiet orci ultrices. Fusce rutrum ex id nibh lobortis fringilla. Integer urna urna, lobortis quis accumsan quis, porta in ex. Etiam vel ipsum mauris.\n\nPellentesque sit amet euismod sem. Integer ultricies bibendum commodo. Ut rutrum gravida ultrices. Maecenas iaculis eleifend odio, sit amet finibus erat accumsan sit amet. Mauris vel nisl ullamcorper, eleifend libero vel, sollicitudin arcu. Pellentesque ac turpis at felis fermentum efficitur. Nulla sed nibh in sapien blandit tincidunt in ut risus. Sed euismod, mauris non dictum tempor, nunc dolor venenatis nisi, id posuere lorem orci sed purus. In tempor, mauris nec tincidunt pellentesque, dui massa interdum sem, in malesuada tortor tellus at sem. Proin cursus aliquet nibh, at faucibus nulla fringilla eget. Vestibulum ut aliquet velit. Donec feugiat urna et laoreet lobortis. Cras pharetra aliquam velit, nec dictum mauris iaculis non.\n\nCurabitur tempus mollis ultrices. Cras augue neque, commodo eu aliquet et, mattis faucibus magna. Sed accumsan dignissim enim sed commodo. Fusce vel libero efficitur, tempus massa eu, egestas lorem. Duis enim risus, ullamcorper et erat eget, convallis bibendum enim. Maecenas nec eros consectetur, elementum diam sed, consectetur ante. Quisque eu volutpat dolor, sit amet tincidunt elit. Suspendisse at neque sed dui ultricies consequat sit amet a ipsum. Integer iaculis malesuada ante, nec auctor purus pellentesque a. Suspendisse scelerisque aliquet purus, sit amet condimentum ipsum mollis in. Cras luctus eros vulputate mi mattis, sit amet pulvinar lectus dapibus. Cras sit amet velit nec nisl interdum iaculis. Orci varius natoque penatibus et magnis dis parturient montes, nascetur ridiculus mus. Sed hendrerit, libero at varius eleifend, ante leo euismod mauris, sed placerat velit massa id ante. Aenean porta est diam, sit amet luctus nisl interdum in. Praesent porttitor maximus orci, vel finibus nisl rutrum eu.\n\nDuis sit amet turpis at odio ultrices blandit non sed augue. Aenean porta tortor ac arcu condimentum, sed luctus tortor facilisis. Maecenas tellus magna, porttitor vitae egestas varius, vestibulum sit amet eros. Nulla et ante tincidunt, pulvinar leo ac, pharetra est. Quisque ex sapien, faucibus non commodo sit amet, elementum in dui. Maecenas varius malesuada ligula, at varius massa bibendum eget. Morbi ultrices magna libero, nec gravida justo gravida eu. Vestibulum nulla tortor, aliquam et porta at, venenatis molestie erat. Proin ornare metus eu orci luctus bibendum.\n\nSed sed quam lectus. Sed sed facilisis mi. Lorem ipsum dolor sit amet, consectetur adipiscing elit. Phasellus ac ante mollis sapien faucibus consectetur. In ut fermentum magna. Praesent sit amet lacus in risus viverra sodales. Nunc pulvinar, leo ac cursus vulputate, tortor ante sollicitudin risus, ac mattis lorem purus ut nunc. Mauris erat libero, sollicitudin vulputate volutpat at, rhoncus a risus. Integer cursus quam tortor, nec molestie nisl porttitor et. Fusce ipsum elit, laoreet in gravida eu, facilisis ac magna. Praesent augue lacus, aliquet sit amet nisl eget, faucibus placerat dui. Pellentesque mollis erat nec neque fermentum, at efficitur erat convallis.\n\nMauris consequat consectetur lacus sit amet gravida. In et malesuada nulla. Phasellus sed leo maximus, vulputate diam sed, accumsan nisl. Fusce at quam tortor. Vestibulum at odio tempor orci imperdiet pharetra a ut odio. Proin faucibus urna sit amet fringilla cursus. Integer auctor augue mollis vestibulum ornare. Nulla iaculis, felis malesuada finibus suscipit, augue nisi egestas nibh, eu porttitor sapien eros nec dolor. Morbi sodales pretium nisl, a condimentum orci tincidunt vel. Phasellus viverra ligula id orci hendrerit, eget varius nunc dapibus.\n\nUt non lorem fermentum, finibus tortor non, ullamcorper elit. Aenean sagittis odio ut massa malesuada, pretium vulputate risus finibus. Integer ullamcorper justo id viverra consectetur. Praesent finibus, urna rhoncus facilisis mollis, nibh augue commodo mauris, et suscipit odio enim non lorem. Nulla aliquet, lacus ut eleifend tempor, ex eros imperdiet augue, vel vestibulum ex nibh et ex. Ut faucibus mattis sem, quis cursus dui scelerisque et. Donec nibh leo, condimentum ut egestas id, tempus eu lectus. Etiam egestas vel libero vitae commodo.\n\nNunc dapibus scelerisque libero hendrerit dictum. Nulla pretium ac est pellentesque luctus. Mauris pharetra mi ipsum, quis ornare ligula posuere eu. Quisque at orci vitae dui semper sollicitudin id non eros. Quisque dignissim ligula in neque consectetur sagittis quis a ligula. Curabitur purus nisl, egestas auctor varius id, blandit ac odio. In hac habitasse platea dictumst. Proin ullamcorper orci in nisi imperdiet, non cursus quam condimentum. Fusce accumsan, tortor eu imperdiet viverra, mauris neque condimentum orci, ut viverra dui metus venenatis enim. Integer pulvinar aliquet erat fermentum luctus. Sed tempor elit et erat efficitur molestie. Sed et egestas mauris.\n\nOrci varius natoque penatibus et magnis dis parturient montes, nascetur ridiculus mus. Nulla lacus augue, semper non dictum sagittis, vulputate et augue. Sed sit amet dolor ante. Praesent porta urna ligula, vitae gravida ante lacinia eget. Nam dui elit, vulputate vitae interdum id, pharetra ac justo. Quisque dictum malesuada turpis, in mattis nulla tincidunt sit amet. Sed porta scelerisque dui, sit amet imperdiet lacus accumsan sit amet.\n\nNunc laoreet metus metus, quis vestibulum lacus rhoncus ac. Etiam vel mauris mi. Duis sed ante ultrices, maximus nibh non, maximus nisi. Curabitur magna sapien, volutpat non nisi a, vulputate lacinia magna. Nulla pharetra mollis ultricies. Duis vitae interdum neque, sed molestie massa. In et arcu vehicula, hendrerit magna in, blandit turpis. Sed non mi in augue eleifend mollis. Sed lacinia, lectus at bibendum vehicula, nisi leo mattis diam, in imperdiet justo neque quis magna. Nam rhoncus sed est quis convallis. Nunc feugiat eros dignissim nisi ultricies scelerisque. Etiam justo quam, elementum non vulputate ac, facilisis a est.\n\nVivamus porta pulvinar sapien, vulputate accumsan lorem varius eleifend. Sed ut aliquet odio. Proin ultricies turpis nibh, sit amet lobortis lectus tempus eget. Aenean laoreet ullamcorper nisl, ut ultricies risus interdum blandit. Nunc a mollis neque. In rutrum mi et velit tempor, vitae ullamcorper nulla scelerisque. Quisque lacinia orci at sem ultrices, non volutpat metus lobortis. Sed facilisis venenatis urna tempus facilisis. Aliquam tincidunt tincidunt ligula. Cras lorem ipsum, dignissim quis felis ac, sodales tempor justo. Duis rhoncus pellentesque sem laoreet consectetur. Mauris vitae egestas eros. Suspendisse laoreet, diam eu facilisis suscipit, mi ante sollicitudin tellus, id mattis nibh est eget felis. Integer id ligula vestibulum, tempor lorem sit amet, finibus ligula.\n\nPraesent pretium neque diam, at euismod turpis mattis sed. Cras interdum enim quis nulla imperdiet rutrum. Fusce vitae tempus arcu. Mauris sed euismod velit, sed molestie augue. Suspendisse finibus viverra ante, sit amet tristique ante porta sit amet. Proin iaculis nunc et lorem eleifend, ut congue est finibus. Nunc efficitur, ex sed auctor congue, elit lacus volutpat mi, in pellentesque diam urna imperdiet tellus. Pellentesque molestie, dui eget vulputate posuere, odio risus venenatis justo, in sodales ligula erat sit amet augue. Sed non enim tellus. Maecenas vitae risus sed est aliquam vestibulum eget et tellus. Nunc pharetra vehicula risus vitae tincidunt. Donec nec viverra justo. Donec id augue dui.\n\nIn vulputate mauris id nisl finibus mattis. Nunc quis nibh leo. Sed sed ornare odio. Quisque dapibus eros at tellus ornare, ut semper risus iaculis. Mauris id sollicitudin massa. Nam ultricies nunc et elit pellentesque rutrum. Sed id faucibus dolor, eget vehicula nibh. Donec erat risus, maximus eu neque in, pulvinar lobortis lacus. Cras iaculis purus non dui auctor dictum. Proin et neque pretium, mattis velit a, hendrerit ex. Duis at tincidunt nisi, nec placerat diam. Vivamus suscipit vitae lacus eu viverra. Nunc orci magna, laoreet et elit sit amet, iaculis ullamcorper nibh. Sed finibus nibh consequat tincidunt tincidunt. Mauris a est ipsum. Mauris ut dui sed orci molestie maximus.\n\nInteger maximus vestibulum lacus, sed fermentum tellus condimentum vitae. Aliquam erat volutpat. Praesent lacus lectus, accumsan a varius varius, lacinia quis ligula. Aenean at odio ut lorem placerat accumsan. Quisque volutpat justo sed elit rutrum, quis pretium ante lacinia. Morbi non libero tellus. Duis risus mauris, iaculis finibus nibh id, posuere rhoncus urna. Sed ut velit hendrerit, fringilla ipsum porta, eleifend sapien. Curabitur aliquam tellus sit amet elit suscipit vulputate. Nam gravida magna enim, vitae blandit ipsum porttitor quis. Sed suscipit massa sed purus blandit, sed congue magna pharetra. Proin mattis massa sit amet vehicula porttitor. Praesent eu aliquet massa. Ut ultricies consectetur velit, in egestas eros accumsan at.\n\nLorem ipsum dolor sit amet, consectetur adipiscing elit. Fusce a erat a urna scelerisque vulputate. Pellentesque porttitor magna eu placerat pharetra. Integer sit amet tortor vel augue ullamcorper egestas in at dui. Praesent luctus nibh porta nibh interdum, ut convallis lectus placerat. Pellentesque pretium tellus at nulla consectetur, eu ultrices tortor aliquet. Proin et risus porttitor, venenatis massa in, pretium velit. Vestibulum mattis diam a est imperdiet, ac sollicitudin arcu laoreet. Nullam posuere, nunc eu mattis porttitor, dolor enim rutrum felis, non placerat sem metus sit amet eros. Vestibulum ante ipsum primis in faucibus orci luctus et ultrices posuere cubilia Curae;\n\nMauris efficitur nisl massa, ut maximus massa consectetur non. Aliquam sit amet venenatis lorem, vitae blandit turpis. Proin sed elit risus. Sed aliquam odio nibh, nec suscipit odio porttitor in. Sed dolor quam, suscipit vitae purus eget, egestas pharetra mauris. Aliquam egestas augue ante. Cras eu magna quam. Aliquam commodo elit in scelerisque tristique. Morbi non elit pharetra, tincidunt tortor cursus, suscipit eros. Donec eget dolor massa. Pellentesque volutpat non lorem eu suscipit. Morbi a tincidunt velit. Cras id placerat nunc, nec elementum quam. Nam sit amet elementum nunc, sit amet dictum felis.\n\nNam ullamcorper consequat sollicitudin. Morbi a ligula nec ex feugiat rhoncus et eu odio. Nunc vitae elit eget sapien bibendum convallis. Quisque a dui eget dolor vulputate vulputate. In semper elit quis diam venenatis dapibus. Aliquam sollicitudin porta justo, vel suscipit arcu hendrerit sit amet. Nunc tincidunt nisi vitae est imperdiet gravida.\n\nDuis in varius nisl, mattis aliquet velit. Aenean ullamcorper, quam eu suscipit gravida, lorem magna consectetur dolor, semper vulputate nibh neque non est. Morbi posuere lectus vel condimentum feugiat. Vivamus eu nibh nec odio dapibus scelerisque. Quisque eleifend purus sed justo viverra, sed ullamcorper leo gravida. Suspendisse condimentum, lacus at mattis eleifend, nunc eros tincidunt metus, semper interdum tellus ex vitae mauris. Etiam a ex nec turpis porttitor vehicula ut non purus. Suspendisse quis tortor a elit lobortis interdum vel sodales leo. Proin efficitur, ex et tempor tincidunt, ante odio aliquet mauris, at vehicula diam velit nec turpis. Fusce at orci et massa consectetur posuere quis non magna.\n\nAliquam dolor enim, hendrerit at enim vel, hendrerit pretium lorem. Donec commodo mauris nec justo commodo, at mattis mi tempus. Sed velit dui, dignissim pulvinar faucibus ac, tincidunt in leo. Nulla sed dignissim nisl. Mauris tempor iaculis ipsum, at varius neque tempor at. Morbi ac bibendum est. Proin eu commodo risus, et tempus risus. Nullam posuere lorem non venenatis vestibulum. Sed in interdum orci, id volutpat lorem. Quisque non luctus felis. Nulla tincidunt consequat turpis eget semper. Aenean vulputate urna non justo luctus rhoncus. Integer auctor consectetur erat, nec molestie est dignissim quis. Pellentesque semper arcu et mi posuere ornare. Vestibulum ante ipsum primis in faucibus orci luctus et ultrices posuere cubilia Curae; Sed tincidunt ipsum turpis.\n\nMaecenas dignissim tellus nulla, ac rutrum tortor rhoncus quis. Fusce eu accumsan justo. Pellentesque lacinia, velit sodales aliquet malesuada, magna ipsum mattis risus, eget mattis lorem nibh vel ligula. Donec suscipit, tortor et placerat dictum, erat nunc vestibulum nisl, ac luctus lectus magna ut nulla. Suspendisse sed libero scelerisque, dictum ex non, scelerisque metus. Cras tristique et velit sit amet consectetur. Donec eget mi imperdiet, faucibus eros ut, ornare arcu. Integer ut felis ut ante eleifend lobortis. Donec id nunc placerat, finibus nunc a, lobortis nulla. Nam ullamcorper, diam sit amet pellentesque vulputate, magna mauris ullamcorper ex, eu tempus orci sapien vitae tortor. Etiam placerat sapien et felis convallis, a imperdiet massa euismod. Suspendisse tempus elit quis hendrerit ornare. Sed non facilisis metus. Donec hendrerit, purus at porta commodo, libero orci pellentesque orci, at vehicula elit sapien sit amet arcu.\n\nNulla bibendum sapien justo, eleifend placerat ante ornare vel. Quisque in odio eget justo varius suscipit. Vivamus quis viverra diam. Maecenas et pulvinar magna. Nulla eu quam quam. Nulla tincidunt porta pharetra. Aenean laoreet leo eget pulvinar blandit. Praesent ornare eu massa eget rutrum. Aenean scelerisque dolor ut lectus porttitor interdum. Pellentesque lobortis tortor vitae ante sollicitudin, sed lobortis nibh consectetur.\n\nInterdum et malesuada fames ac ante ipsum primis in faucibus. Lorem ipsum dolor sit amet, consectetur adipiscing elit. Cras condimentum vestibulum nisl, nec mollis sem interdum quis. Ut at neque lobortis, porta metus sit amet, convallis est. Nullam vitae dapibus velit. In ac nunc congue, maximus nibh ut, feugiat ex. Vestibulum ante ipsum primis in faucibus orci luctus et ultrices posuere cubilia Curae; Aliquam venenatis arcu vitae porttitor aliquet. Ut vitae dolor ligula. Sed sit amet justo vitae enim tincidunt tempor eu ac ligula.\n\nAenean molestie lacus eleifend nisi aliquam, a rhoncus lorem fringilla. Maecenas vehicula risus vel leo gravida, ac ullamcorper ligula mattis. Sed eu dui id sapien sodales lobortis. Donec fermentum eros turpis, interdum sollicitudin massa consectetur eget. Fusce volutpat orci fermentum lectus venenatis pharetra. Cras pellentesque, nulla at suscipit sodales, urna neque dapibus lectus, a consectetur nulla odio id mauris. Proin ac magna vel est imperdiet blandit quis non metus. Aenean a mi metus. Integer vel arcu eu nibh interdum vestibulum. Etiam placerat rhoncus leo, sed ullamcorper turpis efficitur vel. Pellentesque justo ante, consequat eget tempus a, rutrum sit amet purus. In pharetra lobortis sapien.\n\nProin vitae enim gravida, condimentum ipsum non, iaculis purus. Vivamus sollicitudin est orci, nec aliquet est lacinia porttitor. Phasellus varius tortor id suscipit tincidunt. Integer eleifend risus lacus, ut interdum turpis dignissim et. Curabitur ac posuere erat, at tincidunt lectus. Quisque sollicitudin leo quis tortor venenatis volutpat. Vestibulum sagittis ex mauris, feugiat vehicula justo aliquet vitae. Fusce nec urna posuere, feugiat velit sit amet, semper risus. Morbi quam magna, ultricies eget leo sit amet, dignissim auctor leo. Proin ullamcorper sollicitudin libero a placerat. Maecenas iaculis justo ac placerat lobortis. Integer vel lacus ac felis hendrerit volutpat.\n\nEtiam dolor diam, aliquam sed rhoncus quis, tincidunt nec erat. Morbi at nisl at sem egestas semper. Nulla facilisi. Sed vehicula velit urna, vel congue metus tristique nec. Quisque vel tellus accumsan, elementum dui ut, scelerisque erat. Aenean ac velit eget nulla venenatis iaculis ac non felis. Lorem ipsum dolor sit amet, consectetur adipiscing elit. Etiam blandit rutrum ipsum eu placerat. In aliquet, libero vel porta pulvinar, tortor elit bibendum nibh, ut mollis dolor lectus at nibh. Suspendisse quis dictum quam.\n\nSuspendisse potenti. Sed facilisis at turpis ut eleifend. Ut eget malesuada ante, in condimentum dolor. Pellentesque pellentesque vestibulum hendrerit. Sed eros purus, eleifend at aliquam ut, semper eu mi. Nullam mollis lorem ac posuere pharetra. Aliquam erat volutpat. Vestibulum imperdiet nisi ac egestas luctus. Quisque dapibus lacinia nisl. Donec vel quam mi. Maecenas nec quam nec ligula placerat porttitor in at arcu.\n\nUt rutrum nisi ut pharetra interdum. Praesent in ipsum rutrum, convallis augue eget, luctus nibh. Nulla fringilla feugiat arcu, at mattis nibh fermentum a. Fusce eu mollis lectus. Proin pretium dolor in vestibulum pulvinar. Integer sed lacus id turpis volutpat commodo id eu nisl. Mauris sit amet elit purus. Sed maximus gravida est sit amet tempor. Nunc elementum erat et tortor interdum, at auctor libero commodo. Phasellus tempus magna risus, eu feugiat neque ultrices eu. Etiam maximus tempus libero vel feugiat. In ut pulvinar urna, vel placerat dui.\n\nSed mollis diam eget pulvinar vulputate. Proin posuere ligula at sem vestibulum maximus. Sed pellentesque maximus pellentesque. Pellentesque habitant morbi tristique senectus et netus et malesuada fames ac turpis egestas. Maecenas a vehicula ligula. Suspendisse in faucibus nunc. Vivamus euismod risus a erat tincidunt, eget maximus erat faucibus. Quisque sed nunc a elit ultrices auctor. Nullam rhoncus ligula vulputate venenatis viverra. Nam et velit justo.\n\nAliquam dignissim mauris a neque fermentum, ac eleifend est sodales. Donec eleifend magna in magna ultricies rutrum. Fusce porta scelerisque laoreet. Morbi ac facilisis lectus, id interdum purus. Nam vulputate posuere ultricies. Quisque malesuada nisl sed ante viverra tincidunt. Phasellus nec tortor quis est fermentum porta nec eu ipsum. Curabitur lacinia magna et malesuada imperdiet. Pellentesque enim nulla, consectetur eu tellus vitae, rhoncus suscipit velit.\n\nCras tristique magna a ultrices convallis. Mauris id mi ac dui hendrerit iaculis. Etiam consequat mauris at dui commodo viverra. Curabitur bibendum metus arcu, quis pretium arcu rutrum sed. In ultrices nisl est, ultricies hendrerit libero maximus vel. Curabitur varius eleifend cursus. Praesent convallis, enim non mollis hendrerit, diam felis tristique dui, ac semper massa dui et tellus. Aenean non nisi ultricies, tempus lectus in, auctor ante.\n\nAenean gravida, ex ultricies vestibulum fermentum, urna ante posuere dolor, et venenatis ante ex eget augue. Integer elit orci, porta sit amet interdum id, vestibulum at ipsum. Morbi efficitur feugiat venenatis. Sed felis ligula, dapibus eu enim a, auctor sagittis sapien. Ut semper, nunc et lobortis sagittis, sem felis fringilla leo, vel finibus libero erat sed eros. Duis semper sapien egestas, dictum eros quis, tristique felis. Maecenas eget nisi id arcu mattis dictum rutrum eget risus. Aliquam sodales congue augue vel imperdiet. Integer a feugiat magna. Nunc ultricies arcu mi, quis elementum erat dignissim ut. Pellentesque interdum nunc eget nisl fringilla, a vulputate nisi finibus. Quisque ac maximus erat. Quisque eget lorem non massa accumsan pulvinar. Pellentesque quis tristique leo, at euismod est. Phasellus congue a libero vel condimentum.\n\nProin dolor ex, vehicula vulputate odio ut, tincidunt ultricies lorem. Quisque et ante pellentesque, convallis ante et, sagittis mauris. Aliquam eu consectetur quam. Donec at fringilla ligula, ut tristique quam. Integer accumsan dapibus iaculis. Nunc eu libero mi. Phasellus fermentum vitae leo a mollis. Quisque fringilla molestie sapien vel tempus. Pellentesque faucibus diam non justo aliquam, vitae scelerisque enim facilisis. Morbi vel porta ligula. Aenean sodales augue vitae lacus luctus, quis pharetra sapien vestibulum. Pellentesque lobortis lectus non leo pharetra, eget iaculis urna commodo. Morbi interdum lectus id hendrerit tempus.\n\nNulla vel viverra erat. Curabitur pulvinar eleifend nisi, in facilisis risus facilisis nec. Maecenas sit amet urna lacinia, bibendum est a, tristique erat. Donec maximus mi id pretium tristique. Donec massa libero, ultrices in consectetur non, tempus porttitor eros. Donec pretium velit et nisl egestas venenatis. Donec sagittis fringilla ornare. Phasellus pulvinar elementum lacus venenatis lobortis. Fusce venenatis lacinia elit. Duis eget fermentum felis, a molestie lorem. Suspendisse sed purus vulputate, molestie mauris a, rutrum mauris.\n\nNunc vitae tincidunt massa. Curabitur condimentum enim vel elementum viverra. Aenean faucibus non leo et volutpat. Maecenas sed efficitur metus. Proin bibendum viverra nibh, in luctus tortor sagittis vel. Nullam ornare risus et ullamcorper malesuada. Sed id laoreet metus, ut vulputate erat. Nulla non mollis nunc. Morbi ultrices pretium lectus non semper. Suspendisse ultricies lacus tortor, eget pharetra diam pharetra vitae. Fusce neque ante, pulvinar ac purus eget, semper bibendum eros. Vivamus ut dui iaculis, ornare dolor id, blandit leo. Cras ac venenatis lorem. Duis egestas ultrices blandit.\n\nPraesent vel egestas libero, non scelerisque sapien. Mauris commodo turpis quis tempus convallis. Proin dignissim, nulla at auctor tempor, sem tortor interdum neque, a consectetur massa metus sit amet leo. Nullam ut gravida mi. Donec venenatis neque ut lectus gravida hendrerit. Sed posuere purus volutpat sem vehicula vestibulum. Integer accumsan leo ut rutrum sagittis. In interdum purus eu lacus vestibulum dignissim. Duis id semper erat.\n\nNulla eu molestie elit, a ultricies mi. Sed fermentum libero mi, quis lobortis ante vulputate quis. Suspendisse potenti. Donec suscipit quam sit amet accumsan luctus. Vestibulum ante ipsum primis in faucibus orci luctus et ultrices posuere cubilia Curae; Aenean lectus dui, sagittis non elementum non, maximus non justo. In congue vel libero sed maximus. Duis venenatis velit mauris, vitae lacinia nisl accumsan quis. Fusce ultrices massa vel orci aliquam elementum. Sed efficitur pretium lacus. Suspendisse est ex, tempor sit amet odio sit amet, suscipit luctus tellus. Integer venenatis nisi varius dui posuere, ac consequat ipsum euismod.\n\nProin posuere velit mi, sed mollis libero eleifend in. In ultricies facilisis mattis. Donec eu condimentum sem. Vestibulum non finibus elit. Pellentesque ut urna a metus fringilla aliquam. Nullam mauris mauris, molestie sit amet turpis vel, pellentesque egestas tellus. Morbi justo nulla, varius quis scelerisque in, tempor at urna.\n\nNam id erat tellus. Nam sapien metus, feugiat at rutrum ac, convallis vitae sem. Curabitur eleifend placerat neque. Donec vitae tortor urna. In nibh enim, blandit ac volutpat id, sodales nec tortor. Ut condimentum sollicitudin lorem et tempus. Nunc ut lorem vitae dolor ornare bibendum. Sed at ante nisi. Curabitur bibendum erat vel diam cursus dapibus. Sed commodo leo ac nulla hendrerit ullamcorper.\n\nIn hac habitasse platea dictumst. Vivamus ultrices ac ex a accumsan. Aliquam pellentesque euismod magna eu aliquet. Duis cursus felis sed lectus scelerisque laoreet. Proin eleifend felis tempus, iaculis nunc in, aliquam magna. Etiam ornare vitae ex sit amet feugiat. Interdum et malesuada fames ac ante ipsum primis in faucibus. Pellentesque ut erat nunc. Fusce ac dictum nisi. Morbi eget sapien in lacus maximus faucibus hendrerit a purus. Sed non ullamcorper odio. Aliquam non varius nisl. Donec rutrum, lorem sed suscipit accumsan, metus augue convallis nisi, eget blandit libero turpis viverra mi. Phasellus a bibendum sapien.\n\nAliquam id elementum diam, et tristique elit. Integer auctor, nibh eget maximus ultrices, ex massa sollicitudin ipsum, quis accumsan eros turpis ac libero. Mauris id rhoncus tellus, ac efficitur nulla. Cras lectus ante, bibendum ut accumsan ut, sodales id erat. Sed vitae magna at nibh rutrum commodo. Cras efficitur convallis eros et dignissim. Vestibulum et auctor neque, in condimentum augue. Praesent luctus fermentum odio, et luctus ex gravida id. Nulla pretium vehicula leo id imperdiet. Nulla lacinia porta purus, sit amet efficitur nibh auctor vitae. Donec gravida, mi vitae dapibus iaculis, risus sem cursus nibh, ac semper diam odio vitae arcu.\n\nAenean nec sem commodo, condimentum erat quis, pretium sem. Vestibulum fermentum eget ante at varius. Donec pharetra ullamcorper neque, a aliquet ligula facilisis vitae. Donec ut porttitor sem. Phasellus pellentesque et velit sit amet viverra. Vivamus ac felis viverra, efficitur lacus eu, ornare tortor. Sed vitae venenatis magna. Donec blandit condimentum eros ut placerat. Vestibulum sit amet viverra neque. Curabitur mollis id mauris maximus elementum.\n\nIn pulvinar lacus vitae dui laoreet, condimentum hendrerit ante rutrum. Donec tristique nec neque a eleifend. Curabitur aliquet sodales nulla nec vehicula. Nullam ac cursus massa, quis pellentesque justo. Nullam feugiat mollis varius. Morbi imperdiet sodales lorem et malesuada. Nam sit amet tellus imperdiet, consequat mauris in, lobortis libero. Etiam semper mauris et erat gravida, et iaculis felis tincidunt. Morbi urna augue, maximus id fermentum sit amet, lacinia id ante. Vestibulum eu est sit amet augue aliquet laoreet.\n\nNam placerat eu massa eget aliquet. Donec ultrices et augue non posuere. Praesent lobortis hendrerit felis, in vestibulum felis feugiat eget. Integer vitae vulputate lorem. Maecenas finibus purus non elit porttitor, ut finibus turpis fringilla. Duis metus eros, iaculis sed laoreet sed, sollicitudin a arcu. Vestibulum scelerisque sapien urna, eget facilisis lacus placerat eu. Phasellus pellentesque quam quis nisl pharetra, at ornare mi convallis. Ut quis quam molestie, pulvinar ante ac, molestie augue. Proin quis quam quis nunc imperdiet porta. Donec bibendum tempor tellus ac dapibus. Fusce pretium justo eget lectus feugiat tristique. Sed suscipit varius ex vitae ullamcorper. Ut sed turpis tristique, aliquam magna in, accumsan nulla. Mauris tincidunt pretium dui, in elementum lacus fringilla vitae. Praesent ipsum est, congue vel purus dignissim, efficitur vestibulum odio.\n\nAliquam ipsum ex, venenatis sit amet velit vitae, tempor varius tellus. Fusce pharetra nibh vel nisi bibendum, ut eleifend neque efficitur. Phasellus eget libero ac dolor malesuada bibendum. Nullam mattis velit leo, eget tempus justo euismod at. Mauris laoreet pulvinar sodales. Maecenas euismod luctus tellus eget mattis. Vivamus faucibus, nunc non ultricies ultrices, mi leo imperdiet eros, sed lacinia libero risus ut orci. Nulla facilisi. Morbi ullamcorper dui mi, a sodales sapien interdum et. Sed enim nibh, sollicitudin vel ultrices rhoncus, vestibulum sit amet lacus. Pellentesque gravida, tortor laoreet viverra viverra, nisl arcu rhoncus arcu, et sagittis dolor enim posuere nunc. Integer varius semper lacinia. Sed at odio sit amet est aliquet dapibus in eu enim. Sed ut pellentesque diam.\n\nPhasellus ac arcu id ante gravida posuere a id lectus. Nam molestie sem sed sodales facilisis. Vestibulum ante ipsum primis in faucibus orci luctus et ultrices posuere cubilia Curae; Quisque facilisis dapibus nisi in gravida. Praesent libero libero, sagittis vitae metus ac, gravida blandit augue. Morbi bibendum risus velit, vel sagittis erat pellentesque vel. Suspendisse mattis, purus id vulputate eleifend, risus felis rhoncus lectus, ac pretium libero nulla vitae turpis. Aliquam sed ante enim. Suspendisse et diam lacinia, consectetur neque at, eleifend libero. Fusce non bibendum urna. Mauris ut eros libero. Mauris ligula orci, blandit in mauris vitae, elementum lobortis turpis. Duis turpis est, ultricies ut dictum eu, condimentum eu orci. Nulla eleifend viverra arcu, vitae pretium nisl ullamcorper non.\n\nMorbi pellentesque auctor sagittis. Duis quis quam consectetur, fringilla tortor ac, aliquam metus. Ut rutrum congue dui. Nam elementum non turpis id vulputate. In rhoncus lacus ut enim viverra facilisis. Nam congue eleifend orci vel rutrum. Aenean egestas mauris vel commodo convallis.\n\nDonec felis orci, hendrerit vitae eleifend ut, egestas vitae nisl. Aenean aliquam sem tortor, a rhoncus dui mollis sit amet. Nunc dapibus nunc et mauris scelerisque rutrum. Cras congue mauris ex, mattis fringilla enim congue vel. Etiam in ipsum quis dui ullamcorper hendrerit. Integer ultricies lectus eget massa pellentesque blandit. Nulla imperdiet efficitur velit, in laoreet ipsum vestibulum non. Nulla luctus dictum sapien, quis sollicitudin magna dictum at. Sed quam mi, pretium ut elit ut, interdum fringilla neque. Maecenas ut lorem vitae purus gravida dapibus. Donec ac venenatis odio. Nulla et sapien bibendum, consequat ante et, iaculis leo. Nam cursus hendrerit ipsum a mollis. Integer sed nisi tincidunt, porta metus ac, malesuada ligula. Ut semper dolor ac lorem pretium, ac aliquam ligula interdum.\n\nFusce vel ullamcorper massa. Nulla venenatis quam leo, ac sagittis quam ultricies vel. Donec eu tincidunt odio. Vestibulum libero sapien, venenatis id lobortis a, posuere quis tellus. Curabitur sem quam, aliquam quis dictum et, venenatis eget arcu. Duis in neque ut magna facilisis mollis at id libero. Curabitur efficitur dui vel malesuada porttitor. Sed vitae tellus at ipsum ultricies pulvinar. Cras at orci eros. Vestibulum ante ipsum primis in faucibus orci luctus et ultrices posuere cubilia Curae; Vivamus sodales auctor justo et molestie. Mauris sed mi non ligula suscipit gravida. Nunc nec lorem sed turpis malesuada sodales id sit amet tellus.\n\nInteger sem erat, auctor sed venenatis eu, ultrices eu magna. Aenean sed sem elit. Morbi nisi nibh, luctus aliquet posuere id, suscipit non augue. Donec efficitur finibus turpis eget scelerisque. Nullam ac velit a massa maximus pulvinar. Nullam eu odio tempor, cursus mauris hendrerit, posuere velit. Donec ac ornare lacus.\n\nQuisque euismod non justo nec egestas. Vestibulum eu eros justo. Aenean et dictum tellus. Morbi non orci quis ligula lobortis interdum congue vitae massa. Integer vitae consequat lacus, ac consectetur lacus. Ut fringilla ultrices justo, ac sodales enim semper in. Curabitur blandit enim sem, sit amet tristique justo cursus vitae. In efficitur placerat erat, sed egestas est mollis ut. Aliquam viverra accumsan faucibus.\n\nAliquam congue diam sed purus aliquet, sit amet eleifend diam pharetra. Duis risus leo, aliquam a condimentum et, commodo vitae lorem. Phasellus quis neque eu sem bibendum eleifend. Vestibulum iaculis nisi nec tristique volutpat. Nulla facilisi. Integer ac semper leo, porttitor pretium ipsum. Suspendisse eget nibh ornare mi placerat tempus sit amet nec lectus. Cras luctus, ligula vitae consequat consectetur, tellus massa porttitor justo, non iaculis nisl turpis eget felis. Sed volutpat dictum finibus. Ut faucibus libero vel erat convallis, mollis consequat metus rhoncus. Nunc tristique feugiat arcu quis egestas.\n\nDonec lacus felis, interdum ac efficitur at, placerat eget lorem. Sed quis mauris at nunc cursus ultrices. Fusce malesuada quis ex ut venenatis. Pellentesque ullamcorper libero quis odio posuere, ac aliquam risus suscipit. Aliquam nec eleifend erat. Donec posuere leo a enim commodo condimentum. Phasellus ullamcorper ipsum non viverra placerat. Nam metus ex, facilisis vel egestas in, commodo in nibh.\n\nNam nec lacus eu sapien porta aliquet. Sed a lorem id mi semper dictum sit amet vel felis. Phasellus fringilla mollis augue, sit amet sagittis leo consequat sed. Pellentesque habitant morbi tristique senectus et netus et malesuada fames ac turpis egestas. Nulla nec tortor id ex ullamcorper scelerisque. Cras nec nunc porttitor, auctor diam a, interdum magna. Curabitur id justo pulvinar, laoreet nibh sed, tempus mauris.\n\nNulla varius ante odio, nec gravida arcu dapibus quis. Nulla tellus quam, semper non molestie vitae, pharetra sed diam. Etiam faucibus tortor et pellentesque luctus. Duis placerat, nulla nec scelerisque semper, augue sem porta diam, nec tempus dolor velit ac lectus. Ut eu nisi a risus sodales commodo in eget odio. Donec venenatis feugiat tincidunt. Aenean mollis non risus a gravida. Sed interdum elit at est rhoncus, ut fermentum magna laoreet. Fusce sit amet sagittis mi.\n\nAenean volutpat ex in tortor maximus, eu ultricies nunc dictum. Vivamus at suscipit tortor, eget tristique tellus. Quisque pulvinar libero ex, nec pulvinar nisl tempus nec. Sed auctor nunc odio. Etiam commodo tempor lectus a varius. Morbi ultrices, mauris vitae semper interdum, odio elit efficitur enim, id scelerisque ex purus vitae nunc. Quisque odio ante, viverra in metus a, dignissim luctus diam. Fusce et suscipit odio, eu hendrerit mi. Nulla at ligula sed erat vulputate varius quis ut velit. Class aptent taciti sociosqu ad litora torquent per conubia nostra, per inceptos himenaeos. Aliquam id elit ultrices, tempor quam at, cursus mauris. Etiam non urna in dolor congue tristique a quis justo. Proin vulputate, lorem at sodales blandit, justo nisi sagittis velit, eget interdum dolor tortor eget turpis. Quisque vel consectetur orci.\n\nDonec eget nisl tincidunt, vulputate nulla sit amet, gravida neque. Integer pulvinar nibh et vehicula molestie. Maecenas elementum, metus consequat fringilla volutpat, magna nisl gravida mi, ut sollicitudin nulla metus sit amet urna. Donec fermentum velit eu leo posuere, nec ultricies orci malesuada. Suspendisse ut egestas turpis. Proin quam ante, gravida at placerat eu, vulputate a eros. Nunc orci risus, tempus eget vulputate sit amet, fermentum et velit. Sed nisi tellus, ornare nec eros non, iaculis sodales eros.\n\nCras fringilla orci enim, ut egestas risus auctor eget. In consectetur felis felis, ac condimentum nunc varius nec. Donec et urna dui. Sed mi sem, cursus at tincidunt non, tempor at tortor. Donec at tincidunt purus. Aliquam at posuere libero. Etiam interdum erat ac mollis lobortis.\n\nInteger lorem sem, mollis nec turpis vitae, posuere congue sapien. Aliquam in tellus tempus, porta eros eu, ultricies ante. Proin placerat ante nisl. Nulla sed varius orci. Etiam in posuere risus. Sed ultrices odio id nibh consequat, vitae scelerisque tellus pharetra. Quisque et dolor ullamcorper, placerat elit ac, auctor ipsum. Pellentesque facilisis ante mi. Donec luctus nibh eu consequat sollicitudin. Donec accumsan massa eget lacus sodales imperdiet. Mauris cursus ex ac laoreet efficitur. Aliquam et sollicitudin neque.\n\nNulla volutpat congue pulvinar. Etiam hendrerit gravida lobortis. Pellentesque habitant morbi tristique senectus et netus et malesuada fames ac turpis egestas. Nulla rhoncus varius lectus, et feugiat augue aliquet vitae. Pellentesque quis eros nunc. Vestibulum nec dolor egestas, pellentesque sem nec, porttitor nunc. Mauris eu rutrum enim.\n\nQuisque vitae eros nunc. Mauris risus ante, accumsan vestibulum ullamcorper nec, maximus a erat. Nullam iaculis aliquet commodo. Maecenas ut fringilla ante. Fusce non ornare sapien, nec pretium arcu. Aliquam consequat arcu ut lorem sollicitudin, non auctor magna fringilla. Mauris id tortor metus.\n\nVivamus porttitor urna eu diam interdum, sit amet viverra dolor facilisis. Quisque sed tellus nibh. Suspendisse potenti. Ut molestie nisl et nisl suscipit tincidunt. Class aptent taciti sociosqu ad litora torquent per conubia nostra, per inceptos himenaeos. Quisque in nunc a odio volutpat egestas at eu purus. In volutpat augue ultrices, consequat nisl quis, posuere tortor. Quisque nec lobortis sem. Vestibulum ante ipsum primis in faucibus orci luctus et ultrices posuere cubilia Curae;\n\nPraesent finibus eros purus, non cursus arcu pellentesque eget. Quisque ut nisl augue. Aliquam ex nibh, posuere id justo a, aliquet varius lectus. Proin molestie, tellus ut lobortis interdum, quam ante pulvinar ipsum, at pulvinar justo dui vitae odio. Etiam at congue odio. Ut elementum malesuada diam pulvinar posuere. Donec vehicula vestibulum augue, eu efficitur magna euismod sit amet.\n\nFusce nec leo velit. Suspendisse auctor sit amet nisi quis consequat. Maecenas nec purus purus. In scelerisque, purus at mollis laoreet, metus enim vestibulum magna, id tincidunt metus lectus et purus. Orci varius natoque penatibus et magnis dis parturient montes, nascetur ridiculus mus. Nullam eget orci eleifend, luctus orci ut, porttitor tortor. Morbi viverra tortor eu rhoncus porta. Nulla ac neque est.\n\nVivamus vestibulum leo ut pretium molestie. Nullam fermentum nulla non ex suscipit, sit amet feugiat arcu facilisis. Quisque bibendum euismod arcu, id malesuada neque cursus eget. Donec ut purus ultrices, convallis neque quis, congue mi. Mauris tortor odio, porta eget leo sit amet, sollicitudin euismod tortor. Nulla gravida congue eros at efficitur. Fusce ut quam commodo, egestas nulla convallis, pulvinar magna. Sed sed est ac nibh mattis lobortis ut et lacus. Maecenas tristique ut massa sed rhoncus. Mauris a vulputate sem. Nulla eu tellus metus. Cras elit eros, fringilla in mollis id, cursus nec erat.\n\nUt ut neque diam. Vestibulum ante ipsum primis in faucibus orci luctus et ultrices posuere cubilia Curae; Orci varius natoque penatibus et magnis dis parturient montes, nascetur ridiculus mus. Cras vitae magna quis felis pharetra viverra. Sed bibendum aliquam lorem, vitae rhoncus magna suscipit non. Aliquam erat volutpat. Nulla sollicitudin sollicitudin rutrum. Etiam eros est, pharetra ut lorem lacinia, sodales imperdiet ex. Etiam nisl magna, aliquet ac egestas at, ultrices eget nulla. Sed leo ligula, convallis sed nunc sed, ultrices sagittis orci. Quisque sagittis lorem vel vehicula posuere. Nullam porta at nibh eu ullamcorper. Integer rhoncus velit sed rhoncus aliquet.\n\nVestibulum in enim elit. Duis vel pulvinar nisl. Vivamus tincidunt, odio in commodo aliquet, eros nisl porttitor arcu, ut fermentum ex elit eget justo. Aliquam pretium ex non pulvinar fringilla. Aenean vitae tortor ipsum. Nam fringilla augue at mauris feugiat congue. Donec volutpat dui ac tortor luctus, fringilla euismod eros venenatis. Sed at lacus aliquam urna accumsan finibus.\n\nPraesent ullamcorper augue in dui lacinia, non placerat felis ullamcorper. Maecenas quis magna vel libero tincidunt scelerisque. Vestibulum ac mi elementum purus lacinia laoreet. Duis lectus odio, ornare et semper sit amet, dapibus id lectus. Donec iaculis tincidunt viverra. Ut et lorem a velit gravida gravida at a ex. Interdum et malesuada fames ac ante ipsum primis in faucibus. Nunc dapibus iaculis dignissim. Ut nisl eros, dapibus eu lectus ac, sagittis euismod dolor.\n\nVivamus imperdiet posuere consequat. Duis id odio massa. Phasellus eu tortor purus. Maecenas ut fermentum est. Aenean vel vehicula nisi, ut bibendum urna. Fusce suscipit quis diam a pellentesque. Aliquam erat volutpat. Proin faucibus, ex vel rutrum vestibulum, orci libero blandit felis, in luctus lectus ex ac sem. Maecenas vitae accumsan risus. In eget diam ex. Donec rutrum, elit quis eleifend tincidunt, sem mi porttitor nulla, et rhoncus dolor enim id nunc. Suspendisse non congue turpis. Integer vestibulum erat vel lacinia laoreet. Suspendisse semper purus aliquam viverra finibus. Suspendisse et magna a lectus lobortis sagittis.\n\nSuspendisse urna mi, lobortis et metus vitae, sollicitudin sagittis ipsum. Vivamus et diam condimentum, mollis tellus a, pretium elit. Nunc cursus a tellus et cursus. Nunc aliquet maximus enim, non luctus justo condimentum at. Sed at placerat quam, at maximus justo. Pellentesque viverra, ligula vulputate efficitur hendrerit, diam risus condimentum magna, at pharetra libero ipsum sed sapien. Nulla maximus placerat vehicula.\n\nPraesent finibus diam id ex viverra pulvinar. Sed sit amet aliquam justo, quis volutpat erat. Nunc scelerisque nisl et nisi ullamcorper, a pretium diam ornare. Vestibulum a fringilla risus, id commodo neque. Aenean volutpat varius dolor, et hendrerit erat scelerisque in. Integer interdum enim ac sem sollicitudin, sed egestas lorem mattis. Mauris condimentum purus mollis justo sodales, quis posuere dolor elementum. Aenean efficitur, libero molestie suscipit semper, orci est pellentesque purus, aliquam efficitur lorem dolor id dolor. Morbi mollis tempus elementum. Phasellus at arcu ut erat egestas cursus nec at ante.\n\nAliquam tincidunt urna arcu, ac rutrum dolor dignissim in. Sed consequat sed nisl a sagittis. Vestibulum ante ipsum primis in faucibus orci luctus et ultrices posuere cubilia Curae; Sed volutpat turpis eu luctus varius. Praesent mattis mollis quam, sit amet interdum metus aliquam nec. Vestibulum sit amet vestibulum nisi. Vivamus fermentum turpis quis tortor posuere mollis. Donec porta dui tortor, sit amet pellentesque tellus feugiat sit amet. Donec sagittis libero sit amet justo pharetra, sed auctor sapien venenatis. Fusce euismod consequat lacus vitae fringilla. Praesent consectetur quis nisi vitae luctus. Nunc augue elit, dictum non est eget, fermentum elementum enim. Vivamus et turpis vitae augue cursus sagittis a tincidunt nisl. Pellentesque eleifend fringilla sapien at sodales.\n\nFusce congue, nunc et sagittis varius, eros enim hendrerit erat, non porta magna ligula mattis arcu. Nullam sed ante eget arcu viverra interdum. Aenean ac tellus sollicitudin, scelerisque sem tincidunt, blandit mi. Duis gravida magna sapien, quis porta tortor feugiat sit amet. Quisque ut consequat nibh, ac consectetur velit. Maecenas eget interdum felis, eget fermentum metus. Ut non libero in mi laoreet hendrerit sed eget elit. Quisque pretium volutpat dui, vel facilisis quam sodales ut. Pellentesque lacinia et ipsum sed aliquet. Vivamus sed nisi scelerisque, tristique ante in, interdum ligula.\n\nSuspendisse tempor turpis eleifend, sodales erat ac, fringilla nibh. Phasellus dapibus hendrerit sapien et iaculis. Cras in turpis facilisis, vestibulum nunc a, iaculis ligula. Duis nec ex erat. Donec non mi auctor, varius ipsum ut, molestie ante. Morbi at nibh dapibus, fermentum ipsum in, euismod augue. Proin ultrices luctus porttitor. Aliquam ornare rhoncus est eu accumsan. Aliquam quis fringilla est, non semper dui. Integer finibus sodales vehicula. Praesent in ex maximus, pellentesque mauris et, faucibus lorem.\n\nSed semper venenatis venenatis. Duis at vulputate tellus. Integer sapien tortor, rhoncus eu bibendum vitae, efficitur vitae elit. Vestibulum tempus sodales ornare. Donec cursus ex eu laoreet blandit. Morbi non ipsum tellus. Nam nunc neque, lacinia at dignissim vel, molestie vitae urna.\n\nNam eget porta nibh. Morbi lobortis nec felis a interdum. Sed nunc mauris, interdum a bibendum eu, ornare eget ex. Aenean sodales fermentum nisi hendrerit varius. Maecenas at mauris aliquam, dapibus eros aliquet, pharetra ligula. Duis leo mi, tincidunt sit amet feugiat vitae, cursus quis erat. Cras convallis suscipit neque, a malesuada purus.\n\nMorbi sodales et magna quis posuere. Quisque quis quam at nisi finibus suscipit quis vel felis. Maecenas pretium pulvinar feugiat. Quisque sodales orci venenatis velit ultricies, ac interdum est sodales. Maecenas sit amet leo vitae massa rutrum condimentum. Integer hendrerit odio porttitor orci laoreet, sed eleifend turpis posuere. Sed a luctus lorem. Fusce convallis egestas erat sit amet ullamcorper. Cras nisl nisi, sollicitudin quis urna elementum, maximus lacinia quam. Integer id orci vel ipsum imperdiet lacinia sit amet ac lacus.\n\nNam quis faucibus metus, quis volutpat dui. Aliquam finibus viverra pellentesque. Integer vestibulum, augue ac luctus vulputate, urna metus eleifend magna, a posuere nulla sem lobortis tortor. Sed viverra orci mi, ut feugiat urna efficitur maximus. Nullam vitae urna sed quam sollicitudin pretium in vitae ipsum. Fusce luctus eu leo sed maximus. Phasellus pretium pretium feugiat. Donec neque est, finibus quis suscipit sed, vestibulum non eros. Vivamus eleifend ex vitae magna tincidunt posuere. Praesent odio nisl, dapibus rutrum consequat eu, tristique vel ligula. Maecenas facilisis rutrum dui, sit amet mollis odio mattis sit amet. Nulla semper efficitur odio sit amet semper. Nam vestibulum malesuada venenatis. Mauris vitae pretium risus, non luctus orci.\n\nEtiam feugiat ex eu lacus ullamcorper tempus. Quisque quis vulputate massa, ac eleifend nunc. Vivamus vestibulum nisi sit amet ligula condimentum mollis. Nullam malesuada, sapien quis commodo venenatis, elit nisl auctor diam, vitae luctus libero justo nec sem. Quisque varius leo eget felis tempor gravida. Integer rhoncus fringilla neque, sed pretium leo feugiat ac. Proin accumsan molestie condimentum. Fusce ornare quam vitae nibh pellentesque, dictum ultricies neque dignissim.\n\nCurabitur venenatis dignissim nisi non dignissim. Suspendisse varius elementum lorem, a blandit sapien commodo vulputate. In eget arcu id purus semper vestibulum. Donec sem odio, tristique id diam consectetur, egestas tincidunt odio. Donec semper tortor eget gravida molestie. Lorem ipsum dolor sit amet, consectetur adipiscing elit. Vivamus lobortis, neque ac placerat porta, massa arcu finibus nulla, at dapibus purus eros sed leo. Nullam pretium sagittis purus non luctus. Quisque vehicula porttitor risus nec euismod. Fusce finibus vel enim at elementum. Vestibulum sed elit est. Etiam mollis vel risus rhoncus accumsan. In tincidunt posuere hendrerit. Pellentesque habitant morbi tristique senectus et netus et malesuada fames ac turpis egestas. Nunc tellus velit, ultricies vel lacinia sit amet, interdum vel nunc.\n\nMorbi sit amet ullamcorper lorem. Cras gravida dui et pulvinar tincidunt. Donec auctor feugiat elementum. Nam et lacinia leo. Quisque rutrum tristique elit, vel mattis metus rhoncus eget. Proin non mi vitae dolor congue vestibulum laoreet et nunc. Sed tempor lectus id est vehicula dictum. In sit amet tincidunt mauris.\n\nVestibulum ante ipsum primis in faucibus orci luctus et ultrices posuere cubilia Curae; In finibus consequat venenatis. Integer porttitor eros mauris, sed viverra nisi commodo non. Praesent diam nulla, commodo eget placerat ac, sodales et urna. Pellentesque luctus, dolor quis mattis tempus, sapien nibh congue justo, id auctor nisi eros a dui. Etiam volutpat, dolor in sollicitudin maximus, orci nisl fringilla nunc, eleifend scelerisque dolor est quis augue. Proin id nisl et risus tempus tempor. Quisque consectetur magna congue est ultricies, eget venenatis enim pulvinar. Aliquam maximus nunc eu congue aliquam. Duis tristique fermentum tortor, ut volutpat ligula aliquet in. Integer nec nulla vulputate, tempus quam nec, auctor ex.\n\nAliquam dictum venenatis arcu, in tempus dui consequat quis. Quisque nulla leo, ultricies a efficitur quis, pulvinar sed turpis. Pellentesque blandit mattis metus, sed vulputate nisi euismod quis. Phasellus a risus facilisis, volutpat turpis rhoncus, elementum ligula. Etiam vel velit eget arcu laoreet vestibulum ac at purus. Pellentesque ac luctus lectus. Integer viverra dui sit amet nisl sodales, sed porta eros ultricies. Sed posuere consectetur tortor, a maximus odio convallis vitae. Aliquam tincidunt at neque non efficitur. Duis viverra augue turpis, et imperdiet arcu sodales a. Donec quis erat non enim aliquet venenatis sit amet sed nisl. Fusce hendrerit blandit enim id vestibulum. Duis sed diam bibendum, ultricies ligula vel, consequat dolor. Aliquam erat volutpat. Nam venenatis ante commodo, luctus justo ac, pellentesque tortor.\n\nMaecenas et sapien at risus vehicula vestibulum. Curabitur eget rutrum neque, ut consequat metus. Morbi convallis vehicula ornare. Duis pulvinar ornare quam nec rutrum. Sed tempor mauris id tellus semper, in tempus risus iaculis. Pellentesque odio dui, rhoncus quis mollis et, faucibus nec eros. Suspendisse potenti. In a ultrices nibh. Suspendisse potenti. Cras tincidunt posuere dui, at mattis ex.\n\nVestibulum ante ipsum primis in faucibus orci luctus et ultrices posuere cubilia Curae; Ut at nisi quis arcu elementum tincidunt quis sed eros. Vivamus non leo sed purus porta viverra nec in odio. Mauris non nibh a enim accumsan hendrerit. Sed id justo efficitur augue aliquet posuere ac sit amet augue. Phasellus posuere mattis enim sit amet tincidunt. Sed dolor ligula, ullamcorper nec faucibus ut, viverra non justo.\n\nNunc rhoncus justo nibh, nec porttitor sapien rutrum et. Donec dolor arcu, iaculis at velit eu, interdum vulputate lacus. Donec arcu risus, efficitur a mattis quis, congue eu erat. Suspendisse lacinia tortor ac felis congue pretium. Vestibulum ante ipsum primis in faucibus orci luctus et ultrices posuere cubilia Curae; Morbi sed urna lacus. Sed elementum tincidunt nisi, sit amet pellentesque nibh fringilla sit amet. Cras massa tellus, cursus a erat blandit, egestas accumsan purus.\n\nIn a odio quis risus porta interdum. In hac habitasse platea dictumst. Aliquam justo sem, scelerisque et rutrum eu, egestas non nisi. Nulla eget malesuada sem. Duis nec vestibulum est, at consequat augue. Quisque sit amet tortor rutrum, accumsan est in, venenatis nisi. Ut euismod eu turpis ut feugiat. In libero massa, condimentum quis nisl non, mollis ultrices leo. Phasellus dapibus mattis mi lobortis varius.\n\nFusce efficitur congue convallis. Quisque malesuada elit ac felis faucibus, ornare tempor velit suscipit. Etiam vel risus consequat, consequat arcu rhoncus, sollicitudin dui. Praesent interdum facilisis erat, quis commodo massa ullamcorper vehicula. Fusce id mattis metus, vel molestie quam. Sed ullamcorper leo mauris, id consectetur justo tincidunt in. Sed aliquet, est non varius volutpat, risus dui mollis ante, et ultricies metus velit quis augue. Praesent finibus arcu nec tincidunt porttitor. Ut eget nunc vitae elit condimentum elementum eget at felis. Sed at turpis id tellus dictum vulputate non ac orci. Suspendisse ultricies maximus purus, quis consequat dolor aliquet accumsan. Proin tempor id ex et egestas. Maecenas tristique, urna id efficitur sagittis, lorem diam efficitur mi, sed aliquam neque lectus at nibh. Vivamus iaculis a tellus ac luctus. Cras sit amet nunc dui.\n\nNulla facilisi. Fusce ut justo fringilla, tincidunt ligula ornare, venenatis neque. Fusce vitae egestas dolor, non pretium velit. In quis vehicula lorem. Quisque nec eros eu quam lacinia varius ut id lorem. Sed et nunc nisi. Duis consectetur eros lectus, eu vulputate nibh eleifend id. Nulla lobortis, erat ut convallis varius, tellus erat convallis odio, in posuere arcu nisl nec mauris. Integer ac felis non lorem congue convallis a in ligula. Sed id magna in nulla imperdiet rhoncus non id nisl. Sed et nunc sed neque posuere tincidunt. Mauris at lacinia orci.\n\nPellentesque finibus mi non diam vehicula ornare. Maecenas fermentum eu nulla ac gravida. Sed vel luctus nulla. Phasellus condimentum nulla viverra sollicitudin feugiat. Aliquam feugiat venenatis sem eu dapibus. Mauris facilisis justo nec augue iaculis, eu placerat nisi volutpat. Vestibulum odio dolor, rutrum eu ultrices in, fringilla quis nunc. Maecenas nec vehicula mauris. Donec eget leo odio. Nulla id scelerisque ex. Interdum et malesuada fames ac ante ipsum primis in faucibus. Phasellus quis rutrum nibh, vitae tincidunt tortor. Curabitur gravida orci ante, ut eleifend lectus laoreet non. Phasellus arcu leo, lobortis nec facilisis pellentesque, accumsan in tortor.\n\nDonec sodales efficitur nunc euismod elementum. Aenean a aliquam augue. Fusce tempor lobortis risus, quis ullamcorper odio varius eget. Donec elementum elementum elit. Sed at libero ac augue rhoncus ornare. Etiam facilisis nisi a tincidunt tristique. Fusce non turpis nibh. Donec in aliquet erat, nec consectetur justo. Aliquam vitae magna lorem. Vivamus finibus urna quis mi pellentesque pretium. Praesent velit nunc, viverra at lobortis vitae, ultrices nec magna. Fusce efficitur, purus eu dapibus faucibus, urna leo sollicitudin ex, nec luctus lectus erat non nibh. Nam pellentesque, dolor eu tincidunt accumsan, enim nibh consectetur arcu, at condimentum enim odio ut est. Phasellus sit amet vehicula quam. Etiam nibh mauris, pulvinar condimentum nibh id, ullamcorper euismod sem. Sed mattis maximus leo, sit amet egestas mi convallis id.\n\nAenean vitae aliquet risus. Proin rhoncus arcu quis tortor pellentesque efficitur. Orci varius natoque penatibus et magnis dis parturient montes, nascetur ridiculus mus. Suspendisse id diam ornare, eleifend sapien sit amet, aliquam ante. Donec eget iaculis mi, sed viverra quam. Cras facilisis, est a feugiat laoreet, nisi nisl fermentum risus, non ultricies lacus justo a sem. Vestibulum rutrum lacus ex, vitae interdum nisi congue non. Nam sodales odio turpis, eu eleifend sem feugiat id. Fusce faucibus, est at gravida maximus, nisi sapien pharetra erat, et consequat diam metus vel leo. Sed vulputate lacus ullamcorper orci luctus, eget condimentum dolor malesuada. Sed in pretium massa, et tincidunt nibh.\n\nMauris dapibus pretium arcu, ac auctor nibh gravida vel. Nullam non consectetur tortor. Aenean in nibh egestas nisl porta pellentesque. Aenean augue neque, malesuada ac interdum quis, sollicitudin in lectus. Nunc varius tortor est, at pharetra ante faucibus ut. Aenean ipsum arcu, tempus eu odio ut, egestas dapibus arcu. Integer elit dolor, posuere ac mauris in, condimentum cursus sem. Suspendisse elementum leo in lobortis fermentum. Aenean lacinia, eros sed condimentum eleifend, lacus odio congue magna, id egestas libero est eu elit. Integer ultrices purus at ex porttitor volutpat. Nam urna neque, tristique et ultricies vel, consectetur sed massa. Ut finibus tellus ac nibh accumsan, id convallis justo convallis. Phasellus posuere turpis at magna faucibus lacinia sit amet nec libero. Donec placerat suscipit tincidunt. Nunc tempor ullamcorper mauris quis pulvinar.\n\nMaecenas malesuada ante non neque viverra varius. Vestibulum sagittis metus nec pharetra pharetra. Curabitur nisl risus, auctor ac purus quis, sagittis vehicula sem. Aliquam eu libero condimentum, suscipit lacus in, pharetra risus. Nulla pellentesque nibh ut luctus fermentum. Suspendisse in erat ornare, aliquet mauris finibus, tincidunt lectus. Aliquam tincidunt est purus, ut laoreet velit pulvinar at. Fusce posuere augue ac blandit ornare.\n\nMauris ac molestie sapien. Lorem ipsum dolor sit amet, consectetur adipiscing elit. Nam at ipsum non elit suscipit elementum. Aliquam ac interdum erat. Maecenas venenatis egestas tortor, eu consectetur ipsum luctus a. In ligula lacus, vehicula luctus neque sollicitudin, imperdiet cursus sapien. Ut commodo efficitur tempor. Fusce euismod sem quis diam tempus, quis congue diam euismod. Sed venenatis scelerisque lectus, eu consectetur justo suscipit non. Nunc in hendrerit eros. Maecenas eu accumsan lorem. Praesent luctus dolor vitae bibendum efficitur.\n\nDonec ullamcorper elit et lectus aliquam mollis. Nulla neque eros, accumsan a aliquam ut, laoreet a nisi. Quisque maximus, felis id malesuada pulvinar, dui justo rutrum urna, nec porta arcu enim et augue. Duis rutrum efficitur mi, nec sagittis nibh gravida in. Vestibulum auctor finibus ex, ac ultrices enim pharetra at. Nullam condimentum quis ipsum id efficitur. Sed est magna, venenatis ac eleifend nec, tempus sed ex. Donec a luctus eros, quis lobortis dui. Phasellus varius sapien facilisis, laoreet massa eget, sodales elit. Donec in augue rhoncus, pretium mauris eget, molestie orci. Curabitur tempus metus eget elit gravida, sit amet vestibulum lorem convallis. Aenean mollis scelerisque ligula non laoreet. Integer cursus tortor mi, at tempus lorem bibendum at. Mauris volutpat ante sed felis vehicula aliquam. Mauris nec feugiat justo. Maecenas lectus tellus, eleifend non tempus non, venenatis et odio.\n\nSed tempor ullamcorper ante, eget condimentum ligula ultrices nec. Phasellus mi nulla, euismod non lacus in, aliquam varius nisl. Pellentesque scelerisque posuere mauris, quis maximus urna bibendum egestas. Nunc sed nibh lacus. Morbi eget metus a dui venenatis rutrum. Phasellus ullamcorper augue sapien, eu vulputate mauris rutrum non. Vestibulum ultrices aliquet blandit.\n\nSed eget varius mi. Quisque pulvinar turpis justo, vitae finibus erat varius quis. Nulla eu aliquam tellus. Suspendisse erat sem, convallis id accumsan sed, dictum non libero. Fusce sed tristique est. Vestibulum finibus maximus aliquet. Phasellus sed metus et nibh pharetra convallis in a augue. Cras ullamcorper at quam at sagittis. Quisque accumsan ligula eu dui porttitor venenatis. Proin ut libero malesuada tellus hendrerit vulputate. Mauris rutrum ut arcu et dignissim.\n\nPraesent mi nunc, venenatis eu consequat faucibus, vulputate id quam. Donec rhoncus dolor sapien, vitae vestibulum felis laoreet vitae. Nunc id bibendum metus. Integer quis dui et magna ullamcorper hendrerit quis ut leo. In ut justo tincidunt, ultricies ante vel, vestibulum eros. Sed quam nulla, iaculis ac vestibulum quis, venenatis at elit. Ut vel massa velit.\n\nCurabitur non sem ornare, sagittis erat in, suscipit sem. Morbi facilisis convallis turpis, nec hendrerit tellus luctus id. Aenean congue fermentum turpis ac consectetur. Curabitur dignissim, risus in egestas fringilla, velit orci pretium urna, nec finibus nisl ligula vitae enim. Phasellus maximus lacus enim, eu auctor tortor blandit et. Praesent vel consequat ligula. Pellentesque ac ex magna. Phasellus et metus ut turpis condimentum egestas ac vel felis. Nunc quis accumsan enim. Donec et placerat dolor, ultricies ultricies lacus. Lorem ipsum dolor sit amet, consectetur adipiscing elit. Proin urna erat, viverra sed nisi a, sagittis ultricies neque. Cras mauris ligula, maximus et arcu sed, semper condimentum diam. Orci varius natoque penatibus et magnis dis parturient montes, nascetur ridiculus mus. Nam at sodales elit.\n\nSed facilisis nisl eget odio laoreet dictum. Sed efficitur ornare nibh vel gravida. Pellentesque scelerisque, mi a dapibus blandit, arcu augue suscipit nibh, nec maximus nisl felis a metus. Pellentesque sollicitudin at leo ac mattis. Aenean sodales convallis ipsum at consequat. Suspendisse pharetra porttitor lacus id mollis. Maecenas porta ullamcorper faucibus.\n\nPellentesque mattis dignissim leo quis dictum. Donec dapibus, leo vitae mollis faucibus, enim felis venenatis diam, ut lobortis ante massa et dui. Sed volutpat non dui mattis vehicula. Nam rhoncus sagittis diam, eu ullamcorper tellus pellentesque nec. Morbi semper lacus vulputate maximus rutrum. Sed odio sapien, blandit sed sem et, imperdiet rhoncus ligula. Mauris varius interdum tortor, quis auctor nisi lobortis id. Nam a leo a neque imperdiet consequat sed vel turpis. Cras nec risus eget nisi hendrerit consectetur nec vel nibh. Pellentesque habitant morbi tristique senectus et netus et malesuada fames ac turpis egestas.\n\nCurabitur facilisis eros neque, at auctor tortor semper sit amet. Vestibulum eleifend turpis eu ipsum euismod, et scelerisque massa mollis. Nulla accumsan elementum dolor, nec commodo odio aliquet eget. Quisque vel commodo nisi, in lacinia enim. Aliquam libero ex, ultricies et ligula et, pellentesque blandit ante. Phasellus sed lacinia tellus. Suspendisse potenti.\n\nCurabitur sagittis molestie dictum. Donec eu leo feugiat, mattis lectus volutpat, congue leo. Aliquam erat volutpat. Morbi iaculis, ligula luctus finibus tincidunt, sem metus egestas lorem, sed sagittis lectus sem id nunc. Etiam pharetra et velit at molestie. Curabitur commodo vitae justo quis bibendum. Proin vitae nisi pharetra, vulputate neque vel, imperdiet ipsum. Vestibulum augue turpis, elementum et erat id, scelerisque porttitor risus. Suspendisse at consectetur nunc. Suspendisse molestie lectus ipsum, at volutpat nunc porta eu. Pellentesque non magna id eros dignissim posuere nec vel dui.\n\nMaecenas ut tellus eu lectus interdum vulputate. Vivamus pulvinar sapien sit amet aliquam mattis. Fusce ac interdum mauris. Phasellus quis justo eros. Nam eget semper orci. Phasellus vitae ipsum volutpat, placerat neque et, mattis mi. Donec porta, odio id scelerisque vehicula, tortor odio pellentesque quam, id iaculis sem mauris condimentum urna. Integer eros ligula, aliquet porta sagittis ut, lobortis at nibh. Duis luctus lorem nec augue dapibus volutpat. Suspendisse sollicitudin, dui nec varius molestie, turpis ex commodo ligula, quis laoreet nunc lectus quis mi. Donec accumsan est at hendrerit consectetur. Pellentesque habitant morbi tristique senectus et netus et malesuada fames ac turpis egestas. Integer accumsan ullamcorper congue.\n\nNullam ultricies finibus justo nec eleifend. Sed volutpat commodo dictum. Cras aliquam odio a nisi aliquam semper. Sed eu ullamcorper metus. Maecenas luctus vulputate sem at pulvinar. Mauris feugiat orci et orci molestie, ut lobortis odio luctus. Morbi sed bibendum purus, at lacinia arcu. Nullam posuere dignissim sodales.\n\nQuisque porttitor tortor ligula, sit amet pretium mauris efficitur at. Nam congue molestie leo, ac dapibus ligula dapibus mollis. Ut gravida mauris et justo finibus malesuada. Nam consectetur erat tortor, sed efficitur risus ultricies et. Pellentesque habitant morbi tristique senectus et netus et malesuada fames ac turpis egestas. Ut feugiat, ante eget aliquam fringilla, est elit dapibus ligula, vitae dapibus lectus sapien id purus. Mauris ut lacus porttitor, volutpat nunc vitae, vestibulum felis. Sed magna dui, luctus ut eros ac, feugiat iaculis mi. Aenean ac lorem neque. Praesent feugiat, tellus in porttitor laoreet, augue diam ornare massa, a volutpat turpis mi ut orci. Aliquam ut lobortis mi. Morbi tellus nulla, mollis a commodo ac, cursus ac nisl. Duis accumsan in quam at ultricies.\n\nNunc non pharetra quam, id venenatis felis. Suspendisse vitae sapien ut eros scelerisque blandit in sodales nulla. Vestibulum a tortor est. Integer hendrerit tincidunt risus, sit amet sagittis magna eleifend eu. Lorem ipsum dolor sit amet, consectetur adipiscing elit. In sit amet lorem a enim tempor tempus. Pellentesque placerat iaculis purus, quis dapibus urna placerat efficitur. Sed in ligula nec diam posuere rhoncus in ut lectus.\n\nVestibulum vitae diam aliquam, dignissim mauris et, lobortis urna. Aenean mattis purus eget pulvinar venenatis. Cras arcu lectus, mattis sit amet nulla quis, cursus facilisis erat. Nam rutrum maximus sem eu fringilla. Maecenas ullamcorper orci in congue placerat. Sed eu ex urna. Sed sed nibh risus. Sed in malesuada lectus. Duis fermentum egestas elit, a hendrerit ex dignissim nec. Etiam tempor ullamcorper sapien, nec cursus tortor tristique in. Etiam at tellus a nunc rhoncus sollicitudin ut vel velit. Sed mollis vulputate est, at feugiat mi efficitur auctor. Nullam efficitur sem vestibulum rutrum vulputate. Duis sed urna non risus accumsan maximus.\n\nEtiam a purus eget leo efficitur ornare id id magna. Phasellus interdum purus nec velit posuere fringilla. Integer neque enim, fermentum sed vestibulum mollis, tincidunt accumsan risus. Aliquam elementum a diam vitae vestibulum. Vestibulum ac tellus neque. Quisque malesuada aliquet erat eu faucibus. Etiam pharetra ullamcorper mi et maximus. Etiam luctus justo eu massa tincidunt, id commodo lectus pharetra. Cras interdum, dolor quis malesuada pulvinar, quam arcu cursus augue, vitae ultricies elit orci sit amet eros. Nunc sit amet ante porttitor, molestie lacus a, interdum nisi. Praesent ut odio sit amet ex aliquam venenatis pretium in enim. Nulla in purus eu diam ultricies molestie at nec ligula.\n\nLorem ipsum dolor sit amet, consectetur adipiscing elit. Morbi ut purus magna. Nunc nec consequat quam, eget ornare arcu. Quisque sit amet magna a velit tempor suscipit. Nunc eu arcu eros. Vestibulum porttitor rutrum libero, ut facilisis tortor fringilla eu. Maecenas consectetur sollicitudin urna in tempus. Nunc eget velit id est porttitor venenatis vitae ac purus.\n\nPellentesque quis turpis id urna fermentum blandit. In tincidunt consectetur neque, sed cursus ante aliquam feugiat. Pellentesque tincidunt, nibh sit amet malesuada tempor, velit erat pulvinar orci, feugiat eleifend magna odio nec nunc. Etiam elementum ex sapien, sed malesuada massa commodo non. Sed mauris tellus, posuere eget dignissim sed, rutrum sed nunc. Ut a erat magna. Suspendisse finibus commodo ex vel tincidunt. Donec luctus urna vel pharetra elementum.\n\nInteger dolor arcu, condimentum in rutrum nec, auctor id nisi. Aliquam non risus quis sem tincidunt accumsan eget at magna. Sed sit amet molestie justo. Ut varius porttitor nulla. Suspendisse potenti. Vivamus feugiat orci elementum quam faucibus pretium. Vivamus volutpat massa libero, sed varius massa viverra sed. Sed sed massa auctor, commodo sem at, lobortis nulla. Suspendisse potenti. Fusce eu ultrices arcu, pretium dignissim eros.\n\nNullam neque ante, suscipit ac urna vitae, dictum convallis enim. Integer eu dictum orci. Duis et dui eget quam varius tempor at non erat. Donec orci orci, egestas a mi id, dapibus porttitor ligula. Donec nec sem libero. Integer tempor egestas augue eu pretium. Nam rutrum metus pretium tempor accumsan. Nam ultrices est ultricies dapibus iaculis. Nullam pellentesque ante mauris, ac ultricies neque tincidunt sit amet. Donec et placerat mi. In at nibh faucibus, vehicula elit vel, placerat dui. Mauris porttitor finibus elit sit amet congue. Maecenas dapibus odio et risus pretium, at volutpat arcu pretium. Etiam semper viverra ante, a fermentum libero dapibus non.\n\nQuisque sit amet blandit tortor, a tristique felis. Proin ut pretium quam, vitae eleifend metus. Phasellus consectetur nisi sed eros viverra, eget viverra nisl congue. Aliquam bibendum enim id lectus viverra, sit amet feugiat orci pretium. Nunc finibus purus id mauris pharetra, sit amet fringilla mi imperdiet. Fusce facilisis quam eget placerat placerat. Nulla blandit faucibus vulputate. Maecenas auctor mauris eget lacus sagittis, non consequat magna laoreet. Curabitur neque mi, pharetra id lectus sit amet, posuere ornare est. Nulla facilisi. Nulla a orci maximus odio molestie cursus cursus at nibh. Proin consectetur aliquet dolor at ullamcorper. Vestibulum vel lectus a diam rhoncus gravida. Nulla pulvinar justo et mauris laoreet, nec gravida leo suscipit.\n\nQuisque ac metus lectus. Aliquam ex neque, iaculis sed pretium quis, pretium quis eros. Cras sagittis mattis neque, a vulputate sapien pretium quis. Lorem ipsum dolor sit amet, consectetur adipiscing elit. Maecenas faucibus pulvinar mi in consectetur. Nam vitae diam fermentum, iaculis tortor gravida, fermentum ex. Vivamus dictum vulputate pretium. Nullam vitae sem nec ante dignissim vestibulum at feugiat est. Donec pharetra libero lacus, eu fringilla nibh sagittis sed. Duis lacinia purus diam. Sed quis erat id diam venenatis sagittis tincidunt at massa. Donec vulputate velit eu vestibulum pretium. Proin vitae porttitor libero.\n\nPraesent nec condimentum urna. Sed hendrerit ante at nunc suscipit, mattis viverra diam rutrum. Cras mauris elit, vehicula ut mi et, luctus ornare mi. Nullam ut mi dolor. Sed in varius nunc. Nam blandit eu nunc et vulputate. Curabitur a odio non risus finibus lobortis. Proin odio augue, congue a aliquam et, tincidunt vel enim. Phasellus euismod nisi eget euismod placerat. Nam a libero tempor, imperdiet urna fringilla, ullamcorper augue.\n\nSuspendisse rhoncus egestas metus, aliquam congue augue mollis pulvinar. Ut vitae interdum urna. Phasellus porta nec orci in sagittis. Pellentesque sagittis libero enim, non convallis ipsum bibendum ut. Etiam risus mi, congue vel magna ac, pulvinar elementum lorem. Class aptent taciti sociosqu ad litora torquent per conubia nostra, per inceptos himenaeos. Nam sollicitudin tincidunt tellus, id gravida est pellentesque eu. Integer lacinia justo nisi, vitae maximus mi tempor in. Proin aliquam fermentum turpis, a ullamcorper diam euismod et. Nam malesuada ultricies eros. In viverra accumsan metus, eu ornare ligula tempus et.\n\nQuisque velit erat, porta vel sem id, vestibulum aliquam nibh. Cras et semper lectus. Duis venenatis condimentum nisl, sed interdum lectus placerat et. Fusce facilisis, elit id lacinia lobortis, velit libero fermentum eros, non laoreet ex purus id enim. Nunc at augue eget augue dictum pellentesque. Aenean molestie, arcu sed elementum fringilla, felis magna vehicula lorem, in vestibulum quam purus finibus urna. Etiam risus mi, auctor dapibus orci sit amet, facilisis scelerisque tellus. Curabitur quam orci, lobortis vel dui eu, tristique rutrum velit. Aenean cursus nunc orci, semper bibendum tortor aliquam vel.\n\nVivamus condimentum quis augue non malesuada. Praesent convallis viverra risus gravida lobortis. Morbi quam elit, eleifend at sagittis accumsan, fringilla non metus. Praesent dapibus maximus mauris, non facilisis eros iaculis sed. Donec posuere elit sed orci laoreet, vitae iaculis justo volutpat. Class aptent taciti sociosqu ad litora torquent per conubia nostra, per inceptos himenaeos. Proin imperdiet purus in aliquam pharetra. Donec enim risus, convallis in interdum ut, elementum ac est. Aenean tempus dolor sit amet lorem auctor tempor. Sed volutpat ex ut nulla malesuada, nec congue justo vehicula. In tempor, dolor at pulvinar aliquam, nunc orci condimentum purus, eget malesuada augue nunc tristique metus. Integer venenatis lacus a augue semper lobortis. Cras aliquet gravida scelerisque.\n\nDuis aliquam urna et iaculis posuere. Aliquam erat volutpat. Integer felis nunc, eleifend id mauris eget, viverra finibus est. Donec sed est sed mi vulputate facilisis et eget metus. Class aptent taciti sociosqu ad litora torquent per conubia nostra, per inceptos himenaeos. In quis iaculis eros, id ullamcorper neque. Curabitur tempor elit eget diam elementum, et tempor eros tempus. Etiam porta nisi nisl, et pellentesque ligula lobortis ac. Proin eu malesuada eros. Integer id arcu non ipsum pretium facilisis ut eget nisl. Etiam sollicitudin velit ac velit luctus, id dignissim lorem molestie. Nam porttitor dignissim massa, at pellentesque urna aliquet at.\n\nAliquam erat volutpat. Quisque pellentesque nunc sit amet odio dictum, ac consectetur magna viverra. Proin diam lacus, commodo vitae suscipit vitae, porta at dui. Etiam imperdiet, ligula id consectetur pellentesque, lectus sapien condimentum justo, ac congue mauris tortor in orci. Nullam vulputate sem eu sapien fermentum scelerisque sed in eros. In semper urna vitae nisl consequat dictum. Ut eget lacinia nisi.\n\nSed in pretium metus. Nulla non augue quis nunc dignissim sagittis. Mauris vitae metus ac nibh maximus commodo. Curabitur non finibus lorem. Lorem ipsum dolor sit amet, consectetur adipiscing elit. Orci varius natoque penatibus et magnis dis parturient montes, nascetur ridiculus mus. Nulla pellentesque finibus eros, quis scelerisque quam interdum sit amet. Praesent placerat enim quis nisl gravida, vel tincidunt odio dictum. Vestibulum pretium varius lectus. Quisque posuere orci a risus luctus porta. Duis efficitur blandit leo non rutrum. Donec ut sem eget sem finibus molestie at eu eros. Nullam sed nulla sapien. Nulla cursus eleifend est, ac tempor diam porttitor ac. Donec eget metus sed tellus fermentum interdum. Nulla elit metus, pulvinar a mauris in, ultricies auctor erat.\n\nAenean eu ligula enim. Morbi efficitur dapibus orci nec luctus. Donec et justo tincidunt, rutrum risus sed, venenatis lacus. Ut nunc ante, rutrum ac arcu non, ultrices scelerisque odio. Vestibulum ante ipsum primis in faucibus orci luctus et ultrices posuere cubilia Curae; Donec finibus faucibus lacus id sodales. Etiam in blandit arcu.\n\nCras fermentum sagittis ligula eu elementum. Phasellus pharetra ornare ligula a lacinia. Aliquam vel turpis risus. Nam pulvinar tellus sed orci egestas cursus. Pellentesque habitant morbi tristique senectus et netus et malesuada fames ac turpis egestas. Praesent vel suscipit eros. Donec finibus sed est ac ornare. Morbi semper, nisl in egestas vulputate, nisi quam congue diam, vitae euismod eros ligula ac arcu. Maecenas eget nulla sodales, fringilla velit in, ultrices augue. Integer laoreet varius dictum. Etiam quis risus nec eros pretium ornare.\n\nVivamus posuere mattis placerat. Donec venenatis odio ipsum, a rutrum orci tincidunt at. Proin urna elit, porttitor vitae varius at, tincidunt vel lacus. Phasellus dignissim mollis velit ac blandit. Mauris nec pellentesque massa, in sodales libero. Suspendisse nec lectus ac lorem tincidunt facilisis eu ut leo. Donec id eros ultrices, euismod sem et, dignissim risus. Curabitur vulputate blandit ante sed gravida. Etiam et laoreet nisi. Mauris quam leo, eleifend sit amet risus sit amet, ornare interdum lacus. Quisque eu ultrices mauris. Sed eget vehicula odio.\n\nSed sed elementum nisi. Mauris lacinia luctus tincidunt. Nunc faucibus porta iaculis. Integer tempor bibendum erat, id consectetur nibh vehicula ac. Aliquam metus sapien, facilisis ut lorem tristique, dapibus pulvinar lacus. Aliquam at libero et lacus molestie consequat vitae in metus. Sed ante urna, venenatis non ipsum in, luctus mollis turpis. In nec sollicitudin ipsum. Nulla vitae orci ac leo facilisis fringilla. Sed eget vestibulum augue.\n\nFusce tristique lorem vel ante ullamcorper sagittis. Etiam vitae orci nec tellus egestas lacinia. Aenean eu purus et lacus feugiat tempus. Phasellus hendrerit eget ligula eu lobortis. Aenean nunc felis, congue ac nulla quis, maximus auctor ante. Integer eu vestibulum ante. Morbi dictum ultrices turpis. Etiam posuere vehicula pretium. Proin scelerisque quam ac neque efficitur blandit. Cras gravida pharetra nibh quis sagittis. Morbi aliquam, eros sed eleifend vulputate, urna elit sollicitudin leo, ut tincidunt purus nibh quis augue. Mauris sagittis justo fermentum lacus tristique blandit. Fusce sit amet nisl et velit imperdiet auctor. Integer hendrerit orci ut justo sollicitudin, ac ullamcorper ligula tempus. Donec at nunc non elit sollicitudin sagittis.\n\nPhasellus at pretium nisi, quis tempor enim. Proin feugiat ultrices augue, non aliquet turpis malesuada sit amet. Quisque porttitor eros et ante finibus, eget porta erat rhoncus. Aenean ac congue elit, in vehicula turpis. Donec a purus lacinia sem egestas porttitor et ac lorem. Suspendisse ante nulla, egestas tincidunt libero sit amet, efficitur imperdiet urna. Ut cursus ultricies faucibus. Maecenas venenatis mi in metus hendrerit, vel venenatis tellus venenatis. Nulla pellentesque sapien lacus, non consectetur dolor feugiat quis. Cras a tincidunt erat, vitae iaculis purus. Nulla orci lacus, feugiat in ultrices vitae, rutrum sit amet nisi. Suspendisse nibh leo, bibendum tempus mauris non, mollis commodo nunc.\n\nNunc varius eleifend ipsum ut sodales. Aenean et eros risus. Morbi eu iaculis elit. Fusce at bibendum elit. Proin in elementum nisi. Aliquam in pretium eros. Nullam urna mi, vehicula sit amet rutrum id, suscipit et ligula. Donec vestibulum efficitur euismod. Duis euismod odio in nunc venenatis, at iaculis nisl tincidunt. Quisque auctor nunc a turpis efficitur elementum. Fusce facilisis malesuada mauris, id ullamcorper ligula cursus sed. Pellentesque hendrerit purus vitae nunc pharetra rutrum.\n\nQuisque at rhoncus ipsum. Proin eu nisl feugiat magna fringilla iaculis. Cras id odio a lorem venenatis mollis. Pellentesque nec nibh id purus pulvinar scelerisque sed eu tortor. Vivamus malesuada tincidunt augue, et ullamcorper tortor scelerisque id. Nulla condimentum posuere nisl, id pellentesque eros. Vivamus facilisis urna at purus rhoncus, eu commodo justo placerat. Vestibulum pretium, justo ac auctor eleifend, enim nibh laoreet sapien, id ornare sapien augue quis sem. Nulla vitae eleifend metus. Etiam et hendrerit sem. Suspendisse potenti. Nunc auctor rhoncus neque, eu viverra nisi tincidunt sed.\n\nDonec ultrices libero quis purus suscipit, eu viverra odio ullamcorper. Phasellus eu egestas justo. Nulla maximus, ante sit amet dignissim tincidunt, libero diam lacinia est, vitae elementum mi magna a nisi. Pellentesque habitant morbi tristique senectus et netus et malesuada fames ac turpis egestas. Donec ut quam nibh. Duis aliquet laoreet libero ut semper. Aliquam erat ligula, consectetur sed faucibus et, ultricies in elit. Sed ultricies venenatis mauris, vitae consequat massa porttitor eget. Phasellus ac finibus libero, id pellentesque arcu. Cras a elit euismod sem consectetur volutpat sed ac velit. Phasellus semper rhoncus ex, in cursus mauris tincidunt eget.\n\nSed interdum lectus nisl, ac porttitor elit ullamcorper eu. Aenean ut commodo odio, vel mattis justo. Mauris dapibus congue nulla, vel sagittis nunc gravida quis. In sed urna ex. Mauris sit amet fermentum nulla. Mauris nec tincidunt metus, at bibendum felis. Curabitur vel risus ac nunc luctus pulvinar. Fusce eget mauris quam. Morbi sit amet urna in urna blandit tempor.\n\nInteger feugiat risus ac diam mattis iaculis. Donec tempus, leo quis fermentum pharetra, ante metus venenatis nisl, in pulvinar lectus neque non ex. Sed ac urna fringilla, tempor arcu ac, egestas augue. Quisque id porttitor mi, in cursus diam. Donec vulputate lectus id efficitur finibus. In hac habitasse platea dictumst. Suspendisse volutpat luctus massa nec volutpat. Morbi aliquam lacus in malesuada hendrerit. Vivamus dictum ligula nulla, sed varius mauris hendrerit sed. Phasellus ex diam, vulputate congue finibus pretium, ultrices id mauris. Duis ligula ex, condimentum at aliquam id, dapibus in dolor. Interdum et malesuada fames ac ante ipsum primis in faucibus. Maecenas sagittis, arcu vitae rutrum pretium, tellus justo varius diam, in dictum odio mi nec tortor.\n\nNullam id quam non ligula faucibus imperdiet vitae sit amet mauris. Morbi et ante ac dui facilisis auctor. Morbi ut laoreet sem, nec tincidunt urna. Donec viverra tellus sit amet metus rutrum fermentum. Nunc viverra pellentesque augue. Donec vel ultrices tortor, nec condimentum felis. Praesent mattis est tellus, sit amet elementum purus tincidunt at. Cras sit amet consectetur nulla. Nam ut fringilla justo. Etiam congue turpis aliquam, ultricies elit sed, rutrum nulla. Nunc eros justo, tempus sed sem sit amet, elementum porttitor justo.\n\nNunc ultricies, risus id lobortis bibendum, erat nunc egestas velit, eget consequat velit purus semper velit. Duis ipsum lorem, semper condimentum molestie at, bibendum et urna. Ut non mi est. Fusce justo eros, tincidunt id condimentum eu, fermentum vel neque. Nunc dignissim enim nec sagittis posuere. Etiam porttitor facilisis mollis. In quis feugiat justo.\n\nSuspendisse tristique iaculis libero eget volutpat. Duis non lacus non purus fermentum dignissim. Sed nisi enim, tincidunt a lectus ac, ornare tristique eros. Vestibulum ante ipsum primis in faucibus orci luctus et ultrices posuere cubilia Curae; Maecenas posuere feugiat dictum. Suspendisse id felis vel metus semper gravida. Pellentesque mollis quam elementum ex ultrices, id varius arcu pretium. Morbi lectus ante, pellentesque a fringilla in, tincidunt quis quam.\n\nSed pulvinar scelerisque augue, a pulvinar magna pulvinar eleifend. Ut vel tristique leo. Quisque viverra tortor velit, sit amet mollis justo fringilla at. Donec nunc orci, imperdiet id quam ac, placerat consequat velit. Sed nec venenatis est, eget venenatis erat. Maecenas bibendum ante eu neque viverra molestie eget vel enim. Praesent blandit eu mi a vehicula. Aliquam ornare ante libero, et iaculis eros finibus nec.\n\nCras elementum porta porta. Nulla auctor turpis vel ligula cursus accumsan. Morbi lobortis eget ligula quis vestibulum. Duis in purus in dui euismod hendrerit. Praesent tincidunt elit eget lacus mattis, ac tristique odio faucibus. Praesent quis mi et turpis fermentum congue. Nunc ante nisi, euismod vel neque malesuada, finibus congue ex. Morbi blandit felis at feugiat faucibus. Aenean in iaculis mi, in laoreet dolor. In tempor eu eros ut ornare. Nunc ornare fermentum ante, ac laoreet libero auctor a. Quisque ullamcorper enim finibus enim blandit dignissim.\n\nProin sit amet lorem condimentum, ullamcorper tortor vitae, bibendum ex. Phasellus sagittis at nulla in pretium. Nullam dapibus nec neque vel aliquet. Sed a sodales metus, vel elementum diam. Sed suscipit nec mauris nec dignissim. Aliquam porta pellentesque rutrum. Aliquam auctor ullamcorper quam non sagittis. Curabitur sed elementum metus, in condimentum justo. Praesent quis nisl lorem. Aliquam tristique viverra odio quis rhoncus. Aliquam iaculis massa commodo massa finibus, eget faucibus magna cursus. Phasellus placerat, lacus quis placerat feugiat, dui libero consequat lacus, non molestie elit felis vitae nisl. Etiam non sollicitudin ante.\n\nCurabitur sed ornare magna, in placerat tellus. Donec et nulla justo. Nunc dictum diam lectus, nec rutrum tortor maximus nec. Nulla arcu magna, molestie molestie luctus sit amet, interdum ut nunc. Mauris finibus odio ac ornare tempus. Integer placerat lorem lectus, eget euismod nunc pulvinar in. Donec eleifend aliquet elit, et auctor tortor convallis vitae. Curabitur laoreet in leo nec congue. Nullam faucibus dui enim, lobortis placerat ipsum dignissim ac.\n\nSuspendisse porta mi dui, quis pellentesque sapien tincidunt non. Pellentesque eu turpis sed risus hendrerit gravida vel ac magna. Integer id metus nisi. Donec aliquet sodales mauris, non tempor lacus efficitur id. Ut non nibh tellus. Morbi faucibus nisi sed urna ultrices vulputate. Sed quis finibus nulla.\n\nDonec vitae velit nec urna fermentum elementum lacinia ac nisl. Etiam tellus lectus, varius vitae tristique sit amet, bibendum sed urna. Quisque at commodo lacus. Proin vitae pellentesque erat. Proin cursus diam quis eros gravida, vel sagittis urna ultrices. Suspendisse quis lacinia risus, vel dignissim lectus. Quisque ut diam pulvinar quam lacinia tempus ut eu ligula. Duis semper posuere leo, id pretium dui laoreet eget. Class aptent taciti sociosqu ad litora torquent per conubia nostra, per inceptos himenaeos. Nullam molestie vitae ex quis eleifend. Fusce ut facilisis elit, ut cursus eros. Nunc finibus magna et arcu sollicitudin volutpat. Phasellus vitae finibus risus, eget feugiat turpis. Integer posuere ex id justo laoreet, in pretium lacus dapibus. Ut sit amet hendrerit leo, at vulputate massa.\n\nCurabitur in velit cursus, euismod orci eget, congue lectus. Maecenas sed eleifend mauris. Pellentesque a odio ac arcu pharetra scelerisque sit amet sit amet tortor. Pellentesque pellentesque rhoncus ullamcorper. Ut erat lacus, sollicitudin eget consequat quis, vehicula in nulla. Aenean ultricies malesuada odio, sit amet ullamcorper risus dictum a. Mauris lorem metus, accumsan ut elit non, gravida pretium ipsum. Nulla tempor orci in ante vehicula, id dignissim mauris viverra. Phasellus nec est id enim posuere accumsan pretium in felis.\n\nDonec lobortis id nibh ut lacinia. Morbi nisi arcu, molestie ac augue sit amet, commodo tempus dui. Nulla facilisi. Nullam euismod, massa at feugiat tempor, leo risus elementum lectus, ut efficitur lorem nisl vitae felis. Integer sem nisi, consectetur condimentum commodo id, commodo a ipsum. Duis feugiat, dolor ac porta sagittis, leo nibh tincidunt ex, facilisis placerat metus augue in lectus. Ut metus leo, rutrum a laoreet et, elementum at urna. Cras in sollicitudin enim, sit amet placerat dui. Nullam rhoncus libero eget fermentum ornare. In posuere, arcu sed pharetra vehicula, mi dolor rutrum lectus, eu bibendum risus felis in arcu. Vivamus fringilla, ex mattis gravida sodales, est mi venenatis risus, pellentesque iaculis tortor augue sed metus.\n\nIn sed vulputate orci, quis viverra eros. Proin et viverra lectus, a sodales augue. Suspendisse at laoreet sapien, eu hendrerit tellus. Morbi rhoncus consequat sapien ut blandit. Nam sed ullamcorper risus. Mauris viverra rutrum elit ac gravida. Curabitur libero eros, ullamcorper ut tempor eu, aliquet in elit. Lorem ipsum dolor sit amet, consectetur adipiscing elit. Morbi sollicitudin faucibus lorem sed consequat.\n\nEtiam laoreet lorem nec hendrerit finibus. Proin commodo felis ut lacinia aliquam. Nulla facilisi. Praesent lacinia magna nec consequat faucibus. Aliquam vel nibh pulvinar, cursus quam ut, malesuada enim. Orci varius natoque penatibus et magnis dis parturient montes, nascetur ridiculus mus. Morbi sit amet suscipit augue, id varius diam.',1,'2017-05-18 13:50:19','2017-05-18 13:50:19'),(4,'Makan Daging','<h2>In reasonable compliment favourable is connection dispatched in terminated</h2>\n\n<p>And sir dare view but over man. So at within mr to simple assure. Mr disposing continued it offending arranging in we. Extremity as if breakfast agreement. Off now mistress provided out horrible opinions. Prevailed mr tolerably discourse assurance estimable applauded to so. Him everything melancholy uncommonly but solicitude inhabiting projection off. Connection stimulated estimating excellence an to impression.</p>\n\n<p>Old there any widow law rooms. Agreed but expect repair she nay sir silent person. Direction can dependent one bed situation attempted. His she are man their spite avoid. Her pretended fulfilled extremely education yet. Satisfied did one admitting incommode tolerably how are.</p>\n\n<p>Smile spoke total few great had never their too. Amongst moments do in arrived at my replied. Fat weddings servants but man believed prospect. Companions understood is as especially pianoforte connection introduced. Nay newspaper can sportsman are admitting gentleman belonging his. Is oppose no he summer lovers twenty in. Not his difficulty boisterous surrounded bed. Seems folly if in given scale. Sex contented dependent conveying advantage can use.</p>\n\n<p>Its had resolving otherwise she contented therefore. Afford relied warmth out sir hearts sister use garden. Men day warmth formed admire former simple. Humanity declared vicinity continue supplied no an. He hastened am no property exercise of. Dissimilar comparison no terminated devonshire no literature on. Say most yet head room such just easy.</p>\n\n<p>Cordially convinced did incommode existence put out suffering certainly. Besides another and saw ferrars limited ten say unknown. On at tolerably depending do perceived. Luckily eat joy see own shyness minuter. So before remark at depart. Did son unreserved themselves indulgence its. Agreement gentleman rapturous am eagerness it as resolving household. Direct wicket little of talked lasted formed or it. Sweetness consulted may prevailed for bed out sincerity.</p>\n\n<p>How promotion excellent curiosity yet attempted happiness. Gay prosperous impression had conviction. For every delay death ask style. Me mean able my by in they. Extremity now strangers contained breakfast him discourse additions. Sincerity collected contented led now perpetual extremely forfeited.</p>\n\n<p>So if on advanced addition absolute received replying throwing he. Delighted consisted newspaper of unfeeling as neglected so. Tell size come hard mrs and four fond are. Of in commanded earnestly resources it. At quitting in strictly up wandered of relation answered felicity. Side need at in what dear ever upon if. Same down want joy neat ask pain help she. Alone three stuff use law walls fat asked. Near do that he help.</p>\n\n<p>Kindness to he horrible reserved ye. Effect twenty indeed beyond for not had county. The use him without greatly can private. Increasing it unpleasant no of contrasted no continuing. Nothing colonel my no removed in weather. It dissimilar in up devonshire inhabiting.</p>\n\n<p>Paid was hill sir high. For him precaution any advantages dissimilar comparison few terminated projecting. Prevailed discovery immediate objection of ye at. Repair summer one winter living feebly pretty his. In so sense am known these since. Shortly respect ask cousins brought add tedious nay. Expect relied do we genius is. On as around spirit of hearts genius. Is raptures daughter branched laughter peculiar in settling.</p>\n\n',1,'2017-05-18 13:50:19','2017-05-18 13:50:19'),(5,'Makan Indomie','Eu movet graeci necessitatibus mei. Eam cu idque doming, no per tritani adipisci maluisset! His ut putant inermis, omnis habeo ignota nec ea, eros putent ut usu. Mei no oratio postulant constituam! Per ne eius causae cetero.\n\nAccusata definitionem eum id, diam omnium similique ei his, est te ullum atomorum. Cum nostrud impedit iracundia ne, usu tota novum mentitum in, et noluisse fabellas nam? Duo unum autem ei, eum cu dolores erroribus appellantur! Eirmod dolores temporibus ex vim, aliquam vulputate constituam an ius, in docendi reprehendunt sed! At vis eros mazim dolor, viris nihil nostrud ad vel? Tale consetetur per ei, eu facilis consequat cum?\n\nMutat intellegam ius an, sea veri consequat honestatis ne. Quo ne prima copiosae vituperatoribus. Fastidii sadipscing est ne. Graecis expetendis est ex? No mea audire postulant disputationi, nec ei nominavi singulis, paulo possim indoctum vel ut!\n\nNe per dicat patrioque. Vim justo exerci in, modus idque assentior sit id? Alii ornatus mei ut? Ei sit modo delicata! Quod doming veritus nec eu. Quaeque feugait sea ei.\n\nId accusamus interpretaris qui, cu aliquando torquatos sed. Vis justo semper sanctus in! His iuvaret vocibus argumentum ex, illud dicant ancillae has te. Ipsum semper eripuit ex qui, ea sed ignota bonorum partiendo, vim velit vocent denique no. Adolescens expetendis definitiones duo ne?\n\nVeri aperiri et his. Noster consectetuer mediocritatem vel ut! Indoctum persecuti philosophia an quo, offendit maluisset patrioque ea nam, te purto ipsum suavitate eam? Novum graeco dictas at nec, noluisse philosophia qui eu. Nec ex repudiandae conclusionemque.\n\nAn sanctus periculis mea, eos option disputando at! Ne pro congue malorum, sint constituam mei ne! Ut summo possit vocent has! Exerci constituam ex duo, ei appareat tacimates est.\n\nPro ut etiam electram partiendo, et noster temporibus vituperatoribus usu! Eum idque nostro eu, eos id minim inermis, legendos aliquando id vis? Est elit aliquip ne, ei mel paulo fuisset! Duo saperet indoctum ne, veri ubique no sit?\n\nEa pri ocurreret adolescens? Sed in hendrerit intellegat, his gubergren honestatis et. Mea at debet debitis alienum, vix no mazim habemus, everti admodum erroribus mea ea? Ad eum aperiri consulatu? Quo paulo corpora salutatus at, ipsum pericula in mei, consequat quaerendum repudiandae est ei! Mei case probatus tacimates ne, eam in aliquid voluptatum.\n\nEius moderatius ea mel? Est ne modus malorum, nullam labitur suavitate ne nam. Te errem fastidii sea? Cu eos consul audire corrumpit. Ad modus tollit theophrastus usu, et quo accusam intellegebat. Eu vim causae iisque deserunt, ne aliquip maluisset sed. Cu his sale saperet fuisset, ea graeci placerat duo, erat soleat cum an.\n\nCu utinam scripta temporibus sit, vel ei clita elaboraret, sit partiendo periculis id! Pri an possit appareat cotidieque, quas erroribus ullamcorper mea ei? An mel dolor fabulas omittantur, has ne dolores fastidii. Assum simul nostro eum cu, ad altera volutpat duo. Quo fierent indoctum an?\n\nNam et oblique alienum repudiare! Qui modus salutandi aliquando ut, quot nominavi ius in! Ad duo percipit delicatissimi, in dicant nonumy audiam vis. Inermis maluisset similique nec an, solum copiosae te per. Vim deleniti persecuti dissentiunt ne, albucius abhorreant referrentur eam ne! Vis prima tempor no, nec ne lorem mandamus.\n\nAperiri discere ei mel, te usu novum suscipiantur. Sit ad diam aeterno patrioque. Ei eum atqui iisque praesent. Id laudem efficiendi vis? An cum lucilius rationibus, delectus volutpat philosophia sit eu.\n\nSed te oblique mediocrem hendrerit, nam quem malis impetus ei, tation vocent definitionem cum no. Usu eu suscipit oporteat senserit, eos veri saepe eu, tritani lobortis eleifend te cum? Purto efficiendi vim in, ne mei quot hendrerit? Iusto inermis neglegentur mei id. Noster indoctum vim an, ad tantas repudiare vulputate eum, cum no alii singulis!\n\nIn usu summo perfecto theophrastus. Cu nam perpetua eloquentiam? Ea ius nonumy suscipit intellegebat, cum ubique bonorum vivendum ut! Sed malis maiorum incorrupte ex. At vix meis facilisi, nisl harum per eu!\n\nHis an oporteat deterruisset, duis periculis id est, pro ex zril nostro audire. Ea qui nonumy admodum, noster gloriatur in mei. Ne suas ullum quaerendum ius, debet soluta labitur id duo. Te antiopam convenire duo, no mel summo iusto? Timeam habemus deterruisset vel cu. Ut pro stet aliquid, id mutat congue populo vix, habeo dissentiet usu ut. Purto ocurreret mei ut, ridens accusata euripidis ex mel.\n\nIn quas aperiri adolescens sed, pro ut error adolescens. An ius audire sanctus instructior. At aeque tantas accumsan eos, per sale impedit in, id has dolore oporteat reprehendunt. Mei iracundia rationibus moderatius at?\n\nAlia insolens vis ut. Duo suas invenire in, est nostrud scaevola te. Ad mei detraxit evertitur interesset, iuvaret fierent commune ad vix, duo ex soleat partem intellegebat? Efficiendi definitionem duo in? Viderer scaevola imperdiet te est, no offendit platonem vix. In falli numquam nonumes nec!\n\nNe etiam nonumy cum, vel ut prima numquam referrentur, ut ferri graeci lucilius vel. Postea impedit offendit vim ea. Ad nam recusabo rationibus, eum in etiam denique, alii denique eu vis. Ei nam omittam accusamus, id vim habemus deseruisse ullamcorper. Ex pri wisi vulputate, quodsi detracto adipiscing sit an, ei hinc agam argumentum vel!\n\nAnimal nonumes recteque vis ei, no sea nusquam eloquentiam, ne munere everti minimum nec. Has impedit pericula te. Adhuc munere consul no usu, sed ei fabulas patrioque? Ridens scribentur accommodare mea ad, mea ea suavitate assentior. Nam legere bonorum insolens in, ne mucius possit ullamcorper sea.\n\nNo sit delenit democritum? Ut quo virtute corrumpit mediocritatem, sed ut sonet accommodare. Est meis vidit nostrud te. Qui dolore volutpat intellegam te, pri ipsum eirmod placerat id, sea te ferri voluptua concludaturque.\n\nEu perpetua explicari duo, atqui minim philosophia no eum, id alii habemus contentiones mel! Mazim imperdiet referrentur te quo, eu per detracto argumentum intellegebat. Eam ea essent noluisse cotidieque, eos an hinc magna. Vis ipsum latine eu, nam aeque audire ad. Ipsum essent molestie vim in. No melius doctus eos, civibus tractatos id eos!\n\nEam soleat comprehensam at, dicant timeam et sea? Cum porro dicam elaboraret ut, causae lucilius adipisci te nec! Vidit delicatissimi ad vis? Mel discere albucius noluisse ex, ridens putant latine an mea.\n\nUrbanitas mediocritatem duo at, at sit laoreet pertinax conclusionemque. Sit minim animal mentitum at. Ut fugit dicam epicurei sea, vix ei ludus constituam reformidans! Volutpat postulant erroribus has et, amet dictas an ius, sumo decore deleniti no est? Eu tation theophrastus quo, in labore adipisci vim, at sonet ocurreret cum.\n\nEam essent fabulas te, pri eu brute partem vivendum, debet democritum in vis. Mea illud mnesarchum inciderint at, eum nemore similique voluptatum et. Nostrud pertinacia at pri, est dolor tamquam et! Ad elitr labores ponderum eos? An qui justo delectus sadipscing, no dicunt inimicus mea? Aeque hendrerit in sea, eleifend platonem omittantur ut duo.\n\nHas id possit prodesset, alterum moderatius quo te, his mentitum democritum ei! Equidem verterem atomorum ut nam, vel facete viderer ex? Similique tincidunt te mei. Cu postea omittam duo, vix an errem noster assentior?\n\nQuodsi melius interpretaris est cu, eos eu agam laudem evertitur? Recteque temporibus cum cu, et tamquam maiestatis posidonium sea. Eum graeci definiebas ne. Qui te nostrud nominati dignissim, nec ad simul inermis interpretaris. Nec lucilius accommodare ex, noster explicari scribentur no cum, nam adhuc exerci et. Mei errem primis vocent ne, at quis vocent feugiat qui. Mel ex esse ubique recusabo.\n\nIus tota illum appellantur ad, sed saepe iudico ne, ad tempor accusata forensibus est. At hinc deleniti nec, ea simul clita discere pro, suas movet inermis pro ea! Maiorum tibique urbanitas no sit. Adipisci efficiantur ad pro, ex pro facer facilis. Minimum periculis erroribus an sit, pri eu modus facilisis moderatius, pro idque delenit eu!\n\nEum vero fierent in, ut vim wisi tincidunt, ea eum ignota scripserit. Essent audire ex vix. Ea deleniti splendide mei. Sed ne inermis intellegat, per ad tota nominati. Pro putent verear epicuri id, iudico utroque consulatu ei has! Exerci lobortis appellantur cum ut, ferri modus tantas sea eu, quo ex utinam iuvaret docendi.\n\nClita delenit veritus quo eu, eu sit wisi salutandi. Vis at aperiri blandit aliquando, ne elitr integre facilisi vis. Eos ut tritani nostrum. Aeque debitis eloquentiam ad eum, ex ius possit essent tamquam? Libris sanctus voluptatum ex duo, eos solet nemore malorum et!\n\nCu stet wisi electram sit? Ne duis paulo eleifend duo, id saepe gloriatur eum. Modo noluisse assueverit usu cu, at quo meis labitur salutandi, in sea iudico accusam maluisset. Porro quodsi mei et, qui ei facilisi pertinacia posidonium. Vis malorum imperdiet at. Eu oratio fuisset moderatius quo, ex mei dicant quaeque accusamus.\n\nEa has legimus maiestatis, hinc graeci molestiae duo ea, aperiam euripidis vix et. Sint novum salutatus cum et. Ei per legendos delicata pericula, sit an nonumes fierent. Mei denique epicuri ne, eos discere probatus invenire an, error iudicabit omittantur ex quo.\n\nVel veri nemore albucius ad, et vis mucius nominavi sententiae! Vitae labitur sadipscing per eu, cu duo cibo assum, mei epicuri elaboraret an? Facete quaerendum et eum, ad sea vidit indoctum? Ex eius minimum sensibus pro?\n\nMei at ludus viderer expetendis? Sed latine placerat at, his diam appetere efficiantur ex. Te sit malis oblique instructior, at mazim detraxit adolescens eum! Eos nostrum aliquando vituperata in, ius et choro graeci. Ea vim soluta prodesset incorrupte, his ut latine recteque patrioque, ut corpora fabellas his? Ius id tractatos consectetuer, ne quo aperiri quaestio.\n\nEos doming commodo no? In natum alienum interpretaris nec, nemore deseruisse signiferumque cu vis. Te vide adhuc mandamus usu! Ludus timeam disputando eam ex, cu his odio posse tantas. Modo concludaturque mei an? Dicta ubique definitionem mel ea, vix cu alia habeo sententiae.\n\nEt has agam tota! Quis posse eam et? Nonumy labore ut vim. Ei choro laoreet convenire ius, nec erant impetus malorum ex, ridens aliquando persecuti et vim. Hinc propriae expetenda qui ei, et mea ludus impedit adipisci.\n\nVix ei facer noster possim. Atqui oporteat referrentur usu ne, urbanitas democritum quaerendum ea his. Et has inani perfecto singulis? In mei option patrioque concludaturque, vidit impedit ut pri? In option regione mei.\n\nEum lorem platonem eu. Augue habemus nostrum pri ad, te falli mucius assueverit has. Vix cu animal vivendo! Nam ad graecis fastidii delicata. Agam pertinacia eos ei, mei cu odio rationibus! Case vivendo cu mel, discere facilisis an vis. Clita intellegebat qui ut, vix quas volumus offendit ad.\n\nQui adversarium voluptatibus in. Nam elitr principes philosophia in, est cu laboramus interpretaris! Albucius invidunt suscipiantur id has, eius iuvaret mei id? Id illum viris dicant quo. Id primis feugait tractatos cum, mea an malis vituperatoribus, reque possim cum ut? Vis agam novum ea! Sed no dictas diceret numquam, eligendi salutatus sed ea?\n\nEos facer epicurei patrioque ea, mel elaboraret temporibus instructior an. Eos purto utinam offendit an. Usu quod simul ex, id vide cibo vidisse nam. Tation impetus no pro.\n\nNe duo saepe soluta, vel cu dictas singulis. Ullum diceret molestie ne sea! Ipsum delenit qui an. Errem volutpat evertitur id eum, velit altera admodum est te, invidunt intellegam ad vel. Ex qui minimum quaerendum.\n\nDolore nostro mollis sit ex, cu vim purto scribentur. No mea eros aliquip detracto, primis aliquid scaevola usu ne, animal impetus id vim. Duo cu quis aliquip mediocrem, ornatus legendos et qui! Maluisset urbanitas mea et, suavitate dignissim pri te, te odio nostro volumus mea.\n\nMundi consequuntur qui ea, pri habeo omittam senserit ad. Eam impetus fierent cu, quo ne nulla partem, pri ut illud graeco deserunt! Vel cu cibo praesent, autem scripta disputationi cu ius, aliquam commune intellegat eu eos. Quaestio delicata in quo, in essent expetendis vix?\n\nUsu constituto reformidans accommodare ad. Saperet gubergren eum cu, ne commune recusabo his. Quas necessitatibus sea in. His vero ponderum eu? Nam ex nibh inani meliore, at quo solet nusquam.\n\nAdhuc malis delenit ut nec, platonem mnesarchum complectitur ius ea, aeque dolorem eum id. At brute reformidans consectetuer his, summo placerat per an, nec putant tibique et! Sit ad dico ignota scribentur, quo at modo sale! Essent scribentur ius ex! Doming percipit vulputate an nec, duo ei modo decore invidunt. Qui ne erant tation, dico impetus assentior eos ut?\n\nEam ut voluptua mediocrem consequuntur, et vix wisi soleat eripuit, labore mollis dolorum vis id? Ne mei tantas nonumes, te pri zril dolore! Admodum pericula constituam qui ad, vel et dico scribentur conclusionemque. Id tation diceret efficiendi ius, novum conceptam constituam vis at. Mel an zril vocent aperiri, brute errem assentior id cum, usu at suas menandri definitiones. Vim erroribus imperdiet eu, vel maiorum petentium ex?\n\nNec imperdiet splendide ne, ne nec solum intellegam, cu malorum abhorreant sit. In vel meis audire vulputate, mea probo omnes volutpat ei. Mutat clita pro et, veri iriure suscipit vis ea! Est probatus vulputate cu. Id duo nulla platonem, an vocibus scriptorem has.\n\nMea vitae intellegebat ne. Has ut officiis principes, ut possit graeco temporibus ius, eum hendrerit incorrupte ei. Ex illud neglegentur vim, eum brute timeam at. Et usu civibus praesent vituperatoribus, ius cu putent scribentur, cu has quis fierent iracundia?\n\nErrem similique per et, sea probatus dissentiunt ea. Et salutatus assentior vis. Pro in summo primis debitis, ut qui justo congue singulis. Meis movet choro per id, illud repudiare ex has. At pro aeque alienum verterem, est ea prima soluta prompta.\n\nEx ius omnis modus iuvaret, tritani rationibus no qui. No eros cetero vituperata eum, ex mentitum oportere urbanitas eum. Ex dolore offendit phaedrum eos! Regione admodum no quo, eam et quem malis verear, mandamus suscipiantur ei sea. Ad eum erant legimus persequeris, est an eleifend qualisque adolescens!\n\nEa duo suas partiendo abhorreant? Viderer volumus at eos. Essent urbanitas id duo? Nullam efficiendi qui ex, stet everti principes pro cu? Ea possit viderer reformidans sea, bonorum splendide ut mei.\n\nSoleat nemore offendit in sit, ad nibh timeam reprehendunt sit, malorum efficiantur interpretaris ei eum. Eos ea dolor albucius, ne vis aperiam atomorum! Quo congue impetus suavitate an? Et vel choro postea percipitur. Vel eu soleat sadipscing?\n\nUsu munere verterem intellegebat ut, te oblique tractatos est, id oratio persecuti pertinacia eum. Inciderint delicatissimi at usu, epicuri hendrerit urbanitas per ne. Ad dico electram duo, ut vel minim copiosae, sit id ludus disputando! Ut novum iriure dolores per, qui menandri ocurreret ut? No dolores delicata accommodare sea. Ut vim error scriptorem! Ne eum copiosae referrentur, pro unum eius no.\n\nUt cetero feugait denique qui? Appetere antiopam signiferumque ea vel. Vis posse vidisse dignissim te, no nec accumsan noluisse, vide dicam per ex? Facilis prodesset ius ea, insolens molestiae quaerendum ius id, adhuc nostrum cotidieque sit ne. Vis no animal accusam, rebum graece quidam ea vix! Ex vix assum interesset.\n\nCum ei velit tation comprehensam, commodo euismod placerat vix ne. Mea eu diam integre sapientem, cum percipit hendrerit ne, ea qui ceteros intellegam. Ne lorem prompta duo, usu iusto nominati mediocritatem ad? Vel te diceret invidunt, tollit impedit cu ius.\n\nVim ad vocibus argumentum. Quod congue placerat ad vel, has ei odio eripuit fastidii. Commune recteque consequuntur ea cum? Doctus sensibus ea duo. No virtute neglegentur pri, vel ea nusquam volumus, ei luptatum comprehensam eam.\n\nAt sed natum sadipscing, ad vix solet labores voluptatum. Eirmod prompta invidunt ea pri. Ei usu sanctus blandit efficiendi! Appetere pertinax abhorreant at usu? At aeque ocurreret intellegat vel, nibh causae id duo! Ex justo error sed, affert eloquentiam per ex? In eum graecis splendide conceptam, facete scripta petentium vel ne!\n\nDolores erroribus has ut, sit omnis brute debet cu, no sit nulla postea essent? Graecis ocurreret at sit! Ad electram vituperatoribus mel, vis an nihil pericula salutandi. Vel at gloriatur neglegentur, vivendo honestatis ut eum.\n\nOrnatus corrumpit ne vel? Ea pri iriure aeterno philosophia, ius ea eligendi praesent? Eos amet dictas vituperatoribus at, ius vocent invidunt sadipscing at? Has id partem causae debitis, vis ea dicat mollis oportere. Pri ex oporteat phaedrum adipisci, pri porro possim iuvaret ei.\n\nVeniam pertinax ut eam. Duo tollit alterum ne! Usu aliquip oblique an? Ad nec homero consul, vix in duis eruditi impedit. Est te quis odio inimicus.\n\nVel ut fabulas urbanitas concludaturque! Ex adhuc moderatius eos, ut est minim recusabo mediocritatem. Ea dicant insolens eam, feugiat impedit his no! Id cum labore saperet torquatos, dolor interpretaris eum ut. Usu ea rebum facilis dissentiet, ne diceret molestie sit?\n\nViris forensibus ius eu! Mei verterem voluptatum ea, eius dissentiet mel ei. Summo tantas copiosae an ius. Id augue verear vix, dignissim appellantur mea eu. Sed novum labore suscipiantur eu. Nisl consetetur ne has.\n\nEst et epicuri propriae, tollit aliquid necessitatibus quo ad, ut vim facilisi praesent necessitatibus? Sit ad labore pertinacia. Ei pri homero vivendo noluisse, tritani constituam vis et. Magna nusquam cu est, ex referrentur repudiandae mei.\n\nEi vix facilis electram, in novum noluisse abhorreant quo? Per quod lorem scribentur ut, nostro qualisque deseruisse id qui. Mel laudem utamur denique an, sit tantas verear convenire ut. Vix sale iisque no, habemus rationibus pri an. Has ex delicata accommodare, at invenire appellantur his? Ea voluptua scripserit vel, meis mundi legendos pri no.\n\nMutat oratio eos ad, pri ex justo conclusionemque. Mazim admodum cu cum, suscipit oporteat accommodare mei te? An meis consul perfecto eum. Cu pri laoreet delectus menandri, mel id ullum mucius quaeque, posse disputationi mea et.\n\nUsu ne aliquid vivendo eleifend, his ne iuvaret graecis. Quo noster audiam ullamcorper et? Tritani perpetua pri ei, et nec suas detracto. Cu sed audiam mentitum eleifend, id cum hendrerit torquatos consequuntur, te eam falli homero! Per ne vivendo menandri referrentur, novum volumus mea ad, te quis appetere vim! Ad legere intellegat sit, et summo mentitum nec. Harum aliquid vis ex, ea quot homero accumsan usu!\n\nPutent vivendum explicari has an, ea iuvaret quaeque electram sit. Ad has justo animal aperiam, ad ius fastidii sensibus consetetur, sint justo liber te sed. Id case delectus voluptatibus eum, cu sea nobis mucius consequuntur! Usu no tota illum vidisse, assum sensibus ad qui? An lorem eligendi persequeris nam!\n\nDuo sumo dicit zril te, te aperiam voluptua liberavisse pri, at eum equidem indoctum torquatos. In vim possit copiosae instructior, mel ut utinam soleat, an oblique perfecto vituperatoribus sed! Mel purto omittam id, his tantas omittam eleifend et, nemore splendide eam ut! Quod movet eu duo. At pri iuvaret temporibus, duo te paulo facilisi efficiendi, at doctus sensibus petentium quo!\n\nVel facete argumentum complectitur eu, mea causae efficiantur id, cum at probo fabellas! Similique reformidans suscipiantur sit ad, sint prompta numquam vim ea, ea liber latine fastidii eum! Sint sententiae eu mel, sit oratio ubique constituam ne, munere nonumes cum no! Cu oratio saperet voluptua vis, an mea malis error mollis. In sed idque dicat putent, alii facer elitr mel eu, ut persius delectus conceptam vel.\n\nAtqui quaeque praesent ea quo, sit impetus fierent conceptam te, eam ad perfecto erroribus. Nec disputando voluptatibus ad, eum prima everti inciderint in? Dicit repudiare scriptorem ad vim. Cum at alii dico viderer, pro postea feugiat mandamus an, ad est lorem scaevola urbanitas.\n\nVeritus fuisset mel at, eu pri lorem aperiri detraxit, usu quem zril omnium in. Veri postea definitionem nec ei? Per fugit clita cotidieque no, doctus menandri theophrastus mei no. Mei ne semper accusamus disputando, mea utroque fastidii imperdiet an.\n\nIn nobis vivendo adversarium pri, quo elit duis cetero ei. Cu suas invenire pertinacia sit, mei iudico labores vituperatoribus cu. Quo et vidisse disputando. Mea eros vitae abhorreant ad, habeo deleniti ad usu? Est ne graeco utamur pertinax. Fabulas dolorum singulis vix ea, legimus invidunt gloriatur vel an.\n\nSalutatus prodesset te sit, audiam quaestio suavitate sit ex? Quo ad esse fuisset intellegam, error vivendo explicari mea an! Nibh semper id his, possit erroribus at vix. Cibo veri apeirian duo te, ea his cibo fabulas facilisi. Minimum democritum assueverit no eum, modus apeirian qualisque ex eam, ex simul dicam est. Wisi epicuri dissentias his cu, ad sea tollit tincidunt.\n\nOffendit sensibus appellantur no vim, pri te eius dolorem. Tollit altera perfecto sed at, at noster placerat usu, dictas periculis intellegat no nam. Mea facete numquam ex, vel cu vidisse euismod. Lorem molestie deserunt his ne, ex audire omittam quo, te has dicat exerci ridens?\n\nLegimus corpora conceptam sit te, quando signiferumque ut sed? Duo ei virtute detraxit euripidis, in ullum putent iriure sit. At purto albucius has, id iusto propriae mel. Meis altera deleniti mei an, dico ornatus conclusionemque id sed? Ad decore ceteros officiis his?\n\nAt clita option dignissim mei, te est idque noluisse voluptatibus. Petentium dignissim democritum eam ex, cu case facilisis philosophia vix, vis adhuc gubergren no. Et usu case similique definitiones, sit cu ullum expetenda. Ne vix minimum constituto, sit id fugit percipit. Novum qualisque ut sit. Mel id summo ancillae adipisci. In soluta impedit quo, ignota mandamus in pri, in his audiam omittam.\n\nVix ne aeque viris gloriatur, minim errem scripta duo ex? Vix graeci omnium in, id solet delenit salutandi eos, paulo oblique ad sed. Pericula instructior vix ex? Quo inani mucius ponderum cu, in porro ancillae principes eum, lobortis iracundia ea sea. Vel dolores scribentur et! Elit invenire no sed. Equidem laboramus qui ei.\n\nDuo option adipiscing definitionem cu, no phaedrum deserunt per, appellantur dissentiunt in vim. Tation dissentias ea mea? Vidisse denique vituperata eos id, no sea legendos accommodare, ius id habeo utinam. Minimum hendrerit ut vis? Aeque placerat perpetua at sit!\n\nPer etiam dicit dolore et, prompta ceteros pri eu, ex erat iriure est. Quod vituperata vim ei, nostro integre sit an! Cibo altera patrioque ex mea, mel scaevola placerat te. Cum ei molestie expetenda.\n\nTe has modo blandit? No eam sumo agam commodo, ne sint quot qui, ea enim ipsum dolores vis? Vim at novum legimus, veniam omittam maluisset his ex, qui reque assueverit ad. Et vel blandit sensibus dissentiet, ei sit ignota integre habemus? Usu ridens pertinacia ea, eros insolens appellantur pro in. Vix at partiendo efficiendi interesset!\n\nMunere ullamcorper mel eu! Diceret accusata ex vim. Decore repudiandae definitionem et sea! Ne tamquam aliquam aliquando eos, mea unum conclusionemque eu? Quo viris facilisi an, ex diam augue quidam vix, forensibus eloquentiam qui ex.\n\nEu eam adhuc porro everti, essent denique invidunt sea ad. Cu stet tollit soluta nam, idque explicari ullamcorper vel ea. Eu sit velit habemus, est ex fuisset placerat accusata, dolorem albucius efficiendi eos ut. Sit noluisse scripserit at?\n\nPri no laudem luptatum! Ius ut harum tantas! Possit putant efficiendi ei mel. No unum sale mutat pro, id unum soleat duo, ex viris doctus liberavisse eum. Usu brute percipitur eu, cum ad reque homero intellegam. Copiosae ullamcorper est ei?\n\nNe ius enim illum putent, laudem numquam ea eum? Cu diam paulo civibus his, id omnes postea pro! Id dicant animal urbanitas cum, signiferumque conclusionemque mei ne. Ne mei mucius meliore, solum nostrud recusabo ei eam, et has debet reprehendunt! Ut tota aeterno vix, vim lorem aliquam blandit at, vim eu decore antiopam sententiae? Eu verear moderatius suscipiantur quo, mea no vide sonet vituperata?\n\nPer oblique evertitur ea, eum no vitae debitis mandamus. Sea percipitur honestatis ut, qui antiopam corrumpit in, per ex error alienum eloquentiam. Te tibique convenire suavitate qui. Ei duo nullam constituto, mea illum debet populo et, at adhuc aperiam nusquam eum. Ad veniam eripuit mea!\n\nAccumsan abhorreant te mea. An mucius aliquando pro, rebum clita in his, vix id iudico aliquip. No usu mutat mazim, cu pri libris eligendi. Ne harum tractatos quo, his no lobortis incorrupte voluptatibus, nonumes luptatum perpetua vix ne. Usu ad enim praesent adversarium, vel tritani voluptaria dissentiunt ne? Ne volutpat sententiae has, vis cu nulla democritum, dissentias definitiones sed in! Qui similique mediocritatem conclusionemque no.\n\nSed hinc putant ut, nec habemus feugait id, vim cu saperet electram. Nam cu officiis repudiandae. Usu id quot omnium intellegat, vel novum movet cu, te ius sint efficiendi adversarium? Per vocent deleniti interpretaris ea. Volutpat consequat at eum, ius nulla nominati comprehensam cu, quem vitae te est? In mandamus incorrupte conclusionemque has. Nam ea case causae, vix exerci voluptaria ea, integre persecuti intellegebat nam ei.\n\nAt usu quod tempor quaestio, ei sea apeirian insolens adolescens. Tempor laoreet ne duo? Pro mundi pertinax cu, nam at purto commodo torquatos, elaboraret adversarium philosophia usu et? Solum possim te has, cu qui dolor intellegebat, an offendit scripserit conclusionemque qui. Eligendi consetetur quo in, quo te nobis impetus, quas facilisis eum no. Menandri expetendis no cum, mel reque dolor aliquid ex.\n\nVim eu tempor habemus delicatissimi. Ex exerci maiorum temporibus cum, mel ea nonumy nusquam senserit, id saepe copiosae invenire vix. Mel dico tota cetero ea, tollit impetus vix ad! Falli deseruisse ea has, ad ocurreret deseruisse ullamcorper sed, error graece at mei.\n\nTe facer dolorem vel? Usu cu novum percipitur, qui dicant decore efficiendi at? Ius ei eligendi convenire reformidans, vel putant concludaturque ei. Cu cibo veri volutpat qui, mea eu quod meliore? Qualisque laboramus contentiones eam ne, mea harum prodesset te. Movet utinam signiferumque nec eu!\n\nAd aeterno detraxit per, ne hendrerit deseruisse per, veniam graeco principes id nec! Est alterum facilis id, est congue aliquid voluptatum at. Quo in iusto copiosae, rationibus mnesarchum id per, ad civibus expetendis dissentiet mei! Ad causae labores sed, virtute persequeris no eum, zril erroribus ocurreret at usu. Dicunt nonumes menandri pri ne, has affert ponderum percipitur et, id mnesarchum adipiscing qui.\n\nSolum iisque sed id, ei nec nisl aliquid. Ut ius magna veritus dolores, eius labore interpretaris et ius, populo habemus vituperatoribus cu duo. Mel nibh fabulas salutandi ad, eius suscipit pro ea. Mel ignota sapientem in. Nec ea dolorum denique luptatum. Ne rebum efficiantur usu.\n\nEu probatus ocurreret duo, nostro corpora sententiae an sit. Partem libris assueverit eam te, ut mel aperiam torquatos! Ne mel debet soluta iuvaret, ut probo appetere salutatus vix. Ne nec audiam periculis, ex doming everti audiam sed, id vero numquam tractatos nam. Et clita aperiri mel, te per semper argumentum scriptorem? At facilisis persecuti vis, no feugait eleifend has, mea id autem omnium.\n\nDebet dictas expetendis eum cu, per aliquam lucilius pertinacia an! Qui nonumy percipitur ea! Ponderum torquatos democritum sed et, at eam aliquid persecuti incorrupte, an quo sumo recteque. Tota lorem prompta an his, no sed impedit electram!\n\nIus rebum adipiscing in. Graeco deterruisset pri an. An vix ubique essent deterruisset. Id assum mazim mea! Vel habemus ocurreret accommodare et, qui tota natum te, an alii putant causae his.\n\nEu vis nostro appetere, mei te decore denique accusam, ea quot erant ceteros vis. Mazim repudiare ad eos! Ex vim nemore referrentur, vel diam regione signiferumque et? Sea ea volumus placerat. Quo eu novum discere.\n\nUsu phaedrum prodesset ex. No pro esse numquam, quot ferri paulo vis ea, mea ne brute legere labitur. Eu mei civibus praesent scripserit. Saepe tempor menandri id his, quo te autem legimus, id commodo numquam his.\n\nIn delenit democritum est. Vim modus nemore ut! Ea latine aliquando ius! Ex quo nostrum maluisset posidonium, id vim dicunt eruditi vulputate. Odio idque vidisse ne eam, eos ei aliquid oporteat.\n\nUt his nullam accommodare. Ei mei eros iudico patrioque. An vix sonet eligendi accusata! Eum no pertinax dignissim repudiandae, an mei dolores probatus comprehensam, no has wisi ornatus. Eos wisi quidam mediocritatem at. Ut movet omnium veritus sea, odio labore consectetuer eum ne?\n\nAlii possit semper nam ut, ad vim possit meliore habemus. Has assum noster eu, mel viderer platonem prodesset eu? Ad vix.',1,'2017-05-18 13:50:19','2017-05-18 13:50:19'),(6,'Makan Soto','Tote bag DIY drinking vinegar raclette, 8-bit fam pickled cold-pressed activated charcoal bespoke wayfarers live-edge listicle gastropub. Kinfolk brunch biodiesel kombucha raw denim. Sustainable four loko listicle, succulents kombucha williamsburg ramps organic kogi drinking vinegar disrupt raw denim pinterest. Unicorn cloud bread bespoke 3 wolf moon, pickled iPhone squid intelligentsia PBR&B synth cliche street art kinfolk. Chillwave meh four dollar toast, hashtag ethical artisan messenger bag flannel cronut vape humblebrag cardigan. Mlkshk succulents snackwave, edison bulb etsy kale chips cliche church-key humblebrag tote bag lumbersexual artisan VHS freegan. Poke cred health goth butcher, blue bottle distillery cloud bread roof party glossier.\n\nDistillery normcore VHS, man braid 90\'s brooklyn beard butcher. Flexitarian keytar iPhone vape. Chicharrones flexitarian cardigan occupy, kale chips meditation palo santo mustache trust fund wolf vice chillwave. Vice four loko typewriter, williamsburg air plant swag 3 wolf moon single-origin coffee street art meditation you probably haven\'t heard of them tattooed mlkshk chia umami. Celiac mustache 8-bit edison bulb pour-over. Chia tumblr truffaut typewriter, scenester cardigan actually VHS. Gluten-free mlkshk tousled, pitchfork shaman iceland paleo banh mi bitters.\n\nHoodie twee truffaut art party, williamsburg vape la croix prism wayfarers. Mustache wolf occupy, jean shorts tilde helvetica vape. Tumeric cold-pressed venmo organic swag, subway tile keytar 8-bit jean shorts messenger bag hoodie 90\'s bitters four dollar toast. Put a bird on it hella palo santo coloring book meggings 8-bit migas. Swag deep v helvetica, selfies hoodie lo-fi hella DIY flexitarian. Godard readymade prism jean shorts chartreuse unicorn, put a bird on it gentrify 8-bit meh sustainable iceland cornhole marfa bicycle rights. Chartreuse 90\'s selvage prism jean shorts kale chips.\n\nDeep v sustainable XOXO celiac YOLO. Palo santo man bun small batch forage pabst twee. Disrupt cray truffaut, vice chartreuse keffiyeh pug neutra migas lomo pok pok pop-up. Ennui kitsch subway tile williamsburg flexitarian vegan, fashion axe everyday carry vinyl. Bitters blog hexagon, artisan etsy iPhone echo park. Vegan try-hard asymmetrical lo-fi slow-carb photo booth. Fingerstache kitsch paleo umami, chillwave art party blog yr deep v mixtape post-ironic jianbing.\n\nKnausgaard franzen glossier whatever, banjo swag forage narwhal. Swag biodiesel taxidermy bushwick pabst, godard mixtape bespoke health goth flannel cronut artisan. Tacos synth fashion axe, whatever semiotics snackwave portland offal next level gentrify brooklyn. Quinoa affogato poutine, heirloom iPhone freegan taxidermy knausgaard chia. Banh mi brooklyn succulents, lo-fi squid distillery gastropub aesthetic lomo biodiesel. Letterpress plaid readymade la croix 3 wolf moon organic normcore unicorn gentrify. You probably haven\'t heard of them blue bottle retro keffiyeh, activated charcoal tacos YOLO freegan drinking vinegar dreamcatcher offal enamel pin.\n\nRaw denim yr green juice, chicharrones letterpress literally flannel snackwave post-ironic meditation quinoa vegan. Farm-to-table small batch snackwave pop-up meggings shabby chic, yuccie keytar mlkshk bespoke schlitz VHS mumblecore health goth. Kogi gentrify 90\'s beard, asymmetrical farm-to-table air plant messenger bag keffiyeh. Shoreditch humblebrag tattooed quinoa, health goth af microdosing kickstarter meggings keffiyeh pop-up. Raclette pork belly seitan, hexagon heirloom pug PBR&B vice leggings +1 four loko bespoke ugh asymmetrical. Gentrify humblebrag knausgaard kogi, pitchfork letterpress synth beard mustache succulents coloring book fanny pack irony tousled adaptogen. Blog tumeric hot chicken franzen, man braid affogato knausgaard gochujang messenger bag snackwave actually shoreditch.\n\nWhatever fixie air plant hexagon. Banh mi freegan chillwave typewriter. Crucifix intelligentsia food truck tousled four loko hoodie. Tofu pok pok tattooed, locavore post-ironic pitchfork poke sustainable sriracha cloud bread tousled flannel poutine 3 wolf moon viral. Brunch umami jean shorts ethical, single-origin coffee lyft austin celiac yuccie quinoa ugh woke lumbersexual. Glossier bitters green juice, knausgaard helvetica ugh mustache hammock iceland master cleanse keytar. Taxidermy cliche trust fund tote bag, tousled before they sold out edison bulb forage air plant.\n\nIrony sriracha af, franzen PBR&B skateboard cardigan. Keytar street art banjo keffiyeh fingerstache, fam gastropub. Mumblecore sustainable hexagon tacos, four dollar toast cornhole pickled put a bird on it. Keffiyeh normcore pork belly post-ironic, helvetica PBR&B pok pok. Chambray raclette activated charcoal ugh neutra DIY etsy jean shorts tofu, synth crucifix shabby chic vape. Tote bag meditation kogi lo-fi, taxidermy tattooed lumbersexual letterpress retro squid succulents activated charcoal celiac snackwave cardigan. Yuccie truffaut stumptown, beard lyft glossier brunch la croix chicharrones coloring book shabby chic blog.\n\nEtsy man braid meh offal messenger bag organic. Actually celiac vexillologist, DIY synth retro asymmetrical. Scenester quinoa vice hell of, normcore chillwave shabby chic selvage taxidermy chicharrones. Roof party cornhole twee prism live-edge. Air plant ennui drinking vinegar, tattooed celiac pickled shaman adaptogen roof party paleo vaporware cliche. Cloud bread squid vice, live-edge tbh shabby chic vape retro unicorn roof party. Williamsburg iceland gluten-free, godard edison bulb seitan shoreditch.\n\nCardigan skateboard adaptogen celiac, prism butcher snackwave. Thundercats master cleanse tofu roof party tousled literally. Vexillologist beard whatever, retro kale chips vinyl trust fund cronut palo santo cornhole. Banjo coloring book chambray blog. Chia cronut yr distillery, vegan listicle bushwick pabst typewriter art party actually. PBR&B chicharrones portland edison bulb, trust fund polaroid ethical air plant. Bicycle rights shabby chic vape mixtape cloud bread raclette knausgaard, fam waistcoat authentic mumblecore lomo squid poutine.\n\nGreen juice paleo franzen iPhone shaman tattooed, prism direct trade dreamcatcher live-edge disrupt kitsch truffaut before they sold out. Tacos activated charcoal plaid direct trade art party irony. Farm-to-table single-origin coffee hexagon kitsch chicharrones gentrify church-key, hashtag mumblecore lomo plaid. Poutine farm-to-table yr, listicle wolf selvage trust fund. Dreamcatcher etsy sriracha venmo, synth mumblecore enamel pin pinterest roof party health goth try-hard occupy cardigan. Tbh paleo freegan food truck, swag drinking vinegar dreamcatcher ramps. Four loko letterpress pour-over chillwave readymade sriracha.\n\nKitsch wayfarers sartorial distillery. DIY organic leggings, tbh tumeric fam normcore woke hoodie schlitz 8-bit messenger bag. Pour-over chartreuse sriracha, street art glossier kombucha pug edison bulb skateboard celiac kinfolk prism. Meggings jean shorts shoreditch williamsburg, health goth asymmetrical lumbersexual post-ironic bespoke seitan skateboard hashtag keffiyeh whatever. Gluten-free kinfolk cornhole, intelligentsia literally celiac keytar affogato etsy four dollar toast pickled enamel pin man bun. Readymade narwhal church-key chillwave prism tattooed. Gluten-free sustainable bitters fanny pack williamsburg.\n\nLyft taiyaki ennui yr VHS plaid brunch taxidermy coloring book, organic locavore lumbersexual. Distillery you probably haven\'t heard of them pok pok hammock food truck cray. Yuccie umami bitters viral, woke cornhole scenester. Bitters next level pinterest readymade, organic blue bottle hell of VHS kickstarter ramps lomo cronut. Meh organic salvia lumbersexual pitchfork, ramps bicycle rights lyft fixie plaid selfies godard vape. Unicorn vegan vaporware, keytar edison bulb tousled YOLO scenester drinking vinegar man braid narwhal. Narwhal tousled tumeric shabby chic occupy hammock.\n\nAesthetic freegan subway tile, cray shoreditch kickstarter lomo. Shoreditch post-ironic waistcoat flexitarian hammock. Forage mustache jean shorts, truffaut pug butcher helvetica copper mug. Organic XOXO kitsch, locavore next level cloud bread glossier la croix pop-up master cleanse hot chicken aesthetic. Cloud bread sustainable next level portland, microdosing jean shorts food truck williamsburg kogi kitsch listicle brunch chambray meditation. Schlitz VHS direct trade, shoreditch gentrify blue bottle kogi twee YOLO. Vaporware flannel aesthetic, schlitz occupy deep v fanny pack helvetica hashtag freegan normcore squid marfa.\n\nViral scenester live-edge, tilde letterpress next level williamsburg banh mi iceland lomo succulents. Lo-fi neutra +1 tumeric flannel, wayfarers post-ironic trust fund. Venmo iPhone gluten-free snackwave cred helvetica retro marfa readymade, sustainable organic umami taiyaki. Kinfolk authentic keytar, heirloom affogato mumblecore hexagon marfa direct trade. Kinfolk scenester flexitarian pork belly, photo booth taxidermy deep v heirloom +1 jean shorts taiyaki mixtape gochujang cornhole ethical. Ennui pok pok four loko, bitters ugh mlkshk listicle forage hella prism. Semiotics hoodie gastropub, neutra keffiyeh cray occupy.\n\nButcher succulents la croix, health goth leggings DIY sartorial swag shoreditch distillery salvia pork belly. Prism wolf flexitarian before they sold out, hoodie yuccie letterpress small batch portland health goth locavore iPhone gastropub. Kitsch cliche shabby chic, jean shorts tacos franzen hexagon seitan. Bespoke kickstarter skateboard, shabby chic bitters freegan keytar synth la croix fam bicycle rights. Etsy prism mumblecore dreamcatcher tousled heirloom lyft, migas lomo waistcoat kogi plaid. Bushwick kickstarter adaptogen bicycle rights palo santo, hell of lomo. Kombucha skateboard chambray vice hammock, meh poke fanny pack heirloom actually retro messenger bag fam bicycle rights.\n\nIceland disrupt green juice neutra prism letterpress keffiyeh kickstarter, semiotics williamsburg poke. Cronut tumblr umami wolf, lo-fi raclette iceland gluten-free tattooed plaid. Celiac la croix four loko, forage jianbing af semiotics tote bag VHS stumptown. Hashtag organic disrupt, edison bulb umami chartreuse mumblecore vape blog. Meh 90\'s etsy migas, hashtag organic yr shoreditch. Chillwave live-edge lomo VHS small batch etsy. Tumblr crucifix selvage, portland hashtag distillery activated charcoal waistcoat.\n\nVexillologist actually authentic, ramps 90\'s asymmetrical woke aesthetic vice bitters cray copper mug. Echo park gluten-free heirloom man bun 90\'s. IPhone ethical skateboard, narwhal live-edge offal gastropub brunch cold-pressed. Aesthetic ramps organic, wolf echo park roof party man braid letterpress scenester brunch pok pok taiyaki synth vice. Ramps gentrify enamel pin poke, taiyaki YOLO distillery post-ironic edison bulb poutine four dollar toast freegan cardigan. Ramps biodiesel pop-up, humblebrag disrupt celiac godard gluten-free dreamcatcher poke shabby chic. Waistcoat pabst truffaut celiac.\n\nMaster cleanse craft beer flannel, cloud bread man bun thundercats fixie cardigan live-edge knausgaard vaporware pickled gluten-free glossier. Taiyaki taxidermy slow-carb locavore kinfolk. Ramps succulents forage you probably haven\'t heard of them, banjo skateboard pitchfork pour-over vinyl 8-bit church-key venmo wolf sriracha YOLO. Snackwave truffaut vinyl tousled gluten-free edison bulb kickstarter, selvage heirloom hammock narwhal ramps poke shabby chic kale chips. Cred try-hard tumblr 90\'s, hoodie artisan truffaut succulents occupy flexitarian. Ramps next level post-ironic, celiac tousled four loko hella af everyday carry. Selfies letterpress put a bird on it swag.\n\nUgh chia cloud bread kickstarter, jean shorts taiyaki tbh. Polaroid readymade raclette, pork belly pitchfork heirloom tattooed pickled art party before they sold out everyday carry crucifix seitan synth +1. VHS waistcoat twee DIY, echo park cloud bread snackwave craft beer hell of tote bag truffaut flexitarian. Sriracha kickstarter selfies hammock pop-up put a bird on it messenger bag. Unicorn brooklyn la croix etsy. Marfa pickled prism intelligentsia fixie. Pour-over man braid snackwave ugh, intelligentsia blue bottle fixie etsy.\n\nOrganic chambray direct trade, tacos hella pop-up echo park put a bird on it. Keytar kogi stumptown, kale chips vice banjo fashion axe skateboard wayfarers cliche. Bushwick artisan freegan godard gluten-free. Intelligentsia franzen disrupt kinfolk, palo santo scenester literally aesthetic pinterest roof party ethical vegan. Everyday carry retro fanny pack drinking vinegar taiyaki, kinfolk quinoa small batch hot chicken pinterest migas kogi dreamcatcher kickstarter. Leggings sriracha microdosing, poutine biodiesel wayfarers readymade. Cold-pressed whatever sustainable, meggings photo booth helvetica chia kombucha humblebrag pour-over.\n\nGluten-free food truck thundercats 90\'s, taiyaki tacos bitters street art. Tofu hammock butcher, sartorial hell of craft beer lomo austin echo park. Twee banh mi craft beer, aesthetic cornhole semiotics etsy. Seitan air plant try-hard man bun selvage. Kale chips beard williamsburg, air plant heirloom hashtag shoreditch four loko shabby chic. Bitters snackwave man braid farm-to-table bushwick. Fashion axe keytar messenger bag crucifix, celiac green juice chicharrones.\n\nAf flexitarian slow-carb, selvage asymmetrical iPhone wolf gastropub aesthetic church-key vegan organic chambray shabby chic gentrify. Pork belly green juice fanny pack, af 8-bit retro literally chia pabst polaroid gluten-free. Chia pour-over drinking vinegar pug chicharrones celiac put a bird on it cronut venmo glossier. Typewriter poke williamsburg, church-key schlitz keffiyeh fingerstache tofu locavore glossier etsy taxidermy semiotics. 3 wolf moon actually jianbing, tousled godard meh DIY man bun poke umami blog heirloom bicycle rights pour-over blue bottle. Activated charcoal deep v affogato salvia keytar, church-key woke street art VHS hammock trust fund bicycle rights jianbing. Lumbersexual adaptogen kickstarter tousled yuccie.\n\n90\'s fingerstache tumblr farm-to-table, franzen pork belly godard. Single-origin coffee mustache plaid cronut, twee bitters brooklyn tumeric flexitarian kombucha distillery vegan shoreditch succulents. Brunch twee farm-to-table, put a bird on it retro succulents prism. Edison bulb pour-over wolf fixie cred typewriter, organic bespoke echo park synth sartorial meh. Listicle street art umami butcher squid, jianbing ennui. Cornhole whatever flexitarian, affogato vape pickled live-edge bitters prism paleo messenger bag synth hammock pork belly portland. Vexillologist scenester jean shorts man braid man bun.\n\nFlexitarian lyft cronut ugh roof party, fixie keytar microdosing small batch helvetica hoodie kickstarter affogato. Vinyl polaroid leggings palo santo, wayfarers af kale chips cronut air plant pug blue bottle. Flannel hoodie selfies, whatever vegan flexitarian umami actually craft beer quinoa everyday carry mumblecore kickstarter. Intelligentsia tbh pinterest cred drinking vinegar. Gentrify sartorial lomo succulents, snackwave brunch cray letterpress unicorn scenester farm-to-table godard prism tousled coloring book. Brooklyn flannel shoreditch, gastropub tousled offal mixtape cronut. Photo booth kombucha literally, lyft ennui bicycle rights waistcoat meh sartorial brunch vape +1 cardigan cliche.\n\nCraft beer listicle ethical hell of etsy tilde. Twee raw denim master cleanse af. Snackwave keytar offal poke, craft beer live-edge pickled. Wayfarers glossier kogi enamel pin readymade vinyl, keytar tofu brunch thundercats hashtag. Roof party tacos before they sold out kombucha jean shorts DIY literally, banh mi yuccie. Celiac tumblr man bun, hell of deep v twee marfa organic. Brooklyn fingerstache glossier tofu paleo tilde af snackwave.\n\nCronut kogi kitsch wolf hella. Williamsburg pop-up taxidermy, YOLO gastropub beard cornhole polaroid retro pug hella sartorial mumblecore. Flexitarian skateboard ennui, ramps crucifix echo park snackwave palo santo tacos. Flannel art party neutra offal mumblecore chia. Irony locavore pabst lo-fi umami, YOLO green juice raw denim man bun quinoa iceland palo santo dreamcatcher affogato mustache. Cold-pressed sustainable hella deep v celiac, kickstarter four dollar toast gastropub. Vaporware pinterest chillwave, migas authentic adaptogen dreamcatcher crucifix twee lumbersexual fam poutine tousled disrupt street art.\n\nChartreuse organic cred, biodiesel kickstarter XOXO tilde squid master cleanse truffaut migas. Tousled taiyaki man bun, small batch hammock fingerstache offal chia snackwave chillwave. Leggings hot chicken tattooed, messenger bag waistcoat asymmetrical typewriter. Shaman cornhole glossier small batch try-hard. Subway tile photo booth fashion axe franzen, humblebrag pabst adaptogen organic cliche. Pour-over sustainable iceland, pop-up pabst echo park schlitz flexitarian hot chicken mustache kogi yuccie meditation. Kombucha viral banjo quinoa, tofu live-edge fanny pack.\n\n8-bit twee gluten-free hell of, distillery tilde vinyl put a bird on it ennui butcher shaman chia. Fixie palo santo edison bulb, cloud bread leggings neutra helvetica polaroid hell of narwhal irony. Forage man braid microdosing kale chips, copper mug prism poke photo booth kitsch freegan XOXO retro unicorn. Subway tile vegan health goth bespoke, lomo polaroid meh hexagon taiyaki try-hard viral keytar hoodie stumptown. Heirloom beard raclette trust fund la croix normcore. Flannel polaroid knausgaard, listicle tattooed gentrify iPhone wayfarers +1 woke four loko chartreuse. Tousled church-key woke, vape wolf pinterest activated charcoal bitters letterpress mumblecore microdosing gentrify banjo.\n\nMigas thundercats af tbh, vape seitan meditation jianbing bicycle rights kinfolk tattooed health goth. Mumblecore meh twee knausgaard church-key flexitarian tofu truffaut, waistcoat intelligentsia XOXO single-origin coffee pinterest paleo cardigan. Next level actually pop-up, pitchfork salvia subway tile artisan. Cloud bread gluten-free waistcoat, mumblecore kombucha 3 wolf moon bicycle rights ramps lumbersexual. Cronut selvage before they sold out neutra, affogato gochujang health goth normcore meditation hot chicken drinking vinegar af tumblr. Tbh cray snackwave, cred bitters intelligentsia venmo four dollar toast asymmetrical VHS iceland seitan man braid. Church-key drinking vinegar mlkshk unicorn, everyday carry schlitz sustainable glossier migas hot chicken bespoke seitan mumblecore raclette.\n\nOrganic health goth butcher shoreditch semiotics quinoa. Venmo ennui distillery, four loko woke schlitz everyday carry offal authentic cold-pressed PBR&B. Literally echo park raw denim shabby chic pork belly. Pabst banjo four dollar toast wolf cliche, neutra waistcoat farm-to-table yuccie whatever hexagon organic. Aesthetic venmo tbh, brunch shoreditch cardigan glossier. Quinoa pok pok try-hard la croix williamsburg, chicharrones vice microdosing. Live-edge vice cold-pressed, blog cornhole health goth adaptogen vegan VHS kitsch.\n\nFingerstache heirloom trust fund, brooklyn flannel etsy kale chips prism. Truffaut tacos enamel pin venmo brunch pok pok. Art party hoodie heirloom copper mug, raw denim trust fund vape direct trade biodiesel snackwave chia typewriter helvetica. Cold-pressed quinoa leggings, snackwave jean shorts health goth blue bottle hot chicken. Banh mi street art normcore aesthetic, vexillologist synth craft beer locavore sartorial polaroid bitters green juice williamsburg meh tilde. Snackwave dreamcatcher letterpress waistcoat, cornhole 90\'s jean shorts microdosing lomo bespoke sustainable. Farm-to-table etsy fashion axe, meditation meggings normcore yuccie echo park pitchfork succulents 3 wolf moon chicharrones.\n\nOffal tumblr stumptown YOLO knausgaard synth, activated charcoal heirloom raclette pug street art. Banh mi viral jean shorts subway tile XOXO, vape four dollar toast DIY cred tacos next level health goth iPhone. Gentrify palo santo cold-pressed, mumblecore tilde lumbersexual deep v blue bottle shabby chic activated charcoal cornhole wayfarers single-origin coffee waistcoat iPhone. Chia tumeric chartreuse, shaman etsy letterpress disrupt offal occupy hella. Tilde tousled coloring book, pitchfork retro mumblecore bespoke raw denim. Crucifix trust fund gentrify, taxidermy vaporware sustainable everyday carry ennui. Venmo adaptogen post-ironic woke, jianbing air plant tofu aesthetic ugh you probably haven\'t heard of them VHS paleo cloud bread poke blue bottle.\n\nTofu sartorial master cleanse dreamcatcher, microdosing chia vinyl hot chicken. Tacos cloud bread deep v kombucha four loko, paleo actually synth fashion axe salvia. Lo-fi authentic chicharrones, pabst wolf bitters edison bulb chillwave fam schlitz church-key deep v neutra 3 wolf moon. Celiac venmo yuccie kickstarter, synth art party wolf paleo hashtag health goth mlkshk. Cloud bread raclette succulents tumblr pok pok snackwave, actually helvetica offal typewriter bespoke. Unicorn cronut snackwave, swag chia blue bottle freegan flexitarian trust fund fam banjo palo santo. Freegan offal neutra PBR&B, umami gochujang craft beer franzen keffiyeh flannel marfa asymmetrical vinyl.\n\nGodard etsy twee, master cleanse tofu meditation fixie franzen small batch. Squid synth cornhole poutine listicle fashion axe, deep v vape fanny pack echo park unicorn affogato twee mixtape. Raclette artisan edison bulb, pok pok fingerstache craft beer selfies iceland before they sold out man bun gluten-free flexitarian. Deep v distillery enamel pin tousled banjo, hot chicken cloud bread skateboard four loko narwhal. Retro banjo readymade tbh twee, fingerstache flexitarian viral. Put a bird on it chia meh raclette, bicycle rights ramps pug cred knausgaard aesthetic pour-over keffiyeh listicle lo-fi. Lumbersexual banh mi kale chips pork belly skateboard.\n\nTumeric chambray four loko craft beer affogato, vinyl messenger bag. Leggings gluten-free jean shorts unicorn banh mi, keytar air plant man braid fam master cleanse. Kitsch wayfarers fashion axe chartreuse, fanny pack skateboard banjo. Subway tile truffaut mlkshk gluten-free, chambray intelligentsia try-hard celiac live-edge vice normcore. Vape vice lyft, keffiyeh tilde shaman letterpress hoodie deep v gentrify brunch four dollar toast truffaut. Art party microdosing quinoa kombucha bushwick semiotics, taiyaki lyft. Cornhole waistcoat intelligentsia, 90\'s typewriter vexillologist cray tattooed listicle mumblecore helvetica.\n\nTaiyaki kickstarter pork belly, yr 90\'s heirloom echo park prism cardigan literally. Adaptogen pug biodiesel quinoa poutine. Skateboard truffaut chia, waistcoat fashion axe offal godard gastropub plaid tumblr williamsburg cornhole chartreuse. Raw denim ramps kombucha roof party, thundercats pitchfork copper mug glossier vegan PBR&B plaid you probably haven\'t heard of them twee put a bird on it. Blog microdosing single-origin coffee hexagon, gluten-free ennui YOLO vinyl. Lo-fi pop-up church-key, hashtag vexillologist chartreuse farm-to-table cloud bread pabst bushwick typewriter williamsburg actually. Offal wayfarers wolf, brooklyn neutra vexillologist brunch chambray semiotics pitchfork distillery godard cronut edison bulb vice.\n\nKeffiyeh polaroid selfies, chia cold-pressed fanny pack unicorn church-key. Biodiesel heirloom literally vice, fanny pack brooklyn enamel pin glossier celiac live-edge woke mixtape. Health goth enamel pin ugh knausgaard lo-fi. Letterpress roof party photo booth flannel tote bag. Banjo snackwave humblebrag, helvetica you probably haven\'t heard of them cold-pressed VHS church-key beard woke selvage migas. Kickstarter kale chips lo-fi, cloud bread seitan mixtape pop-up adaptogen pork belly cold-pressed jean shorts. Pour-over butcher pitchfork pug, quinoa authentic fanny pack artisan kogi.\n\nPoutine flannel tacos keffiyeh air plant, truffaut forage kinfolk hexagon whatever fam health goth banjo semiotics prism. Brooklyn paleo photo booth, williamsburg marfa jianbing vegan kinfolk authentic seitan af. Four dollar toast gluten-free dreamcatcher put a bird on it lomo. Waistcoat organic gluten-free pabst occupy, hoodie meggings ethical health goth hell of taxidermy before they sold out skateboard synth salvia. Gluten-free cloud bread wolf intelligentsia, chia ennui helvetica subway tile migas pour-over tofu. Flexitarian PBR&B beard whatever. Fixie prism coloring book, cardigan brooklyn tacos irony iceland wolf.\n\nGastropub YOLO put a bird on it, XOXO man bun cold-pressed lomo austin kombucha tbh flannel DIY. Paleo thundercats yuccie, +1 iPhone kombucha iceland twee blog before they sold out pok pok mixtape post-ironic austin. Post-ironic shaman jianbing gentrify occupy tote bag, flannel adaptogen. Yr williamsburg bushwick banh mi, poke live-edge pitchfork. Tote bag readymade affogato, unicorn organic truffaut scenester disrupt keytar sriracha trust fund adaptogen small batch. Humblebrag marfa poke asymmetrical street art. Offal snackwave whatever, poutine gastropub godard tumblr meditation woke quinoa subway tile.\n\nGodard hella you probably haven\'t heard of them, vinyl seitan drinking vinegar normcore ramps. Butcher woke selfies etsy, bespoke skateboard church-key intelligentsia master cleanse man bun flannel wayfarers messenger bag prism leggings. Blue bottle trust fund normcore cronut woke, next level lomo bicycle rights gluten-free. Retro viral iPhone vexillologist man braid. Kitsch mlkshk franzen selfies selvage chambray. Crucifix bushwick chillwave, before they sold out sartorial leggings meditation chia activated charcoal +1 flexitarian. Letterpress taiyaki bitters affogato.\n\nWilliamsburg etsy la croix helvetica paleo. Tumeric woke chartreuse, quinoa cloud bread live-edge bitters chambray meh. Vegan pickled normcore, copper mug dreamcatcher shabby chic biodiesel farm-to-table microdosing pinterest meggings. Literally hot chicken migas enamel pin pork belly, hammock vexillologist. Actually lomo drinking vinegar live-edge. Ramps poutine shabby chic, street art blue bottle narwhal kombucha aesthetic mlkshk. Tumblr kogi occupy organic, forage chillwave shoreditch tousled keytar kinfolk.\n\nJean shorts yr iceland, whatever deep v vaporware air plant scenester biodiesel art party prism meggings readymade tbh. Microdosing cray pop-up kale chips sartorial thundercats. Vice chartreuse gochujang unicorn bicycle rights. Tumeric locavore copper mug, deep v fanny pack blue bottle blog +1 gochujang literally umami bicycle rights. Edison bulb fashion axe try-hard portland ugh. Photo booth four dollar toast waistcoat blue bottle. Tilde offal food truck, cray +1 bushwick four loko.\n\nShabby chic meggings meh, umami hoodie blog kinfolk shoreditch deep v before they sold out waistcoat butcher ugh. Pork belly cloud bread mumblecore fixie schlitz raclette. Shoreditch 3 wolf moon you probably haven\'t heard of them, seitan taiyaki before they sold out subway tile farm-to-table whatever venmo craft beer four loko franzen. Artisan tofu shabby chic +1, dreamcatcher neutra scenester meggings 3 wolf moon butcher wayfarers coloring book subway tile keffiyeh la croix. Lumbersexual marfa tousled, humblebrag tacos PBR&B locavore neutra chillwave craft beer franzen blue bottle distillery schlitz heirloom. Street art hammock meh lumbersexual single-origin coffee. Vape keffiyeh pabst, irony asymmetrical forage squid mumblecore roof party hell of next level subway tile.\n\nEveryday carry disrupt wolf helvetica swag chartreuse. Yr waistcoat la croix snackwave seitan, salvia sriracha aesthetic tumblr. Kickstarter lomo post-ironic, VHS brooklyn shaman man braid mlkshk organic gentrify tbh cold-pressed humblebrag pabst jianbing. Pabst celiac poke, you probably haven\'t heard of them keffiyeh echo park gluten-free raclette. Activated charcoal wayfarers hashtag pork belly brunch aesthetic. Distillery four dollar toast seitan narwhal, yuccie squid raw denim. Celiac kombucha food truck keffiyeh 90\'s echo park.\n\nLocavore chillwave snackwave cronut, butcher blue bottle cardigan edison bulb literally gluten-free PBR&B. 3 wolf moon affogato gentrify af crucifix. Palo santo put a bird on it direct trade air plant, waistcoat fanny pack plaid live-edge tumblr literally af retro flexitarian meh selvage. DIY prism iPhone, shoreditch aesthetic pickled vinyl farm-to-table authentic. Fanny pack activated charcoal photo booth blue bottle chicharrones mixtape. Tote bag austin beard, small batch keffiyeh sartorial banjo. Flexitarian chicharrones humblebrag, celiac hot chicken activated charcoal ethical green juice hella single-origin coffee street art.\n\nSriracha raw denim hashtag, kickstarter tilde venmo woke forage snackwave banh mi echo park truffaut. Bitters vice hoodie kickstarter crucifix. You probably haven\'t heard of them forage heirloom live-edge, taiyaki selfies VHS tumblr. Letterpress beard edison bulb plaid scenester kitsch. Flexitarian tumblr pickled, before they sold out sustainable kickstarter austin unicorn. Direct trade cronut drinking vinegar, austin keytar godard you probably haven\'t heard of them etsy. Chia coloring book blue bottle semiotics, vaporware tilde art party mustache listicle polaroid.\n\nActivated charcoal brooklyn raclette sriracha tilde, migas palo santo pitchfork williamsburg messenger bag drinking vinegar kale chips before they sold out asymmetrical. Affogato you probably haven\'t heard of them stumptown raw denim. Deep v glossier PBR&B unicorn hell of cold-pressed forage subway tile, tattooed yuccie hashtag slow-carb pinterest tbh bicycle rights. Humblebrag fam offal fixie enamel pin. Lumbersexual tote bag jean shorts swag, sustainable quinoa mlkshk lyft gastropub crucifix keytar vice +1 fanny pack tofu. Succulents typewriter trust fund, live-edge jianbing affogato twee cornhole ramps lyft. Street art blue bottle tattooed synth man braid, brunch vaporware chia marfa kickstarter.\n\nVaporware beard chia cold-pressed banh mi, cornhole XOXO mlkshk brunch street art tumeric sartorial prism taiyaki hoodie. Artisan four loko leggings ennui enamel pin health goth. 8-bit readymade sriracha, ethical blog bicycle rights raw denim. Trust fund quinoa brunch, put a bird on it glossier helvetica godard deep v salvia authentic celiac small batch hell of taiyaki tacos. Yr hashtag glossier cold-pressed, +1 marfa actually gochujang. Slow-carb edison bulb sriracha meh ugh hell of narwhal leggings, unicorn cliche mlkshk. Fam XOXO sartorial, chambray franzen prism next level pitchfork truffaut plaid keffiyeh offal pabst vice shoreditch.\n\nPaleo biodiesel readymade jianbing, single-origin coffee prism godard live-edge craft beer. Hell of plaid VHS, gochujang adaptogen tattooed single-origin coffee knausgaard retro photo booth. Keytar bitters cray hexagon raclette cred. Paleo tilde chambray semiotics cray. Hot chicken selvage brooklyn banjo etsy lomo gastropub. Jianbing plaid venmo, gastropub gentrify roof party adaptogen chillwave meditation typewriter. Pour-over kogi hashtag butcher.\n\nSynth before they sold out paleo, pok pok wayfarers seitan jianbing palo santo forage health goth pour-over. Knausgaard +1 austin microdosing, blue bottle sustainable pork belly 3 wolf moon franzen. Intelligentsia actually meh meditation, ethical readymade tote bag selvage poutine freegan gentrify. Selvage polaroid occupy slow-carb, readymade vice tote bag. Poke gochujang subway tile plaid, seitan vexillologist authentic yuccie mlkshk kombucha marfa. Post-ironic salvia humblebrag, cred iPhone poutine fam. Freegan 3 wolf moon tofu scenester tousled, vinyl single-origin coffee portland.\n\nJianbing seitan DIY edison bulb. Pinterest chicharrones lyft meggings semiotics cronut. Wolf literally you probably haven\'t heard of them kogi kombucha, meh actually. Pickled pinterest humblebrag polaroid. Air plant synth YOLO crucifix edison bulb next level. Pabst tofu DIY truffaut, air plant fixie iceland lyft readymade. Synth health goth wayfarers gluten-free subway tile salvia.\n\nDeep v meditation shaman, ennui twee etsy edison bulb paleo selfies trust fund. VHS portland vape cold-pressed. Chillwave pug af unicorn, heirloom whatever waistcoat blue bottle kitsch tote bag narwhal vice slow-carb biodiesel. Synth air plant iPhone vaporware. IPhone echo park adaptogen lo-fi subway tile. Cloud bread skateboard enamel pin prism ramps. Farm-to-table authentic organic, poke cloud bread thundercats put a bird on it pork belly woke gentrify.\n\nCopper mug man braid letterpress offal, coloring book knausgaard lomo palo santo kinfolk. La croix cloud bread cold-pressed, normcore truffaut tattooed hoodie woke. Pug post-ironic squid, tousled vape jianbing selvage semiotics. Glossier plaid pabst, normcore health goth selvage keytar freegan taiyaki. Tote bag crucifix pok pok, 8-bit seitan thundercats tacos kogi keytar. Af locavore raw denim typewriter semiotics helvetica, ugh fanny pack. Photo booth lumbersexual fingerstache, artisan whatever brooklyn austin iPhone fixie subway tile.\n\nMan bun pabst street art whatever, 3 wolf moon sartorial activated charcoal disrupt biodiesel try-hard narwhal migas tilde art party blue bottle. Master cleanse kitsch before they sold out hoodie ethical. Kitsch hammock taxidermy, normcore cloud bread schlitz 90\'s. Organic cardigan try-hard, salvia tattooed chia offal pour-over tilde put a bird on it taxidermy wolf austin actually. Pinterest banjo man bun, green juice shaman chartreuse seitan blog street art tattooed echo park food truck tbh lo-fi crucifix. Pok pok poke cornhole 90\'s copper mug chartreuse. Church-key meditation tousled umami portland tofu.\n\nCliche fixie swag flexitarian, biodiesel PBR&B artisan. Fixie single-origin coffee tote bag, everyday carry 8-bit tbh stumptown heirloom unicorn tumeric sriracha green juice portland actually. Typewriter green juice four dollar toast taxidermy yuccie occupy gastropub, twee microdosing. Farm-to-table gastropub tacos, slow-carb dreamcatcher fashion axe retro street art letterpress iPhone edison bulb viral. Pork belly lyft etsy, offal godard fingerstache hot chicken. Mumblecore hexagon YOLO ramps messenger bag chartreuse. Ugh sartorial iPhone, kinfolk deep v jean shorts humblebrag yr glossier ramps retro beard raclette knausgaard meh.\n\nSelvage taxidermy four dollar toast beard, hoodie coloring book farm-to-table mustache subway tile. Cornhole lo-fi banjo photo booth offal la croix austin glossier, crucifix health goth single-origin coffee ugh knausgaard. Everyday carry hashtag XOXO, chicharrones 90\'s typewriter kitsch thundercats. Humblebrag try-hard everyday carry asymmetrical helvetica, +1 taxidermy lomo biodiesel snackwave disrupt slow-carb unicorn keffiyeh sriracha. Hot chicken vice williamsburg kickstarter echo park literally artisan humblebrag. Glossier irony occupy biodiesel pork belly truffaut. Shaman activated charcoal chillwave echo park plaid man braid, pork belly brooklyn vinyl flexitarian actually stumptown tilde.\n\nSelvage microdosing +1 snackwave. Meh health goth +1 seitan cray sartorial. Shabby chic kickstarter fingerstache selfies, affogato keffiyeh narwhal celiac. Snackwave taxidermy scenester, four dollar toast neutra bitters pug next level. Cray listicle twee, microdosing cronut readymade sustainable disrupt whatever plaid chartreuse locavore man braid mixtape truffaut. Slow-carb kitsch venmo fixie. Retro leggings pitchfork, keytar bushwick ramps polaroid organic celiac irony small batch kombucha locavore vaporware coloring book.\n\nPlaid cronut poutine, pop-up woke slow-carb street art drinking vinegar austin. Twee austin heirloom, waistcoat four loko pitchfork kale chips meggings tousled PBR&B typewriter unicorn. Chia 8-bit plaid mixtape, deep v subway tile typewriter kinfolk disrupt vexillologist activated charcoal four loko microdosing. Venmo direct trade helvetica ethical raclette vaporware, gochujang woke tbh cardigan gastropub cliche forage disrupt. Quinoa echo park sartorial vegan chicharrones. Chicharrones hammock pop-up, readymade keffiyeh vegan polaroid pabst organic pour-over hell of williamsburg master cleanse farm-to-table. Gluten-free mlkshk occupy wayfarers, migas neutra distillery hoodie pinterest aesthetic artisan swag mixtape 3 wolf moon.\n\nHashtag fingerstache ramps portland green juice roof party. VHS franzen yuccie kickstarter tofu PBR&B. Tbh distillery offal godard retro edison bulb shabby chic kinfolk post-ironic kale chips, polaroid selfies. Butcher pork belly try-hard yuccie fingerstache. Small batch paleo roof party, tacos cray biodiesel listicle quinoa edison bulb 8-bit. Truffaut chillwave thundercats, shoreditch four dollar toast locavore biodiesel 3 wolf moon tote bag keytar fixie meggings hoodie letterpress. Organic pork belly hell of gastropub neutra.\n\nColoring book activated charcoal jean shorts, blue bottle lumbersexual mlkshk wayfarers kogi. Beard chartreuse austin selfies, selvage cardigan leggings. Salvia brunch disrupt, marfa hammock austin crucifix. Iceland blog hexagon retro, tousled post-ironic quinoa. Neutra kitsch microdosing venmo messenger bag, salvia hexagon schlitz retro. Readymade helvetica disrupt, scenester cred lumbersexual post-ironic normcore coloring book typewriter. Farm-to-table hammock four dollar toast chambray glossier fanny pack.\n\nTaxidermy before they sold out scenester, quinoa jianbing vexillologist meditation tattooed polaroid chia raclette YOLO ugh poke. Subway tile street art ennui kombucha, listicle 8-bit hashtag palo santo post-ironic tofu pop-up poke direct trade. Everyday carry occupy actually, vexillologist ennui williamsburg succulents four dollar toast deep v. Everyday carry green juice fam tote bag, taiyaki flannel pickled mustache heirloom viral. YOLO readymade actually, man bun leggings chia intelligentsia pug vegan squid skateboard woke plaid. Authentic retro artisan lo-fi man braid cred, sriracha tumeric umami yr four dollar toast pork belly af gastropub. Kickstarter offal letterpress before they sold out, asymmetrical artisan vice flexitarian celiac truffaut bespoke neutra air plant pug.\n\nDrinking vinegar fanny pack chillwave, vice blue bottle celiac sustainable poutine you probably haven\'t heard of them synth. Intelligentsia humblebrag yuccie selvage, pork belly celiac letterpress portland bespoke biodiesel palo santo la croix kogi meditation. Copper mug bitters health goth unicorn master cleanse affogato. Green juice kale chips readymade taiyaki hammock. Aesthetic occupy pabst la croix poutine gastropub. Vape 3 wolf moon synth sustainable neutra narwhal dreamcatcher church-key. Wolf master cleanse dreamcatcher, woke mustache ramps kinfolk small batch authentic schlitz try-hard.\n\nRaw denim meh skateboard, ugh snackwave tumeric salvia man braid artisan biodiesel bitters. Celiac bushwick XOXO butcher neutra hammock, chambray master cleanse snackwave pork belly tumblr swag you probably haven\'t heard of them vexillologist. Neutra sartorial jean shorts, lo-fi synth distillery migas keffiyeh wayfarers dreamcatcher. Vaporware health goth biodiesel, normcore hot chicken direct trade semiotics wayfarers echo park whatever stumptown jean shorts. Prism pork belly williamsburg, af lomo chicharrones messenger bag fanny pack cliche seitan butcher fixie lo-fi swag. Venmo actually try-hard bespoke. Actually quinoa cardigan, cold-pressed air plant etsy next level.\n\nSalvia semiotics banjo, farm-to-table mlkshk coloring book 3 wolf moon meh hell of. Selfies umami echo park yuccie vegan. Slow-carb normcore meditation, swag taiyaki drinking vinegar stumptown paleo enamel pin af tumblr. Cold-pressed butcher subway tile thundercats. Locavore cronut selfies austin. Celiac irony try-hard unicorn succulents. Godard taiyaki ethical, yuccie scenester lo-fi kickstarter distillery selfies aesthetic keffiyeh retro yr fingerstache.\n\nWaistcoat coloring book scenester meditation vice. Asymmetrical banjo next level waistcoat readymade YOLO tattooed chicharrones. Farm-to-table shaman austin, organic tote bag kickstarter palo santo seitan franzen. Gochujang hot chicken vexillologist, chia street art succulents plaid umami vaporware banh mi. Iceland poke kinfolk, twee butcher literally trust fund four dollar toast activated charcoal cronut prism humblebrag godard. Kitsch celiac health goth meh tote bag. Vape enamel pin butcher iPhone, woke vegan pok pok listicle vaporware schlitz kogi.\n\nShaman unicorn adaptogen health goth. Gastropub letterpress la croix pop-up vinyl glossier swag art party retro bitters. Distillery lomo banh mi kogi, coloring book raw denim before they sold out af food truck vice deep v. Selfies chambray poutine man braid tbh. DIY taxidermy humblebrag artisan try-hard master cleanse. +1 art party YOLO 8-bit. Tbh taxidermy raw denim shoreditch disrupt four loko bushwick tumblr, skateboard fashion axe next level tilde.\n\nFranzen kogi enamel pin, kale chips glossier XOXO seitan cred banjo VHS wolf organic skateboard hexagon affogato. Irony franzen microdosing vape, retro humblebrag blog bitters XOXO fingerstache ethical. Synth iPhone artisan, blue bottle thundercats narwhal chicharrones four dollar toast everyday carry fingerstache hoodie butcher tote bag YOLO yr. Poutine swag iPhone, actually vexillologist gochujang humblebrag banjo. Selfies roof party hexagon, whatever before they sold out blog distillery. Blue bottle disrupt heirloom hella bushwick photo booth flannel portland, cornhole brooklyn shabby chic YOLO yuccie chia. Kogi tbh street art tumeric, freegan microdosing mlkshk bushwick leggings palo santo green juice iPhone lomo.\n\nOffal whatever gastropub, craft beer kinfolk forage wolf enamel pin cronut hammock pork belly XOXO tumblr next level knausgaard. Jianbing locavore before they sold out portland artisan, aesthetic raclette migas taxidermy ethical four loko affogato keytar. Scenester street art photo booth kinfolk mustache tumblr. Bitters humblebrag hammock godard. Salvia cardigan tumeric twee literally. Quinoa fingerstache aesthetic cred, vice polaroid health goth pinterest banh mi post-ironic craft beer church-key PBR&B. IPhone church-key mlkshk vaporware.\n\nKale chips asymmetrical tofu, sustainable freegan ethical skateboard succulents migas pour-over bicycle rights direct trade banh mi organic thundercats. Street art whatever 8-bit you probably haven\'t heard of them sriracha. Whatever humblebrag palo santo succulents, quinoa lo-fi pug iceland hexagon cold-pressed church-key bitters franzen typewriter. Austin chia dreamcatcher, sartorial ethical mixtape hammock pour-over palo santo fanny pack prism la croix sriracha viral locavore. Photo booth mustache lumbersexual, tacos offal lyft salvia meditation letterpress adaptogen celiac. Affogato glossier forage, YOLO hot chicken actually letterpress. Deep v taxidermy kale chips, af meh intelligentsia leggings salvia fashion axe hell of.\n\nBiodiesel mumblecore yuccie keytar, taiyaki adaptogen skateboard. Selvage cloud bread thundercats taiyaki hexagon, occupy pug cold-pressed health goth asymmetrical tattooed plaid freegan chambray you probably haven\'t heard of them. Meggings sustainable photo booth vegan. Portland crucifix artisan 3 wolf moon prism coloring book, post-ironic tilde single-origin coffee biodiesel. Bitters heirloom crucifix, craft beer edison bulb salvia everyday carry bespoke whatever green juice four loko. Plaid shoreditch gochujang everyday carry. 90\'s waistcoat meh XOXO, keffiyeh raw denim iPhone banjo pinterest meggings tacos edison bulb glossier meditation.\n\nNeutra viral lumbersexual hella, chambray copper mug fanny pack. Chartreuse brunch small batch actually butcher. Jianbing fingerstache street art heirloom. Synth flannel flexitarian, edison bulb brunch vegan blue bottle quinoa taxidermy. Distillery freegan PBR&B 90\'s cred everyday carry seitan, ethical blog intelligentsia direct trade raw denim schlitz keytar kogi. Lyft fanny pack kombucha, cold-pressed street art tattooed leggings activated charcoal four dollar toast hot chicken. Tilde art party semiotics direct trade tumblr.\n\nPBR&B organic trust fund, palo santo godard church-key next level cred leggings 8-bit. Gochujang unicorn salvia, woke actually semiotics occupy ennui cornhole. Quinoa tofu semiotics everyday carry godard, pinterest palo santo readymade kinfolk man bun taxidermy man braid vice chillwave food truck. Hell of 3 wolf moon keytar, yr chia +1 echo park quinoa freegan cold-pressed activated charcoal drinking vinegar pok pok. Four dollar toast knausgaard pork belly fixie dreamcatcher, before they sold out green juice sustainable cred health goth tumeric typewriter twee adaptogen. Hashtag brooklyn woke, yuccie fashion axe chillwave gochujang succulents direct trade cold-pressed. Plaid brunch pabst kickstarter bushwick, lumbersexual kogi woke.\n\nRaclette etsy jean shorts, 8-bit vape shoreditch humblebrag. Swag viral kickstarter wayfarers ramps, poutine photo booth master cleanse vice. Banh mi vice typewriter irony. Chambray hexagon lumbersexual, flexitarian umami pork belly heirloom pitchfork. Literally pabst church-key deep v cold-pressed, synth pop-up viral wayfarers humblebrag typewriter ethical lyft. Cardigan DIY wayfarers, pok pok messenger bag vice pitchfork craft beer bushwick succulents marfa cold-pressed hot chicken salvia. Man bun crucifix fixie messenger bag hoodie pabst.\n\nScenester deep v direct trade, vape iceland vexillologist meditation cronut paleo keffiyeh before they sold out selfies disrupt four loko. Swag 90\'s taiyaki, farm-to-table narwhal viral street art next level tumeric. Try-hard plaid schlitz drinking vinegar deep v cray. Pinterest thundercats small batch irony. Mustache listicle cronut, YOLO mlkshk tumblr neutra. Palo santo readymade vape semiotics godard succulents. Meggings photo booth kogi pitchfork af DIY art party, locavore migas tattooed selvage mixtape.\n\nXOXO you probably haven\'t heard of them butcher, woke cred pinterest fam tacos humblebrag drinking vinegar stumptown sustainable DIY air plant heirloom. Fixie ugh mlkshk, edison bulb synth fashion axe portland biodiesel lumbersexual selvage hell of mumblecore typewriter kitsch. Meh street art beard photo booth salvia, actually succulents. Occupy ramps semiotics, fixie try-hard tousled brunch paleo iceland seitan pop-up locavore viral. Brunch literally YOLO synth jianbing. Occupy raw denim letterpress austin hammock glossier sustainable kombucha kogi stumptown. Distillery 8-bit iceland, subway tile microdosing pitchfork beard af organic fanny pack.\n\nUnicorn sartorial literally dreamcatcher chillwave selvage edison bulb knausgaard. Humblebrag lo-fi portland blog, neutra drinking vinegar freegan master cleanse jianbing. Master cleanse mixtape cold-pressed flannel, bespoke iceland vinyl paleo. Af chillwave fixie, flannel irony keffiyeh cloud bread next level kickstarter raw denim meditation. Pok pok man braid heirloom vaporware, pour-over poke poutine. Butcher gentrify VHS air plant gastropub, distillery sartorial vape fashion axe messenger bag actually pok pok cliche shaman. Gluten-free pop-up VHS +1 kale chips.\n\nFranzen chartreuse you probably haven\'t heard of them, la croix lomo before they sold out asymmetrical taxidermy polaroid offal cronut. Disrupt blog YOLO sriracha. Waistcoat twee microdosing truffaut biodiesel, cred art party. Tofu chillwave YOLO deep v try-hard stumptown bicycle rights, tilde occupy godard ramps. Thundercats polaroid coloring book, kickstarter bitters iPhone stumptown offal air plant tote bag mumblecore flexitarian lomo. Craft beer mustache venmo asymmetrical biodiesel, poke helvetica hashtag swag celiac godard gastropub schlitz. Truffaut man braid vegan, cliche craft beer austin keffiyeh lyft swag gentrify freegan sustainable you probably haven\'t heard of them pinterest.\n\nPickled ethical cardigan tacos. Glossier vaporware photo booth blog, gastropub listicle next level af before they sold out asymmetrical cronut heirloom offal narwhal. Put a bird on it VHS poutine af, synth hexagon tbh chambray. Pickled hoodie cred, hella banh mi cray yuccie direct trade art party lomo fingerstache sriracha. Waistcoat echo park banh mi, shoreditch hammock four loko franzen neutra succulents. Fam listicle chillwave fixie, marfa hexagon plaid health goth locavore. Pok pok hell of iPhone unicorn, hoodie hashtag kickstarter everyday carry seitan synth neutra retro tacos.\n\nActivated charcoal skateboard coloring book, vinyl gochujang freegan marfa street art mixtape hexagon edison bulb pok pok vice. Celiac everyday carry snackwave flexitarian street art. Next level four dollar toast cornhole direct trade viral squid. Gluten-free tumeric vice butcher seitan. Artisan yuccie bushwick, fingerstache humblebrag roof party cornhole single-origin coffee jean shorts vinyl mixtape hell of stumptown lyft gentrify. Kale chips put a bird on it ethical hot chicken, lyft locavore af next level YOLO. You probably haven\'t heard of them 90\'s sustainable aesthetic seitan leggings.\n\nCraft beer tattooed chia fashion axe, glossier sustainable XOXO ugh sriracha health goth you probably haven\'t heard of them seitan. Vinyl synth migas, waistcoat artisan banh mi semiotics distillery mumblecore. Cronut aesthetic fixie brunch tacos. Meh readymade bespoke chia, pop-up mixtape wayfarers gochujang adaptogen. Pug roof party whatever, heirloom edison bulb lo-fi butcher palo santo cronut tofu helvetica. Shabby chic wayfarers iPhone, paleo letterpress try-hard pok pok fashion axe microdosing ennui banjo raw denim. IPhone flannel man braid lomo ethical.\n\n90\'s microdosing VHS shoreditch air plant, cred meh schlitz beard normcore slow-carb enamel pin banjo. Disrupt cred pour-over, hell of messenger bag next level freegan prism literally enamel pin. Chillwave locavore trust fund, wayfarers helvetica godard health goth microdosing squid organic before they sold out. Tousled bicycle rights helvetica subway tile. Edison bulb chicharrones selfies, everyday carry actually vape selvage. Hoodie craft beer marfa, hashtag wayfarers literally palo santo paleo artisan air plant messenger bag. Authentic yuccie yr bitters bespoke, poutine umami vinyl seitan kitsch hammock tilde tbh.\n\nEthical pour-over kinfolk, marfa humblebrag taiyaki lomo hexagon air plant church-key swag skateboard beard activated charcoal butcher. Man braid crucifix pabst, four dollar toast pork belly tousled meditation raclette. Tilde taiyaki la croix yr ennui tote bag. Umami quinoa activated charcoal, before they sold out celiac pickled selvage pinterest. Intelligentsia mustache tbh la croix. Freegan master cleanse synth taiyaki, shabby chic tumeric adaptogen vaporware brooklyn. Migas shaman keytar meh live-edge organic.\n\nDisrupt squid live-edge, waistcoat man braid PBR&B tote bag meh hella craft beer crucifix lo-fi four dollar toast hexagon. Flexitarian occupy unicorn food truck, lumbersexual 8-bit raclette hashtag waistcoat activated charcoal woke DIY. Disrupt actually helvetica franzen. Leggings chicharrones taiyaki, adaptogen letterpress shoreditch tattooed kitsch wayfarers four loko. Poke trust fund freegan kombucha deep v gluten-free, yuccie narwhal bushwick vape 8-bit four loko bespoke vice. Schlitz tilde tofu, mlkshk kombucha hell of organic four dollar toast stumptown biodiesel franzen put a bird on it. Unicorn lomo af mumblecore health goth.\n\nOccupy shoreditch pinterest actually mlkshk distillery offal. Raclette squid cred cray, chambray tote bag flexitarian la croix organic cloud bread vinyl poutine kinfolk palo santo fanny pack. Health goth activated charcoal cronut, raclette gentrify food truck sriracha af austin fam twee chambray quinoa. Vaporware selfies lyft typewriter. Street art fingerstache woke, VHS unicorn gentrify slow-carb affogato pork belly. DIY blog ethical four dollar toast man braid. Mumblecore put a bird on it twee mustache XOXO YOLO.\n\nSartorial yr glossier austin. Lo-fi artisan raclette sustainable green juice small batch tacos, banh mi vinyl waistcoat before they sold out tote bag blog ennui. Bushwick snackwave ennui, small batch hoodie blue bottle photo booth kogi PBR&B ramps retro chambray bicycle rights. Tbh ugh VHS, fixie chambray kinfolk plaid keffiyeh kickstarter heirloom iceland freegan disrupt pug. Pickled vape post-ironic typewriter asymmetrical try-hard, hella butcher etsy before they sold out scenester bitters. Tbh asymmetrical farm-to-table lomo everyday carry, bitters scenester semiotics cornhole microdosing kitsch kickstarter VHS occupy offal. Seitan cred hell of humblebrag, listicle portland occupy mlkshk williamsburg literally 3 wolf moon.\n\nLiterally asymmetrical godard, cray next level kombucha normcore freegan ugh waistcoat. Flexitarian banjo cronut poutine, selfies XOXO humblebrag salvia iceland. Polaroid quinoa seitan, fashion axe taxidermy live-edge flexitarian cliche. Sustainable austin normcore bicycle rights beard, pour-over cliche gluten-free poke tote bag. PBR&B pop-up raw denim, master cleanse dreamcatcher small batch marfa 8-bit. Enamel pin wolf knausgaard portland leggings four loko, activated charcoal disrupt +1 air plant typewriter blue bottle celiac. 90\'s hell of blog occupy.\n\nDistillery fanny pack asymmetrical offal shoreditch. Irony chia normcore tote bag sartorial godard. Echo park farm-to-table drinking vinegar bespoke, messenger bag keytar kickstarter bicycle rights shoreditch ugh leggings fanny pack. Copper mug ramps church-key lo-fi cray venmo put a bird on it neutra kickstarter salvia glossier flannel, vice master cleanse cloud bread. Blue bottle poke trust fund, vaporware succulents drinking vinegar bicycle rights tbh butcher bitters iceland distillery fixie twee. Four loko ramps fam, swag neutra gastropub tumblr. Succulents waistcoat distillery forage shoreditch church-key, knausgaard ramps crucifix gluten-free adaptogen.\n\nRaw denim pok pok aesthetic blue bottle. DIY craft beer cold-pressed, biodiesel authentic mustache occupy offal semiotics hoodie hexagon jean shorts. Tote bag gentrify prism, tacos venmo shabby chic plaid vinyl franzen listicle ennui etsy taiyaki distillery. Lomo farm-to-table kombucha 90\'s listicle, YOLO XOXO skateboard distillery meh. Squid cray ethical poutine. Squid gentrify man braid intelligentsia, pitchfork irony palo santo brooklyn health goth hashtag fingerstache street art taxidermy master cleanse. Pour-over small batch cloud bread chillwave, XOXO tousled mlkshk woke lomo offal cray cold-pressed.\n\nBrunch disrupt health goth, forage slow-carb kinfolk ennui chillwave everyday carry pop-up tousled. Woke kale chips lyft stumptown coloring book, schlitz gentrify wayfarers tofu biodiesel keytar franzen forage. Disrupt fingerstache next level, skateboard 8-bit polaroid biodiesel pour-over art party helvetica migas green juice. Semiotics unicorn wayfarers tbh, shoreditch chillwave activated charcoal beard fam four loko pop-up 90\'s. Fingerstache freegan irony lyft tacos, austin gastropub iceland 8-bit cliche vice chillwave sartorial neutra. Iceland waistcoat retro skateboard, tote bag palo santo celiac normcore selfies photo booth gluten-free pabst craft beer helvetica drinking vinegar. VHS pour-over palo santo +1 master cleanse.\n\nBrunch live-edge mlkshk, jianbing hexagon schlitz chicharrones vexillologist wolf dreamcatcher pickled cornhole fingerstache. Meh roof party microdosing, chartreuse air plant DIY snackwave four loko thundercats bushwick tumeric organic narwhal gluten-free knausgaard. Skateboard +1 3 wolf moon chartreuse, selfies man bun man braid mlkshk locavore chillwave affogato 8-bit. Wayfarers offal cardigan, etsy slow-carb edison bulb flannel. You probably haven\'t heard of them ugh synth fam chia echo park try-hard, edison bulb next level la croix single-origin coffee waistcoat literally locavore. Wayfarers ugh irony shabby chic affogato letterpress. Waistcoat occupy pitchfork, selfies tumblr cardigan pork belly glossier authentic truffaut flannel single-origin coffee put a bird on it coloring book butcher.\n\nPork belly vexillologist post-ironic before they sold out man braid lo-fi pop-up, austin vinyl kitsch brunch wolf. Everyday carry chia health goth knausgaard, viral copper mug lomo. Sriracha swag bushwick, salvia small batch air plant chillwave church-key craft beer before they sold out affogato adaptogen blue bottle art party gentrify. Street art wayfarers cardigan sriracha, lo-fi try-hard health goth art party semiotics. Letterpress man bun literally dreamcatcher try-hard, marfa banjo irony knausgaard iPhone selfies. Mustache jianbing kitsch man braid messenger bag. Vice humblebrag la croix, affogato try-hard enamel pin cliche.\n\nFranzen viral man braid jianbing, prism pug craft beer photo booth irony beard bespoke. Tumeric chillwave distillery, cliche meh cold-pressed franzen selvage stumptown readymade deep v meggings cloud bread chartreuse post-ironic. Raw denim meditation everyday carry, vexillologist activated charcoal offal succulents kitsch jean shorts XOXO. Kogi glossier disrupt deep v, truffaut affogato DIY chicharrones hammock. Austin tousled artisan, kombucha plaid crucifix succulents. Pug freegan butcher wayfarers, chillwave truffaut four dollar toast. Sartorial blue bottle sustainable pabst organic.\n\nDeep v leggings raclette lumbersexual, tofu asymmetrical locavore food truck jean shorts la croix shabby chic. Vape microdosing direct trade small batch, raw denim craft beer thundercats irony authentic actually man braid ramps. Ennui portland roof party etsy fanny pack scenester, gastropub mumblecore taiyaki. Mlkshk bitters tilde disrupt put a bird on it, lumbersexual gluten-free next level meditation brooklyn craft beer. Prism gochujang actually, flexitarian cred meditation +1. Hashtag next level tousled synth messenger bag, butcher brunch. Art party whatever coloring book ethical, cornhole gentrify lo-fi kickstarter blue bottle forage.\n\nActually pabst cloud bread, kombucha hot chicken godard unicorn gochujang cliche activated charcoal yr seitan portland locavore bespoke. Chillwave tumblr cornhole succulents, ugh street art heirloom copper mug hot chicken wolf ethical. Vape 90\'s gluten-free, portland lomo cold-pressed af literally. Forage DIY offal, 3 wolf moon austin butcher intelligentsia venmo. Hammock sriracha bicycle rights meditation photo booth copper mug, distillery seitan brooklyn YOLO cardigan sustainable meggings dreamcatcher bespoke. Craft beer venmo bicycle rights tilde pabst, food truck everyday carry bushwick synth crucifix cornhole before they sold out hashtag fashion axe. Everyday carry pop-up taiyaki, roof party VHS direct trade messenger bag literally offal.\n\nEveryday carry 8-bit forage bitters church-key synth. Retro taxidermy cray, tumblr blog deep v vaporware scenester intelligentsia brooklyn tousled celiac dreamcatcher occupy seitan. Hexagon +1 williamsburg, kickstarter readymade shabby chic master cleanse thundercats banh mi selvage gluten-free vaporware humblebrag green juice food truck. Listicle seitan flannel, everyday carry gastropub cold-pressed venmo you probably haven\'t heard of them pok pok raclette green juice. Tumeric XOXO pitchfork, 90\'s crucifix semiotics selfies jianbing chillwave photo booth keytar shoreditch subway tile vice franzen. Pop-up palo santo etsy scenester tumeric selvage. Selvage meditation ugh blue bottle craft beer gentrify.\n\nNext level you probably haven\'t heard of them flexitarian yuccie, ugh vice direct trade chartreuse cardigan shabby chic jianbing live-edge tumeric. Palo santo food truck paleo cred offal keffiyeh, ramps trust fund semiotics plaid subway tile. Pour-over vinyl retro kitsch kale chips leggings. Green juice truffaut disrupt, before they sold out tattooed selvage four loko PBR&B vape church-key crucifix wolf. Keytar yuccie pitchfork, man braid vaporware fingerstache marfa edison bulb kinfolk blog. Polaroid unicorn lo-fi, shoreditch tattooed chillwave williamsburg cardigan kinfolk ennui. Cold-pressed knausgaard biodiesel meggings stumptown coloring book.\n\nHoodie aesthetic tousled, pabst tumblr skateboard yuccie cardigan iPhone roof party chillwave venmo lo-fi paleo vaporware. Locavore glossier sartorial bicycle rights VHS pour-over. Franzen chia whatever taxidermy woke, intelligentsia kinfolk vaporware palo santo banh mi tofu viral craft beer listicle vegan. Shabby chic williamsburg plaid, flexitarian church-key kogi trust fund kickstarter. Poutine banjo master cleanse, flexitarian poke DIY drinking vinegar neutra bespoke beard. Flexitarian skateboard asymmetrical franzen. Tbh taxidermy helvetica pitchfork, celiac hella tofu try-hard twee swag green juice williamsburg banjo poke man bun.\n\nTilde godard occupy tumeric enamel pin cornhole. DIY poke cliche schlitz kombucha man braid mlkshk, bespoke shaman intelligentsia squid williamsburg health goth cornhole keffiyeh. Irony fingerstache post-ironic godard, migas gochujang hell of asymmetrical farm-to-table distillery 90\'s meggings cred ugh. Kickstarter godard cardigan, echo park cornhole tbh small batch selfies heirloom iPhone portland taxidermy. Vaporware blue bottle lomo, tousled pok pok man bun pickled. Mlkshk hell of 8-bit, tilde gluten-free mumblecore ethical etsy lumbersexual master cleanse. Sartorial freegan farm-to-table street art cloud bread.\nOh. You need a little dummy text for your mockup? How quaint.\n\nI bet you’re still using Bootstrap too…',1,'2017-05-18 13:50:19','2017-05-18 13:50:19');
-/*!40000 ALTER TABLE `article` ENABLE KEYS */;
-UNLOCK TABLES;
-
---
--- Table structure for table `article_category`
---
-
-DROP TABLE IF EXISTS `article_category`;
-/*!40101 SET @saved_cs_client     = @@character_set_client */;
-/*!40101 SET character_set_client = utf8 */;
-CREATE TABLE `article_category` (
-  `id` int(11) NOT NULL AUTO_INCREMENT,
-  `article_id` int(11) NOT NULL,
-  `category_id` int(11) NOT NULL,
-  PRIMARY KEY (`id`),
-  UNIQUE KEY `composite` (`article_id`,`category_id`)
-) ENGINE=InnoDB AUTO_INCREMENT=12 DEFAULT CHARSET=utf8 COLLATE=utf8_unicode_ci;
-/*!40101 SET character_set_client = @saved_cs_client */;
-
---
--- Dumping data for table `article_category`
---
-
-LOCK TABLES `article_category` WRITE;
-/*!40000 ALTER TABLE `article_category` DISABLE KEYS */;
-INSERT INTO `article_category` VALUES (1,1,1),(2,1,2),(3,1,3),(4,2,1),(5,2,2),(6,2,3),(7,3,3),(8,4,3),(9,5,2),(11,6,1),(10,6,2);
-/*!40000 ALTER TABLE `article_category` ENABLE KEYS */;
-UNLOCK TABLES;
-
---
--- Table structure for table `author`
---
-
-DROP TABLE IF EXISTS `author`;
-/*!40101 SET @saved_cs_client     = @@character_set_client */;
-/*!40101 SET character_set_client = utf8 */;
-CREATE TABLE `author` (
-  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
-  `name` varchar(200) COLLATE utf8_unicode_ci DEFAULT '""',
-  `created_at` datetime DEFAULT NULL,
-  `updated_at` datetime DEFAULT NULL,
-  PRIMARY KEY (`id`)
-) ENGINE=InnoDB AUTO_INCREMENT=2 DEFAULT CHARSET=utf8 COLLATE=utf8_unicode_ci;
-/*!40101 SET character_set_client = @saved_cs_client */;
-
---
--- Dumping data for table `author`
---
-
-LOCK TABLES `author` WRITE;
-/*!40000 ALTER TABLE `author` DISABLE KEYS */;
-INSERT INTO `author` VALUES (1,'Iman Tumorang','2017-05-18 13:50:19','2017-05-18 13:50:19');
-/*!40000 ALTER TABLE `author` ENABLE KEYS */;
-UNLOCK TABLES;
-
---
--- Table structure for table `category`
---
-
-DROP TABLE IF EXISTS `category`;
-/*!40101 SET @saved_cs_client     = @@character_set_client */;
-/*!40101 SET character_set_client = utf8 */;
-CREATE TABLE `category` (
-  `id` int(11) NOT NULL AUTO_INCREMENT,
-  `name` varchar(45) COLLATE utf8_unicode_ci NOT NULL,
-  `tag` varchar(45) COLLATE utf8_unicode_ci NOT NULL,
-  `created_at` datetime DEFAULT NULL,
-  `updated_at` datetime DEFAULT NULL,
-  PRIMARY KEY (`id`)
-) ENGINE=InnoDB AUTO_INCREMENT=4 DEFAULT CHARSET=utf8 COLLATE=utf8_unicode_ci;
-/*!40101 SET character_set_client = @saved_cs_client */;
-
---
--- Dumping data for table `category`
---
-
-LOCK TABLES `category` WRITE;
-/*!40000 ALTER TABLE `category` DISABLE KEYS */;
-INSERT INTO `category` VALUES (1,'Makanan','food','2017-05-18 13:50:19','2017-05-18 13:50:19'),(2,'Kehidupan','life','2017-05-18 13:50:19','2017-05-18 13:50:19'),(3,'Kasih Sayang','love','2017-05-18 13:50:19','2017-05-18 13:50:19');
-/*!40000 ALTER TABLE `category` ENABLE KEYS */;
-UNLOCK TABLES;
-/*!40103 SET TIME_ZONE=@OLD_TIME_ZONE */;
-
-/*!40101 SET SQL_MODE=@OLD_SQL_MODE */;
-/*!40014 SET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS */;
-/*!40014 SET UNIQUE_CHECKS=@OLD_UNIQUE_CHECKS */;
-/*!40101 SET CHARACTER_SET_CLIENT=@OLD_CHARACTER_SET_CLIENT */;
-/*!40101 SET CHARACTER_SET_RESULTS=@OLD_CHARACTER_SET_RESULTS */;
-/*!40101 SET COLLATION_CONNECTION=@OLD_COLLATION_CONNECTION */;
-/*!40111 SET SQL_NOTES=@OLD_SQL_NOTES */;
-
--- Dump completed on 2017-12-13 17:17:00
@@ -17,6 +17,10 @@ import (
 	validator "gopkg.in/go-playground/validator.v9"
 )
 
+// statusClientClosedRequest is answered, as nginx does, when the client
+// went away before its response was ready
+const statusClientClosedRequest = 499
+
 // ResponseError represent the reseponse error struct
 type ResponseError struct {
 	Message string `json:"message"`
@@ -53,12 +57,14 @@ func (a *HttpArticleHandler) FetchArticle(w http.ResponseWriter, req *http.Reque
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	listAr, err := a.AUsecase.Fetch(ctx, cursor, int64(num))
+	listAr, page, err := a.AUsecase.Fetch(ctx, cursor, int64(num))
 
 	if err != nil {
 		w.WriteHeader(getStatusCode(err))
 		return
 	}
+	w.Header().Set("X-Cursor", page.Next)
+	w.Header().Set("X-Prev-Cursor", page.Prev)
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 	w.WriteHeader(getStatusCode(err))
 	json.NewEncoder(w).Encode(listAr)
@@ -127,8 +133,7 @@ func (a *HttpArticleHandler) Store(w http.ResponseWriter, req *http.Request) {
 	err = a.AUsecase.Store(ctx, &article)
 
 	if err != nil {
-		logrus.Error(err)
-		w.WriteHeader(http.StatusConflict)
+		w.WriteHeader(getStatusCode(err))
 		return
 	}
 	w.WriteHeader(http.StatusCreated)
@@ -162,6 +167,11 @@ func getStatusCode(err error) int {
 	if err == nil {
 		return http.StatusOK
 	}
+	if err == models.ErrCanceled {
+		// the client went away, the server did not fail
+		logrus.Debug(err)
+		return statusClientClosedRequest
+	}
 	logrus.Error(err)
 	switch err {
 	case models.ErrInternalServerError:
@@ -170,6 +180,10 @@ func getStatusCode(err error) int {
 		return http.StatusNotFound
 	case models.ErrConflict:
 		return http.StatusConflict
+	case models.ErrBadParamInput:
+		return http.StatusBadRequest
+	case models.ErrTimeout:
+		return http.StatusGatewayTimeout
 	case models.ErrUnprocessableEntity:
 		return http.StatusUnprocessableEntity
 	default:
@@ -29,7 +29,7 @@ func TestFetch(t *testing.T) {
 	mockListArticle = append(mockListArticle, &mockArticle)
 	num := 1
 	cursor := "2"
-	mockUCase.On("Fetch", mock.Anything, cursor, int64(num)).Return(mockListArticle, nil)
+	mockUCase.On("Fetch", mock.Anything, cursor, int64(num)).Return(mockListArticle, models.Page{Next: "next", Prev: "prev"}, nil)
 
 	req, err := http.NewRequest(http.MethodGet, "/articles?num=1&cursor="+cursor, strings.NewReader(""))
 	assert.NoError(t, err)
@@ -41,6 +41,8 @@ func TestFetch(t *testing.T) {
 	handler.FetchArticle(rec, req)
 
 	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "next", rec.Header().Get("X-Cursor"))
+	assert.Equal(t, "prev", rec.Header().Get("X-Prev-Cursor"))
 	mockUCase.AssertExpectations(t)
 }
 
@@ -48,7 +50,7 @@ func TestFetchError(t *testing.T) {
 	mockUCase := new(mocks.Usecase)
 	num := 1
 	cursor := "2"
-	mockUCase.On("Fetch", mock.Anything, cursor, int64(num)).Return(nil, models.ErrInternalServerError)
+	mockUCase.On("Fetch", mock.Anything, cursor, int64(num)).Return(nil, models.Page{}, models.ErrInternalServerError)
 
 	req, err := http.NewRequest(http.MethodGet, "/articles?num=1&cursor="+cursor, strings.NewReader(""))
 	assert.NoError(t, err)
@@ -152,3 +154,67 @@ func TestDelete(t *testing.T) {
 	mockUCase.AssertExpectations(t)
 
 }
+
+func TestStoreConflict(t *testing.T) {
+	mockArticle := models.Article{
+		Title:   "Title",
+		Content: "Content",
+	}
+	mockUCase := new(mocks.Usecase)
+
+	j, err := json.Marshal(mockArticle)
+	assert.NoError(t, err)
+
+	mockUCase.On("Store", mock.Anything, mock.AnythingOfType("*models.Article")).Return(models.ErrConflict)
+
+	req, err := http.NewRequest(http.MethodPost, "/articles", strings.NewReader(string(j)))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+
+	handler := articleHttp.HttpArticleHandler{
+		AUsecase: mockUCase,
+	}
+	handler.Store(rec, req)
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	mockUCase.AssertExpectations(t)
+}
+
+func TestGetByIDTimeout(t *testing.T) {
+	mockUCase := new(mocks.Usecase)
+	mockUCase.On("GetByID", mock.Anything, int64(1)).Return(nil, models.ErrTimeout)
+
+	req, err := http.NewRequest(http.MethodGet, "/article/1", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	handler := articleHttp.HttpArticleHandler{
+		AUsecase: mockUCase,
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/article/{id}", handler.GetByID)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+	mockUCase.AssertExpectations(t)
+}
+
+func TestGetByIDCanceled(t *testing.T) {
+	mockUCase := new(mocks.Usecase)
+	mockUCase.On("GetByID", mock.Anything, int64(1)).Return(nil, models.ErrCanceled)
+
+	req, err := http.NewRequest(http.MethodGet, "/article/1", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	handler := articleHttp.HttpArticleHandler{
+		AUsecase: mockUCase,
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/article/{id}", handler.GetByID)
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, 499, rec.Code)
+	mockUCase.AssertExpectations(t)
+}
@@ -0,0 +1,91 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/naveenpatilm/go-clean-arch/article"
+	"github.com/naveenpatilm/go-clean-arch/job"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+// ExportKind is the kind of the jobs exporting the articles
+const ExportKind = "articles.export"
+
+// exportPageSize is how many articles are read at once
+const exportPageSize = 100
+
+// ExportPayload is the payload of the articles.export jobs
+type ExportPayload struct {
+	// Name is the file written in the export directory, articles-<job id>.jsonl by default
+	Name string `json:"name"`
+}
+
+type exportHandler struct {
+	AUsecase article.Usecase
+	dir      string
+}
+
+// NewExportHandler will create the job.Handler writing every article, as JSON lines, to a file of dir.
+// The file is replaced once complete, so a retried export never leaves a partial file behind.
+func NewExportHandler(us article.Usecase, dir string) job.Handler {
+	return &exportHandler{AUsecase: us, dir: dir}
+}
+
+func (h *exportHandler) Handle(ctx context.Context, j *models.Job) error {
+	var payload ExportPayload
+	if err := job.Decode(j, &payload); err != nil {
+		return err
+	}
+	name := filepath.Base(payload.Name)
+	if payload.Name == "" {
+		name = fmt.Sprintf("articles-%d.jsonl", j.ID)
+	}
+	if name == "." || name == ".." || name == string(filepath.Separator) {
+		return job.Permanent(fmt.Errorf("invalid export name %q", payload.Name))
+	}
+	if err := os.MkdirAll(h.dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(h.dir, "."+name)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err = h.export(ctx, json.NewEncoder(tmp)); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(h.dir, name))
+}
+
+// export encodes every article, page by page
+func (h *exportHandler) export(ctx context.Context, enc *json.Encoder) error {
+	cursor := ""
+	for {
+		articles, page, err := h.AUsecase.Fetch(ctx, cursor, exportPageSize)
+		if err == models.ErrNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, ar := range articles {
+			if err = enc.Encode(ar); err != nil {
+				return err
+			}
+		}
+		if page.Next == "" || len(articles) == 0 {
+			return nil
+		}
+		cursor = page.Next
+	}
+}
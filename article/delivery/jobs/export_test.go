@@ -0,0 +1,60 @@
+package jobs_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/article/delivery/jobs"
+	"github.com/naveenpatilm/go-clean-arch/article/mocks"
+	"github.com/naveenpatilm/go-clean-arch/job"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+func TestExport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "export")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	mockUCase := new(mocks.Usecase)
+	mockUCase.On("Fetch", mock.Anything, "", int64(100)).Return([]*models.Article{{ID: 1, Title: "first"}}, models.Page{Next: "c1"}, nil).Once()
+	mockUCase.On("Fetch", mock.Anything, "c1", int64(100)).Return([]*models.Article{{ID: 2, Title: "second"}}, models.Page{}, nil).Once()
+	h := jobs.NewExportHandler(mockUCase, dir)
+
+	err = h.Handle(context.TODO(), &models.Job{ID: 7, Kind: jobs.ExportKind, Payload: `{"name": "../all.jsonl"}`})
+
+	require.NoError(t, err)
+	b, err := ioutil.ReadFile(filepath.Join(dir, "all.jsonl"))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[1], `"title":"second"`)
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1, "the temporary file is removed")
+	mockUCase.AssertExpectations(t)
+}
+
+func TestExportFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "export")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	mockUCase := new(mocks.Usecase)
+	mockUCase.On("Fetch", mock.Anything, "", int64(100)).Return(nil, models.Page{}, models.ErrTimeout).Once()
+	h := jobs.NewExportHandler(mockUCase, dir)
+
+	err = h.Handle(context.TODO(), &models.Job{ID: 7, Kind: jobs.ExportKind, Payload: `{}`})
+	assert.Equal(t, models.ErrTimeout, err)
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files, "no partial export is left")
+
+	err = h.Handle(context.TODO(), &models.Job{ID: 7, Kind: jobs.ExportKind, Payload: `[]`})
+	assert.True(t, job.IsPermanent(err))
+}
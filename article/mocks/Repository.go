@@ -26,7 +26,7 @@ func (_m *Repository) Delete(ctx context.Context, id int64) error {
 }
 
 // Fetch provides a mock function with given fields: ctx, cursor, num
-func (_m *Repository) Fetch(ctx context.Context, cursor string, num int64) ([]*models.Article, error) {
+func (_m *Repository) Fetch(ctx context.Context, cursor string, num int64) ([]*models.Article, models.Page, error) {
 	ret := _m.Called(ctx, cursor, num)
 
 	var r0 []*models.Article
@@ -38,14 +38,21 @@ func (_m *Repository) Fetch(ctx context.Context, cursor string, num int64) ([]*m
 		}
 	}
 
-	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, int64) error); ok {
+	var r1 models.Page
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64) models.Page); ok {
 		r1 = rf(ctx, cursor, num)
 	} else {
-		r1 = ret.Error(1)
+		r1 = ret.Get(1).(models.Page)
 	}
 
-	return r0, r1
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, int64) error); ok {
+		r2 = rf(ctx, cursor, num)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
 
 // GetByID provides a mock function with given fields: ctx, id
@@ -121,3 +128,17 @@ func (_m *Repository) Update(ctx context.Context, ar *models.Article) error {
 
 	return r0
 }
+
+// UpdateAuthor provides a mock function with given fields: ctx, id, authorID
+func (_m *Repository) UpdateAuthor(ctx context.Context, id int64, authorID int64) error {
+	ret := _m.Called(ctx, id, authorID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = rf(ctx, id, authorID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -26,7 +26,7 @@ func (_m *Usecase) Delete(ctx context.Context, id int64) error {
 }
 
 // Fetch provides a mock function with given fields: ctx, cursor, num
-func (_m *Usecase) Fetch(ctx context.Context, cursor string, num int64) ([]*models.Article, error) {
+func (_m *Usecase) Fetch(ctx context.Context, cursor string, num int64) ([]*models.Article, models.Page, error) {
 	ret := _m.Called(ctx, cursor, num)
 
 	var r0 []*models.Article
@@ -38,14 +38,21 @@ func (_m *Usecase) Fetch(ctx context.Context, cursor string, num int64) ([]*mode
 		}
 	}
 
-	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, int64) error); ok {
+	var r1 models.Page
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64) models.Page); ok {
 		r1 = rf(ctx, cursor, num)
 	} else {
-		r1 = ret.Error(1)
+		r1 = ret.Get(1).(models.Page)
 	}
 
-	return r0, r1
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, int64) error); ok {
+		r2 = rf(ctx, cursor, num)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
 
 // GetByID provides a mock function with given fields: ctx, id
@@ -94,6 +101,29 @@ func (_m *Usecase) GetByTitle(ctx context.Context, title string) (*models.Articl
 	return r0, r1
 }
 
+// Reassign provides a mock function with given fields: ctx, id, authorID
+func (_m *Usecase) Reassign(ctx context.Context, id int64, authorID int64) (*models.Article, error) {
+	ret := _m.Called(ctx, id, authorID)
+
+	var r0 *models.Article
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) *models.Article); ok {
+		r0 = rf(ctx, id, authorID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Article)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, id, authorID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Store provides a mock function with given fields: _a0, _a1
 func (_m *Usecase) Store(_a0 context.Context, _a1 *models.Article) error {
 	ret := _m.Called(_a0, _a1)
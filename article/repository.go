@@ -8,10 +8,12 @@ import (
 
 // Repository represent the article's repository contract
 type Repository interface {
-	Fetch(ctx context.Context, cursor string, num int64) (res []*models.Article, err error)
+	Fetch(ctx context.Context, cursor string, num int64) (res []*models.Article, page models.Page, err error)
 	GetByID(ctx context.Context, id int64) (*models.Article, error)
 	GetByTitle(ctx context.Context, title string) (*models.Article, error)
 	Update(ctx context.Context, ar *models.Article) error
+	// UpdateAuthor changes only the author of the article id
+	UpdateAuthor(ctx context.Context, id, authorID int64) error
 	Store(ctx context.Context, a *models.Article) error
 	Delete(ctx context.Context, id int64) error
 }
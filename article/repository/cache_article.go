@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"container/list"
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/naveenpatilm/go-clean-arch/article"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+// CacheStats holds the counters of a caching repository
+type CacheStats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Size      int    `json:"size"`
+}
+
+// CachingRepository is an article.Repository that reports how its cache performs.
+// The writes of a transaction.Manager go to the repositories of its transaction,
+// not to this one: whoever commits them must call Invalidate afterwards, as
+// the article usecase does through its event publisher.
+type CachingRepository interface {
+	article.Repository
+	Stats() CacheStats
+	// Invalidate drops the article with id and title, for writes that bypass the cache
+	Invalidate(id int64, title string)
+}
+
+type cacheEntry struct {
+	key       string
+	article   models.Article
+	expiresAt time.Time
+}
+
+type cachingArticleRepository struct {
+	article.Repository
+
+	size  int
+	ttl   time.Duration
+	group singleflight.Group
+
+	mu      sync.Mutex
+	ll      *list.List
+	entries map[string]*list.Element
+	// titles holds the title keys cached for each article ID
+	titles     map[int64]map[string]struct{}
+	generation uint64
+
+	hits, misses, evictions uint64
+}
+
+// NewCachingArticleRepository wraps repo with a read-through LRU cache for GetByID and GetByTitle.
+// At most size articles are kept, each for ttl. Concurrent misses on one key share a single query.
+// Reads whose context is marked with models.ReadYourWrites skip the cache.
+// Writes made through the wrapper invalidate the cache; writes that bypass it,
+// like those inside a transaction.Manager, must call Invalidate once committed
+// or are only picked up when entries expire.
+func NewCachingArticleRepository(repo article.Repository, size int, ttl time.Duration) CachingRepository {
+
+	return &cachingArticleRepository{
+		Repository: repo,
+		size:       size,
+		ttl:        ttl,
+		ll:         list.New(),
+		entries:    map[string]*list.Element{},
+		titles:     map[int64]map[string]struct{}{},
+	}
+}
+
+// fetchTimeout bounds a query shared by concurrent misses, which outlives the
+// caller that started it
+const fetchTimeout = 5 * time.Second
+
+func idKey(id int64) string {
+	return "id:" + strconv.FormatInt(id, 10)
+}
+
+func titleKey(title string) string {
+	return "title:" + title
+}
+
+func (m *cachingArticleRepository) GetByID(ctx context.Context, id int64) (*models.Article, error) {
+	return m.load(ctx, idKey(id), func(ctx context.Context) (*models.Article, error) {
+		return m.Repository.GetByID(ctx, id)
+	})
+}
+
+func (m *cachingArticleRepository) GetByTitle(ctx context.Context, title string) (*models.Article, error) {
+	return m.load(ctx, titleKey(title), func(ctx context.Context) (*models.Article, error) {
+		return m.Repository.GetByTitle(ctx, title)
+	})
+}
+
+func (m *cachingArticleRepository) Store(ctx context.Context, a *models.Article) error {
+	err := m.Repository.Store(ctx, a)
+	m.invalidate(a.ID, a.Title)
+	return err
+}
+
+func (m *cachingArticleRepository) Update(ctx context.Context, ar *models.Article) error {
+	err := m.Repository.Update(ctx, ar)
+	m.invalidate(ar.ID, ar.Title)
+	return err
+}
+
+func (m *cachingArticleRepository) UpdateAuthor(ctx context.Context, id, authorID int64) error {
+	err := m.Repository.UpdateAuthor(ctx, id, authorID)
+	m.invalidate(id, "")
+	return err
+}
+
+func (m *cachingArticleRepository) Delete(ctx context.Context, id int64) error {
+	err := m.Repository.Delete(ctx, id)
+	m.invalidate(id, "")
+	return err
+}
+
+func (m *cachingArticleRepository) Invalidate(id int64, title string) {
+	m.invalidate(id, title)
+}
+
+func (m *cachingArticleRepository) Stats() CacheStats {
+	m.mu.Lock()
+	size := m.ll.Len()
+	m.mu.Unlock()
+
+	return CacheStats{
+		Hits:      atomic.LoadUint64(&m.hits),
+		Misses:    atomic.LoadUint64(&m.misses),
+		Evictions: atomic.LoadUint64(&m.evictions),
+		Size:      size,
+	}
+}
+
+// load returns a copy of the cached article for key, calling fetch on a miss.
+// Errors are never cached. The query runs without the cancellation of the
+// caller that started it, so the other callers waiting for it do not fail
+// with it; each caller still stops waiting when its own ctx is done.
+func (m *cachingArticleRepository) load(ctx context.Context, key string, fetch func(context.Context) (*models.Article, error)) (*models.Article, error) {
+	if models.IsReadYourWrites(ctx) {
+		return fetch(ctx)
+	}
+	if a, ok := m.get(key); ok {
+		atomic.AddUint64(&m.hits, 1)
+		return &a, nil
+	}
+	atomic.AddUint64(&m.misses, 1)
+
+	fetchCtx := context.WithoutCancel(ctx)
+	ch := m.group.DoChan(key, func() (interface{}, error) {
+		m.mu.Lock()
+		generation := m.generation
+		m.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(fetchCtx, fetchTimeout)
+		defer cancel()
+		a, err := fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		m.add(generation, *a)
+		return *a, nil
+	})
+	select {
+	case <-ctx.Done():
+		return nil, models.ContextError(ctx, ctx.Err())
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		a := res.Val.(models.Article)
+		return &a, nil
+	}
+}
+
+func (m *cachingArticleRepository) get(key string) (models.Article, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.entries[key]
+	if !ok {
+		return models.Article{}, false
+	}
+	entry := el.Value.(*cacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		m.remove(el)
+		return models.Article{}, false
+	}
+	m.ll.MoveToFront(el)
+	return entry.article, true
+}
+
+// add caches a under both its id and title keys, unless a write invalidated
+// the cache after the query started.
+func (m *cachingArticleRepository) add(generation uint64, a models.Article) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if generation != m.generation || m.size <= 0 {
+		return
+	}
+	expiresAt := time.Now().Add(m.ttl)
+	for _, key := range []string{idKey(a.ID), titleKey(a.Title)} {
+		if el, ok := m.entries[key]; ok {
+			m.remove(el)
+		}
+		m.entries[key] = m.ll.PushFront(&cacheEntry{key: key, article: a, expiresAt: expiresAt})
+	}
+	if m.titles[a.ID] == nil {
+		m.titles[a.ID] = map[string]struct{}{}
+	}
+	m.titles[a.ID][titleKey(a.Title)] = struct{}{}
+	for m.ll.Len() > m.size {
+		m.remove(m.ll.Back())
+		atomic.AddUint64(&m.evictions, 1)
+	}
+}
+
+// invalidate drops every entry of the article with id and the given title
+func (m *cachingArticleRepository) invalidate(id int64, title string) {
+	m.mu.Lock()
+	m.generation++
+	keys := []string{idKey(id), titleKey(title)}
+	for key := range m.titles[id] {
+		keys = append(keys, key)
+	}
+	for _, key := range keys {
+		if el, ok := m.entries[key]; ok {
+			m.remove(el)
+		}
+	}
+	m.mu.Unlock()
+
+	m.group.Forget(idKey(id))
+	m.group.Forget(titleKey(title))
+}
+
+func (m *cachingArticleRepository) remove(el *list.Element) {
+	m.ll.Remove(el)
+	entry := el.Value.(*cacheEntry)
+	delete(m.entries, entry.key)
+	if titles, ok := m.titles[entry.article.ID]; ok && entry.key != idKey(entry.article.ID) {
+		delete(titles, entry.key)
+		if len(titles) == 0 {
+			delete(m.titles, entry.article.ID)
+		}
+	}
+}
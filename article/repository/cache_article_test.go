@@ -0,0 +1,196 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/article/mocks"
+	articleRepo "github.com/naveenpatilm/go-clean-arch/article/repository"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+func TestCacheGetByID(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockRepo.On("GetByID", mock.Anything, int64(1)).Return(&models.Article{ID: 1, Title: "Hello"}, nil).Once()
+	repo := articleRepo.NewCachingArticleRepository(mockRepo, 10, time.Minute)
+
+	first, err := repo.GetByID(context.TODO(), 1)
+	require.NoError(t, err)
+	// callers can not mutate the cached article
+	first.Title = "Changed"
+
+	second, err := repo.GetByID(context.TODO(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", second.Title)
+
+	// the title key was filled by the GetByID
+	byTitle, err := repo.GetByTitle(context.TODO(), "Hello")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), byTitle.ID)
+
+	assert.Equal(t, articleRepo.CacheStats{Hits: 2, Misses: 1, Size: 2}, repo.Stats())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCacheErrorsAreNotCached(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockRepo.On("GetByTitle", mock.Anything, "Hello").Return(nil, models.ErrNotFound).Twice()
+	repo := articleRepo.NewCachingArticleRepository(mockRepo, 10, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		_, err := repo.GetByTitle(context.TODO(), "Hello")
+		assert.Equal(t, models.ErrNotFound, err)
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCacheWritesInvalidate(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockRepo.On("GetByID", mock.Anything, int64(1)).Return(&models.Article{ID: 1, Title: "Hello"}, nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+	mockRepo.On("GetByID", mock.Anything, int64(1)).Return(&models.Article{ID: 1, Title: "World"}, nil).Once()
+	mockRepo.On("Delete", mock.Anything, int64(1)).Return(nil).Once()
+	mockRepo.On("GetByTitle", mock.Anything, "World").Return(nil, models.ErrNotFound).Once()
+	repo := articleRepo.NewCachingArticleRepository(mockRepo, 10, time.Minute)
+
+	_, err := repo.GetByID(context.TODO(), 1)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Update(context.TODO(), &models.Article{ID: 1, Title: "World"}))
+	res, err := repo.GetByID(context.TODO(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "World", res.Title)
+
+	require.NoError(t, repo.Delete(context.TODO(), 1))
+	_, err = repo.GetByTitle(context.TODO(), "World")
+	assert.Equal(t, models.ErrNotFound, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCacheReadYourWritesSkipsTheCache(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockRepo.On("GetByID", mock.Anything, int64(1)).Return(&models.Article{ID: 1, Title: "Hello"}, nil).Twice()
+	repo := articleRepo.NewCachingArticleRepository(mockRepo, 10, time.Minute)
+
+	_, err := repo.GetByID(context.TODO(), 1)
+	require.NoError(t, err)
+	_, err = repo.GetByID(models.ReadYourWrites(context.TODO()), 1)
+	require.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockRepo.On("GetByID", mock.Anything, int64(1)).Return(&models.Article{ID: 1, Title: "Hello"}, nil).Twice()
+	repo := articleRepo.NewCachingArticleRepository(mockRepo, 10, time.Minute)
+
+	_, err := repo.GetByID(context.TODO(), 1)
+	require.NoError(t, err)
+	repo.Invalidate(1, "Hello")
+	_, err = repo.GetByID(context.TODO(), 1)
+	require.NoError(t, err)
+
+	// the title entry cached with the article is dropped by its ID
+	mockRepo.On("GetByTitle", mock.Anything, "Hello").Return(&models.Article{ID: 1, Title: "Hello"}, nil).Once()
+	repo.Invalidate(1, "")
+	_, err = repo.GetByTitle(context.TODO(), "Hello")
+	require.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCacheTTLAndSize(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	for _, id := range []int64{1, 2} {
+		mockRepo.On("GetByID", mock.Anything, id).Return(&models.Article{ID: id, Title: strconv.FormatInt(id, 10)}, nil)
+	}
+
+	repo := articleRepo.NewCachingArticleRepository(mockRepo, 10, 10*time.Millisecond)
+	_, err := repo.GetByID(context.TODO(), 1)
+	require.NoError(t, err)
+	time.Sleep(20 * time.Millisecond)
+	_, err = repo.GetByID(context.TODO(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), repo.Stats().Misses)
+
+	// each article takes an id and a title entry
+	repo = articleRepo.NewCachingArticleRepository(mockRepo, 2, time.Minute)
+	for _, id := range []int64{1, 2} {
+		_, err = repo.GetByID(context.TODO(), id)
+		require.NoError(t, err)
+	}
+	stats := repo.Stats()
+	assert.Equal(t, 2, stats.Size)
+	assert.Equal(t, uint64(2), stats.Evictions)
+}
+
+func TestCacheConcurrentMissesShareOneQuery(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockRepo.On("GetByID", mock.Anything, int64(1)).
+		After(50*time.Millisecond).
+		Return(&models.Article{ID: 1, Title: "Hello"}, nil).Once()
+	repo := articleRepo.NewCachingArticleRepository(mockRepo, 10, time.Minute)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.GetByID(context.TODO(), 1)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCacheSharedQueryOutlivesItsCaller(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	var queryErr error
+	mockRepo.On("GetByID", mock.Anything, int64(1)).
+		Run(func(args mock.Arguments) {
+			time.Sleep(50 * time.Millisecond)
+			queryErr = args.Get(0).(context.Context).Err()
+		}).
+		Return(&models.Article{ID: 1, Title: "Hello"}, nil).Once()
+	repo := articleRepo.NewCachingArticleRepository(mockRepo, 10, time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	done := make(chan error)
+	go func() {
+		_, err := repo.GetByID(ctx, 1)
+		done <- err
+	}()
+	time.Sleep(5 * time.Millisecond)
+	res, err := repo.GetByID(context.TODO(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", res.Title)
+	assert.NoError(t, queryErr)
+	assert.Equal(t, models.ErrTimeout, <-done)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCacheStoreError(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockRepo.On("Store", mock.Anything, mock.Anything).Return(errors.New("Unexpected")).Once()
+	repo := articleRepo.NewCachingArticleRepository(mockRepo, 10, time.Minute)
+
+	assert.Error(t, repo.Store(context.TODO(), &models.Article{Title: "Hello"}))
+	mockRepo.AssertExpectations(t)
+}
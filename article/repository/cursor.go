@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+const (
+	timeFormat = "2006-01-02T15:04:05.999Z07:00" // reduce precision from RFC3339Nano as date format
+
+	cursorVersion = "v1"
+)
+
+var errUnknownCursorVersion = errors.New("unknown cursor version")
+
+// Cursor represent a position in the (created_at, id) ordering of articles
+type Cursor struct {
+	CreatedAt time.Time
+	ID        int64
+	// Backward is set when the cursor points at the page before CreatedAt/ID
+	Backward bool
+}
+
+type cursorPayload struct {
+	CreatedAt time.Time `json:"t"`
+	ID        int64     `json:"id"`
+	Backward  bool      `json:"b,omitempty"`
+}
+
+// EncodeCursor encodes c as an opaque, versioned cursor string
+func EncodeCursor(c Cursor) string {
+	byt, _ := json.Marshal(cursorPayload{
+		CreatedAt: c.CreatedAt.UTC(),
+		ID:        c.ID,
+		Backward:  c.Backward,
+	})
+
+	return cursorVersion + "." + base64.RawURLEncoding.EncodeToString(byt)
+}
+
+// DecodeCursor decodes a cursor produced by EncodeCursor. Unversioned cursors
+// holding only a created_at time are still accepted; they continue after
+// every article created at that time.
+func DecodeCursor(encoded string) (Cursor, error) {
+	idx := strings.Index(encoded, ".")
+	if idx < 0 {
+		return decodeLegacyCursor(encoded)
+	}
+
+	if encoded[:idx] != cursorVersion {
+		return Cursor{}, errUnknownCursorVersion
+	}
+	byt, err := base64.RawURLEncoding.DecodeString(encoded[idx+1:])
+	if err != nil {
+		return Cursor{}, err
+	}
+	var p cursorPayload
+	if err = json.Unmarshal(byt, &p); err != nil {
+		return Cursor{}, err
+	}
+
+	return Cursor{CreatedAt: p.CreatedAt, ID: p.ID, Backward: p.Backward}, nil
+}
+
+func decodeLegacyCursor(encodedTime string) (Cursor, error) {
+	byt, err := base64.StdEncoding.DecodeString(encodedTime)
+	if err != nil {
+		return Cursor{}, err
+	}
+
+	t, err := time.Parse(timeFormat, string(byt))
+	if err != nil {
+		return Cursor{}, err
+	}
+
+	return Cursor{CreatedAt: t, ID: math.MaxInt64}, nil
+}
+
+// newPage builds the cursors around a window of articles fetched from c.
+// The articles must already be in ascending (created_at, id) order and
+// hasMore reports whether the query found a row beyond the window.
+func newPage(articles []*models.Article, c *Cursor, hasMore bool) models.Page {
+	if len(articles) == 0 {
+		return models.Page{}
+	}
+	first, last := articles[0], articles[len(articles)-1]
+	next := EncodeCursor(Cursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	prev := EncodeCursor(Cursor{CreatedAt: first.CreatedAt, ID: first.ID, Backward: true})
+
+	var page models.Page
+	switch {
+	case c == nil:
+		if hasMore {
+			page.Next = next
+		}
+	case c.Backward:
+		page.Next = next
+		if hasMore {
+			page.Prev = prev
+		}
+	default:
+		page.Prev = prev
+		if hasMore {
+			page.Next = next
+		}
+	}
+	return page
+}
@@ -0,0 +1,30 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	articleRepo "github.com/naveenpatilm/go-clean-arch/article/repository"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	c := articleRepo.Cursor{
+		CreatedAt: time.Date(2018, 5, 12, 10, 0, 0, 123456000, time.UTC),
+		ID:        42,
+		Backward:  true,
+	}
+
+	encoded := articleRepo.EncodeCursor(c)
+	decoded, err := articleRepo.DecodeCursor(encoded)
+
+	require.NoError(t, err)
+	assert.Equal(t, c, decoded)
+}
+
+func TestDecodeCursorUnknownVersion(t *testing.T) {
+	_, err := articleRepo.DecodeCursor("v2.eyJpZCI6MX0")
+	assert.Error(t, err)
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+// articleDialect holds what differs between the SQL databases behind gormArticleRepository
+type articleDialect interface {
+	// titleEquals is the condition matching a title case-insensitively
+	titleEquals() string
+	// isUniqueViolation reports whether err was raised by the unique title index
+	isUniqueViolation(err error) bool
+	// beforeSave adjusts an article before it is written
+	beforeSave(a *models.Article)
+}
+
+// gormArticleRepository is the article.Repository shared by the SQL databases
+type gormArticleRepository struct {
+	DB      models.Gormw
+	dialect articleDialect
+}
+
+func (m *gormArticleRepository) Fetch(ctx context.Context, cursor string, num int64) ([]*models.Article, models.Page, error) {
+
+	query := m.DB.WithContext(ctx).Limit(int(num) + 1) // one extra row tells whether there is a next page
+	var c *Cursor
+	if cursor != "" {
+		decodedCursor, err := DecodeCursor(cursor)
+		if err != nil {
+			return nil, models.Page{}, models.ErrBadParamInput
+		}
+		c = &decodedCursor
+	}
+
+	switch {
+	case c == nil:
+		query = query.Order("created_at, id")
+	case c.Backward:
+		query = query.Where("created_at < ? OR (created_at = ? AND id < ?)", c.CreatedAt, c.CreatedAt, c.ID).
+			Order("created_at DESC, id DESC")
+	default:
+		query = query.Where("created_at > ? OR (created_at = ? AND id > ?)", c.CreatedAt, c.CreatedAt, c.ID).
+			Order("created_at, id")
+	}
+
+	var articles []*models.Article
+	err := models.ContextError(ctx, query.Find(&articles).Error())
+	if err != nil {
+		return nil, models.Page{}, err
+	}
+	if len(articles) == 0 {
+		return nil, models.Page{}, models.ErrNotFound
+	}
+
+	hasMore := int64(len(articles)) > num
+	if hasMore {
+		articles = articles[:num]
+	}
+	if c != nil && c.Backward {
+		for i, j := 0, len(articles)-1; i < j; i, j = i+1, j-1 {
+			articles[i], articles[j] = articles[j], articles[i]
+		}
+	}
+
+	return articles, newPage(articles, c, hasMore), nil
+}
+
+func (m *gormArticleRepository) GetByID(ctx context.Context, id int64) (*models.Article, error) {
+	var article models.Article
+	err := models.ContextError(ctx, m.DB.WithContext(ctx).First(&article, id).Error())
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &article, nil
+}
+
+func (m *gormArticleRepository) GetByTitle(ctx context.Context, title string) (*models.Article, error) {
+	var article models.Article
+	err := m.DB.WithContext(ctx).Where(m.dialect.titleEquals(), title).First(&article).Error()
+	err = models.ContextError(ctx, err)
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &article, nil
+}
+
+func (m *gormArticleRepository) Store(ctx context.Context, a *models.Article) error {
+	m.dialect.beforeSave(a)
+	err := models.ContextError(ctx, m.DB.WithContext(ctx).Create(a).Error())
+	if m.dialect.isUniqueViolation(err) {
+		return models.ErrConflict
+	}
+	if err != nil {
+		return err
+	}
+	logrus.Debug("Created At: ", a.CreatedAt)
+	return nil
+}
+
+func (m *gormArticleRepository) Delete(ctx context.Context, id int64) error {
+	res := m.DB.WithContext(ctx).Where("id = ?", id).Delete(models.Article{})
+	err := models.ContextError(ctx, res.Error())
+	if err != nil {
+		return err
+	}
+	rowsAffected := res.RowsAffected()
+	if rowsAffected != 1 {
+		err = fmt.Errorf("Weird  Behaviour. Total Affected: %d", rowsAffected)
+		return err
+	}
+
+	return nil
+}
+
+func (m *gormArticleRepository) Update(ctx context.Context, ar *models.Article) error {
+	m.dialect.beforeSave(ar)
+	res := m.DB.WithContext(ctx).Save(ar)
+
+	err := models.ContextError(ctx, res.Error())
+	if m.dialect.isUniqueViolation(err) {
+		return models.ErrConflict
+	}
+	if err != nil {
+		return err
+	}
+
+	affected := res.RowsAffected()
+	if affected != 1 {
+		err = fmt.Errorf("Weird  Behaviour. Total Affected: %d", affected)
+		return err
+	}
+
+	return nil
+}
+
+func (m *gormArticleRepository) UpdateAuthor(ctx context.Context, id, authorID int64) error {
+	res := m.DB.WithContext(ctx).Model(&models.Article{ID: id}).UpdateColumn("author_id", authorID)
+	if err := models.ContextError(ctx, res.Error()); err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
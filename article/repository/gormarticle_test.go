@@ -0,0 +1,297 @@
+package repository_test
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	_ "github.com/jinzhu/gorm/dialects/mysql"
+	_ "github.com/jinzhu/gorm/dialects/postgres"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/article"
+	articleRepo "github.com/naveenpatilm/go-clean-arch/article/repository"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+// ph matches a bind parameter of either dialect
+const ph = `(?:\$\d+|\?)`
+
+var articleColumns = []string{"id", "created_at", "updated_at", "deleted_at", "title", "content", "author_id"}
+
+// dialect describes one SQL database the gorm repository runs against
+type dialect struct {
+	name    string
+	newRepo func(models.Gormw) article.Repository
+	// titleWhere is the condition given to Where for a title, and
+	// titleEquals the pattern of its SQL
+	titleWhere  string
+	titleEquals string
+	uniqueErr   error
+	// expectInsert registers the statement gorm issues to create an article,
+	// failing with err when it is not nil
+	expectInsert func(mock sqlmock.Sqlmock, delay time.Duration, err error)
+}
+
+var dialects = []dialect{
+	{
+		name:        "postgres",
+		newRepo:     articleRepo.NewPostgresArticleRepository,
+		titleWhere:  "lower(title) = lower(?)",
+		titleEquals: `lower\(title\) = lower\(` + ph + `\)`,
+		uniqueErr:   &pq.Error{Code: "23505"},
+		expectInsert: func(mock sqlmock.Sqlmock, delay time.Duration, err error) {
+			q := mock.ExpectQuery(`INSERT INTO "articles"`).WillDelayFor(delay)
+			if err != nil {
+				q.WillReturnError(err)
+				return
+			}
+			q.WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		},
+	},
+	{
+		name:        "mysql",
+		newRepo:     articleRepo.NewMysqlArticleRepository,
+		titleWhere:  "title = ?",
+		titleEquals: `title = ` + ph,
+		uniqueErr:   &mysql.MySQLError{Number: 1062, Message: "Duplicate entry"},
+		expectInsert: func(mock sqlmock.Sqlmock, delay time.Duration, err error) {
+			e := mock.ExpectExec("INSERT INTO `articles`").WillDelayFor(delay)
+			if err != nil {
+				e.WillReturnError(err)
+				return
+			}
+			e.WillReturnResult(sqlmock.NewResult(1, 1))
+		},
+	},
+	{
+		name:        "sqlite3",
+		newRepo:     articleRepo.NewSqliteArticleRepository,
+		titleWhere:  "title = ? COLLATE NOCASE",
+		titleEquals: `title = \? COLLATE NOCASE`,
+		uniqueErr:   sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintUnique},
+		expectInsert: func(mock sqlmock.Sqlmock, delay time.Duration, err error) {
+			e := mock.ExpectExec(`INSERT INTO "articles"`).WillDelayFor(delay)
+			if err != nil {
+				e.WillReturnError(err)
+				return
+			}
+			e.WillReturnResult(sqlmock.NewResult(1, 1))
+		},
+	},
+}
+
+// forEachDialect runs the same behaviour test against every dialect
+func forEachDialect(t *testing.T, test func(t *testing.T, d dialect, repo article.Repository, mock sqlmock.Sqlmock)) {
+	forEachDialectWithHook(t, nil, test)
+}
+
+// forEachDialectWithHook is forEachDialect calling hook around the statements
+func forEachDialectWithHook(t *testing.T, hook models.StatementHook, test func(t *testing.T, d dialect, repo article.Repository, mock sqlmock.Sqlmock)) {
+	for _, d := range dialects {
+		d := d
+		t.Run(d.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			gormDB, err := models.Openw(d.name, db)
+			require.NoError(t, err)
+			if hook != nil {
+				gormDB = models.WithStatementHook(gormDB, hook)
+			}
+
+			test(t, d, d.newRepo(gormDB), mock)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func articleRows(createdAt time.Time, ids ...int64) *sqlmock.Rows {
+	rows := sqlmock.NewRows(articleColumns)
+	for _, id := range ids {
+		rows.AddRow(id, createdAt, createdAt, nil, "title", "content", int64(1))
+	}
+	return rows
+}
+
+func TestFetchFirstPage(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, d dialect, repo article.Repository, mock sqlmock.Sqlmock) {
+		createdAt := time.Date(2018, 5, 12, 10, 0, 0, 0, time.UTC)
+		mock.ExpectQuery(`SELECT \* FROM .articles. WHERE .articles.\..deleted_at. IS NULL ORDER BY created_at, id LIMIT 3`).
+			WillReturnRows(articleRows(createdAt, 1, 2, 3))
+
+		list, page, err := repo.Fetch(context.TODO(), "", 2)
+
+		require.NoError(t, err)
+		require.Len(t, list, 2)
+		assert.Equal(t, int64(1), list[0].ID)
+		assert.Equal(t, int64(1), list[0].Author.ID)
+		assert.Empty(t, page.Prev)
+
+		next, err := articleRepo.DecodeCursor(page.Next)
+		require.NoError(t, err)
+		assert.Equal(t, articleRepo.Cursor{CreatedAt: createdAt, ID: 2}, next)
+	})
+}
+
+func TestFetchForward(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, d dialect, repo article.Repository, mock sqlmock.Sqlmock) {
+		createdAt := time.Date(2018, 5, 12, 10, 0, 0, 0, time.UTC)
+		cursor := articleRepo.EncodeCursor(articleRepo.Cursor{CreatedAt: createdAt, ID: 2})
+		mock.ExpectQuery(`IS NULL AND \(\(created_at > `+ph+` OR \(created_at = `+ph+` AND id > `+ph+`\)\)\) ORDER BY created_at, id LIMIT 3`).
+			WithArgs(createdAt, createdAt, 2).
+			WillReturnRows(articleRows(createdAt, 3, 4))
+
+		list, page, err := repo.Fetch(context.TODO(), cursor, 2)
+
+		require.NoError(t, err)
+		require.Len(t, list, 2)
+		assert.Empty(t, page.Next)
+
+		prev, err := articleRepo.DecodeCursor(page.Prev)
+		require.NoError(t, err)
+		assert.Equal(t, articleRepo.Cursor{CreatedAt: createdAt, ID: 3, Backward: true}, prev)
+	})
+}
+
+func TestFetchBackward(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, d dialect, repo article.Repository, mock sqlmock.Sqlmock) {
+		createdAt := time.Date(2018, 5, 12, 10, 0, 0, 0, time.UTC)
+		cursor := articleRepo.EncodeCursor(articleRepo.Cursor{CreatedAt: createdAt, ID: 5, Backward: true})
+		mock.ExpectQuery(`\(\(created_at < `+ph+` OR \(created_at = `+ph+` AND id < `+ph+`\)\)\) ORDER BY created_at DESC, id DESC LIMIT 3`).
+			WithArgs(createdAt, createdAt, 5).
+			WillReturnRows(articleRows(createdAt, 4, 3, 2))
+
+		list, page, err := repo.Fetch(context.TODO(), cursor, 2)
+
+		require.NoError(t, err)
+		require.Len(t, list, 2)
+		assert.Equal(t, int64(3), list[0].ID)
+		assert.Equal(t, int64(4), list[1].ID)
+
+		prev, err := articleRepo.DecodeCursor(page.Prev)
+		require.NoError(t, err)
+		assert.Equal(t, articleRepo.Cursor{CreatedAt: createdAt, ID: 3, Backward: true}, prev)
+		next, err := articleRepo.DecodeCursor(page.Next)
+		require.NoError(t, err)
+		assert.Equal(t, articleRepo.Cursor{CreatedAt: createdAt, ID: 4}, next)
+	})
+}
+
+func TestFetchLegacyCursor(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, d dialect, repo article.Repository, mock sqlmock.Sqlmock) {
+		cursor := base64.StdEncoding.EncodeToString([]byte("2018-05-12T10:00:00Z"))
+		mock.ExpectQuery(`created_at > `+ph+` OR \(created_at = `+ph+` AND id > `+ph+`\)`).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), int64(1<<63-1)).
+			WillReturnRows(sqlmock.NewRows(articleColumns))
+
+		_, _, err := repo.Fetch(context.TODO(), cursor, 2)
+
+		assert.Equal(t, models.ErrNotFound, err)
+	})
+}
+
+func TestFetchInvalidCursor(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, d dialect, repo article.Repository, mock sqlmock.Sqlmock) {
+		for _, cursor := range []string{"not a cursor", "v9.e30", "v1.!!!"} {
+			_, _, err := repo.Fetch(context.TODO(), cursor, 2)
+			assert.Equal(t, models.ErrBadParamInput, err, cursor)
+		}
+	})
+}
+
+func TestGetByIDNotFound(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, d dialect, repo article.Repository, mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(`SELECT \* FROM .articles.`).
+			WillReturnRows(sqlmock.NewRows(articleColumns))
+
+		_, err := repo.GetByID(context.TODO(), 1)
+
+		assert.Equal(t, models.ErrNotFound, err)
+	})
+}
+
+func TestGetByTitle(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, d dialect, repo article.Repository, mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(`\(\(` + d.titleEquals + `\)\)`).
+			WithArgs("HELLO").
+			WillReturnRows(articleRows(time.Now(), 1))
+
+		res, err := repo.GetByTitle(context.TODO(), "HELLO")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), res.ID)
+	})
+}
+
+func TestStoreUniqueViolation(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, d dialect, repo article.Repository, mock sqlmock.Sqlmock) {
+		d.expectInsert(mock, 0, d.uniqueErr)
+
+		err := repo.Store(context.TODO(), &models.Article{Title: "title", Content: "content"})
+
+		assert.Equal(t, models.ErrConflict, err)
+	})
+}
+
+func TestUpdateUniqueViolation(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, d dialect, repo article.Repository, mock sqlmock.Sqlmock) {
+		mock.ExpectExec(`UPDATE .articles. SET`).WillReturnError(d.uniqueErr)
+
+		err := repo.Update(context.TODO(), &models.Article{ID: 1, Title: "title", Content: "content"})
+
+		assert.Equal(t, models.ErrConflict, err)
+	})
+}
+
+func TestUpdateAuthor(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, d dialect, repo article.Repository, mock sqlmock.Sqlmock) {
+		mock.ExpectExec(`UPDATE .articles. SET .author_id. = `+ph+` WHERE .articles.\..deleted_at. IS NULL AND .articles.\..id. = `+ph).
+			WithArgs(2, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE .articles. SET .author_id.`).
+			WithArgs(2, 3).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.NoError(t, repo.UpdateAuthor(context.TODO(), 1, 2))
+		assert.Equal(t, models.ErrNotFound, repo.UpdateAuthor(context.TODO(), 3, 2))
+	})
+}
+
+func TestGetByIDSlowQueryIsInterrupted(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, d dialect, repo article.Repository, mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(`SELECT \* FROM .articles.`).
+			WillDelayFor(5 * time.Second).
+			WillReturnRows(articleRows(time.Now(), 1))
+
+		ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := repo.GetByID(ctx, 1)
+
+		assert.Equal(t, models.ErrTimeout, err)
+		assert.True(t, time.Since(start) < time.Second, "query was not interrupted")
+	})
+}
+
+func TestStoreCancelled(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, d dialect, repo article.Repository, mock sqlmock.Sqlmock) {
+		d.expectInsert(mock, 5*time.Second, nil)
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		start := time.Now()
+		err := repo.Store(ctx, &models.Article{Title: "title", Content: "content"})
+
+		assert.Equal(t, models.ErrCanceled, err)
+		assert.True(t, time.Since(start) < time.Second, "query was not interrupted")
+	})
+}
@@ -0,0 +1,240 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/article"
+	articleRepo "github.com/naveenpatilm/go-clean-arch/article/repository"
+	"github.com/naveenpatilm/go-clean-arch/models"
+	_gormMock "github.com/naveenpatilm/go-clean-arch/models/mocks"
+)
+
+// forEachDialectOnMock runs test against the repository of every dialect on a
+// Gormw mock. Every chained call returns the same mock, so each test only
+// registers the calls it expects.
+func forEachDialectOnMock(t *testing.T, test func(t *testing.T, d dialect, db *_gormMock.Gormw, repo article.Repository)) {
+	for _, d := range dialects {
+		d := d
+		t.Run(d.name, func(t *testing.T) {
+			db := new(_gormMock.Gormw)
+			db.On("WithContext", mock.Anything).Return(db)
+
+			test(t, d, db, d.newRepo(db))
+			db.AssertExpectations(t)
+		})
+	}
+}
+
+func fillArticles(articles ...*models.Article) func(args mock.Arguments) {
+	return func(args mock.Arguments) {
+		*args.Get(0).(*[]*models.Article) = articles
+	}
+}
+
+func TestGormwFetchFirstPage(t *testing.T) {
+	forEachDialectOnMock(t, func(t *testing.T, d dialect, db *_gormMock.Gormw, repo article.Repository) {
+		createdAt := time.Date(2018, 5, 12, 10, 0, 0, 0, time.UTC)
+		db.On("Limit", 3).Return(db).Once()
+		db.On("Order", "created_at, id").Return(db).Once()
+		db.On("Find", mock.AnythingOfType("*[]*models.Article")).
+			Run(fillArticles(&models.Article{ID: 1, CreatedAt: createdAt}, &models.Article{ID: 2, CreatedAt: createdAt}, &models.Article{ID: 3, CreatedAt: createdAt})).
+			Return(db).Once()
+		db.On("Error").Return(nil).Once()
+
+		list, page, err := repo.Fetch(context.TODO(), "", 2)
+
+		require.NoError(t, err)
+		assert.Len(t, list, 2)
+		assert.Empty(t, page.Prev)
+		next, err := articleRepo.DecodeCursor(page.Next)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), next.ID)
+	})
+}
+
+func TestGormwFetchBackward(t *testing.T) {
+	forEachDialectOnMock(t, func(t *testing.T, d dialect, db *_gormMock.Gormw, repo article.Repository) {
+		createdAt := time.Date(2018, 5, 12, 10, 0, 0, 0, time.UTC)
+		cursor := articleRepo.EncodeCursor(articleRepo.Cursor{CreatedAt: createdAt, ID: 5, Backward: true})
+		db.On("Limit", 3).Return(db).Once()
+		db.On("Where", "created_at < ? OR (created_at = ? AND id < ?)", createdAt, createdAt, int64(5)).Return(db).Once()
+		db.On("Order", "created_at DESC, id DESC").Return(db).Once()
+		db.On("Find", mock.AnythingOfType("*[]*models.Article")).
+			Run(fillArticles(&models.Article{ID: 4, CreatedAt: createdAt}, &models.Article{ID: 3, CreatedAt: createdAt})).
+			Return(db).Once()
+		db.On("Error").Return(nil).Once()
+
+		list, _, err := repo.Fetch(context.TODO(), cursor, 2)
+
+		require.NoError(t, err)
+		require.Len(t, list, 2)
+		assert.Equal(t, int64(3), list[0].ID)
+		assert.Equal(t, int64(4), list[1].ID)
+	})
+}
+
+func TestGormwFetchErrors(t *testing.T) {
+	t.Run("invalid-cursor", func(t *testing.T) {
+		forEachDialectOnMock(t, func(t *testing.T, d dialect, db *_gormMock.Gormw, repo article.Repository) {
+			db.On("Limit", 3).Return(db).Once()
+
+			_, _, err := repo.Fetch(context.TODO(), "v1.???", 2)
+
+			assert.Equal(t, models.ErrBadParamInput, err)
+		})
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		forEachDialectOnMock(t, func(t *testing.T, d dialect, db *_gormMock.Gormw, repo article.Repository) {
+			db.On("Limit", 3).Return(db).Once()
+			db.On("Order", "created_at, id").Return(db).Once()
+			db.On("Find", mock.AnythingOfType("*[]*models.Article")).Return(db).Once()
+			db.On("Error").Return(nil).Once()
+
+			_, _, err := repo.Fetch(context.TODO(), "", 2)
+
+			assert.Equal(t, models.ErrNotFound, err)
+		})
+	})
+
+	t.Run("db-error", func(t *testing.T) {
+		forEachDialectOnMock(t, func(t *testing.T, d dialect, db *_gormMock.Gormw, repo article.Repository) {
+			db.On("Limit", 3).Return(db).Once()
+			db.On("Order", "created_at, id").Return(db).Once()
+			db.On("Find", mock.AnythingOfType("*[]*models.Article")).Return(db).Once()
+			db.On("Error").Return(errors.New("connection reset")).Once()
+
+			_, _, err := repo.Fetch(context.TODO(), "", 2)
+
+			assert.EqualError(t, err, "connection reset")
+		})
+	})
+}
+
+func TestGormwGetByID(t *testing.T) {
+	forEachDialectOnMock(t, func(t *testing.T, d dialect, db *_gormMock.Gormw, repo article.Repository) {
+		db.On("First", mock.AnythingOfType("*models.Article"), int64(1)).
+			Run(func(args mock.Arguments) {
+				*args.Get(0).(*models.Article) = models.Article{ID: 1, Title: "Hello"}
+			}).
+			Return(db).Once()
+		db.On("Error").Return(nil).Once()
+
+		res, err := repo.GetByID(context.TODO(), 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Hello", res.Title)
+	})
+}
+
+func TestGormwGetByIDNotFound(t *testing.T) {
+	forEachDialectOnMock(t, func(t *testing.T, d dialect, db *_gormMock.Gormw, repo article.Repository) {
+		db.On("First", mock.AnythingOfType("*models.Article"), int64(1)).Return(db).Once()
+		db.On("Error").Return(gorm.ErrRecordNotFound).Once()
+
+		res, err := repo.GetByID(context.TODO(), 1)
+
+		assert.Nil(t, res)
+		assert.Equal(t, models.ErrNotFound, err)
+	})
+}
+
+func TestGormwGetByTitle(t *testing.T) {
+	forEachDialectOnMock(t, func(t *testing.T, d dialect, db *_gormMock.Gormw, repo article.Repository) {
+		db.On("Where", d.titleWhere, "Hello").Return(db).Once()
+		db.On("First", mock.AnythingOfType("*models.Article")).Return(db).Once()
+		db.On("Error").Return(gorm.ErrRecordNotFound).Once()
+
+		_, err := repo.GetByTitle(context.TODO(), "Hello")
+
+		assert.Equal(t, models.ErrNotFound, err)
+	})
+}
+
+func TestGormwStore(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		forEachDialectOnMock(t, func(t *testing.T, d dialect, db *_gormMock.Gormw, repo article.Repository) {
+			a := &models.Article{Title: "Hello"}
+			db.On("Create", a).Return(db).Once()
+			db.On("Error").Return(nil).Once()
+
+			assert.NoError(t, repo.Store(context.TODO(), a))
+		})
+	})
+
+	t.Run("unique-violation", func(t *testing.T) {
+		forEachDialectOnMock(t, func(t *testing.T, d dialect, db *_gormMock.Gormw, repo article.Repository) {
+			a := &models.Article{Title: "Hello"}
+			db.On("Create", a).Return(db).Once()
+			db.On("Error").Return(d.uniqueErr).Once()
+
+			assert.Equal(t, models.ErrConflict, repo.Store(context.TODO(), a))
+		})
+	})
+}
+
+func TestGormwDelete(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		forEachDialectOnMock(t, func(t *testing.T, d dialect, db *_gormMock.Gormw, repo article.Repository) {
+			db.On("Where", "id = ?", int64(1)).Return(db).Once()
+			db.On("Delete", models.Article{}).Return(db).Once()
+			db.On("Error").Return(nil).Once()
+			db.On("RowsAffected").Return(int64(1)).Once()
+
+			assert.NoError(t, repo.Delete(context.TODO(), 1))
+		})
+	})
+
+	t.Run("nothing-deleted", func(t *testing.T) {
+		forEachDialectOnMock(t, func(t *testing.T, d dialect, db *_gormMock.Gormw, repo article.Repository) {
+			db.On("Where", "id = ?", int64(1)).Return(db).Once()
+			db.On("Delete", models.Article{}).Return(db).Once()
+			db.On("Error").Return(nil).Once()
+			db.On("RowsAffected").Return(int64(0)).Once()
+
+			assert.EqualError(t, repo.Delete(context.TODO(), 1), "Weird  Behaviour. Total Affected: 0")
+		})
+	})
+}
+
+func TestGormwUpdate(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		forEachDialectOnMock(t, func(t *testing.T, d dialect, db *_gormMock.Gormw, repo article.Repository) {
+			a := &models.Article{ID: 1, Title: "Hello"}
+			db.On("Save", a).Return(db).Once()
+			db.On("Error").Return(nil).Once()
+			db.On("RowsAffected").Return(int64(1)).Once()
+
+			assert.NoError(t, repo.Update(context.TODO(), a))
+		})
+	})
+
+	t.Run("unique-violation", func(t *testing.T) {
+		forEachDialectOnMock(t, func(t *testing.T, d dialect, db *_gormMock.Gormw, repo article.Repository) {
+			a := &models.Article{ID: 1, Title: "Hello"}
+			db.On("Save", a).Return(db).Once()
+			db.On("Error").Return(d.uniqueErr).Once()
+
+			assert.Equal(t, models.ErrConflict, repo.Update(context.TODO(), a))
+		})
+	})
+}
+
+func TestGormwUpdateAuthor(t *testing.T) {
+	forEachDialectOnMock(t, func(t *testing.T, d dialect, db *_gormMock.Gormw, repo article.Repository) {
+		db.On("Model", &models.Article{ID: 1}).Return(db).Once()
+		db.On("UpdateColumn", "author_id", int64(2)).Return(db).Once()
+		db.On("Error").Return(nil).Once()
+		db.On("RowsAffected").Return(int64(1)).Once()
+
+		assert.NoError(t, repo.UpdateAuthor(context.TODO(), 1, 2))
+	})
+}
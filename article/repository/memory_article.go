@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/naveenpatilm/go-clean-arch/article"
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/transaction"
+)
+
+type memoryArticleRepository struct {
+	mu       sync.RWMutex
+	lastID   int64
+	articles map[int64]models.Article // soft deleted articles are kept with DeletedAt set
+}
+
+// NewMemoryArticleRepository will create a thread-safe in-memory implementation of article.Repository.
+// It mirrors the postgres semantics: keyset pagination, case-insensitive unique titles and soft delete.
+func NewMemoryArticleRepository() article.Repository {
+
+	return &memoryArticleRepository{
+		articles: map[int64]models.Article{},
+	}
+}
+
+func (m *memoryArticleRepository) Fetch(ctx context.Context, cursor string, num int64) ([]*models.Article, models.Page, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, models.Page{}, models.ContextError(ctx, err)
+	}
+
+	var c *Cursor
+	if cursor != "" {
+		decodedCursor, err := DecodeCursor(cursor)
+		if err != nil {
+			return nil, models.Page{}, models.ErrBadParamInput
+		}
+		c = &decodedCursor
+	}
+
+	m.mu.RLock()
+	live := make([]*models.Article, 0, len(m.articles))
+	for _, a := range m.articles {
+		if a.DeletedAt != nil {
+			continue
+		}
+		a := a
+		live = append(live, &a)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(live, func(i, j int) bool {
+		return less(live[i].CreatedAt, live[i].ID, live[j].CreatedAt, live[j].ID)
+	})
+
+	var window []*models.Article
+	switch {
+	case c == nil:
+		window = live
+	case c.Backward:
+		end := sort.Search(len(live), func(i int) bool {
+			return !less(live[i].CreatedAt, live[i].ID, c.CreatedAt, c.ID)
+		})
+		window = live[:end]
+	default:
+		start := sort.Search(len(live), func(i int) bool {
+			return less(c.CreatedAt, c.ID, live[i].CreatedAt, live[i].ID)
+		})
+		window = live[start:]
+	}
+	if len(window) == 0 {
+		return nil, models.Page{}, models.ErrNotFound
+	}
+
+	hasMore := int64(len(window)) > num
+	if hasMore {
+		if c != nil && c.Backward {
+			window = window[int64(len(window))-num:]
+		} else {
+			window = window[:num]
+		}
+	}
+
+	return window, newPage(window, c, hasMore), nil
+}
+
+func (m *memoryArticleRepository) GetByID(ctx context.Context, id int64) (*models.Article, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, models.ContextError(ctx, err)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	a, ok := m.articles[id]
+	if !ok || a.DeletedAt != nil {
+		return nil, models.ErrNotFound
+	}
+	return &a, nil
+}
+
+func (m *memoryArticleRepository) GetByTitle(ctx context.Context, title string) (*models.Article, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, models.ContextError(ctx, err)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	a, ok := m.findByTitle(title)
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return &a, nil
+}
+
+func (m *memoryArticleRepository) Store(ctx context.Context, a *models.Article) error {
+	if err := ctx.Err(); err != nil {
+		return models.ContextError(ctx, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.findByTitle(a.Title); ok {
+		return models.ErrConflict
+	}
+
+	now := time.Now()
+	m.lastID++
+	a.ID = m.lastID
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = now
+	}
+	if a.UpdatedAt.IsZero() {
+		a.UpdatedAt = now
+	}
+	a.AuthorID = a.Author.ID
+	m.articles[a.ID] = stored(a)
+	id := a.ID
+	transaction.RecordUndo(ctx, func() { m.restore(id, nil) })
+	return nil
+}
+
+func (m *memoryArticleRepository) Delete(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return models.ContextError(ctx, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a, ok := m.articles[id]
+	if !ok || a.DeletedAt != nil {
+		return fmt.Errorf("Weird  Behaviour. Total Affected: %d", 0)
+	}
+	now := time.Now()
+	deleted := a
+	deleted.DeletedAt = &now
+	m.articles[id] = deleted
+	transaction.RecordUndo(ctx, func() { m.restore(id, &a) })
+	return nil
+}
+
+func (m *memoryArticleRepository) Update(ctx context.Context, ar *models.Article) error {
+	if err := ctx.Err(); err != nil {
+		return models.ContextError(ctx, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	existing, ok := m.articles[ar.ID]
+	if !ok || existing.DeletedAt != nil {
+		return fmt.Errorf("Weird  Behaviour. Total Affected: %d", 0)
+	}
+	if other, ok := m.findByTitle(ar.Title); ok && other.ID != ar.ID {
+		return models.ErrConflict
+	}
+
+	ar.UpdatedAt = time.Now()
+	ar.AuthorID = ar.Author.ID
+	m.articles[ar.ID] = stored(ar)
+	transaction.RecordUndo(ctx, func() { m.restore(existing.ID, &existing) })
+	return nil
+}
+
+func (m *memoryArticleRepository) UpdateAuthor(ctx context.Context, id, authorID int64) error {
+	if err := ctx.Err(); err != nil {
+		return models.ContextError(ctx, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	existing, ok := m.articles[id]
+	if !ok || existing.DeletedAt != nil {
+		return models.ErrNotFound
+	}
+	updated := existing
+	updated.AuthorID = authorID
+	updated.Author = models.Author{ID: authorID}
+	m.articles[id] = updated
+	transaction.RecordUndo(ctx, func() { m.restore(id, &existing) })
+	return nil
+}
+
+// restore puts back the article id as it was before a write undone by the
+// in-memory transaction manager, nil when it did not exist
+func (m *memoryArticleRepository) restore(id int64, a *models.Article) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if a == nil {
+		delete(m.articles, id)
+		return
+	}
+	m.articles[id] = *a
+}
+
+// findByTitle must be called with m.mu held
+func (m *memoryArticleRepository) findByTitle(title string) (models.Article, bool) {
+	title = strings.ToLower(title)
+	for _, a := range m.articles {
+		if a.DeletedAt == nil && strings.ToLower(a.Title) == title {
+			return a, true
+		}
+	}
+	return models.Article{}, false
+}
+
+// stored returns the copy of a kept by the repository. Like the database it
+// only remembers the author reference, not the author details.
+func stored(a *models.Article) models.Article {
+	s := *a
+	s.Author = models.Author{ID: a.AuthorID}
+	return s
+}
+
+// less orders articles by (created_at, id)
+func less(createdAtA time.Time, idA int64, createdAtB time.Time, idB int64) bool {
+	if createdAtA.Equal(createdAtB) {
+		return idA < idB
+	}
+	return createdAtA.Before(createdAtB)
+}
@@ -0,0 +1,153 @@
+package repository_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	articleRepo "github.com/naveenpatilm/go-clean-arch/article/repository"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+func TestMemoryStoreAndGet(t *testing.T) {
+	repo := articleRepo.NewMemoryArticleRepository()
+	a := &models.Article{Title: "Hello", Content: "Content", Author: models.Author{ID: 3, Name: "Iman"}}
+
+	require.NoError(t, repo.Store(context.TODO(), a))
+	assert.NotZero(t, a.ID)
+	assert.False(t, a.CreatedAt.IsZero())
+
+	byID, err := repo.GetByID(context.TODO(), a.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", byID.Title)
+	assert.Equal(t, models.Author{ID: 3}, byID.Author)
+
+	byTitle, err := repo.GetByTitle(context.TODO(), "HELLO")
+	require.NoError(t, err)
+	assert.Equal(t, a.ID, byTitle.ID)
+
+	// callers can not mutate the stored article
+	byID.Title = "Changed"
+	again, err := repo.GetByID(context.TODO(), a.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", again.Title)
+
+	_, err = repo.GetByID(context.TODO(), a.ID+1)
+	assert.Equal(t, models.ErrNotFound, err)
+	_, err = repo.GetByTitle(context.TODO(), "missing")
+	assert.Equal(t, models.ErrNotFound, err)
+}
+
+func TestMemoryTitleUniqueness(t *testing.T) {
+	repo := articleRepo.NewMemoryArticleRepository()
+	first := &models.Article{Title: "Hello", Content: "Content"}
+	require.NoError(t, repo.Store(context.TODO(), first))
+
+	err := repo.Store(context.TODO(), &models.Article{Title: "hello", Content: "Content"})
+	assert.Equal(t, models.ErrConflict, err)
+
+	second := &models.Article{Title: "World", Content: "Content"}
+	require.NoError(t, repo.Store(context.TODO(), second))
+	second.Title = "HELLO"
+	assert.Equal(t, models.ErrConflict, repo.Update(context.TODO(), second))
+
+	// a soft deleted title can be used again
+	require.NoError(t, repo.Delete(context.TODO(), first.ID))
+	assert.NoError(t, repo.Update(context.TODO(), second))
+}
+
+func TestMemoryStoreConcurrentSameTitle(t *testing.T) {
+	repo := articleRepo.NewMemoryArticleRepository()
+
+	var wg sync.WaitGroup
+	var winners int32
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if repo.Store(context.TODO(), &models.Article{Title: "Hello", Content: "Content"}) == nil {
+				atomic.AddInt32(&winners, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), winners)
+}
+
+func TestMemorySoftDelete(t *testing.T) {
+	repo := articleRepo.NewMemoryArticleRepository()
+	a := &models.Article{Title: "Hello", Content: "Content"}
+	require.NoError(t, repo.Store(context.TODO(), a))
+
+	require.NoError(t, repo.Delete(context.TODO(), a.ID))
+
+	_, err := repo.GetByID(context.TODO(), a.ID)
+	assert.Equal(t, models.ErrNotFound, err)
+	_, _, err = repo.Fetch(context.TODO(), "", 10)
+	assert.Equal(t, models.ErrNotFound, err)
+	assert.Error(t, repo.Delete(context.TODO(), a.ID))
+	assert.Error(t, repo.Update(context.TODO(), a))
+	assert.Equal(t, models.ErrNotFound, repo.UpdateAuthor(context.TODO(), a.ID, 2))
+}
+
+func TestMemoryFetchPages(t *testing.T) {
+	repo := articleRepo.NewMemoryArticleRepository()
+	createdAt := time.Date(2018, 5, 12, 10, 0, 0, 0, time.UTC)
+	// five articles sharing one timestamp must still be paged without gaps
+	for i := 0; i < 5; i++ {
+		a := &models.Article{Title: "Title " + strconv.Itoa(i), Content: "Content", CreatedAt: createdAt}
+		require.NoError(t, repo.Store(context.TODO(), a))
+	}
+
+	ids := func(list []*models.Article) []int64 {
+		var res []int64
+		for _, a := range list {
+			res = append(res, a.ID)
+		}
+		return res
+	}
+
+	list, page, err := repo.Fetch(context.TODO(), "", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, ids(list))
+	assert.Empty(t, page.Prev)
+
+	list, page, err = repo.Fetch(context.TODO(), page.Next, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3, 4}, ids(list))
+
+	list, last, err := repo.Fetch(context.TODO(), page.Next, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{5}, ids(list))
+	assert.Empty(t, last.Next)
+
+	list, page, err = repo.Fetch(context.TODO(), last.Prev, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3, 4}, ids(list))
+
+	list, page, err = repo.Fetch(context.TODO(), page.Prev, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, ids(list))
+	assert.Empty(t, page.Prev)
+	assert.NotEmpty(t, page.Next)
+
+	_, _, err = repo.Fetch(context.TODO(), "not a cursor", 2)
+	assert.Equal(t, models.ErrBadParamInput, err)
+}
+
+func TestMemoryContextDeadline(t *testing.T) {
+	repo := articleRepo.NewMemoryArticleRepository()
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	_, err := repo.GetByID(ctx, 1)
+	assert.Equal(t, models.ErrTimeout, err)
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/naveenpatilm/go-clean-arch/article"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+const (
+	mysqlDuplicateEntry = 1062 // mysql error number for ER_DUP_ENTRY
+)
+
+type mysqlDialect struct{}
+
+// NewMysqlArticleRepository will create an object that represent the article.Repository interface on mysql
+func NewMysqlArticleRepository(DB models.Gormw) article.Repository {
+
+	return &gormArticleRepository{DB: DB, dialect: mysqlDialect{}}
+}
+
+// titleEquals relies on the case-insensitive collation of the title column
+func (mysqlDialect) titleEquals() string {
+	return "title = ?"
+}
+
+func (mysqlDialect) isUniqueViolation(err error) bool {
+	myErr, ok := err.(*mysql.MySQLError)
+	return ok && myErr.Number == mysqlDuplicateEntry
+}
+
+func (mysqlDialect) beforeSave(a *models.Article) {}
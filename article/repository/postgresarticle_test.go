@@ -1 +0,0 @@
-package repository_test
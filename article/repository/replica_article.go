@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/naveenpatilm/go-clean-arch/article"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+type replicaArticleRepository struct {
+	article.Repository
+	replica article.Repository
+}
+
+// NewReplicaArticleRepository sends the reads of an article.Repository to replica and its writes to primary.
+// Reads whose context is marked with models.ReadYourWrites stay on primary.
+func NewReplicaArticleRepository(primary, replica article.Repository) article.Repository {
+
+	return &replicaArticleRepository{
+		Repository: primary,
+		replica:    replica,
+	}
+}
+
+func (m *replicaArticleRepository) reader(ctx context.Context) article.Repository {
+	if models.IsReadYourWrites(ctx) {
+		return m.Repository
+	}
+	return m.replica
+}
+
+func (m *replicaArticleRepository) Fetch(ctx context.Context, cursor string, num int64) ([]*models.Article, models.Page, error) {
+	return m.reader(ctx).Fetch(ctx, cursor, num)
+}
+
+func (m *replicaArticleRepository) GetByID(ctx context.Context, id int64) (*models.Article, error) {
+	return m.reader(ctx).GetByID(ctx, id)
+}
+
+func (m *replicaArticleRepository) GetByTitle(ctx context.Context, title string) (*models.Article, error) {
+	return m.reader(ctx).GetByTitle(ctx, title)
+}
@@ -0,0 +1,45 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/naveenpatilm/go-clean-arch/article/mocks"
+	articleRepo "github.com/naveenpatilm/go-clean-arch/article/repository"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+func TestReplicaRouting(t *testing.T) {
+	primary := new(mocks.Repository)
+	replica := new(mocks.Repository)
+	repo := articleRepo.NewReplicaArticleRepository(primary, replica)
+
+	replica.On("GetByID", mock.Anything, int64(1)).Return(&models.Article{ID: 1}, nil).Once()
+	replica.On("GetByTitle", mock.Anything, "Hello").Return(&models.Article{ID: 1}, nil).Once()
+	replica.On("Fetch", mock.Anything, "", int64(10)).Return(nil, models.Page{}, models.ErrNotFound).Once()
+	primary.On("GetByID", mock.Anything, int64(2)).Return(&models.Article{ID: 2}, nil).Once()
+	primary.On("Store", mock.Anything, mock.Anything).Return(nil).Once()
+	primary.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+	primary.On("Delete", mock.Anything, int64(1)).Return(nil).Once()
+
+	_, err := repo.GetByID(context.TODO(), 1)
+	assert.NoError(t, err)
+	_, err = repo.GetByTitle(context.TODO(), "Hello")
+	assert.NoError(t, err)
+	_, _, err = repo.Fetch(context.TODO(), "", 10)
+	assert.Equal(t, models.ErrNotFound, err)
+
+	// marked reads must see the writes
+	_, err = repo.GetByID(models.ReadYourWrites(context.TODO()), 2)
+	assert.NoError(t, err)
+
+	assert.NoError(t, repo.Store(context.TODO(), &models.Article{}))
+	assert.NoError(t, repo.Update(context.TODO(), &models.Article{}))
+	assert.NoError(t, repo.Delete(context.TODO(), 1))
+
+	primary.AssertExpectations(t)
+	replica.AssertExpectations(t)
+}
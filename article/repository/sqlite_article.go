@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/naveenpatilm/go-clean-arch/article"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+type sqliteDialect struct{}
+
+// NewSqliteArticleRepository will create an object that represent the article.Repository interface on sqlite
+func NewSqliteArticleRepository(DB models.Gormw) article.Repository {
+
+	return &gormArticleRepository{DB: DB, dialect: sqliteDialect{}}
+}
+
+// titleEquals uses the NOCASE collation of the unique index
+func (sqliteDialect) titleEquals() string {
+	return "title = ? COLLATE NOCASE"
+}
+
+func (sqliteDialect) isUniqueViolation(err error) bool {
+	sqliteErr, ok := err.(sqlite3.Error)
+	return ok && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+}
+
+// beforeSave stores created_at in UTC. Sqlite keeps times as text, so
+// keyset pagination only orders correctly when every row uses one offset.
+func (sqliteDialect) beforeSave(a *models.Article) {
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now()
+	}
+	a.CreatedAt = a.CreatedAt.UTC()
+}
@@ -0,0 +1,163 @@
+package repository_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/article"
+	articleRepo "github.com/naveenpatilm/go-clean-arch/article/repository"
+	"github.com/naveenpatilm/go-clean-arch/database"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+func newSqliteRepo(t *testing.T) (article.Repository, func()) {
+	dir, err := ioutil.TempDir("", "articles")
+	require.NoError(t, err)
+	db, err := database.Open(database.Config{Driver: database.Sqlite, Path: filepath.Join(dir, "article.db")})
+	require.NoError(t, err)
+	migrator, err := database.NewMigrator(db.DB(), database.Sqlite)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Up(context.TODO()))
+	cleanup := func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+
+	var mode string
+	require.NoError(t, db.DB().QueryRow("PRAGMA journal_mode").Scan(&mode))
+	require.Equal(t, "wal", mode)
+	return articleRepo.NewSqliteArticleRepository(db), cleanup
+}
+
+func TestSqliteStoreAndGet(t *testing.T) {
+	repo, cleanup := newSqliteRepo(t)
+	defer cleanup()
+	a := &models.Article{Title: "Hello", Content: "Content", Author: models.Author{ID: 3}}
+
+	require.NoError(t, repo.Store(context.TODO(), a))
+	assert.NotZero(t, a.ID)
+
+	byID, err := repo.GetByID(context.TODO(), a.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", byID.Title)
+	assert.Equal(t, int64(3), byID.Author.ID)
+
+	byTitle, err := repo.GetByTitle(context.TODO(), "HELLO")
+	require.NoError(t, err)
+	assert.Equal(t, a.ID, byTitle.ID)
+
+	_, err = repo.GetByID(context.TODO(), a.ID+1)
+	assert.Equal(t, models.ErrNotFound, err)
+}
+
+func TestSqliteUpdateAuthor(t *testing.T) {
+	repo, cleanup := newSqliteRepo(t)
+	defer cleanup()
+	a := &models.Article{Title: "Hello", Content: "Content", Author: models.Author{ID: 3}}
+	require.NoError(t, repo.Store(context.TODO(), a))
+	// a concurrent change of the content is not overwritten
+	a.Content = "Edited"
+	require.NoError(t, repo.Update(context.TODO(), a))
+
+	require.NoError(t, repo.UpdateAuthor(context.TODO(), a.ID, 4))
+
+	res, err := repo.GetByID(context.TODO(), a.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), res.Author.ID)
+	assert.Equal(t, "Edited", res.Content)
+
+	require.NoError(t, repo.Delete(context.TODO(), a.ID))
+	assert.Equal(t, models.ErrNotFound, repo.UpdateAuthor(context.TODO(), a.ID, 3))
+}
+
+func TestSqliteTitleUniqueness(t *testing.T) {
+	repo, cleanup := newSqliteRepo(t)
+	defer cleanup()
+	first := &models.Article{Title: "Hello", Content: "Content"}
+	require.NoError(t, repo.Store(context.TODO(), first))
+
+	err := repo.Store(context.TODO(), &models.Article{Title: "hello", Content: "Content"})
+	assert.Equal(t, models.ErrConflict, err)
+
+	second := &models.Article{Title: "World", Content: "Content"}
+	require.NoError(t, repo.Store(context.TODO(), second))
+	second.Title = "HELLO"
+	assert.Equal(t, models.ErrConflict, repo.Update(context.TODO(), second))
+
+	// a soft deleted title can be used again
+	require.NoError(t, repo.Delete(context.TODO(), first.ID))
+	_, err = repo.GetByID(context.TODO(), first.ID)
+	assert.Equal(t, models.ErrNotFound, err)
+	assert.NoError(t, repo.Update(context.TODO(), second))
+}
+
+func TestSqliteStoreConcurrentSameTitle(t *testing.T) {
+	repo, cleanup := newSqliteRepo(t)
+	defer cleanup()
+
+	var wg sync.WaitGroup
+	var winners, conflicts int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			switch repo.Store(context.TODO(), &models.Article{Title: "Hello", Content: "Content"}) {
+			case nil:
+				atomic.AddInt32(&winners, 1)
+			case models.ErrConflict:
+				atomic.AddInt32(&conflicts, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), winners)
+	assert.Equal(t, int32(19), conflicts)
+}
+
+func TestSqliteFetchPages(t *testing.T) {
+	repo, cleanup := newSqliteRepo(t)
+	defer cleanup()
+	// the same instant in different zones must sort as one timestamp
+	createdAt := time.Date(2018, 5, 12, 10, 0, 0, 0, time.UTC)
+	zones := []*time.Location{time.UTC, time.FixedZone("WIB", 7*3600), time.FixedZone("EST", -5*3600)}
+	for i := 0; i < 5; i++ {
+		a := &models.Article{Title: "Title " + strconv.Itoa(i), Content: "Content", CreatedAt: createdAt.In(zones[i%len(zones)])}
+		require.NoError(t, repo.Store(context.TODO(), a))
+	}
+
+	ids := func(list []*models.Article) []int64 {
+		var res []int64
+		for _, a := range list {
+			res = append(res, a.ID)
+		}
+		return res
+	}
+
+	list, page, err := repo.Fetch(context.TODO(), "", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, ids(list))
+
+	list, page, err = repo.Fetch(context.TODO(), page.Next, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3, 4}, ids(list))
+
+	list, last, err := repo.Fetch(context.TODO(), page.Next, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{5}, ids(list))
+	assert.Empty(t, last.Next)
+
+	list, _, err = repo.Fetch(context.TODO(), last.Prev, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3, 4}, ids(list))
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/naveenpatilm/go-clean-arch/article"
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/tracing"
+)
+
+type tracingArticleRepository struct {
+	next article.Repository
+}
+
+// NewTracingArticleRepository wraps every call to an article.Repository in a span.
+// The statements run by a gorm repository are traced as its children.
+func NewTracingArticleRepository(next article.Repository) article.Repository {
+	return &tracingArticleRepository{next: next}
+}
+
+func (m *tracingArticleRepository) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, "ArticleRepository."+method, trace.WithAttributes(attrs...))
+}
+
+// end records err on span, except ErrNotFound which is an expected answer
+func (m *tracingArticleRepository) end(span trace.Span, err error) {
+	if err == models.ErrNotFound {
+		span.SetAttributes(attribute.Bool("article.not_found", true))
+		err = nil
+	}
+	tracing.End(span, err)
+}
+
+func (m *tracingArticleRepository) Fetch(ctx context.Context, cursor string, num int64) (res []*models.Article, page models.Page, err error) {
+	ctx, span := m.start(ctx, "Fetch", attribute.String("article.cursor", cursor), attribute.Int64("article.num", num))
+	defer func() {
+		span.SetAttributes(attribute.Int("article.count", len(res)))
+		m.end(span, err)
+	}()
+	return m.next.Fetch(ctx, cursor, num)
+}
+
+func (m *tracingArticleRepository) GetByID(ctx context.Context, id int64) (res *models.Article, err error) {
+	ctx, span := m.start(ctx, "GetByID", attribute.Int64("article.id", id))
+	defer func() { m.end(span, err) }()
+	return m.next.GetByID(ctx, id)
+}
+
+func (m *tracingArticleRepository) GetByTitle(ctx context.Context, title string) (res *models.Article, err error) {
+	ctx, span := m.start(ctx, "GetByTitle", attribute.String("article.title", title))
+	defer func() { m.end(span, err) }()
+	return m.next.GetByTitle(ctx, title)
+}
+
+func (m *tracingArticleRepository) Update(ctx context.Context, ar *models.Article) (err error) {
+	ctx, span := m.start(ctx, "Update", attribute.Int64("article.id", ar.ID))
+	defer func() { m.end(span, err) }()
+	return m.next.Update(ctx, ar)
+}
+
+func (m *tracingArticleRepository) UpdateAuthor(ctx context.Context, id, authorID int64) (err error) {
+	ctx, span := m.start(ctx, "UpdateAuthor", attribute.Int64("article.id", id), attribute.Int64("article.author_id", authorID))
+	defer func() { m.end(span, err) }()
+	return m.next.UpdateAuthor(ctx, id, authorID)
+}
+
+func (m *tracingArticleRepository) Store(ctx context.Context, a *models.Article) (err error) {
+	ctx, span := m.start(ctx, "Store")
+	defer func() {
+		span.SetAttributes(attribute.Int64("article.id", a.ID))
+		m.end(span, err)
+	}()
+	return m.next.Store(ctx, a)
+}
+
+func (m *tracingArticleRepository) Delete(ctx context.Context, id int64) (err error) {
+	ctx, span := m.start(ctx, "Delete", attribute.Int64("article.id", id))
+	defer func() { m.end(span, err) }()
+	return m.next.Delete(ctx, id)
+}
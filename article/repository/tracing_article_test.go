@@ -0,0 +1,79 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/naveenpatilm/go-clean-arch/article"
+	articleRepo "github.com/naveenpatilm/go-clean-arch/article/repository"
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/tracing"
+)
+
+// recordSpans installs a tracer provider keeping every ended span
+func recordSpans() (*tracetest.SpanRecorder, func()) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	return recorder, func() { otel.SetTracerProvider(previous) }
+}
+
+func attributeOf(s sdktrace.ReadOnlySpan, key string) attribute.Value {
+	for _, kv := range s.Attributes() {
+		if string(kv.Key) == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTracingRecordsRepositoryAndSQLSpans(t *testing.T) {
+	forEachDialectWithHook(t, tracing.SQLStatement, func(t *testing.T, d dialect, repo article.Repository, mock sqlmock.Sqlmock) {
+		recorder, restore := recordSpans()
+		defer restore()
+		mock.ExpectQuery(`SELECT \* FROM .articles.`).
+			WillReturnRows(sqlmock.NewRows(articleColumns))
+
+		_, err := articleRepo.NewTracingArticleRepository(repo).GetByID(context.TODO(), 1)
+		assert.Equal(t, models.ErrNotFound, err)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 2)
+		statement, method := spans[0], spans[1]
+
+		assert.Equal(t, "ArticleRepository.GetByID", method.Name())
+		assert.Equal(t, int64(1), attributeOf(method, "article.id").AsInt64())
+		assert.Equal(t, codes.Unset, method.Status().Code, "not found is not an error")
+
+		assert.Equal(t, "SELECT", statement.Name())
+		assert.Equal(t, method.SpanContext().SpanID(), statement.Parent().SpanID())
+		assert.Contains(t, attributeOf(statement, "db.query.text").AsString(), "FROM")
+		assert.NotEmpty(t, attributeOf(statement, "db.system").AsString())
+	})
+}
+
+func TestTracingRecordsErrors(t *testing.T) {
+	forEachDialectWithHook(t, tracing.SQLStatement, func(t *testing.T, d dialect, repo article.Repository, mock sqlmock.Sqlmock) {
+		recorder, restore := recordSpans()
+		defer restore()
+		d.expectInsert(mock, 0, d.uniqueErr)
+
+		err := articleRepo.NewTracingArticleRepository(repo).Store(context.TODO(), &models.Article{Title: "Hello"})
+		assert.Equal(t, models.ErrConflict, err)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 2)
+		assert.Equal(t, "INSERT", spans[0].Name())
+		assert.Equal(t, "ArticleRepository.Store", spans[1].Name())
+		assert.Equal(t, codes.Error, spans[1].Status().Code)
+	})
+}
@@ -8,10 +8,12 @@ import (
 
 // Usecase represent the article's usecases
 type Usecase interface {
-	Fetch(ctx context.Context, cursor string, num int64) ([]*models.Article, error)
+	Fetch(ctx context.Context, cursor string, num int64) ([]*models.Article, models.Page, error)
 	GetByID(ctx context.Context, id int64) (*models.Article, error)
 	Update(ctx context.Context, ar *models.Article) error
 	GetByTitle(ctx context.Context, title string) (*models.Article, error)
 	Store(context.Context, *models.Article) error
 	Delete(ctx context.Context, id int64) error
+	// Reassign gives the article id to the author authorID
+	Reassign(ctx context.Context, id, authorID int64) (*models.Article, error)
 }
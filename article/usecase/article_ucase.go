@@ -8,96 +8,96 @@ import (
 
 	"github.com/naveenpatilm/go-clean-arch/article"
 	"github.com/naveenpatilm/go-clean-arch/author"
-	"golang.org/x/sync/errgroup"
+	"github.com/naveenpatilm/go-clean-arch/event"
+	"github.com/naveenpatilm/go-clean-arch/transaction"
+	"github.com/sirupsen/logrus"
 )
 
 type articleUsecase struct {
 	articleRepo    article.Repository
 	authorRepo     author.Repository
+	txManager      transaction.Manager
+	publisher      event.Publisher
 	contextTimeout time.Duration
+	maxPageSize    int64
 }
 
-// NewArticleUsecase will create new an articleUsecase object representation of article.Usecase interface
-func NewArticleUsecase(a article.Repository, ar author.Repository, timeout time.Duration) article.Usecase {
+const defaultPageSize = 10
+
+// NewArticleUsecase will create new an articleUsecase object representation of article.Usecase interface.
+// Fetch never returns more than maxPageSize articles per page.
+// Store, Update and Delete save their models.Event in the outbox, in the transaction
+// of the change, and emit it through p once the change is committed.
+func NewArticleUsecase(a article.Repository, ar author.Repository, tm transaction.Manager, p event.Publisher, timeout time.Duration, maxPageSize int64) article.Usecase {
+	if maxPageSize <= 0 {
+		maxPageSize = defaultPageSize
+	}
 	return &articleUsecase{
 		articleRepo:    a,
 		authorRepo:     ar,
+		txManager:      tm,
+		publisher:      p,
 		contextTimeout: timeout,
+		maxPageSize:    maxPageSize,
 	}
 }
 
-/*
-* In this function below, I'm using errgroup with the pipeline pattern
-* Look how this works in this package explanation
-* in godoc: https://godoc.org/golang.org/x/sync/errgroup#ex-Group--Pipeline
- */
-func (a *articleUsecase) fillAuthorDetails(c context.Context, data []*models.Article) ([]*models.Article, error) {
-
-	g, ctx := errgroup.WithContext(c)
-
-	// Get the author's id
-	mapAuthors := map[int64]models.Author{}
-
-	for _, article := range data {
-		mapAuthors[article.Author.ID] = models.Author{}
-	}
-	// Using goroutine to fetch the author's detail
-	chanAuthor := make(chan *models.Author)
-	for authorID, _ := range mapAuthors {
-		authorID := authorID
-		g.Go(func() error {
-			res, err := a.authorRepo.GetByID(ctx, authorID)
-			if err != nil {
-				return err
-			}
-			chanAuthor <- res
-			return nil
-		})
-	}
-
-	go func() {
-		g.Wait()
-		close(chanAuthor)
-	}()
-
-	for author := range chanAuthor {
-		if author != nil {
-			mapAuthors[author.ID] = *author
+// fillAuthorDetails loads the authors of data with one GetByIDs call.
+// An article whose author no longer exists keeps an Author holding only the ID.
+func (a *articleUsecase) fillAuthorDetails(ctx context.Context, data []*models.Article) ([]*models.Article, error) {
+	ids := make([]int64, 0, len(data))
+	seen := map[int64]bool{}
+	for _, item := range data {
+		if !seen[item.Author.ID] {
+			seen[item.Author.ID] = true
+			ids = append(ids, item.Author.ID)
 		}
 	}
 
-	if err := g.Wait(); err != nil {
+	authors, err := a.authorRepo.GetByIDs(ctx, ids)
+	if err != nil {
 		return nil, err
 	}
+	mapAuthors := make(map[int64]models.Author, len(authors))
+	for _, author := range authors {
+		mapAuthors[author.ID] = *author
+	}
 
 	// merge the author's data
 	for index, item := range data {
-		if a, ok := mapAuthors[item.Author.ID]; ok {
-			data[index].Author = a
+		author, ok := mapAuthors[item.Author.ID]
+		if !ok {
+			logrus.Warnf("author %d of article %d not found", item.Author.ID, item.ID)
+			data[index].Author = models.Author{ID: item.Author.ID}
+			continue
 		}
+		data[index].Author = author
 	}
 	return data, nil
 }
 
-func (a *articleUsecase) Fetch(c context.Context, cursor string, num int64) ([]*models.Article, error) {
-	if num == 0 {
-		num = 10
+func (a *articleUsecase) Fetch(c context.Context, cursor string, num int64) ([]*models.Article, models.Page, error) {
+	if num <= 0 {
+		num = defaultPageSize
+	}
+	if num > a.maxPageSize {
+		num = a.maxPageSize
 	}
 
 	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
 	defer cancel()
 
-	listArticle, err := a.articleRepo.Fetch(ctx, cursor, num)
+	listArticle, page, err := a.articleRepo.Fetch(ctx, cursor, num)
 	if err != nil {
-		return nil, err
+		return nil, models.Page{}, err
 	}
 
 	listArticle, err = a.fillAuthorDetails(ctx, listArticle)
 	if err != nil {
-		return nil, err
+		return nil, models.Page{}, err
 	}
 
-	return listArticle, nil
+	return listArticle, page, nil
 }
 
 func (a *articleUsecase) GetByID(c context.Context, id int64) (*models.Article, error) {
@@ -110,11 +110,9 @@ func (a *articleUsecase) GetByID(c context.Context, id int64) (*models.Article,
 		return nil, err
 	}
 
-	resAuthor, err := a.authorRepo.GetByID(ctx, res.Author.ID)
-	if err != nil {
+	if _, err = a.fillAuthorDetails(ctx, []*models.Article{res}); err != nil {
 		return nil, err
 	}
-	res.Author = *resAuthor
 	return res, nil
 }
 
@@ -124,7 +122,18 @@ func (a *articleUsecase) Update(c context.Context, ar *models.Article) error {
 	defer cancel()
 
 	ar.UpdatedAt = time.Now()
-	return a.articleRepo.Update(ctx, ar)
+	e := models.ArticleUpdated{Article: *ar, OccurredAt: ar.UpdatedAt}
+	err := a.txManager.Do(ctx, func(ctx context.Context, repos transaction.Repositories) error {
+		if err := repos.Article.Update(ctx, ar); err != nil {
+			return err
+		}
+		return repos.Outbox.Store(ctx, e)
+	})
+	if err != nil {
+		return err
+	}
+	a.publish(ctx, e)
+	return nil
 }
 
 func (a *articleUsecase) GetByTitle(c context.Context, title string) (*models.Article, error) {
@@ -136,12 +145,9 @@ func (a *articleUsecase) GetByTitle(c context.Context, title string) (*models.Ar
 		return nil, err
 	}
 
-	resAuthor, err := a.authorRepo.GetByID(ctx, res.Author.ID)
-	if err != nil {
+	if _, err = a.fillAuthorDetails(ctx, []*models.Article{res}); err != nil {
 		return nil, err
 	}
-	res.Author = *resAuthor
-
 	return res, nil
 }
 
@@ -149,27 +155,95 @@ func (a *articleUsecase) Store(c context.Context, m *models.Article) error {
 
 	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
 	defer cancel()
-	existedArticle, _ := a.GetByTitle(ctx, m.Title)
-	if existedArticle != nil {
-		return models.ErrConflict
-	}
 
-	err := a.articleRepo.Store(ctx, m)
+	// Title uniqueness is enforced by the repository, which reports a
+	// duplicate as models.ErrConflict. Checking GetByTitle first would race
+	// with concurrent creates.
+	var e models.Event
+	err := a.txManager.Do(ctx, func(ctx context.Context, repos transaction.Repositories) error {
+		if m.Author.ID == 0 && m.Author.Name != "" {
+			// a new author is created together with their first article
+			if err := repos.Author.Store(ctx, &m.Author); err != nil {
+				return err
+			}
+		}
+		if err := repos.Article.Store(ctx, m); err != nil {
+			return err
+		}
+		e = models.ArticleCreated{Article: *m, OccurredAt: time.Now()}
+		return repos.Outbox.Store(ctx, e)
+	})
 	if err != nil {
 		return err
 	}
+	a.publish(ctx, e)
 	return nil
 }
 
 func (a *articleUsecase) Delete(c context.Context, id int64) error {
 	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
 	defer cancel()
-	existedArticle, err := a.articleRepo.GetByID(ctx, id)
+	// a lagging replica could miss an article that was just created
+	existedArticle, err := a.articleRepo.GetByID(models.ReadYourWrites(ctx), id)
 	if err != nil {
 		return err
 	}
 	if existedArticle == nil {
 		return models.ErrNotFound
 	}
-	return a.articleRepo.Delete(ctx, id)
+	e := models.ArticleDeleted{Article: *existedArticle, OccurredAt: time.Now()}
+	err = a.txManager.Do(ctx, func(ctx context.Context, repos transaction.Repositories) error {
+		if err := repos.Article.Delete(ctx, id); err != nil {
+			return err
+		}
+		return repos.Outbox.Store(ctx, e)
+	})
+	if err != nil {
+		return err
+	}
+	a.publish(ctx, e)
+	return nil
+}
+
+func (a *articleUsecase) Reassign(c context.Context, id, authorID int64) (*models.Article, error) {
+	ctx, cancel := context.WithTimeout(c, a.contextTimeout)
+	defer cancel()
+	// a lagging replica could miss an article or an author that was just created
+	ar, err := a.articleRepo.GetByID(models.ReadYourWrites(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	author, err := a.authorRepo.GetByID(models.ReadYourWrites(ctx), authorID)
+	if err != nil {
+		return nil, err
+	}
+	if ar.AuthorID == author.ID {
+		ar.Author = *author
+		return ar, nil
+	}
+
+	// only author_id is written, so the changes made to the article since it
+	// was read are kept
+	ar.AuthorID = author.ID
+	ar.Author = *author
+	e := models.ArticleUpdated{Article: *ar, OccurredAt: time.Now()}
+	err = a.txManager.Do(ctx, func(ctx context.Context, repos transaction.Repositories) error {
+		if err := repos.Article.UpdateAuthor(ctx, id, author.ID); err != nil {
+			return err
+		}
+		return repos.Outbox.Store(ctx, e)
+	})
+	if err != nil {
+		return nil, err
+	}
+	a.publish(ctx, e)
+	return ar, nil
+}
+
+// publish emits e for a change that is already saved, so failing to
+// publish is logged instead of failing the request
+func (a *articleUsecase) publish(ctx context.Context, e models.Event) {
+	if err := a.publisher.Publish(ctx, e); err != nil {
+		logrus.WithField("event", e.EventName()).Error("publishing the article event failed: ", err)
+	}
 }
@@ -9,11 +9,41 @@ import (
 	"github.com/naveenpatilm/go-clean-arch/article/mocks"
 	ucase "github.com/naveenpatilm/go-clean-arch/article/usecase"
 	_authorMock "github.com/naveenpatilm/go-clean-arch/author/mocks"
+	"github.com/naveenpatilm/go-clean-arch/event"
+	_eventMock "github.com/naveenpatilm/go-clean-arch/event/mocks"
 	"github.com/naveenpatilm/go-clean-arch/models"
+	_outboxMock "github.com/naveenpatilm/go-clean-arch/outbox/mocks"
+	"github.com/naveenpatilm/go-clean-arch/transaction"
+	_txMock "github.com/naveenpatilm/go-clean-arch/transaction/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
+// runInTx makes a mocked transaction.Manager call fn with repos
+func runInTx(repos transaction.Repositories) func(context.Context, func(context.Context, transaction.Repositories) error) error {
+	return func(ctx context.Context, fn func(context.Context, transaction.Repositories) error) error {
+		return fn(ctx, repos)
+	}
+}
+
+func acceptingOutbox() *_outboxMock.Repository {
+	outboxRepo := new(_outboxMock.Repository)
+	outboxRepo.On("Store", mock.Anything, mock.Anything).Return(nil)
+	return outboxRepo
+}
+
+// newTxManager returns a transaction.Manager whose transactions write to articleRepo
+func newTxManager(articleRepo *mocks.Repository) *_txMock.Manager {
+	mockTx := new(_txMock.Manager)
+	mockTx.On("Do", mock.Anything, mock.Anything).Return(runInTx(transaction.Repositories{
+		Article: articleRepo,
+		Author:  new(_authorMock.Repository),
+		Outbox:  acceptingOutbox(),
+	}))
+	return mockTx
+}
+
 func TestFetch(t *testing.T) {
 	mockArticleRepo := new(mocks.Repository)
 	mockArticle := &models.Article{
@@ -26,19 +56,20 @@ func TestFetch(t *testing.T) {
 
 	t.Run("success", func(t *testing.T) {
 		mockArticleRepo.On("Fetch", mock.Anything, mock.AnythingOfType("string"),
-			mock.AnythingOfType("int64")).Return(mockListArtilce, nil).Once()
+			mock.AnythingOfType("int64")).Return(mockListArtilce, models.Page{Next: "next"}, nil).Once()
 		mockAuthor := &models.Author{
 			ID:   1,
 			Name: "Iman Tumorang",
 		}
 		mockAuthorrepo := new(_authorMock.Repository)
-		mockAuthorrepo.On("GetByID", mock.Anything, mock.AnythingOfType("int64")).Return(mockAuthor, nil)
-		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, time.Second*2)
+		mockAuthorrepo.On("GetByIDs", mock.Anything, []int64{0}).Return([]*models.Author{mockAuthor}, nil).Once()
+		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, newTxManager(mockArticleRepo), event.Discard, time.Second*2, 10)
 		num := int64(1)
 		cursor := "12"
-		list, err := u.Fetch(context.TODO(), cursor, num)
+		list, page, err := u.Fetch(context.TODO(), cursor, num)
 		assert.NoError(t, err)
 		assert.Len(t, list, len(mockListArtilce))
+		assert.Equal(t, "next", page.Next)
 
 		mockArticleRepo.AssertExpectations(t)
 		mockAuthorrepo.AssertExpectations(t)
@@ -46,13 +77,13 @@ func TestFetch(t *testing.T) {
 
 	t.Run("error-failed", func(t *testing.T) {
 		mockArticleRepo.On("Fetch", mock.Anything, mock.AnythingOfType("string"),
-			mock.AnythingOfType("int64")).Return(nil, errors.New("Unexpexted Error")).Once()
+			mock.AnythingOfType("int64")).Return(nil, models.Page{}, errors.New("Unexpexted Error")).Once()
 
 		mockAuthorrepo := new(_authorMock.Repository)
-		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, time.Second*2)
+		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, newTxManager(mockArticleRepo), event.Discard, time.Second*2, 10)
 		num := int64(1)
 		cursor := "12"
-		list, err := u.Fetch(context.TODO(), cursor, num)
+		list, _, err := u.Fetch(context.TODO(), cursor, num)
 
 		assert.Error(t, err)
 		assert.Len(t, list, 0)
@@ -60,6 +91,54 @@ func TestFetch(t *testing.T) {
 		mockAuthorrepo.AssertExpectations(t)
 	})
 
+	t.Run("authors-are-loaded-in-one-call", func(t *testing.T) {
+		list := []*models.Article{
+			{ID: 1, Author: models.Author{ID: 1}},
+			{ID: 2, Author: models.Author{ID: 2}},
+			{ID: 3, Author: models.Author{ID: 1}},
+		}
+		mockArticleRepo.On("Fetch", mock.Anything, "", int64(10)).Return(list, models.Page{}, nil).Once()
+
+		mockAuthorrepo := new(_authorMock.Repository)
+		// author 2 was removed and is missing from the result
+		mockAuthorrepo.On("GetByIDs", mock.Anything, []int64{1, 2}).
+			Return([]*models.Author{{ID: 1, Name: "Iman Tumorang"}}, nil).Once()
+		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, newTxManager(mockArticleRepo), event.Discard, time.Second*2, 10)
+		res, _, err := u.Fetch(context.TODO(), "", 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Iman Tumorang", res[0].Author.Name)
+		assert.Equal(t, models.Author{ID: 2}, res[1].Author)
+		assert.Equal(t, "Iman Tumorang", res[2].Author.Name)
+		mockArticleRepo.AssertExpectations(t)
+		mockAuthorrepo.AssertExpectations(t)
+	})
+
+	t.Run("error-loading-authors", func(t *testing.T) {
+		list := []*models.Article{{ID: 1, Author: models.Author{ID: 1}}}
+		mockArticleRepo.On("Fetch", mock.Anything, "", int64(10)).Return(list, models.Page{}, nil).Once()
+
+		mockAuthorrepo := new(_authorMock.Repository)
+		mockAuthorrepo.On("GetByIDs", mock.Anything, []int64{1}).Return(nil, errors.New("Unexpected")).Once()
+		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, newTxManager(mockArticleRepo), event.Discard, time.Second*2, 10)
+		res, _, err := u.Fetch(context.TODO(), "", 10)
+
+		assert.Error(t, err)
+		assert.Nil(t, res)
+		mockAuthorrepo.AssertExpectations(t)
+	})
+
+	t.Run("page-size-is-capped", func(t *testing.T) {
+		mockArticleRepo.On("Fetch", mock.Anything, "", int64(10)).Return(nil, models.Page{}, models.ErrNotFound).Once()
+
+		mockAuthorrepo := new(_authorMock.Repository)
+		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, newTxManager(mockArticleRepo), event.Discard, time.Second*2, 10)
+		_, _, err := u.Fetch(context.TODO(), "", 1000)
+
+		assert.Equal(t, models.ErrNotFound, err)
+		mockArticleRepo.AssertExpectations(t)
+	})
+
 }
 
 func TestGetByID(t *testing.T) {
@@ -76,8 +155,8 @@ func TestGetByID(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockArticleRepo.On("GetByID", mock.Anything, mock.AnythingOfType("int64")).Return(&mockArticle, nil).Once()
 		mockAuthorrepo := new(_authorMock.Repository)
-		mockAuthorrepo.On("GetByID", mock.Anything, mock.AnythingOfType("int64")).Return(mockAuthor, nil)
-		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, time.Second*2)
+		mockAuthorrepo.On("GetByIDs", mock.Anything, mock.AnythingOfType("[]int64")).Return([]*models.Author{mockAuthor}, nil)
+		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, newTxManager(mockArticleRepo), event.Discard, time.Second*2, 10)
 
 		a, err := u.GetByID(context.TODO(), mockArticle.ID)
 
@@ -87,11 +166,25 @@ func TestGetByID(t *testing.T) {
 		mockArticleRepo.AssertExpectations(t)
 		mockAuthorrepo.AssertExpectations(t)
 	})
+	t.Run("author-is-not-exist", func(t *testing.T) {
+		orphan := models.Article{ID: 7, Title: "Hello", Author: models.Author{ID: 9}}
+		mockArticleRepo.On("GetByTitle", mock.Anything, "Hello").Return(&orphan, nil).Once()
+		mockAuthorrepo := new(_authorMock.Repository)
+		mockAuthorrepo.On("GetByIDs", mock.Anything, []int64{9}).Return([]*models.Author{}, nil).Once()
+		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, newTxManager(mockArticleRepo), event.Discard, time.Second*2, 10)
+
+		a, err := u.GetByTitle(context.TODO(), "Hello")
+
+		require.NoError(t, err)
+		assert.Equal(t, models.Author{ID: 9}, a.Author)
+		mockArticleRepo.AssertExpectations(t)
+		mockAuthorrepo.AssertExpectations(t)
+	})
 	t.Run("error-failed", func(t *testing.T) {
 		mockArticleRepo.On("GetByID", mock.Anything, mock.AnythingOfType("int64")).Return(nil, errors.New("Unexpected")).Once()
 
 		mockAuthorrepo := new(_authorMock.Repository)
-		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, time.Second*2)
+		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, newTxManager(mockArticleRepo), event.Discard, time.Second*2, 10)
 
 		a, err := u.GetByID(context.TODO(), mockArticle.ID)
 
@@ -114,11 +207,10 @@ func TestStore(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		tempMockArticle := mockArticle
 		tempMockArticle.ID = 0
-		mockArticleRepo.On("GetByTitle", mock.Anything, mock.AnythingOfType("string")).Return(nil, models.ErrNotFound).Once()
 		mockArticleRepo.On("Store", mock.Anything, mock.AnythingOfType("*models.Article")).Return(nil).Once()
 
 		mockAuthorrepo := new(_authorMock.Repository)
-		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, time.Second*2)
+		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, newTxManager(mockArticleRepo), event.Discard, time.Second*2, 10)
 
 		err := u.Store(context.TODO(), &tempMockArticle)
 
@@ -128,25 +220,91 @@ func TestStore(t *testing.T) {
 	})
 	t.Run("existing-title", func(t *testing.T) {
 		existingArticle := mockArticle
-		mockArticleRepo.On("GetByTitle", mock.Anything, mock.AnythingOfType("string")).Return(&existingArticle, nil).Once()
-		mockAuthor := &models.Author{
-			ID:   1,
-			Name: "Iman Tumorang",
-		}
+		mockArticleRepo.On("Store", mock.Anything, mock.AnythingOfType("*models.Article")).Return(models.ErrConflict).Once()
+
 		mockAuthorrepo := new(_authorMock.Repository)
-		mockAuthorrepo.On("GetByID", mock.Anything, mock.AnythingOfType("int64")).Return(mockAuthor, nil)
+		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, newTxManager(mockArticleRepo), event.Discard, time.Second*2, 10)
+
+		err := u.Store(context.TODO(), &existingArticle)
+
+		assert.Equal(t, models.ErrConflict, err)
+		mockArticleRepo.AssertExpectations(t)
+		mockAuthorrepo.AssertExpectations(t)
+	})
+	t.Run("error-happens-in-db", func(t *testing.T) {
+		tempMockArticle := mockArticle
+		mockArticleRepo.On("Store", mock.Anything, mock.AnythingOfType("*models.Article")).Return(errors.New("Unexpected Error")).Once()
 
-		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, time.Second*2)
+		mockAuthorrepo := new(_authorMock.Repository)
+		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, newTxManager(mockArticleRepo), event.Discard, time.Second*2, 10)
 
-		err := u.Store(context.TODO(), &mockArticle)
+		err := u.Store(context.TODO(), &tempMockArticle)
 
 		assert.Error(t, err)
+		assert.NotEqual(t, models.ErrConflict, err)
 		mockArticleRepo.AssertExpectations(t)
-		mockAuthorrepo.AssertExpectations(t)
 	})
 
 }
 
+func TestStoreWithNewAuthor(t *testing.T) {
+	newArticle := func() *models.Article {
+		return &models.Article{
+			Title:   "Hello",
+			Content: "Content",
+			Author:  models.Author{Name: "Iman Tumorang"},
+		}
+	}
+	t.Run("success", func(t *testing.T) {
+		txArticleRepo := new(mocks.Repository)
+		txAuthorRepo := new(_authorMock.Repository)
+		txAuthorRepo.On("Store", mock.Anything, mock.AnythingOfType("*models.Author")).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.Author).ID = 7
+		}).Return(nil).Once()
+		txArticleRepo.On("Store", mock.Anything, mock.MatchedBy(func(a *models.Article) bool {
+			return a.Author.ID == 7
+		})).Return(nil).Once()
+
+		mockTx := new(_txMock.Manager)
+		mockTx.On("Do", mock.Anything, mock.Anything).Return(runInTx(transaction.Repositories{
+			Article: txArticleRepo,
+			Author:  txAuthorRepo,
+			Outbox:  acceptingOutbox(),
+		})).Once()
+
+		u := ucase.NewArticleUsecase(new(mocks.Repository), new(_authorMock.Repository), mockTx, event.Discard, time.Second*2, 10)
+
+		err := u.Store(context.TODO(), newArticle())
+
+		assert.NoError(t, err)
+		mockTx.AssertExpectations(t)
+		txArticleRepo.AssertExpectations(t)
+		txAuthorRepo.AssertExpectations(t)
+	})
+	t.Run("article-fails", func(t *testing.T) {
+		txArticleRepo := new(mocks.Repository)
+		txAuthorRepo := new(_authorMock.Repository)
+		txAuthorRepo.On("Store", mock.Anything, mock.AnythingOfType("*models.Author")).Return(nil).Once()
+		txArticleRepo.On("Store", mock.Anything, mock.AnythingOfType("*models.Article")).Return(models.ErrConflict).Once()
+
+		mockTx := new(_txMock.Manager)
+		mockTx.On("Do", mock.Anything, mock.Anything).Return(runInTx(transaction.Repositories{
+			Article: txArticleRepo,
+			Author:  txAuthorRepo,
+			Outbox:  acceptingOutbox(),
+		})).Once()
+
+		u := ucase.NewArticleUsecase(new(mocks.Repository), new(_authorMock.Repository), mockTx, event.Discard, time.Second*2, 10)
+
+		err := u.Store(context.TODO(), newArticle())
+
+		assert.Equal(t, models.ErrConflict, err)
+		mockTx.AssertExpectations(t)
+		txArticleRepo.AssertExpectations(t)
+		txAuthorRepo.AssertExpectations(t)
+	})
+}
+
 func TestDelete(t *testing.T) {
 	mockArticleRepo := new(mocks.Repository)
 	mockArticle := models.Article{
@@ -160,7 +318,7 @@ func TestDelete(t *testing.T) {
 		mockArticleRepo.On("Delete", mock.Anything, mock.AnythingOfType("int64")).Return(nil).Once()
 
 		mockAuthorrepo := new(_authorMock.Repository)
-		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, time.Second*2)
+		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, newTxManager(mockArticleRepo), event.Discard, time.Second*2, 10)
 
 		err := u.Delete(context.TODO(), mockArticle.ID)
 
@@ -172,7 +330,7 @@ func TestDelete(t *testing.T) {
 		mockArticleRepo.On("GetByID", mock.Anything, mock.AnythingOfType("int64")).Return(nil, nil).Once()
 
 		mockAuthorrepo := new(_authorMock.Repository)
-		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, time.Second*2)
+		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, newTxManager(mockArticleRepo), event.Discard, time.Second*2, 10)
 
 		err := u.Delete(context.TODO(), mockArticle.ID)
 
@@ -184,7 +342,7 @@ func TestDelete(t *testing.T) {
 		mockArticleRepo.On("GetByID", mock.Anything, mock.AnythingOfType("int64")).Return(nil, errors.New("Unexpected Error")).Once()
 
 		mockAuthorrepo := new(_authorMock.Repository)
-		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, time.Second*2)
+		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, newTxManager(mockArticleRepo), event.Discard, time.Second*2, 10)
 
 		err := u.Delete(context.TODO(), mockArticle.ID)
 
@@ -207,10 +365,121 @@ func TestUpdate(t *testing.T) {
 		mockArticleRepo.On("Update", mock.Anything, &mockArticle).Once().Return(nil)
 
 		mockAuthorrepo := new(_authorMock.Repository)
-		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, time.Second*2)
+		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, newTxManager(mockArticleRepo), event.Discard, time.Second*2, 10)
 
 		err := u.Update(context.TODO(), &mockArticle)
 		assert.NoError(t, err)
 		mockArticleRepo.AssertExpectations(t)
 	})
 }
+
+func TestReassign(t *testing.T) {
+	readYourWrites := mock.MatchedBy(models.IsReadYourWrites)
+	author := &models.Author{ID: 2, Name: "Iman"}
+
+	t.Run("success", func(t *testing.T) {
+		mockArticleRepo := new(mocks.Repository)
+		mockArticleRepo.On("GetByID", readYourWrites, int64(5)).Return(&models.Article{ID: 5, Title: "Hello", AuthorID: 1}, nil).Once()
+		mockArticleRepo.On("UpdateAuthor", mock.Anything, int64(5), int64(2)).Return(nil).Once()
+		mockAuthorrepo := new(_authorMock.Repository)
+		mockAuthorrepo.On("GetByID", readYourWrites, int64(2)).Return(author, nil).Once()
+		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, newTxManager(mockArticleRepo), event.Discard, time.Second*2, 10)
+
+		ar, err := u.Reassign(context.TODO(), 5, 2)
+
+		require.NoError(t, err)
+		assert.Equal(t, *author, ar.Author)
+		mockArticleRepo.AssertExpectations(t)
+		mockAuthorrepo.AssertExpectations(t)
+	})
+	t.Run("same-author", func(t *testing.T) {
+		mockArticleRepo := new(mocks.Repository)
+		mockArticleRepo.On("GetByID", readYourWrites, int64(5)).Return(&models.Article{ID: 5, Title: "Hello", AuthorID: 2}, nil).Once()
+		mockAuthorrepo := new(_authorMock.Repository)
+		mockAuthorrepo.On("GetByID", readYourWrites, int64(2)).Return(author, nil).Once()
+		mockTx := new(_txMock.Manager)
+		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, mockTx, event.Discard, time.Second*2, 10)
+
+		ar, err := u.Reassign(context.TODO(), 5, 2)
+
+		require.NoError(t, err)
+		assert.Equal(t, *author, ar.Author)
+		mockTx.AssertNotCalled(t, "Do", mock.Anything, mock.Anything)
+	})
+	t.Run("author-is-not-exist", func(t *testing.T) {
+		mockArticleRepo := new(mocks.Repository)
+		mockArticleRepo.On("GetByID", readYourWrites, int64(5)).Return(&models.Article{ID: 5, Title: "Hello", AuthorID: 1}, nil).Once()
+		mockAuthorrepo := new(_authorMock.Repository)
+		mockAuthorrepo.On("GetByID", readYourWrites, int64(2)).Return(nil, models.ErrNotFound).Once()
+		mockTx := new(_txMock.Manager)
+		u := ucase.NewArticleUsecase(mockArticleRepo, mockAuthorrepo, mockTx, event.Discard, time.Second*2, 10)
+
+		_, err := u.Reassign(context.TODO(), 5, 2)
+
+		assert.Equal(t, models.ErrNotFound, err)
+		mockTx.AssertNotCalled(t, "Do", mock.Anything, mock.Anything)
+	})
+}
+
+func TestPublishEvents(t *testing.T) {
+	mockArticleRepo := new(mocks.Repository)
+	mockArticleRepo.On("Store", mock.Anything, mock.AnythingOfType("*models.Article")).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.Article).ID = 5
+	}).Return(nil).Once()
+	mockArticleRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.Article")).Return(nil).Once()
+	mockArticleRepo.On("GetByID", mock.Anything, int64(5)).Return(&models.Article{ID: 5, Title: "Hello", Author: models.Author{ID: 2}}, nil).Once()
+	mockArticleRepo.On("Delete", mock.Anything, int64(5)).Return(nil).Once()
+
+	var names []string
+	mockPublisher := new(_eventMock.Publisher)
+	mockPublisher.On("Publish", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		e := args.Get(1).(models.Event)
+		assert.Equal(t, int64(5), e.EventArticle().ID)
+		assert.False(t, e.EventTime().IsZero())
+		names = append(names, e.EventName())
+	}).Return(errors.New("bus closed"))
+
+	u := ucase.NewArticleUsecase(mockArticleRepo, new(_authorMock.Repository), newTxManager(mockArticleRepo), mockPublisher, time.Second*2, 10)
+	ar := &models.Article{Title: "Hello", Content: "Content", Author: models.Author{ID: 2}}
+
+	// the change is saved, so a failing publisher does not fail the request
+	assert.NoError(t, u.Store(context.TODO(), ar))
+	assert.NoError(t, u.Update(context.TODO(), ar))
+	assert.NoError(t, u.Delete(context.TODO(), ar.ID))
+
+	assert.Equal(t, []string{models.ArticleCreatedEvent, models.ArticleUpdatedEvent, models.ArticleDeletedEvent}, names)
+	mockArticleRepo.AssertExpectations(t)
+}
+
+func TestNoEventOnError(t *testing.T) {
+	mockArticleRepo := new(mocks.Repository)
+	mockArticleRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.Article")).Return(models.ErrNotFound).Once()
+	mockPublisher := new(_eventMock.Publisher)
+
+	u := ucase.NewArticleUsecase(mockArticleRepo, new(_authorMock.Repository), newTxManager(mockArticleRepo), mockPublisher, time.Second*2, 10)
+
+	assert.Equal(t, models.ErrNotFound, u.Update(context.TODO(), &models.Article{ID: 5}))
+	mockPublisher.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+func TestOutboxFailureFailsTheChange(t *testing.T) {
+	mockArticleRepo := new(mocks.Repository)
+	mockArticleRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.Article")).Return(nil).Once()
+	failingOutbox := new(_outboxMock.Repository)
+	failingOutbox.On("Store", mock.Anything, mock.MatchedBy(func(e models.Event) bool {
+		return e.EventName() == models.ArticleUpdatedEvent && e.EventArticle().ID == 5
+	})).Return(errors.New("disk full")).Once()
+	mockTx := new(_txMock.Manager)
+	mockTx.On("Do", mock.Anything, mock.Anything).Return(runInTx(transaction.Repositories{
+		Article: mockArticleRepo,
+		Outbox:  failingOutbox,
+	})).Once()
+	mockPublisher := new(_eventMock.Publisher)
+
+	u := ucase.NewArticleUsecase(mockArticleRepo, new(_authorMock.Repository), mockTx, mockPublisher, time.Second*2, 10)
+
+	// the transaction rolls back the update with the outbox message
+	assert.EqualError(t, u.Update(context.TODO(), &models.Article{ID: 5}), "disk full")
+	mockPublisher.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+	failingOutbox.AssertExpectations(t)
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/naveenpatilm/go-clean-arch/article"
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/tracing"
+)
+
+type tracingArticleUsecase struct {
+	next article.Usecase
+}
+
+// NewTracingArticleUsecase wraps every call to an article.Usecase in a span
+func NewTracingArticleUsecase(next article.Usecase) article.Usecase {
+	return &tracingArticleUsecase{next: next}
+}
+
+func (u *tracingArticleUsecase) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, "ArticleUsecase."+method, trace.WithAttributes(attrs...))
+}
+
+func (u *tracingArticleUsecase) Fetch(ctx context.Context, cursor string, num int64) (res []*models.Article, page models.Page, err error) {
+	ctx, span := u.start(ctx, "Fetch", attribute.String("article.cursor", cursor), attribute.Int64("article.num", num))
+	defer func() {
+		span.SetAttributes(attribute.Int("article.count", len(res)))
+		tracing.End(span, err)
+	}()
+	return u.next.Fetch(ctx, cursor, num)
+}
+
+func (u *tracingArticleUsecase) GetByID(ctx context.Context, id int64) (res *models.Article, err error) {
+	ctx, span := u.start(ctx, "GetByID", attribute.Int64("article.id", id))
+	defer func() { tracing.End(span, err) }()
+	return u.next.GetByID(ctx, id)
+}
+
+func (u *tracingArticleUsecase) Update(ctx context.Context, ar *models.Article) (err error) {
+	ctx, span := u.start(ctx, "Update", attribute.Int64("article.id", ar.ID))
+	defer func() { tracing.End(span, err) }()
+	return u.next.Update(ctx, ar)
+}
+
+func (u *tracingArticleUsecase) GetByTitle(ctx context.Context, title string) (res *models.Article, err error) {
+	ctx, span := u.start(ctx, "GetByTitle", attribute.String("article.title", title))
+	defer func() { tracing.End(span, err) }()
+	return u.next.GetByTitle(ctx, title)
+}
+
+func (u *tracingArticleUsecase) Store(ctx context.Context, ar *models.Article) (err error) {
+	ctx, span := u.start(ctx, "Store")
+	defer func() {
+		span.SetAttributes(attribute.Int64("article.id", ar.ID))
+		tracing.End(span, err)
+	}()
+	return u.next.Store(ctx, ar)
+}
+
+func (u *tracingArticleUsecase) Delete(ctx context.Context, id int64) (err error) {
+	ctx, span := u.start(ctx, "Delete", attribute.Int64("article.id", id))
+	defer func() { tracing.End(span, err) }()
+	return u.next.Delete(ctx, id)
+}
+
+func (u *tracingArticleUsecase) Reassign(ctx context.Context, id, authorID int64) (res *models.Article, err error) {
+	ctx, span := u.start(ctx, "Reassign", attribute.Int64("article.id", id), attribute.Int64("article.author_id", authorID))
+	defer func() { tracing.End(span, err) }()
+	return u.next.Reassign(ctx, id, authorID)
+}
@@ -33,3 +33,40 @@ func (_m *Repository) GetByID(ctx context.Context, id int64) (*models.Author, er
 
 	return r0, r1
 }
+
+// GetByIDs provides a mock function with given fields: ctx, ids
+func (_m *Repository) GetByIDs(ctx context.Context, ids []int64) ([]*models.Author, error) {
+	ret := _m.Called(ctx, ids)
+
+	var r0 []*models.Author
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) []*models.Author); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Author)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []int64) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Store provides a mock function with given fields: ctx, a
+func (_m *Repository) Store(ctx context.Context, a *models.Author) error {
+	ret := _m.Called(ctx, a)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Author) error); ok {
+		r0 = rf(ctx, a)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -9,4 +9,8 @@ import (
 // Repository represent the author's repository contract
 type Repository interface {
 	GetByID(ctx context.Context, id int64) (*models.Author, error)
+	// GetByIDs returns the authors found for ids in a single query.
+	// Unknown ids are left out of the result instead of failing.
+	GetByIDs(ctx context.Context, ids []int64) ([]*models.Author, error)
+	Store(ctx context.Context, a *models.Author) error
 }
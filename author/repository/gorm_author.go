@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/naveenpatilm/go-clean-arch/author"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+// gormAuthorRepo is the author.Repository shared by the SQL databases.
+// None of its queries are dialect specific.
+type gormAuthorRepo struct {
+	DB models.Gormw
+}
+
+// NewPostgresAuthorRepository will create an implementation of author.Repository on postgres
+func NewPostgresAuthorRepository(db models.Gormw) author.Repository {
+
+	return &gormAuthorRepo{
+		DB: db,
+	}
+}
+
+// NewMysqlAuthorRepository will create an implementation of author.Repository on mysql
+func NewMysqlAuthorRepository(db models.Gormw) author.Repository {
+
+	return &gormAuthorRepo{
+		DB: db,
+	}
+}
+
+// NewSqliteAuthorRepository will create an implementation of author.Repository on sqlite
+func NewSqliteAuthorRepository(db models.Gormw) author.Repository {
+
+	return &gormAuthorRepo{
+		DB: db,
+	}
+}
+
+func (m *gormAuthorRepo) GetByID(ctx context.Context, id int64) (*models.Author, error) {
+	var author models.Author
+	err := models.ContextError(ctx, m.DB.WithContext(ctx).First(&author, id).Error())
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &author, nil
+}
+
+func (m *gormAuthorRepo) GetByIDs(ctx context.Context, ids []int64) ([]*models.Author, error) {
+	res := make([]*models.Author, 0, len(ids))
+	if len(ids) == 0 {
+		return res, nil
+	}
+	err := models.ContextError(ctx, m.DB.WithContext(ctx).Where("id IN (?)", ids).Find(&res).Error())
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (m *gormAuthorRepo) Store(ctx context.Context, a *models.Author) error {
+	return models.ContextError(ctx, m.DB.WithContext(ctx).Create(a).Error())
+}
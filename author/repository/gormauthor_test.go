@@ -0,0 +1,101 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	_ "github.com/jinzhu/gorm/dialects/mysql"
+	_ "github.com/jinzhu/gorm/dialects/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/author"
+	authorRepo "github.com/naveenpatilm/go-clean-arch/author/repository"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+var dialects = map[string]func(models.Gormw) author.Repository{
+	"postgres": authorRepo.NewPostgresAuthorRepository,
+	"mysql":    authorRepo.NewMysqlAuthorRepository,
+}
+
+// forEachDialect runs the same behaviour test against every dialect
+func forEachDialect(t *testing.T, test func(t *testing.T, repo author.Repository, mock sqlmock.Sqlmock)) {
+	for name, newRepo := range dialects {
+		name, newRepo := name, newRepo
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			gormDB, err := models.Openw(name, db)
+			require.NoError(t, err)
+
+			test(t, newRepo(gormDB), mock)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetByID(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, repo author.Repository, mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(`SELECT \* FROM .authors.`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Iman Tumorang"))
+
+		res, err := repo.GetByID(context.TODO(), 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Iman Tumorang", res.Name)
+	})
+}
+
+func TestGetByIDNotFound(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, repo author.Repository, mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(`SELECT \* FROM .authors.`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+
+		_, err := repo.GetByID(context.TODO(), 1)
+
+		assert.Equal(t, models.ErrNotFound, err)
+	})
+}
+
+func TestGetByIDSlowQueryIsInterrupted(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, repo author.Repository, mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(`SELECT \* FROM .authors.`).
+			WillDelayFor(5 * time.Second).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Iman Tumorang"))
+
+		ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := repo.GetByID(ctx, 1)
+
+		assert.Equal(t, models.ErrTimeout, err)
+		assert.True(t, time.Since(start) < time.Second, "query was not interrupted")
+	})
+}
+
+func TestGetByIDs(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, repo author.Repository, mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(`SELECT \* FROM .authors. WHERE .*\(id IN \(.+,.+\)\)`).
+			WithArgs(1, 2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Iman Tumorang"))
+
+		res, err := repo.GetByIDs(context.TODO(), []int64{1, 2})
+
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		assert.Equal(t, "Iman Tumorang", res[0].Name)
+	})
+}
+
+func TestGetByIDsEmpty(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, repo author.Repository, mock sqlmock.Sqlmock) {
+		res, err := repo.GetByIDs(context.TODO(), nil)
+
+		require.NoError(t, err)
+		assert.Empty(t, res)
+	})
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/naveenpatilm/go-clean-arch/author"
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/transaction"
+)
+
+type memoryAuthorRepo struct {
+	mu      sync.RWMutex
+	lastID  int64
+	authors map[int64]models.Author
+}
+
+// NewMemoryAuthorRepository will create a thread-safe in-memory implementation of author.Repository
+func NewMemoryAuthorRepository() author.Repository {
+
+	return &memoryAuthorRepo{
+		authors: map[int64]models.Author{},
+	}
+}
+
+func (m *memoryAuthorRepo) GetByID(ctx context.Context, id int64) (*models.Author, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, models.ContextError(ctx, err)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	a, ok := m.authors[id]
+	if !ok || a.DeletedAt != nil {
+		return nil, models.ErrNotFound
+	}
+	return &a, nil
+}
+
+func (m *memoryAuthorRepo) GetByIDs(ctx context.Context, ids []int64) ([]*models.Author, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, models.ContextError(ctx, err)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	res := make([]*models.Author, 0, len(ids))
+	for _, id := range ids {
+		a, ok := m.authors[id]
+		if !ok || a.DeletedAt != nil {
+			continue
+		}
+		res = append(res, &a)
+	}
+	return res, nil
+}
+
+func (m *memoryAuthorRepo) Store(ctx context.Context, a *models.Author) error {
+	if err := ctx.Err(); err != nil {
+		return models.ContextError(ctx, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	m.lastID++
+	a.ID = m.lastID
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = now
+	}
+	if a.UpdatedAt.IsZero() {
+		a.UpdatedAt = now
+	}
+	m.authors[a.ID] = *a
+	id := a.ID
+	transaction.RecordUndo(ctx, func() {
+		m.mu.Lock()
+		delete(m.authors, id)
+		m.mu.Unlock()
+	})
+	return nil
+}
@@ -0,0 +1,38 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	authorRepo "github.com/naveenpatilm/go-clean-arch/author/repository"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+func TestMemoryStoreAndGetByID(t *testing.T) {
+	repo := authorRepo.NewMemoryAuthorRepository()
+	a := &models.Author{Name: "Iman Tumorang"}
+
+	require.NoError(t, repo.Store(context.TODO(), a))
+	assert.NotZero(t, a.ID)
+
+	res, err := repo.GetByID(context.TODO(), a.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Iman Tumorang", res.Name)
+
+	_, err = repo.GetByID(context.TODO(), a.ID+1)
+	assert.Equal(t, models.ErrNotFound, err)
+}
+
+func TestMemoryGetByIDs(t *testing.T) {
+	repo := authorRepo.NewMemoryAuthorRepository()
+	a := &models.Author{Name: "Iman Tumorang"}
+	require.NoError(t, repo.Store(context.TODO(), a))
+
+	res, err := repo.GetByIDs(context.TODO(), []int64{a.ID, a.ID + 1})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, "Iman Tumorang", res[0].Name)
+}
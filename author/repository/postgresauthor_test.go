@@ -1 +1,78 @@
 package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/author"
+	authorRepo "github.com/naveenpatilm/go-clean-arch/author/repository"
+	"github.com/naveenpatilm/go-clean-arch/models"
+	_gormMock "github.com/naveenpatilm/go-clean-arch/models/mocks"
+)
+
+func newPostgresRepo() (*_gormMock.Gormw, author.Repository) {
+	db := new(_gormMock.Gormw)
+	db.On("WithContext", mock.Anything).Return(db)
+	return db, authorRepo.NewPostgresAuthorRepository(db)
+}
+
+func TestPostgresGetByID(t *testing.T) {
+	db, repo := newPostgresRepo()
+	db.On("First", mock.AnythingOfType("*models.Author"), int64(1)).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*models.Author) = models.Author{ID: 1, Name: "Iman Tumorang"}
+		}).
+		Return(db).Once()
+	db.On("Error").Return(nil).Once()
+
+	res, err := repo.GetByID(context.TODO(), 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Iman Tumorang", res.Name)
+	db.AssertExpectations(t)
+}
+
+func TestPostgresGetByIDNotFound(t *testing.T) {
+	db, repo := newPostgresRepo()
+	db.On("First", mock.AnythingOfType("*models.Author"), int64(1)).Return(db).Once()
+	db.On("Error").Return(gorm.ErrRecordNotFound).Once()
+
+	_, err := repo.GetByID(context.TODO(), 1)
+
+	assert.Equal(t, models.ErrNotFound, err)
+	db.AssertExpectations(t)
+}
+
+func TestPostgresGetByIDs(t *testing.T) {
+	db, repo := newPostgresRepo()
+	db.On("Where", "id IN (?)", []int64{1, 2}).Return(db).Once()
+	db.On("Find", mock.AnythingOfType("*[]*models.Author")).
+		Run(func(args mock.Arguments) {
+			*args.Get(0).(*[]*models.Author) = []*models.Author{{ID: 2}}
+		}).
+		Return(db).Once()
+	db.On("Error").Return(nil).Once()
+
+	res, err := repo.GetByIDs(context.TODO(), []int64{1, 2})
+
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, int64(2), res[0].ID)
+	db.AssertExpectations(t)
+}
+
+func TestPostgresStore(t *testing.T) {
+	db, repo := newPostgresRepo()
+	a := &models.Author{Name: "Iman Tumorang"}
+	db.On("Create", a).Return(db).Once()
+	db.On("Error").Return(errors.New("connection reset")).Once()
+
+	assert.EqualError(t, repo.Store(context.TODO(), a), "connection reset")
+	db.AssertExpectations(t)
+}
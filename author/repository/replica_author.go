@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/naveenpatilm/go-clean-arch/author"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+type replicaAuthorRepo struct {
+	author.Repository
+	replica author.Repository
+}
+
+// NewReplicaAuthorRepository sends the reads of an author.Repository to replica and its writes to primary.
+// Reads whose context is marked with models.ReadYourWrites stay on primary.
+func NewReplicaAuthorRepository(primary, replica author.Repository) author.Repository {
+
+	return &replicaAuthorRepo{
+		Repository: primary,
+		replica:    replica,
+	}
+}
+
+func (m *replicaAuthorRepo) reader(ctx context.Context) author.Repository {
+	if models.IsReadYourWrites(ctx) {
+		return m.Repository
+	}
+	return m.replica
+}
+
+func (m *replicaAuthorRepo) GetByID(ctx context.Context, id int64) (*models.Author, error) {
+	return m.reader(ctx).GetByID(ctx, id)
+}
+
+func (m *replicaAuthorRepo) GetByIDs(ctx context.Context, ids []int64) ([]*models.Author, error) {
+	return m.reader(ctx).GetByIDs(ctx, ids)
+}
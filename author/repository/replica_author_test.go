@@ -0,0 +1,35 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/naveenpatilm/go-clean-arch/author/mocks"
+	authorRepo "github.com/naveenpatilm/go-clean-arch/author/repository"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+func TestReplicaRouting(t *testing.T) {
+	primary := new(mocks.Repository)
+	replica := new(mocks.Repository)
+	repo := authorRepo.NewReplicaAuthorRepository(primary, replica)
+
+	replica.On("GetByID", mock.Anything, int64(1)).Return(&models.Author{ID: 1}, nil).Once()
+	replica.On("GetByIDs", mock.Anything, []int64{1}).Return([]*models.Author{{ID: 1}}, nil).Once()
+	primary.On("GetByID", mock.Anything, int64(2)).Return(&models.Author{ID: 2}, nil).Once()
+	primary.On("Store", mock.Anything, mock.Anything).Return(nil).Once()
+
+	_, err := repo.GetByID(context.TODO(), 1)
+	assert.NoError(t, err)
+	_, err = repo.GetByIDs(context.TODO(), []int64{1})
+	assert.NoError(t, err)
+	_, err = repo.GetByID(models.ReadYourWrites(context.TODO()), 2)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Store(context.TODO(), &models.Author{}))
+
+	primary.AssertExpectations(t)
+	replica.AssertExpectations(t)
+}
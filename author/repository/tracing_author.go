@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/naveenpatilm/go-clean-arch/author"
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/tracing"
+)
+
+type tracingAuthorRepository struct {
+	next author.Repository
+}
+
+// NewTracingAuthorRepository wraps every call to an author.Repository in a span
+func NewTracingAuthorRepository(next author.Repository) author.Repository {
+	return &tracingAuthorRepository{next: next}
+}
+
+func (m *tracingAuthorRepository) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, "AuthorRepository."+method, trace.WithAttributes(attrs...))
+}
+
+func (m *tracingAuthorRepository) GetByID(ctx context.Context, id int64) (res *models.Author, err error) {
+	ctx, span := m.start(ctx, "GetByID", attribute.Int64("author.id", id))
+	defer func() {
+		if err == models.ErrNotFound {
+			span.SetAttributes(attribute.Bool("author.not_found", true))
+			err = nil
+		}
+		tracing.End(span, err)
+	}()
+	return m.next.GetByID(ctx, id)
+}
+
+func (m *tracingAuthorRepository) GetByIDs(ctx context.Context, ids []int64) (res []*models.Author, err error) {
+	ctx, span := m.start(ctx, "GetByIDs", attribute.Int64Slice("author.ids", ids))
+	defer func() {
+		span.SetAttributes(attribute.Int("author.count", len(res)))
+		tracing.End(span, err)
+	}()
+	return m.next.GetByIDs(ctx, ids)
+}
+
+func (m *tracingAuthorRepository) Store(ctx context.Context, a *models.Author) (err error) {
+	ctx, span := m.start(ctx, "Store")
+	defer func() {
+		span.SetAttributes(attribute.Int64("author.id", a.ID))
+		tracing.End(span, err)
+	}()
+	return m.next.Store(ctx, a)
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+// articleList is the JSON form of a page of articles
+type articleList struct {
+	Articles []*models.Article `json:"articles"`
+	models.Page
+}
+
+func newAdminCommand(opts *options) *cobra.Command {
+	admin := &cobra.Command{
+		Use:   "admin",
+		Short: "Administer the data through the usecases, without the HTTP API",
+	}
+	articles := &cobra.Command{
+		Use:   "articles",
+		Short: "List, delete and reassign articles",
+	}
+	admin.AddCommand(articles)
+
+	var cursor string
+	var num int64
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List a page of articles",
+		Args:  cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			return withApp(opts, func(ctx context.Context, a *app) error {
+				res, page, err := a.Articles.Fetch(ctx, cursor, num)
+				if err == models.ErrNotFound {
+					// an empty page is not an error for scripts
+					res, err = []*models.Article{}, nil
+				}
+				if err != nil {
+					return err
+				}
+				return opts.print(c.OutOrStdout(), articleList{Articles: res, Page: page}, func(out io.Writer) {
+					w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+					fmt.Fprintln(w, "ID\tTITLE\tAUTHOR\tCREATED AT")
+					for _, ar := range res {
+						fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", ar.ID, ar.Title, ar.Author.Name, ar.CreatedAt.Format("2006-01-02 15:04:05 MST"))
+					}
+					w.Flush()
+					if page.Next != "" {
+						fmt.Fprintln(out, "next cursor:", page.Next)
+					}
+				})
+			})
+		},
+	}
+	list.Flags().StringVar(&cursor, "cursor", "", "cursor of the page to list")
+	list.Flags().Int64Var(&num, "num", 10, "number of articles to list")
+
+	get := &cobra.Command{
+		Use:   "get <id>",
+		Short: "Show an article",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+			return withApp(opts, func(ctx context.Context, a *app) error {
+				ar, err := a.Articles.GetByID(ctx, id)
+				if err != nil {
+					return err
+				}
+				return opts.print(c.OutOrStdout(), ar, func(w io.Writer) {
+					printArticle(w, ar)
+				})
+			})
+		},
+	}
+
+	del := &cobra.Command{
+		Use:   "delete <id>",
+		Short: "Delete an article",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+			return withApp(opts, func(ctx context.Context, a *app) error {
+				if err := a.Articles.Delete(ctx, id); err != nil {
+					return err
+				}
+				return opts.print(c.OutOrStdout(), map[string]int64{"deleted": id}, func(w io.Writer) {
+					fmt.Fprintf(w, "deleted article %d\n", id)
+				})
+			})
+		},
+	}
+
+	var authorID int64
+	reassign := &cobra.Command{
+		Use:   "reassign <id> --author <author id>",
+		Short: "Give an article to another author",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+			if authorID <= 0 {
+				return usageError("--author is required")
+			}
+			return withApp(opts, func(ctx context.Context, a *app) error {
+				ar, err := reassignArticle(ctx, a, id, authorID)
+				if err != nil {
+					return err
+				}
+				return opts.print(c.OutOrStdout(), ar, func(w io.Writer) {
+					printArticle(w, ar)
+				})
+			})
+		},
+	}
+	reassign.Flags().Int64Var(&authorID, "author", 0, "id of the new author")
+
+	articles.AddCommand(list, get, del, reassign)
+	return admin
+}
+
+// withApp runs fn with the usecases of the configured storage
+func withApp(opts *options, fn func(ctx context.Context, a *app) error) error {
+	cfg, err := opts.loadConfig()
+	if err != nil {
+		return err
+	}
+	a, err := newApp(cfg)
+	if err != nil {
+		return fail(err)
+	}
+	defer a.Close()
+	return fail(fn(context.Background(), a))
+}
+
+// reassignArticle moves the article id to the author authorID, who must exist
+func reassignArticle(ctx context.Context, a *app, id, authorID int64) (*models.Article, error) {
+	if _, err := a.AuthorRepo.GetByID(models.ReadYourWrites(ctx), authorID); err != nil {
+		if err == models.ErrNotFound {
+			return nil, &exitError{code: ExitNotFound, err: fmt.Errorf("author %d not found", authorID)}
+		}
+		return nil, err
+	}
+	return a.Articles.Reassign(ctx, id, authorID)
+}
+
+func parseID(arg string) (int64, error) {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, usageError("invalid id %q", arg)
+	}
+	return id, nil
+}
+
+func printArticle(w io.Writer, ar *models.Article) {
+	fmt.Fprintf(w, "ID:         %d\n", ar.ID)
+	fmt.Fprintf(w, "Title:      %s\n", ar.Title)
+	fmt.Fprintf(w, "Author:     %s (%d)\n", ar.Author.Name, ar.Author.ID)
+	fmt.Fprintf(w, "Created at: %s\n", ar.CreatedAt.Format("2006-01-02 15:04:05 MST"))
+	fmt.Fprintf(w, "Updated at: %s\n", ar.UpdatedAt.Format("2006-01-02 15:04:05 MST"))
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, ar.Content)
+}
@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/naveenpatilm/go-clean-arch/article"
+	_articleJobs "github.com/naveenpatilm/go-clean-arch/article/delivery/jobs"
+	_articleRepo "github.com/naveenpatilm/go-clean-arch/article/repository"
+	_articleUcase "github.com/naveenpatilm/go-clean-arch/article/usecase"
+	"github.com/naveenpatilm/go-clean-arch/author"
+	_authorRepo "github.com/naveenpatilm/go-clean-arch/author/repository"
+	"github.com/naveenpatilm/go-clean-arch/config"
+	"github.com/naveenpatilm/go-clean-arch/database"
+	"github.com/naveenpatilm/go-clean-arch/event"
+	_eventBus "github.com/naveenpatilm/go-clean-arch/event/bus"
+	"github.com/naveenpatilm/go-clean-arch/health"
+	"github.com/naveenpatilm/go-clean-arch/job"
+	_jobRepo "github.com/naveenpatilm/go-clean-arch/job/repository"
+	_jobUcase "github.com/naveenpatilm/go-clean-arch/job/usecase"
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/outbox"
+	_outboxRepo "github.com/naveenpatilm/go-clean-arch/outbox/repository"
+	"github.com/naveenpatilm/go-clean-arch/stream"
+	_streamRepo "github.com/naveenpatilm/go-clean-arch/stream/repository"
+	"github.com/naveenpatilm/go-clean-arch/tracing"
+	"github.com/naveenpatilm/go-clean-arch/transaction"
+	_transactionRepo "github.com/naveenpatilm/go-clean-arch/transaction/repository"
+	"github.com/naveenpatilm/go-clean-arch/webhook"
+	_webhookRepo "github.com/naveenpatilm/go-clean-arch/webhook/repository"
+	_webhookUcase "github.com/naveenpatilm/go-clean-arch/webhook/usecase"
+)
+
+// app holds the repositories and usecases shared by the commands
+type app struct {
+	Config      *config.Config
+	ArticleRepo article.Repository
+	AuthorRepo  author.Repository
+	TxManager   transaction.Manager
+	OutboxRepo  outbox.Repository
+	WebhookRepo webhook.Repository
+	JobRepo     job.Repository
+	Articles    article.Usecase
+	Webhooks    webhook.Usecase
+	Jobs        job.Usecase
+	// JobKinds holds the handlers of the background jobs
+	JobKinds job.Registry
+	// StreamLog is the history of the article events the stream clients resume from
+	StreamLog stream.Log
+	Health    *health.Health
+	// Events carries the article events; subscribe to react to article changes
+	Events event.Bus
+	// Cache is nil when the article cache is disabled
+	Cache _articleRepo.CachingRepository
+
+	cluster         *database.Cluster
+	shutdownTracing func(context.Context) error
+}
+
+// newApp connects the storage selected by cfg and builds the usecases.
+// It fails when the database schema is not up to date.
+func newApp(cfg *config.Config) (*app, error) {
+	a := &app{
+		Config: cfg,
+		Health: health.New(time.Duration(cfg.Health.Timeout) * time.Second),
+	}
+	dbConfig := cfg.DatabaseConfig()
+	if cfg.Events.Async {
+		a.Events = _eventBus.NewAsyncBus(cfg.Events.Buffer)
+	} else {
+		a.Events = _eventBus.NewSyncBus()
+	}
+	a.Events.Subscribe(logEvent)
+
+	shutdownTracing, err := tracing.Setup(cfg.TracingConfig())
+	if err != nil {
+		return nil, err
+	}
+	a.shutdownTracing = shutdownTracing
+
+	traced := cfg.Tracing.Exporter != "" && cfg.Tracing.Exporter != tracing.ExporterNone
+	switch dbConfig.Driver {
+	case database.Memory:
+		a.ArticleRepo = _articleRepo.NewMemoryArticleRepository()
+		a.AuthorRepo = _authorRepo.NewMemoryAuthorRepository()
+		a.OutboxRepo = _outboxRepo.NewMemoryOutboxRepository()
+		a.WebhookRepo = _webhookRepo.NewMemoryWebhookRepository()
+		a.JobRepo = _jobRepo.NewMemoryJobRepository()
+		a.TxManager = _transactionRepo.NewMemoryTransactionManager(a.ArticleRepo, a.AuthorRepo,
+			_webhookRepo.NewEnqueuingOutboxRepository(a.OutboxRepo, a.WebhookRepo))
+	case database.Postgres, database.Mysql, database.Sqlite:
+		cluster, err := database.OpenCluster(dbConfig)
+		if err != nil {
+			a.Close()
+			return nil, err
+		}
+		a.cluster = cluster
+		if traced {
+			cluster.Primary = models.WithStatementHook(cluster.Primary, tracing.SQLStatement)
+			cluster.Replica = models.WithStatementHook(cluster.Replica, tracing.SQLStatement)
+		}
+		dbConn := cluster.Primary
+
+		migrator, err := database.NewMigrator(dbConn.DB(), dbConfig.Driver)
+		if err != nil {
+			a.Close()
+			return nil, err
+		}
+		if err = migrator.CheckCurrent(context.Background()); err != nil {
+			a.Close()
+			return nil, err
+		}
+		a.Health.Register("database", health.CheckerFunc(cluster.Ping))
+		a.Health.Register("migrations", health.CheckerFunc(migrator.CheckCurrent))
+		if len(dbConfig.Replicas) > 0 {
+			interval := dbConfig.ReplicaCheckInterval
+			if interval <= 0 {
+				interval = database.DefaultReplicaCheckInterval
+			}
+			a.Health.Register("replicas", replicaChecker(cluster, time.Duration(cfg.Health.MissedPolls)*interval))
+		}
+
+		newArticleRepo, newAuthorRepo := _articleRepo.NewPostgresArticleRepository, _authorRepo.NewPostgresAuthorRepository
+		newOutboxRepo, newWebhookRepo := _outboxRepo.NewPostgresOutboxRepository, _webhookRepo.NewPostgresWebhookRepository
+		newJobRepo := _jobRepo.NewPostgresJobRepository
+		switch dbConfig.Driver {
+		case database.Mysql:
+			newArticleRepo, newAuthorRepo = _articleRepo.NewMysqlArticleRepository, _authorRepo.NewMysqlAuthorRepository
+			newOutboxRepo, newWebhookRepo = _outboxRepo.NewMysqlOutboxRepository, _webhookRepo.NewMysqlWebhookRepository
+			newJobRepo = _jobRepo.NewMysqlJobRepository
+		case database.Sqlite:
+			newArticleRepo, newAuthorRepo = _articleRepo.NewSqliteArticleRepository, _authorRepo.NewSqliteAuthorRepository
+			newOutboxRepo, newWebhookRepo = _outboxRepo.NewSqliteOutboxRepository, _webhookRepo.NewSqliteWebhookRepository
+			newJobRepo = _jobRepo.NewSqliteJobRepository
+		}
+		a.ArticleRepo = newArticleRepo(dbConn)
+		a.AuthorRepo = newAuthorRepo(dbConn)
+		a.OutboxRepo = newOutboxRepo(dbConn)
+		a.WebhookRepo = newWebhookRepo(dbConn)
+		a.JobRepo = newJobRepo(dbConn)
+		if len(dbConfig.Replicas) > 0 {
+			a.ArticleRepo = _articleRepo.NewReplicaArticleRepository(a.ArticleRepo, newArticleRepo(cluster.Replica))
+			a.AuthorRepo = _authorRepo.NewReplicaAuthorRepository(a.AuthorRepo, newAuthorRepo(cluster.Replica))
+		}
+		txArticleRepo, txAuthorRepo := newArticleRepo, newAuthorRepo
+		if traced {
+			txArticleRepo = func(db models.Gormw) article.Repository {
+				return _articleRepo.NewTracingArticleRepository(newArticleRepo(db))
+			}
+			txAuthorRepo = func(db models.Gormw) author.Repository {
+				return _authorRepo.NewTracingAuthorRepository(newAuthorRepo(db))
+			}
+		}
+		// the webhook deliveries of an event are enqueued with its outbox message
+		txOutboxRepo := func(db models.Gormw) outbox.Repository {
+			return _webhookRepo.NewEnqueuingOutboxRepository(newOutboxRepo(db), newWebhookRepo(db))
+		}
+		a.TxManager = _transactionRepo.NewGormTransactionManager(dbConn, txArticleRepo, txAuthorRepo, txOutboxRepo)
+	default:
+		a.Close()
+		return nil, fmt.Errorf("unknown database.driver %q", dbConfig.Driver)
+	}
+
+	if cfg.Stream.Log == stream.LogOutbox {
+		a.StreamLog = _streamRepo.NewOutboxLog(a.cluster.Primary, time.Duration(cfg.Stream.Settle)*time.Second)
+	} else {
+		memoryLog := _streamRepo.NewMemoryLog(cfg.Stream.LogSize)
+		a.Events.Subscribe(memoryLog.Append)
+		a.StreamLog = memoryLog
+	}
+
+	if cfg.Cache.Size > 0 {
+		a.Cache = _articleRepo.NewCachingArticleRepository(a.ArticleRepo, cfg.Cache.Size, time.Duration(cfg.Cache.TTL)*time.Second)
+		a.ArticleRepo = a.Cache
+	}
+	if traced {
+		a.ArticleRepo = _articleRepo.NewTracingArticleRepository(a.ArticleRepo)
+		a.AuthorRepo = _authorRepo.NewTracingAuthorRepository(a.AuthorRepo)
+	}
+
+	var publisher event.Publisher = a.Events
+	if a.Cache != nil {
+		// the usecase writes in transactions, which bypass the cache
+		publisher = event.Multi(event.PublisherFunc(a.invalidateCache), a.Events)
+	}
+	timeoutContext := time.Duration(cfg.Context.Timeout) * time.Second
+	a.Articles = _articleUcase.NewArticleUsecase(a.ArticleRepo, a.AuthorRepo, a.TxManager, publisher, timeoutContext, cfg.Pagination.MaxSize)
+	if traced {
+		a.Articles = _articleUcase.NewTracingArticleUsecase(a.Articles)
+	}
+	a.Webhooks = _webhookUcase.NewWebhookUsecase(a.WebhookRepo, net.DefaultResolver, cfg.Webhooks.AllowPrivate, timeoutContext)
+
+	a.JobKinds = job.NewRegistry()
+	a.JobKinds.Register(job.Definition{
+		Kind:    _articleJobs.ExportKind,
+		Handler: _articleJobs.NewExportHandler(a.Articles, cfg.Jobs.ExportDir),
+	})
+	a.Jobs = _jobUcase.NewJobUsecase(a.JobRepo, a.JobKinds, cfg.Jobs.MaxAttempts, timeoutContext)
+	return a, nil
+}
+
+// watchLoop registers a readiness checker for a background loop polling
+// every interval, and returns the function the loop calls after each
+// successful poll. The loop is unready once health.missed_polls intervals,
+// plus slack, went by without one.
+func (a *app) watchLoop(name string, interval, slack time.Duration) func() {
+	h := health.NewHeartbeat(time.Duration(a.Config.Health.MissedPolls)*interval + slack)
+	a.Health.Register(name, h)
+	return h.Beat
+}
+
+// replicaChecker fails when the health checks of the replicas stalled for
+// maxAge, and reports the service degraded while every replica is out of
+// rotation, since the reads then fall back to the primary
+func replicaChecker(cluster *database.Cluster, maxAge time.Duration) health.Checker {
+	return health.CheckerFunc(func(ctx context.Context) error {
+		if age := time.Since(cluster.ReplicasCheckedAt()); age > maxAge {
+			return fmt.Errorf("no replica health check for %s", age.Truncate(time.Second))
+		}
+		if cluster.HealthyReplicas() == 0 {
+			return health.Degraded(errors.New("every replica is down, the reads go to the primary"))
+		}
+		return nil
+	})
+}
+
+// invalidateCache drops the changed articles from the cache once their change is committed
+func (a *app) invalidateCache(ctx context.Context, events ...models.Event) error {
+	for _, e := range events {
+		ar := e.EventArticle()
+		a.Cache.Invalidate(ar.ID, ar.Title)
+	}
+	return nil
+}
+
+// logEvent traces the article events in the debug logs
+func logEvent(ctx context.Context, e models.Event) error {
+	logrus.WithFields(logrus.Fields{"event": e.EventName(), "article_id": e.EventArticle().ID}).Debug("article event")
+	return nil
+}
+
+// tracingShutdownTimeout bounds the export of the last spans on Close
+const tracingShutdownTimeout = 5 * time.Second
+
+// eventsShutdownTimeout bounds the handling of the pending events on Close
+const eventsShutdownTimeout = 5 * time.Second
+
+// Close handles the pending events, releases the database connections and flushes the pending spans
+func (a *app) Close() {
+	if a.Events != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), eventsShutdownTimeout)
+		defer cancel()
+		if err := a.Events.Close(ctx); err != nil {
+			log.Println(err)
+		}
+	}
+	if a.cluster != nil {
+		if err := a.cluster.Close(); err != nil {
+			log.Println(err)
+		}
+	}
+	if a.shutdownTracing != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+		if err := a.shutdownTracing(ctx); err != nil {
+			log.Println(err)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package cmd_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/cmd"
+)
+
+// newSqliteConfig writes a configuration using a sqlite database in a temporary directory
+func newSqliteConfig(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "cmd")
+	require.NoError(t, err)
+	content := fmt.Sprintf(`{"cache": {"size": 0}, "database": {"driver": "sqlite3", "path": %q}}`, filepath.Join(dir, "article.db"))
+	path := filepath.Join(dir, "config.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func run(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	root := cmd.NewRootCommand()
+	root.SetOut(&stdout)
+	root.SetErr(&stderr)
+	code := cmd.Run(root, args)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestUsageErrors(t *testing.T) {
+	code, _, stderr := run("unknown")
+	assert.Equal(t, cmd.ExitUsage, code)
+	assert.Contains(t, stderr, "unknown command")
+
+	code, _, _ = run("admin", "articles", "get", "abc")
+	assert.Equal(t, cmd.ExitUsage, code)
+
+	code, _, _ = run("admin", "articles", "get", "1", "2")
+	assert.Equal(t, cmd.ExitUsage, code)
+
+	code, _, _ = run("config", "validate", "--output", "yaml")
+	assert.Equal(t, cmd.ExitUsage, code)
+}
+
+func TestConfigValidate(t *testing.T) {
+	path, cleanup := newSqliteConfig(t)
+	defer cleanup()
+
+	code, stdout, _ := run("config", "validate", "--config", path, "-o", "json")
+	require.Equal(t, cmd.ExitOK, code)
+	var res struct {
+		Database struct {
+			Driver string `json:"driver"`
+		} `json:"database"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &res))
+	assert.Equal(t, "sqlite3", res.Database.Driver)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"database": {"driver": "oracle"}}`), 0600))
+	code, _, stderr := run("config", "validate", "--config", path, "-o", "json")
+	assert.Equal(t, cmd.ExitConfig, code)
+	var failure struct {
+		Error string `json:"error"`
+		Code  int    `json:"code"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stderr), &failure))
+	assert.Equal(t, cmd.ExitConfig, failure.Code)
+	assert.Contains(t, failure.Error, "database.driver")
+}
+
+func TestAdminArticles(t *testing.T) {
+	path, cleanup := newSqliteConfig(t)
+	defer cleanup()
+
+	code, _, stderr := run("admin", "articles", "list", "--config", path)
+	require.Equal(t, cmd.ExitFailure, code, "the schema is not migrated yet")
+	assert.Contains(t, stderr, "migrate")
+
+	code, _, stderr = run("migrate", "up", "--config", path)
+	require.Equal(t, cmd.ExitOK, code, stderr)
+	code, _, stderr = run("seed", "--config", path, "--authors", "2", "--articles", "3")
+	require.Equal(t, cmd.ExitOK, code, stderr)
+
+	var list struct {
+		Articles []struct {
+			ID     int64
+			Title  string `json:"title"`
+			Author struct {
+				ID   int64
+				Name string `json:"name"`
+			} `json:"author"`
+		} `json:"articles"`
+		Next string `json:"next"`
+	}
+	code, stdout, stderr := run("admin", "articles", "list", "--config", path, "-o", "json", "--num", "2")
+	require.Equal(t, cmd.ExitOK, code, stderr)
+	require.NoError(t, json.Unmarshal([]byte(stdout), &list))
+	require.Len(t, list.Articles, 2)
+	assert.NotEmpty(t, list.Next)
+	assert.NotEmpty(t, list.Articles[0].Title)
+	assert.NotEmpty(t, list.Articles[0].Author.Name)
+
+	code, _, stderr = run("admin", "articles", "reassign", "1", "--author", "2", "--config", path, "-o", "json")
+	require.Equal(t, cmd.ExitOK, code, stderr)
+	code, stdout, _ = run("admin", "articles", "get", "1", "--config", path, "-o", "json")
+	require.Equal(t, cmd.ExitOK, code)
+	var got struct {
+		Author struct {
+			ID int64
+		} `json:"author"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &got))
+	assert.Equal(t, int64(2), got.Author.ID)
+
+	code, _, _ = run("admin", "articles", "reassign", "1", "--author", "99", "--config", path)
+	assert.Equal(t, cmd.ExitNotFound, code)
+
+	code, _, stderr = run("admin", "articles", "delete", "1", "--config", path)
+	require.Equal(t, cmd.ExitOK, code, stderr)
+	code, _, _ = run("admin", "articles", "delete", "1", "--config", path)
+	assert.Equal(t, cmd.ExitNotFound, code)
+}
+
+func TestJobs(t *testing.T) {
+	path, cleanup := newSqliteConfig(t)
+	defer cleanup()
+	code, _, stderr := run("migrate", "up", "--config", path)
+	require.Equal(t, cmd.ExitOK, code, stderr)
+
+	code, _, _ = run("jobs", "enqueue", "unknown.kind", "--config", path)
+	assert.Equal(t, cmd.ExitFailure, code)
+	code, _, _ = run("jobs", "enqueue", "articles.export", "{", "--config", path)
+	assert.Equal(t, cmd.ExitUsage, code)
+
+	var j struct {
+		ID    int64  `json:"id"`
+		Kind  string `json:"kind"`
+		Queue string `json:"queue"`
+		State string `json:"state"`
+	}
+	code, stdout, stderr := run("jobs", "enqueue", "articles.export", `{"name": "all.jsonl"}`,
+		"--unique-key", "export", "--run-at", "2100-01-01T00:00:00Z", "--config", path, "-o", "json")
+	require.Equal(t, cmd.ExitOK, code, stderr)
+	require.NoError(t, json.Unmarshal([]byte(stdout), &j))
+	assert.Equal(t, "articles.export", j.Kind)
+	assert.Equal(t, "default", j.Queue)
+	assert.Equal(t, "pending", j.State)
+
+	code, _, _ = run("jobs", "enqueue", "articles.export", "--unique-key", "export", "--config", path)
+	assert.Equal(t, cmd.ExitConflict, code)
+
+	var list []struct {
+		ID int64 `json:"id"`
+	}
+	code, stdout, stderr = run("jobs", "list", "--state", "pending", "--config", path, "-o", "json")
+	require.Equal(t, cmd.ExitOK, code, stderr)
+	require.NoError(t, json.Unmarshal([]byte(stdout), &list))
+	require.Len(t, list, 1)
+	assert.Equal(t, j.ID, list[0].ID)
+
+	code, _, stderr = run("jobs", "get", fmt.Sprint(j.ID), "--config", path)
+	require.Equal(t, cmd.ExitOK, code, stderr)
+	code, _, _ = run("jobs", "get", "99", "--config", path)
+	assert.Equal(t, cmd.ExitNotFound, code)
+
+	// only dead jobs are retried
+	code, _, _ = run("jobs", "retry", fmt.Sprint(j.ID), "--config", path)
+	assert.Equal(t, cmd.ExitConflict, code)
+}
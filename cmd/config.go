@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+func newConfigCommand(opts *options) *cobra.Command {
+	cfg := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration",
+	}
+	cfg.AddCommand(&cobra.Command{
+		Use:   "validate",
+		Short: "Load and validate the configuration, then print it with secrets redacted",
+		Args:  cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			loaded, err := opts.loadConfig()
+			if err != nil {
+				return err
+			}
+			return opts.print(c.OutOrStdout(), loaded.Redacted(), func(w io.Writer) {
+				fmt.Fprintln(w, "configuration is valid")
+				fmt.Fprintln(w, loaded)
+			})
+		},
+	})
+	return cfg
+}
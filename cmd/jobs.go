@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/naveenpatilm/go-clean-arch/job"
+	"github.com/naveenpatilm/go-clean-arch/job/worker"
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/server"
+)
+
+func newJobsCommand(opts *options) *cobra.Command {
+	jobsCmd := &cobra.Command{
+		Use:   "jobs",
+		Short: "Run, enqueue, inspect and retry the background jobs",
+	}
+	jobsCmd.AddCommand(&cobra.Command{
+		Use:   "work",
+		Short: "Run the job worker until SIGINT or SIGTERM",
+		Long: `Run the job worker until SIGINT or SIGTERM.
+
+serve already runs a worker unless jobs.worker is false. Several workers may
+run at once: each job is claimed by one of them.`,
+		Args: cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			return withApp(opts, func(ctx context.Context, a *app) error {
+				ctx, stop := server.WithSignals(ctx)
+				defer stop()
+				return runWorker(ctx, a)
+			})
+		},
+	})
+
+	var f models.JobFilter
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List the latest jobs, newest first",
+		Args:  cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			return withApp(opts, func(ctx context.Context, a *app) error {
+				jobs, err := a.Jobs.Fetch(ctx, f)
+				if err != nil {
+					return err
+				}
+				return opts.print(c.OutOrStdout(), jobs, func(out io.Writer) {
+					w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+					fmt.Fprintln(w, "ID\tKIND\tQUEUE\tSTATE\tATTEMPTS\tRUN AT\tLAST ERROR")
+					for _, j := range jobs {
+						fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d/%d\t%s\t%s\n", j.ID, j.Kind, j.Queue, j.State,
+							j.Attempts, j.MaxAttempts, j.RunAt.Format("2006-01-02 15:04:05 MST"), j.LastError)
+					}
+					w.Flush()
+				})
+			})
+		},
+	}
+	list.Flags().StringVar(&f.Queue, "queue", "", "only list the jobs of a queue")
+	list.Flags().StringVar(&f.Kind, "kind", "", "only list the jobs of a kind")
+	list.Flags().StringVar(&f.State, "state", "", "only list the jobs in a state: pending, running, succeeded or dead")
+	list.Flags().Int64Var(&f.Num, "num", 20, "number of jobs to list")
+
+	get := &cobra.Command{
+		Use:   "get <id>",
+		Short: "Show a job",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+			return withApp(opts, func(ctx context.Context, a *app) error {
+				j, err := a.Jobs.GetByID(ctx, id)
+				if err != nil {
+					return err
+				}
+				return opts.print(c.OutOrStdout(), j, func(w io.Writer) {
+					printJob(w, j)
+				})
+			})
+		},
+	}
+
+	retry := &cobra.Command{
+		Use:   "retry <id>",
+		Short: "Run a dead job again",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+			return withApp(opts, func(ctx context.Context, a *app) error {
+				j, err := a.Jobs.Retry(ctx, id)
+				if err != nil {
+					return err
+				}
+				return opts.print(c.OutOrStdout(), j, func(w io.Writer) {
+					printJob(w, j)
+				})
+			})
+		},
+	}
+
+	var enqueueOpts job.EnqueueOptions
+	var runAt string
+	enqueue := &cobra.Command{
+		Use:   "enqueue <kind> [payload]",
+		Short: "Enqueue a job, the payload being JSON",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(c *cobra.Command, args []string) error {
+			payload := json.RawMessage("{}")
+			if len(args) == 2 {
+				payload = json.RawMessage(args[1])
+				if !json.Valid(payload) {
+					return usageError("invalid JSON payload %q", args[1])
+				}
+			}
+			if runAt != "" {
+				t, err := time.Parse(time.RFC3339, runAt)
+				if err != nil {
+					return usageError("invalid --run-at %q, must be RFC 3339", runAt)
+				}
+				enqueueOpts.RunAt = t
+			}
+			return withApp(opts, func(ctx context.Context, a *app) error {
+				if _, ok := a.JobKinds.Lookup(args[0]); !ok {
+					return fmt.Errorf("unknown job kind %q, the kinds are %v", args[0], a.JobKinds.Kinds())
+				}
+				j, err := a.Jobs.Enqueue(ctx, args[0], payload, enqueueOpts)
+				if err != nil {
+					return err
+				}
+				return opts.print(c.OutOrStdout(), j, func(w io.Writer) {
+					printJob(w, j)
+				})
+			})
+		},
+	}
+	enqueue.Flags().StringVar(&enqueueOpts.Queue, "queue", "", "queue of the job, instead of the one of its kind")
+	enqueue.Flags().StringVar(&runAt, "run-at", "", "time the job runs at, RFC 3339, instead of now")
+	enqueue.Flags().StringVar(&enqueueOpts.UniqueKey, "unique-key", "", "refuse the job while another one with this key is pending or running")
+	enqueue.Flags().IntVar(&enqueueOpts.MaxAttempts, "max-attempts", 0, "attempts before the job dies, instead of the default")
+
+	jobsCmd.AddCommand(list, get, retry, enqueue)
+	return jobsCmd
+}
+
+// runWorker runs the jobs of the configured queues until ctx is done
+func runWorker(ctx context.Context, a *app) error {
+	c := a.Config.WorkerConfig()
+	c.Polled = a.watchLoop("job_worker", c.PollInterval, 0)
+	return worker.NewWorker(a.JobRepo, a.JobKinds, c).Run(ctx)
+}
+
+func printJob(w io.Writer, j *models.Job) {
+	fmt.Fprintf(w, "ID:         %d\n", j.ID)
+	fmt.Fprintf(w, "Kind:       %s\n", j.Kind)
+	fmt.Fprintf(w, "Queue:      %s\n", j.Queue)
+	fmt.Fprintf(w, "State:      %s\n", j.State)
+	fmt.Fprintf(w, "Attempts:   %d/%d\n", j.Attempts, j.MaxAttempts)
+	fmt.Fprintf(w, "Run at:     %s\n", j.RunAt.Format("2006-01-02 15:04:05 MST"))
+	if j.UniqueKey != "" {
+		fmt.Fprintf(w, "Unique key: %s\n", j.UniqueKey)
+	}
+	if j.FinishedAt != nil {
+		fmt.Fprintf(w, "Finished:   %s\n", j.FinishedAt.Format("2006-01-02 15:04:05 MST"))
+	}
+	if j.LastError != "" {
+		fmt.Fprintf(w, "Last error: %s\n", j.LastError)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, j.Payload)
+}
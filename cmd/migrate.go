@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/naveenpatilm/go-clean-arch/database"
+)
+
+// migrationStatus is the JSON form of database.MigrationStatus
+type migrationStatus struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at"`
+}
+
+func newMigrateCommand(opts *options) *cobra.Command {
+	migrate := &cobra.Command{
+		Use:   "migrate",
+		Short: "Inspect and change the database schema",
+	}
+
+	migrate.AddCommand(
+		&cobra.Command{
+			Use:   "status",
+			Short: "List the migrations and when they were applied",
+			Args:  cobra.NoArgs,
+			RunE: func(c *cobra.Command, args []string) error {
+				return withMigrator(opts, func(ctx context.Context, m *database.Migrator) error {
+					list, err := m.Status(ctx)
+					if err != nil {
+						return err
+					}
+					res := make([]migrationStatus, 0, len(list))
+					for _, s := range list {
+						res = append(res, migrationStatus{Version: s.Version, Name: s.Name, AppliedAt: s.AppliedAt})
+					}
+					return opts.print(c.OutOrStdout(), res, func(out io.Writer) {
+						w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+						fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+						for _, s := range res {
+							appliedAt := "pending"
+							if s.AppliedAt != nil {
+								appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+							}
+							fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+						}
+						w.Flush()
+					})
+				})
+			},
+		},
+		&cobra.Command{
+			Use:   "up",
+			Short: "Apply every pending migration",
+			Args:  cobra.NoArgs,
+			RunE: func(c *cobra.Command, args []string) error {
+				return withMigrator(opts, func(ctx context.Context, m *database.Migrator) error {
+					return m.Up(ctx)
+				})
+			},
+		},
+		&cobra.Command{
+			Use:   "down",
+			Short: "Revert the most recent migration",
+			Args:  cobra.NoArgs,
+			RunE: func(c *cobra.Command, args []string) error {
+				return withMigrator(opts, func(ctx context.Context, m *database.Migrator) error {
+					return m.Down(ctx)
+				})
+			},
+		},
+		&cobra.Command{
+			Use:   "to <version>",
+			Short: "Apply or revert migrations until the schema is at version, 0 reverts all",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(c *cobra.Command, args []string) error {
+				version, err := strconv.ParseInt(args[0], 10, 64)
+				if err != nil {
+					return usageError("invalid version %q", args[0])
+				}
+				return withMigrator(opts, func(ctx context.Context, m *database.Migrator) error {
+					return m.To(ctx, version)
+				})
+			},
+		},
+	)
+	return migrate
+}
+
+// withMigrator runs fn with a Migrator on the configured database. Unlike
+// newApp it does not require the schema to be current.
+func withMigrator(opts *options, fn func(ctx context.Context, m *database.Migrator) error) error {
+	cfg, err := opts.loadConfig()
+	if err != nil {
+		return err
+	}
+	c := cfg.DatabaseConfig()
+	if c.Driver == database.Memory {
+		return fail(fmt.Errorf("the %s driver has no schema to migrate", database.Memory))
+	}
+
+	db, err := database.Open(c)
+	if err != nil {
+		return fail(err)
+	}
+	defer db.Close()
+	migrator, err := database.NewMigrator(db.DB(), c.Driver)
+	if err != nil {
+		return fail(err)
+	}
+	return fail(fn(context.Background(), migrator))
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/naveenpatilm/go-clean-arch/outbox/relay"
+	"github.com/naveenpatilm/go-clean-arch/outbox/sink"
+	"github.com/naveenpatilm/go-clean-arch/server"
+)
+
+func newOutboxCommand(opts *options) *cobra.Command {
+	outboxCmd := &cobra.Command{
+		Use:   "outbox",
+		Short: "Deliver the article events saved in the outbox",
+	}
+	outboxCmd.AddCommand(&cobra.Command{
+		Use:   "relay",
+		Short: "Run the outbox relay until SIGINT or SIGTERM",
+		Long: `Run the outbox relay until SIGINT or SIGTERM.
+
+serve already runs a relay unless outbox.relay is false. Several relays may
+run at once: each message is claimed by one of them.`,
+		Args: cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			return withApp(opts, func(ctx context.Context, a *app) error {
+				ctx, stop := server.WithSignals(ctx)
+				defer stop()
+				return runRelay(ctx, a)
+			})
+		},
+	})
+	return outboxCmd
+}
+
+// runRelay delivers the outbox messages to the configured sink until ctx is done
+func runRelay(ctx context.Context, a *app) error {
+	outboxSink, err := sink.New(a.Config.OutboxSinkConfig())
+	if err != nil {
+		return err
+	}
+	c := a.Config.RelayConfig()
+	// a batch holds the next poll up for at most its lease
+	c.Polled = a.watchLoop("outbox_relay", c.PollInterval, c.Lease)
+	return relay.NewRelay(a.OutboxRepo, outboxSink, c).Run(ctx)
+}
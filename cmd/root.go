@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/naveenpatilm/go-clean-arch/config"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+// Exit codes of the engine binary
+const (
+	ExitOK       = 0
+	ExitFailure  = 1
+	ExitUsage    = 2
+	ExitConfig   = 3
+	ExitNotFound = 4
+	ExitConflict = 5
+)
+
+// Output formats selected with --output
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// options are the flags shared by every command
+type options struct {
+	configPath string
+	profile    string
+	output     string
+}
+
+// exitError carries the exit code of a command that failed after its
+// arguments were accepted. Any other error returned by cobra is a usage error.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+// fail wraps err with the exit code matching it
+func fail(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*exitError); ok {
+		return err
+	}
+	code := ExitFailure
+	switch err {
+	case models.ErrNotFound:
+		code = ExitNotFound
+	case models.ErrConflict:
+		code = ExitConflict
+	default:
+		if _, ok := err.(*config.ValidationError); ok {
+			code = ExitConfig
+		}
+	}
+	return &exitError{code: code, err: err}
+}
+
+// usageError reports arguments that cobra can not validate by itself
+func usageError(format string, args ...interface{}) error {
+	return fmt.Errorf(format, args...)
+}
+
+// NewRootCommand will create the engine command with every subcommand
+func NewRootCommand() *cobra.Command {
+	opts := &options{}
+	root := &cobra.Command{
+		Use:           "engine",
+		Short:         "Article management service",
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		PersistentPreRunE: func(c *cobra.Command, args []string) error {
+			if opts.output != OutputText && opts.output != OutputJSON {
+				return usageError("invalid --output %q, must be %s or %s", opts.output, OutputText, OutputJSON)
+			}
+			return nil
+		},
+	}
+
+	flags := root.PersistentFlags()
+	flags.StringVarP(&opts.configPath, "config", "c", envOr("APP_CONFIG", "config.json"), "configuration file, APP_CONFIG")
+	flags.StringVarP(&opts.profile, "profile", "p", os.Getenv("APP_PROFILE"), "configuration profile merged over the file, APP_PROFILE")
+	flags.StringVarP(&opts.output, "output", "o", OutputText, "output format, text or json")
+
+	root.AddCommand(
+		newServeCommand(opts),
+		newMigrateCommand(opts),
+		newSeedCommand(opts),
+		newConfigCommand(opts),
+		newAdminCommand(opts),
+		newOutboxCommand(opts),
+		newWebhooksCommand(opts),
+		newJobsCommand(opts),
+	)
+	return root
+}
+
+// Execute runs the command line of the process and returns its exit code
+func Execute() int {
+	return Run(NewRootCommand(), os.Args[1:])
+}
+
+// Run runs root with args and returns the exit code. Errors are written to
+// the error output of root, as JSON when --output json is set.
+func Run(root *cobra.Command, args []string) int {
+	root.SetArgs(args)
+	c, err := root.ExecuteC()
+	if err == nil {
+		return ExitOK
+	}
+
+	code := ExitUsage
+	if e, ok := err.(*exitError); ok {
+		code = e.code
+	}
+	out := root.ErrOrStderr()
+	if output, _ := root.PersistentFlags().GetString("output"); output == OutputJSON {
+		writeJSON(out, map[string]interface{}{"error": err.Error(), "code": code})
+		return code
+	}
+	fmt.Fprintln(out, "Error:", err)
+	if code == ExitUsage {
+		fmt.Fprintf(out, "Run '%s --help' for usage.\n", c.CommandPath())
+	}
+	return code
+}
+
+// loadConfig loads the configuration selected by the shared flags
+func (o *options) loadConfig() (*config.Config, error) {
+	cfg, err := config.Load(o.configPath, o.profile)
+	return cfg, fail(err)
+}
+
+// print writes v as indented JSON, or calls text when the output is text
+func (o *options) print(w io.Writer, v interface{}, text func(io.Writer)) error {
+	if o.output == OutputJSON {
+		return writeJSON(w, v)
+	}
+	text(w)
+	return nil
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// envOr returns the environment variable key, or fallback when it is not set
+func envOr(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
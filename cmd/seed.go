@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/naveenpatilm/go-clean-arch/seed"
+)
+
+func newSeedCommand(opts *options) *cobra.Command {
+	seedOpts := seed.Options{}
+	var until string
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Fill an empty database with realistic authors and articles",
+		Long: `Fill an empty database with realistic authors and articles.
+
+The same --seed, --until and --days always generate the same data. Data is
+stored through the repositories, so running seed twice on the same database
+fails on the duplicated titles.`,
+		Args: cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			if seedOpts.Authors < 1 || seedOpts.Articles < 0 || seedOpts.Days < 1 {
+				return usageError("--authors and --days must be positive and --articles not negative")
+			}
+			if until != "" {
+				t, err := time.Parse("2006-01-02", until)
+				if err != nil {
+					return usageError("invalid --until %q, expected YYYY-MM-DD", until)
+				}
+				seedOpts.Until = t
+			}
+			return withApp(opts, func(ctx context.Context, a *app) error {
+				res, err := seed.Run(ctx, a.AuthorRepo, a.ArticleRepo, seedOpts)
+				if err != nil {
+					return err
+				}
+				return opts.print(c.OutOrStdout(), res, func(w io.Writer) {
+					fmt.Fprintf(w, "created %d authors and %d articles\n", res.Authors, res.Articles)
+				})
+			})
+		},
+	}
+	flags := cmd.Flags()
+	flags.IntVar(&seedOpts.Authors, "authors", 10, "number of authors to create")
+	flags.IntVar(&seedOpts.Articles, "articles", 100, "number of articles to create")
+	flags.Int64Var(&seedOpts.Seed, "seed", 1, "seed of the generator")
+	flags.StringVar(&until, "until", seed.DefaultUntil.Format("2006-01-02"), "date of the most recent article, YYYY-MM-DD")
+	flags.IntVar(&seedOpts.Days, "days", 365, "number of days over which articles are spread")
+	return cmd
+}
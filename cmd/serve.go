@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/naveenpatilm/go-clean-arch/admin"
+	_articleHttpDeliver "github.com/naveenpatilm/go-clean-arch/article/delivery/http"
+	_healthHttpDeliver "github.com/naveenpatilm/go-clean-arch/health/delivery/http"
+	_jobHttpDeliver "github.com/naveenpatilm/go-clean-arch/job/delivery/http"
+	"github.com/naveenpatilm/go-clean-arch/middleware"
+	"github.com/naveenpatilm/go-clean-arch/server"
+	_streamBroker "github.com/naveenpatilm/go-clean-arch/stream/broker"
+	_streamHttpDeliver "github.com/naveenpatilm/go-clean-arch/stream/delivery/http"
+	_webhookHttpDeliver "github.com/naveenpatilm/go-clean-arch/webhook/delivery/http"
+)
+
+func newServeCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Start the HTTP server until SIGINT or SIGTERM",
+		Args:  cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			return fail(serve(c, opts))
+		},
+	}
+}
+
+// serve runs the server and drains it once a signal is received
+func serve(c *cobra.Command, opts *options) error {
+	cfg, err := opts.loadConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.Debug {
+		fmt.Fprintln(c.OutOrStdout(), "Service RUN on DEBUG mode")
+		fmt.Fprintln(c.OutOrStdout(), cfg)
+	}
+
+	a, err := newApp(cfg)
+	if err != nil {
+		return err
+	}
+	// the server has drained when the database is closed
+	defer a.Close()
+
+	router := mux.NewRouter()
+	middL := middleware.InitMiddleware()
+	router.Use(middL.Tracing)
+	_articleHttpDeliver.NewArticleHttpHandler(router, a.Articles)
+	_healthHttpDeliver.NewHealthHttpHandler(router, a.Health)
+	if cfg.Webhooks.API {
+		_webhookHttpDeliver.NewWebhookHttpHandler(router, a.Webhooks, cfg.Webhooks.Token)
+	}
+	if cfg.Jobs.API {
+		_jobHttpDeliver.NewJobHttpHandler(router, a.Jobs, cfg.Jobs.Token)
+	}
+
+	serverConfig := cfg.ServerConfig()
+	brokerConfig := cfg.BrokerConfig()
+	brokerConfig.Polled = a.watchLoop("stream_broker", brokerConfig.PollInterval, 0)
+	broker := _streamBroker.NewBroker(a.StreamLog, brokerConfig)
+	_streamHttpDeliver.NewStreamHttpHandler(router, a.StreamLog, broker, _streamHttpDeliver.Config{
+		Heartbeat: time.Duration(cfg.Stream.Heartbeat) * time.Second,
+	})
+	ctx, stop := server.WithSignals(context.Background())
+	defer stop()
+	ctx, stopServing := server.WithDrainDelay(ctx, serverConfig.DrainDelay, a.Health.Drain)
+	defer stopServing()
+
+	// every listener stops when one of them fails
+	g, ctx := errgroup.WithContext(ctx)
+	srv := server.New(serverConfig, middL.CORS(router))
+	if serverConfig.TLS != nil {
+		certs, err := server.NewCertReloader(serverConfig.TLS.CertFile, serverConfig.TLS.KeyFile)
+		if err != nil {
+			return err
+		}
+		if srv.TLSConfig, err = server.NewTLSConfig(*serverConfig.TLS, certs); err != nil {
+			return err
+		}
+		g.Go(func() error {
+			certs.Watch(ctx, serverConfig.TLS.ReloadInterval)
+			return nil
+		})
+	}
+	g.Go(func() error {
+		return server.ListenAndServe(ctx, srv, serverConfig.ShutdownGrace)
+	})
+	if serverConfig.RedirectAddress != "" {
+		redirect := server.NewRedirect(serverConfig, serverConfig.RedirectAddress, serverConfig.Address)
+		g.Go(func() error {
+			return server.ListenAndServe(ctx, redirect, serverConfig.ShutdownGrace)
+		})
+	}
+	// closing the streams lets the server drain
+	g.Go(func() error {
+		return broker.Run(ctx)
+	})
+	if cfg.Outbox.Relay {
+		g.Go(func() error {
+			return runRelay(ctx, a)
+		})
+	}
+	if cfg.Webhooks.Dispatcher {
+		g.Go(func() error {
+			return runDispatcher(ctx, a)
+		})
+	}
+	if cfg.Jobs.Worker {
+		g.Go(func() error {
+			return runWorker(ctx, a)
+		})
+	}
+	if cfg.Admin.Address != "" {
+		adminConfig := serverConfig
+		// CPU profiles and traces stream for as long as they were asked for
+		adminConfig.Address, adminConfig.WriteTimeout = cfg.Admin.Address, 0
+		adminSrv := server.New(adminConfig, newAdminHandler(a, router))
+		g.Go(func() error {
+			return server.ListenAndServe(ctx, adminSrv, serverConfig.ShutdownGrace)
+		})
+	}
+	return g.Wait()
+}
+
+// newAdminHandler exposes pprof, the runtime and cache stats, the redacted
+// configuration and the routes of router
+func newAdminHandler(a *app, router *mux.Router) http.Handler {
+	vars := map[string]func() interface{}{}
+	if a.Cache != nil {
+		vars["article_cache"] = func() interface{} { return a.Cache.Stats() }
+	}
+	return admin.NewHandler(admin.Config{
+		Token:    a.Config.Admin.Token,
+		Settings: a.Config.Redacted(),
+		Router:   router,
+		Vars:     vars,
+	})
+}
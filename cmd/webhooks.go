@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/naveenpatilm/go-clean-arch/server"
+	"github.com/naveenpatilm/go-clean-arch/webhook/dispatcher"
+)
+
+func newWebhooksCommand(opts *options) *cobra.Command {
+	webhooksCmd := &cobra.Command{
+		Use:   "webhooks",
+		Short: "Send the webhook deliveries",
+	}
+	webhooksCmd.AddCommand(&cobra.Command{
+		Use:   "dispatch",
+		Short: "Run the webhook dispatcher until SIGINT or SIGTERM",
+		Long: `Run the webhook dispatcher until SIGINT or SIGTERM.
+
+serve already runs a dispatcher unless webhooks.dispatcher is false. Several
+dispatchers may run at once: each delivery is claimed by one of them.`,
+		Args: cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			return withApp(opts, func(ctx context.Context, a *app) error {
+				ctx, stop := server.WithSignals(ctx)
+				defer stop()
+				return runDispatcher(ctx, a)
+			})
+		},
+	})
+	return webhooksCmd
+}
+
+// runDispatcher sends the webhook deliveries until ctx is done
+func runDispatcher(ctx context.Context, a *app) error {
+	c := a.Config.DispatcherConfig()
+	c.Polled = a.watchLoop("webhook_dispatcher", c.PollInterval, c.Lease)
+	return dispatcher.NewDispatcher(a.WebhookRepo, c).Run(ctx)
+}
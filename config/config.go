@@ -0,0 +1,699 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/naveenpatilm/go-clean-arch/database"
+	"github.com/naveenpatilm/go-clean-arch/job/worker"
+	"github.com/naveenpatilm/go-clean-arch/outbox/relay"
+	"github.com/naveenpatilm/go-clean-arch/outbox/sink"
+	"github.com/naveenpatilm/go-clean-arch/server"
+	"github.com/naveenpatilm/go-clean-arch/stream"
+	"github.com/naveenpatilm/go-clean-arch/stream/broker"
+	"github.com/naveenpatilm/go-clean-arch/tracing"
+	"github.com/naveenpatilm/go-clean-arch/webhook/dispatcher"
+)
+
+// EnvPrefix starts the name of every environment variable overriding a value,
+// e.g. APP_DATABASE_HOST overrides database.host
+const EnvPrefix = "APP"
+
+// redacted replaces secrets when the configuration is dumped
+const redacted = "*****"
+
+// Config is the whole configuration of the service. Durations are in seconds.
+type Config struct {
+	Debug      bool             `mapstructure:"debug" json:"debug"`
+	Server     ServerConfig     `mapstructure:"server" json:"server"`
+	Health     HealthConfig     `mapstructure:"health" json:"health"`
+	Context    ContextConfig    `mapstructure:"context" json:"context"`
+	Pagination PaginationConfig `mapstructure:"pagination" json:"pagination"`
+	Cache      CacheConfig      `mapstructure:"cache" json:"cache"`
+	Database   DatabaseConfig   `mapstructure:"database" json:"database"`
+	Tracing    TracingConfig    `mapstructure:"tracing" json:"tracing"`
+	Admin      AdminConfig      `mapstructure:"admin" json:"admin"`
+	Events     EventsConfig     `mapstructure:"events" json:"events"`
+	Outbox     OutboxConfig     `mapstructure:"outbox" json:"outbox"`
+	Webhooks   WebhooksConfig   `mapstructure:"webhooks" json:"webhooks"`
+	Stream     StreamConfig     `mapstructure:"stream" json:"stream"`
+	Jobs       JobsConfig       `mapstructure:"jobs" json:"jobs"`
+}
+
+// ServerConfig configures the http server
+type ServerConfig struct {
+	Address       string `mapstructure:"address" json:"address"`
+	ReadTimeout   int    `mapstructure:"read_timeout" json:"read_timeout"`
+	WriteTimeout  int    `mapstructure:"write_timeout" json:"write_timeout"`
+	IdleTimeout   int    `mapstructure:"idle_timeout" json:"idle_timeout"`
+	DrainDelay    int    `mapstructure:"drain_delay" json:"drain_delay"`
+	ShutdownGrace int    `mapstructure:"shutdown_grace" json:"shutdown_grace"`
+	// RedirectAddress is where plain HTTP is redirected to HTTPS, when set
+	RedirectAddress string    `mapstructure:"redirect_address" json:"redirect_address"`
+	TLS             TLSConfig `mapstructure:"tls" json:"tls"`
+}
+
+// TLSConfig configures HTTPS. The server is plain HTTP when CertFile is empty.
+type TLSConfig struct {
+	CertFile       string   `mapstructure:"cert_file" json:"cert_file"`
+	KeyFile        string   `mapstructure:"key_file" json:"key_file"`
+	MinVersion     string   `mapstructure:"min_version" json:"min_version"`
+	CipherSuites   []string `mapstructure:"cipher_suites" json:"cipher_suites"`
+	ReloadInterval int      `mapstructure:"reload_interval" json:"reload_interval"`
+}
+
+// HealthConfig configures the readiness checks
+type HealthConfig struct {
+	Timeout int `mapstructure:"timeout" json:"timeout"`
+	// MissedPolls is how many poll intervals a background loop may go
+	// without a successful poll before the service is unready
+	MissedPolls int `mapstructure:"missed_polls" json:"missed_polls"`
+}
+
+// ContextConfig configures the usecases
+type ContextConfig struct {
+	Timeout int `mapstructure:"timeout" json:"timeout"`
+}
+
+// PaginationConfig configures article listing
+type PaginationConfig struct {
+	MaxSize int64 `mapstructure:"max_size" json:"max_size"`
+}
+
+// CacheConfig configures the article cache. A zero Size disables it.
+type CacheConfig struct {
+	Size int `mapstructure:"size" json:"size"`
+	TTL  int `mapstructure:"ttl" json:"ttl"`
+}
+
+// DatabaseConfig configures the storage backend
+type DatabaseConfig struct {
+	Driver               string   `mapstructure:"driver" json:"driver"`
+	Host                 string   `mapstructure:"host" json:"host"`
+	Port                 string   `mapstructure:"port" json:"port"`
+	User                 string   `mapstructure:"user" json:"user"`
+	Pass                 string   `mapstructure:"pass" json:"pass"`
+	Name                 string   `mapstructure:"name" json:"name"`
+	SSLMode              string   `mapstructure:"ssl_mode" json:"ssl_mode"`
+	Path                 string   `mapstructure:"path" json:"path"`
+	Replicas             []string `mapstructure:"replicas" json:"replicas"`
+	ReplicaCheckInterval int      `mapstructure:"replica_check_interval" json:"replica_check_interval"`
+}
+
+// TracingConfig configures the export of OpenTelemetry spans
+type TracingConfig struct {
+	Exporter    string  `mapstructure:"exporter" json:"exporter"`
+	Path        string  `mapstructure:"path" json:"path"`
+	ServiceName string  `mapstructure:"service_name" json:"service_name"`
+	SampleRatio float64 `mapstructure:"sample_ratio" json:"sample_ratio"`
+}
+
+// AdminConfig configures the admin server. It is disabled when Address is empty.
+type AdminConfig struct {
+	Address string `mapstructure:"address" json:"address"`
+	Token   string `mapstructure:"token" json:"token"`
+}
+
+// EventsConfig configures the in-process bus of the article events
+type EventsConfig struct {
+	// Async handles the events in the background instead of during the request
+	Async bool `mapstructure:"async" json:"async"`
+	// Buffer is how many events may wait for each subscriber of an async bus
+	Buffer int `mapstructure:"buffer" json:"buffer"`
+}
+
+// OutboxConfig configures the relay delivering the article events saved in the outbox
+type OutboxConfig struct {
+	// Relay runs the relay in the serve command
+	Relay bool `mapstructure:"relay" json:"relay"`
+	// Sink is log, file or http
+	Sink         string `mapstructure:"sink" json:"sink"`
+	Path         string `mapstructure:"path" json:"path"`
+	URL          string `mapstructure:"url" json:"url"`
+	Timeout      int    `mapstructure:"timeout" json:"timeout"`
+	PollInterval int    `mapstructure:"poll_interval" json:"poll_interval"`
+	BatchSize    int    `mapstructure:"batch_size" json:"batch_size"`
+	// Lease is how long a relay may take to deliver the batch it claimed
+	Lease      int `mapstructure:"lease" json:"lease"`
+	MinBackoff int `mapstructure:"min_backoff" json:"min_backoff"`
+	MaxBackoff int `mapstructure:"max_backoff" json:"max_backoff"`
+	// Retention is how long dispatched messages are kept, 0 keeps them forever
+	Retention int `mapstructure:"retention" json:"retention"`
+}
+
+// WebhooksConfig configures the webhook API and the dispatcher sending the deliveries
+type WebhooksConfig struct {
+	// API serves the webhook API in the serve command. Token is then required.
+	API   bool   `mapstructure:"api" json:"api"`
+	Token string `mapstructure:"token" json:"token"`
+	// AllowPrivate accepts the webhooks of the internal network, e.g. for development
+	AllowPrivate bool `mapstructure:"allow_private" json:"allow_private"`
+	// Dispatcher runs the dispatcher in the serve command
+	Dispatcher   bool `mapstructure:"dispatcher" json:"dispatcher"`
+	Timeout      int  `mapstructure:"timeout" json:"timeout"`
+	PollInterval int  `mapstructure:"poll_interval" json:"poll_interval"`
+	BatchSize    int  `mapstructure:"batch_size" json:"batch_size"`
+	Concurrency  int  `mapstructure:"concurrency" json:"concurrency"`
+	// Lease is how long a dispatcher may take to send the batch it claimed
+	Lease       int `mapstructure:"lease" json:"lease"`
+	MinBackoff  int `mapstructure:"min_backoff" json:"min_backoff"`
+	MaxBackoff  int `mapstructure:"max_backoff" json:"max_backoff"`
+	MaxAttempts int `mapstructure:"max_attempts" json:"max_attempts"`
+}
+
+// StreamConfig configures the Server-Sent Events stream of the article changes
+type StreamConfig struct {
+	// Log is memory or outbox, the history the clients resume from
+	Log string `mapstructure:"log" json:"log"`
+	// LogSize is how many events the memory log keeps
+	LogSize int `mapstructure:"log_size" json:"log_size"`
+	// Buffer is how many events may wait for a client before it is disconnected
+	Buffer       int `mapstructure:"buffer" json:"buffer"`
+	Heartbeat    int `mapstructure:"heartbeat" json:"heartbeat"`
+	PollInterval int `mapstructure:"poll_interval" json:"poll_interval"`
+	// Settle is how old an outbox message must be to be streamed, see NewOutboxLog
+	Settle int `mapstructure:"settle" json:"settle"`
+}
+
+// JobsConfig configures the job API and the worker running the background jobs
+type JobsConfig struct {
+	// API serves the job API in the serve command. Token is then required.
+	API   bool   `mapstructure:"api" json:"api"`
+	Token string `mapstructure:"token" json:"token"`
+	// Worker runs the worker in the serve command
+	Worker bool `mapstructure:"worker" json:"worker"`
+	// Queues are the queues the worker runs, as name:concurrency
+	Queues       []string `mapstructure:"queues" json:"queues"`
+	PollInterval int      `mapstructure:"poll_interval" json:"poll_interval"`
+	// Timeout bounds each attempt of a job
+	Timeout     int `mapstructure:"timeout" json:"timeout"`
+	MinBackoff  int `mapstructure:"min_backoff" json:"min_backoff"`
+	MaxBackoff  int `mapstructure:"max_backoff" json:"max_backoff"`
+	MaxAttempts int `mapstructure:"max_attempts" json:"max_attempts"`
+	// ExportDir is where the articles.export jobs write their files
+	ExportDir string `mapstructure:"export_dir" json:"export_dir"`
+}
+
+// defaults are used for every key missing from the files and the environment.
+// Each key must be listed so that its environment variable is looked up.
+var defaults = map[string]interface{}{
+	"debug":                           false,
+	"server.address":                  ":9090",
+	"server.read_timeout":             10,
+	"server.write_timeout":            10,
+	"server.idle_timeout":             60,
+	"server.drain_delay":              5,
+	"server.shutdown_grace":           15,
+	"server.redirect_address":         "",
+	"server.tls.cert_file":            "",
+	"server.tls.key_file":             "",
+	"server.tls.min_version":          "1.2",
+	"server.tls.cipher_suites":        []string{},
+	"server.tls.reload_interval":      10,
+	"health.timeout":                  2,
+	"health.missed_polls":             5,
+	"context.timeout":                 2,
+	"pagination.max_size":             100,
+	"cache.size":                      1000,
+	"cache.ttl":                       60,
+	"database.driver":                 database.Postgres,
+	"database.host":                   "",
+	"database.port":                   "",
+	"database.user":                   "",
+	"database.pass":                   "",
+	"database.name":                   "",
+	"database.ssl_mode":               "disable",
+	"database.path":                   "",
+	"database.replicas":               []string{},
+	"database.replica_check_interval": 5,
+	"tracing.exporter":                tracing.ExporterNone,
+	"tracing.path":                    "traces.jsonl",
+	"tracing.service_name":            "article-service",
+	"tracing.sample_ratio":            1.0,
+	"admin.address":                   "",
+	"admin.token":                     "",
+	"events.async":                    true,
+	"events.buffer":                   1024,
+	"outbox.relay":                    true,
+	"outbox.sink":                     sink.Log,
+	"outbox.path":                     "outbox.jsonl",
+	"outbox.url":                      "",
+	"outbox.timeout":                  10,
+	"outbox.poll_interval":            1,
+	"outbox.batch_size":               100,
+	"outbox.lease":                    300,
+	"outbox.min_backoff":              1,
+	"outbox.max_backoff":              300,
+	"outbox.retention":                604800,
+	"webhooks.api":                    false,
+	"webhooks.token":                  "",
+	"webhooks.allow_private":          false,
+	"webhooks.dispatcher":             true,
+	"webhooks.timeout":                10,
+	"webhooks.poll_interval":          1,
+	"webhooks.batch_size":             50,
+	"webhooks.concurrency":            8,
+	"webhooks.lease":                  300,
+	"webhooks.min_backoff":            10,
+	"webhooks.max_backoff":            3600,
+	"webhooks.max_attempts":           10,
+	"stream.log":                      stream.LogMemory,
+	"stream.log_size":                 1000,
+	"stream.buffer":                   64,
+	"stream.heartbeat":                15,
+	"stream.poll_interval":            1,
+	"stream.settle":                   5,
+	"jobs.api":                        false,
+	"jobs.token":                      "",
+	"jobs.worker":                     true,
+	"jobs.queues":                     []string{"default:4"},
+	"jobs.poll_interval":              1,
+	"jobs.timeout":                    300,
+	"jobs.min_backoff":                10,
+	"jobs.max_backoff":                3600,
+	"jobs.max_attempts":               10,
+	"jobs.export_dir":                 "exports",
+}
+
+// Load reads the configuration from path, then from the file of profile next
+// to it (config.prod.json for profile prod), then from APP_ environment
+// variables. Each source overrides the previous one. path may be missing,
+// but the profile file must exist when profile is set.
+func Load(path, profile string) (*Config, error) {
+	v := viper.New()
+	for key, value := range defaults {
+		v.SetDefault(key, value)
+	}
+
+	v.SetConfigType("json")
+	if _, err := os.Stat(path); err == nil {
+		v.SetConfigFile(path)
+		if err = v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("reading %s: %v", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if profile != "" {
+		profilePath := ProfilePath(path, profile)
+		v.SetConfigFile(profilePath)
+		if err := v.MergeInConfig(); err != nil {
+			return nil, fmt.Errorf("reading profile %q from %s: %v", profile, profilePath, err)
+		}
+	}
+
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	var c Config
+	if err := v.Unmarshal(&c); err != nil {
+		return nil, err
+	}
+	// a list given through the environment is comma separated
+	splitList(v, "database.replicas", &c.Database.Replicas)
+	splitList(v, "server.tls.cipher_suites", &c.Server.TLS.CipherSuites)
+	splitList(v, "jobs.queues", &c.Jobs.Queues)
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// splitList sets list from the comma separated string of key, if it is a string
+func splitList(v *viper.Viper, key string, list *[]string) {
+	value, ok := v.Get(key).(string)
+	if !ok {
+		return
+	}
+	*list = nil
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*list = append(*list, item)
+		}
+	}
+}
+
+// ProfilePath returns the file holding the values of profile, next to path
+func ProfilePath(path, profile string) string {
+	ext := filepath.Ext(path)
+	if ext == "" {
+		ext = ".json"
+	}
+	return strings.TrimSuffix(path, filepath.Ext(path)) + "." + profile + ext
+}
+
+// ValidationError lists every problem found in a configuration
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Validate returns a *ValidationError when a value is missing or invalid
+func (c *Config) Validate() error {
+	var problems []string
+	required := func(key, value string) {
+		if value == "" {
+			problems = append(problems, key+" is required")
+		}
+	}
+	notNegative := func(key string, value int) {
+		if value < 0 {
+			problems = append(problems, fmt.Sprintf("%s must not be negative, got %d", key, value))
+		}
+	}
+	positive := func(key string, value int) {
+		if value <= 0 {
+			problems = append(problems, fmt.Sprintf("%s must be positive, got %d", key, value))
+		}
+	}
+
+	required("server.address", c.Server.Address)
+	notNegative("server.read_timeout", c.Server.ReadTimeout)
+	notNegative("server.write_timeout", c.Server.WriteTimeout)
+	notNegative("server.idle_timeout", c.Server.IdleTimeout)
+	notNegative("server.drain_delay", c.Server.DrainDelay)
+	notNegative("server.shutdown_grace", c.Server.ShutdownGrace)
+	if tlsConfig := c.Server.TLS; tlsConfig.CertFile != "" || tlsConfig.KeyFile != "" {
+		required("server.tls.cert_file", tlsConfig.CertFile)
+		required("server.tls.key_file", tlsConfig.KeyFile)
+		if !contains(server.TLSVersions(), tlsConfig.MinVersion) {
+			problems = append(problems, fmt.Sprintf("server.tls.min_version must be one of %s, got %q",
+				strings.Join(server.TLSVersions(), ", "), tlsConfig.MinVersion))
+		}
+		for _, name := range tlsConfig.CipherSuites {
+			if _, ok := server.CipherSuite(name); !ok {
+				problems = append(problems, fmt.Sprintf("server.tls.cipher_suites has an unknown or insecure suite %q", name))
+			}
+		}
+		notNegative("server.tls.reload_interval", tlsConfig.ReloadInterval)
+	} else if c.Server.RedirectAddress != "" {
+		problems = append(problems, "server.redirect_address needs server.tls.cert_file and server.tls.key_file")
+	}
+	notNegative("health.timeout", c.Health.Timeout)
+	positive("health.missed_polls", c.Health.MissedPolls)
+	if c.Context.Timeout <= 0 {
+		problems = append(problems, fmt.Sprintf("context.timeout must be positive, got %d", c.Context.Timeout))
+	}
+	if c.Pagination.MaxSize <= 0 {
+		problems = append(problems, fmt.Sprintf("pagination.max_size must be positive, got %d", c.Pagination.MaxSize))
+	}
+	notNegative("cache.size", c.Cache.Size)
+	if c.Cache.Size > 0 && c.Cache.TTL <= 0 {
+		problems = append(problems, "cache.ttl must be positive when the cache is enabled")
+	}
+
+	switch c.Database.Driver {
+	case database.Postgres, database.Mysql:
+		required("database.host", c.Database.Host)
+		required("database.port", c.Database.Port)
+		required("database.user", c.Database.User)
+		required("database.name", c.Database.Name)
+	case database.Sqlite:
+		required("database.path", c.Database.Path)
+	case database.Memory:
+	default:
+		problems = append(problems, fmt.Sprintf("database.driver must be one of %s, %s, %s or %s, got %q",
+			database.Postgres, database.Mysql, database.Sqlite, database.Memory, c.Database.Driver))
+	}
+	if len(c.Database.Replicas) > 0 && c.Database.Driver != database.Postgres && c.Database.Driver != database.Mysql {
+		problems = append(problems, "database.replicas are only supported by postgres and mysql")
+	}
+	notNegative("database.replica_check_interval", c.Database.ReplicaCheckInterval)
+
+	switch c.Tracing.Exporter {
+	case "", tracing.ExporterNone, tracing.ExporterStdout:
+	case tracing.ExporterOTLPFile:
+		required("tracing.path", c.Tracing.Path)
+	default:
+		problems = append(problems, fmt.Sprintf("tracing.exporter must be one of %s, %s or %s, got %q",
+			tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLPFile, c.Tracing.Exporter))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		problems = append(problems, fmt.Sprintf("tracing.sample_ratio must be between 0 and 1, got %g", c.Tracing.SampleRatio))
+	}
+	if c.Events.Async && c.Events.Buffer <= 0 {
+		problems = append(problems, fmt.Sprintf("events.buffer must be positive, got %d", c.Events.Buffer))
+	}
+	switch c.Outbox.Sink {
+	case sink.Log:
+	case sink.File:
+		required("outbox.path", c.Outbox.Path)
+	case sink.HTTP:
+		required("outbox.url", c.Outbox.URL)
+		positive("outbox.timeout", c.Outbox.Timeout)
+	default:
+		problems = append(problems, fmt.Sprintf("outbox.sink must be one of %s, %s or %s, got %q",
+			sink.Log, sink.File, sink.HTTP, c.Outbox.Sink))
+	}
+	positive("outbox.poll_interval", c.Outbox.PollInterval)
+	positive("outbox.batch_size", c.Outbox.BatchSize)
+	positive("outbox.lease", c.Outbox.Lease)
+	positive("outbox.min_backoff", c.Outbox.MinBackoff)
+	if c.Outbox.MaxBackoff < c.Outbox.MinBackoff {
+		problems = append(problems, "outbox.max_backoff must not be less than outbox.min_backoff")
+	}
+	notNegative("outbox.retention", c.Outbox.Retention)
+	if c.Webhooks.API {
+		required("webhooks.token", c.Webhooks.Token)
+	}
+	positive("webhooks.timeout", c.Webhooks.Timeout)
+	positive("webhooks.poll_interval", c.Webhooks.PollInterval)
+	positive("webhooks.batch_size", c.Webhooks.BatchSize)
+	positive("webhooks.concurrency", c.Webhooks.Concurrency)
+	positive("webhooks.lease", c.Webhooks.Lease)
+	positive("webhooks.min_backoff", c.Webhooks.MinBackoff)
+	if c.Webhooks.MaxBackoff < c.Webhooks.MinBackoff {
+		problems = append(problems, "webhooks.max_backoff must not be less than webhooks.min_backoff")
+	}
+	positive("webhooks.max_attempts", c.Webhooks.MaxAttempts)
+	switch c.Stream.Log {
+	case stream.LogMemory:
+		positive("stream.log_size", c.Stream.LogSize)
+	case stream.LogOutbox:
+		if c.Database.Driver == database.Memory {
+			problems = append(problems, "stream.log outbox needs a SQL database.driver")
+		}
+		// a transaction lasts at most context.timeout
+		if c.Stream.Settle <= c.Context.Timeout {
+			problems = append(problems, fmt.Sprintf("stream.settle must be greater than context.timeout, got %d", c.Stream.Settle))
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("stream.log must be %s or %s, got %q", stream.LogMemory, stream.LogOutbox, c.Stream.Log))
+	}
+	positive("stream.buffer", c.Stream.Buffer)
+	notNegative("stream.heartbeat", c.Stream.Heartbeat)
+	positive("stream.poll_interval", c.Stream.PollInterval)
+	if c.Jobs.API {
+		required("jobs.token", c.Jobs.Token)
+	}
+	if len(c.Jobs.Queues) == 0 {
+		problems = append(problems, "jobs.queues is required")
+	}
+	for _, queue := range c.Jobs.Queues {
+		if _, _, err := parseQueue(queue); err != nil {
+			problems = append(problems, "jobs.queues "+err.Error())
+		}
+	}
+	positive("jobs.poll_interval", c.Jobs.PollInterval)
+	positive("jobs.timeout", c.Jobs.Timeout)
+	positive("jobs.min_backoff", c.Jobs.MinBackoff)
+	if c.Jobs.MaxBackoff < c.Jobs.MinBackoff {
+		problems = append(problems, "jobs.max_backoff must not be less than jobs.min_backoff")
+	}
+	positive("jobs.max_attempts", c.Jobs.MaxAttempts)
+	required("jobs.export_dir", c.Jobs.ExportDir)
+	// the admin endpoints must never be reachable from the public listener
+	if c.Admin.Address != "" && (c.Admin.Address == c.Server.Address || c.Admin.Address == c.Server.RedirectAddress) {
+		problems = append(problems, fmt.Sprintf("admin.address must differ from the server addresses, got %q", c.Admin.Address))
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// parseQueue reads the name and the concurrency of a queue given as name:concurrency
+func parseQueue(queue string) (string, int, error) {
+	i := strings.LastIndex(queue, ":")
+	if i <= 0 {
+		return "", 0, fmt.Errorf("must be given as name:concurrency, got %q", queue)
+	}
+	concurrency, err := strconv.Atoi(queue[i+1:])
+	if err != nil || concurrency <= 0 {
+		return "", 0, fmt.Errorf("must have a positive concurrency, got %q", queue)
+	}
+	return queue[:i], concurrency, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// passwordInDSN matches the password of a postgres (password=...) or mysql (user:password@) DSN
+var passwordInDSN = regexp.MustCompile(`(password=)\S+|(^[^:@/]*:)[^@]*(@)`)
+
+// Redacted returns a copy of c that is safe to print
+func (c Config) Redacted() Config {
+	if c.Database.Pass != "" {
+		c.Database.Pass = redacted
+	}
+	replicas := make([]string, len(c.Database.Replicas))
+	for i, dsn := range c.Database.Replicas {
+		replicas[i] = passwordInDSN.ReplaceAllString(dsn, "${1}${2}"+redacted+"${3}")
+	}
+	c.Database.Replicas = replicas
+	if c.Admin.Token != "" {
+		c.Admin.Token = redacted
+	}
+	if c.Webhooks.Token != "" {
+		c.Webhooks.Token = redacted
+	}
+	if c.Jobs.Token != "" {
+		c.Jobs.Token = redacted
+	}
+	return c
+}
+
+// String dumps the redacted configuration as JSON
+func (c Config) String() string {
+	b, err := json.MarshalIndent(c.Redacted(), "", "  ")
+	if err != nil {
+		return err.Error()
+	}
+	return string(b)
+}
+
+// DatabaseConfig returns the settings of database.Open
+func (c *Config) DatabaseConfig() database.Config {
+	return database.Config{
+		Driver:               c.Database.Driver,
+		Host:                 c.Database.Host,
+		Port:                 c.Database.Port,
+		User:                 c.Database.User,
+		Pass:                 c.Database.Pass,
+		Name:                 c.Database.Name,
+		SSLMode:              c.Database.SSLMode,
+		Path:                 c.Database.Path,
+		Replicas:             c.Database.Replicas,
+		ReplicaCheckInterval: seconds(c.Database.ReplicaCheckInterval),
+	}
+}
+
+// OutboxSinkConfig returns the settings of sink.New
+func (c *Config) OutboxSinkConfig() sink.Config {
+	return sink.Config{
+		Kind:    c.Outbox.Sink,
+		Path:    c.Outbox.Path,
+		URL:     c.Outbox.URL,
+		Timeout: seconds(c.Outbox.Timeout),
+	}
+}
+
+// RelayConfig returns the settings of relay.NewRelay
+func (c *Config) RelayConfig() relay.Config {
+	return relay.Config{
+		PollInterval: seconds(c.Outbox.PollInterval),
+		BatchSize:    c.Outbox.BatchSize,
+		Lease:        seconds(c.Outbox.Lease),
+		MinBackoff:   seconds(c.Outbox.MinBackoff),
+		MaxBackoff:   seconds(c.Outbox.MaxBackoff),
+		Retention:    seconds(c.Outbox.Retention),
+	}
+}
+
+// DispatcherConfig returns the settings of dispatcher.NewDispatcher
+func (c *Config) DispatcherConfig() dispatcher.Config {
+	return dispatcher.Config{
+		PollInterval: seconds(c.Webhooks.PollInterval),
+		BatchSize:    c.Webhooks.BatchSize,
+		Concurrency:  c.Webhooks.Concurrency,
+		Timeout:      seconds(c.Webhooks.Timeout),
+		Lease:        seconds(c.Webhooks.Lease),
+		MinBackoff:   seconds(c.Webhooks.MinBackoff),
+		MaxBackoff:   seconds(c.Webhooks.MaxBackoff),
+		MaxAttempts:  c.Webhooks.MaxAttempts,
+		AllowPrivate: c.Webhooks.AllowPrivate,
+	}
+}
+
+// BrokerConfig returns the settings of broker.NewBroker
+func (c *Config) BrokerConfig() broker.Config {
+	return broker.Config{
+		PollInterval: seconds(c.Stream.PollInterval),
+		Buffer:       c.Stream.Buffer,
+	}
+}
+
+// WorkerConfig returns the settings of worker.NewWorker
+func (c *Config) WorkerConfig() worker.Config {
+	queues := map[string]int{}
+	for _, queue := range c.Jobs.Queues {
+		if name, concurrency, err := parseQueue(queue); err == nil {
+			queues[name] = concurrency
+		}
+	}
+	return worker.Config{
+		Queues:       queues,
+		PollInterval: seconds(c.Jobs.PollInterval),
+		Timeout:      seconds(c.Jobs.Timeout),
+		MinBackoff:   seconds(c.Jobs.MinBackoff),
+		MaxBackoff:   seconds(c.Jobs.MaxBackoff),
+	}
+}
+
+// TracingConfig returns the settings of tracing.Setup
+func (c *Config) TracingConfig() tracing.Config {
+	return tracing.Config{
+		Exporter:    c.Tracing.Exporter,
+		Path:        c.Tracing.Path,
+		ServiceName: c.Tracing.ServiceName,
+		SampleRatio: c.Tracing.SampleRatio,
+	}
+}
+
+// ServerConfig returns the settings of server.New
+func (c *Config) ServerConfig() server.Config {
+	res := server.Config{
+		Address:         c.Server.Address,
+		ReadTimeout:     seconds(c.Server.ReadTimeout),
+		WriteTimeout:    seconds(c.Server.WriteTimeout),
+		IdleTimeout:     seconds(c.Server.IdleTimeout),
+		DrainDelay:      seconds(c.Server.DrainDelay),
+		ShutdownGrace:   seconds(c.Server.ShutdownGrace),
+		RedirectAddress: c.Server.RedirectAddress,
+	}
+	if c.Server.TLS.CertFile != "" {
+		res.TLS = &server.TLSConfig{
+			CertFile:       c.Server.TLS.CertFile,
+			KeyFile:        c.Server.TLS.KeyFile,
+			MinVersion:     c.Server.TLS.MinVersion,
+			CipherSuites:   c.Server.TLS.CipherSuites,
+			ReloadInterval: seconds(c.Server.TLS.ReloadInterval),
+		}
+	}
+	return res
+}
+
+func seconds(n int) time.Duration {
+	return time.Duration(n) * time.Second
+}
@@ -0,0 +1,234 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/config"
+)
+
+const baseConfig = `{
+  "server": {"address": ":9090"},
+  "database": {
+    "driver": "postgres",
+    "host": "localhost",
+    "port": "5432",
+    "user": "postgres",
+    "pass": "secret",
+    "name": "article"
+  }
+}`
+
+func writeConfig(t *testing.T, files map[string]string) (string, func()) {
+	dir, err := ioutil.TempDir("", "config")
+	require.NoError(t, err)
+	for name, content := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+	return filepath.Join(dir, "config.json"), func() { os.RemoveAll(dir) }
+}
+
+func setenv(t *testing.T, key, value string) func() {
+	old, had := os.LookupEnv(key)
+	require.NoError(t, os.Setenv(key, value))
+	return func() {
+		if had {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path, cleanup := writeConfig(t, map[string]string{"config.json": baseConfig})
+	defer cleanup()
+
+	c, err := config.Load(path, "")
+
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", c.Database.Host)
+	// missing values fall back to their default
+	assert.Equal(t, 2, c.Context.Timeout)
+	assert.Equal(t, int64(100), c.Pagination.MaxSize)
+	assert.Equal(t, "disable", c.Database.SSLMode)
+}
+
+func TestLoadProfileAndEnvironment(t *testing.T) {
+	path, cleanup := writeConfig(t, map[string]string{
+		"config.json":      baseConfig,
+		"config.prod.json": `{"database": {"host": "db.internal", "name": "articles"}}`,
+	})
+	defer cleanup()
+	defer setenv(t, "APP_DATABASE_NAME", "from_env")()
+	defer setenv(t, "APP_DATABASE_REPLICAS", "host=replica1, host=replica2")()
+
+	c, err := config.Load(path, "prod")
+
+	require.NoError(t, err)
+	assert.Equal(t, "db.internal", c.Database.Host)
+	assert.Equal(t, "from_env", c.Database.Name)
+	assert.Equal(t, "5432", c.Database.Port)
+	assert.Equal(t, []string{"host=replica1", "host=replica2"}, c.Database.Replicas)
+
+	_, err = config.Load(path, "staging")
+	assert.Error(t, err)
+}
+
+func TestLoadWithoutFile(t *testing.T) {
+	defer setenv(t, "APP_DATABASE_DRIVER", "memory")()
+
+	c, err := config.Load(filepath.Join(os.TempDir(), "missing-config.json"), "")
+
+	require.NoError(t, err)
+	assert.Equal(t, "memory", c.Database.Driver)
+}
+
+func TestValidate(t *testing.T) {
+	path, cleanup := writeConfig(t, map[string]string{
+		"config.json": `{"pagination": {"max_size": 0}, "database": {"driver": "postgres", "host": "localhost"}}`,
+	})
+	defer cleanup()
+
+	_, err := config.Load(path, "")
+
+	require.IsType(t, &config.ValidationError{}, err)
+	assert.Equal(t, []string{
+		"pagination.max_size must be positive, got 0",
+		"database.port is required",
+		"database.user is required",
+		"database.name is required",
+	}, err.(*config.ValidationError).Problems)
+
+	c := config.Config{}
+	c.Database.Driver = "oracle"
+	err = c.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `database.driver must be one of postgres, mysql, sqlite3 or memory, got "oracle"`)
+
+	c = config.Config{}
+	c.Tracing = config.TracingConfig{Exporter: "otlp-file", SampleRatio: 2}
+	err = c.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tracing.path is required")
+	assert.Contains(t, err.Error(), "tracing.sample_ratio must be between 0 and 1, got 2")
+
+	c = config.Config{}
+	c.Server.TLS = config.TLSConfig{CertFile: "cert.pem", MinVersion: "1.4", CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}}
+	err = c.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "server.tls.key_file is required")
+	assert.Contains(t, err.Error(), `server.tls.min_version must be one of 1.0, 1.1, 1.2, 1.3, got "1.4"`)
+	assert.Contains(t, err.Error(), `server.tls.cipher_suites has an unknown or insecure suite "TLS_RSA_WITH_RC4_128_SHA"`)
+
+	c = config.Config{}
+	c.Server.RedirectAddress = ":80"
+	err = c.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "server.redirect_address needs server.tls.cert_file and server.tls.key_file")
+
+	c = config.Config{}
+	c.Server.Address, c.Admin.Address = ":9090", ":9090"
+	err = c.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `admin.address must differ from the server addresses, got ":9090"`)
+
+	c = config.Config{}
+	c.Outbox = config.OutboxConfig{Sink: "http", MinBackoff: 10, MaxBackoff: 5}
+	err = c.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "outbox.url is required")
+	assert.Contains(t, err.Error(), "outbox.batch_size must be positive, got 0")
+	assert.Contains(t, err.Error(), "outbox.lease must be positive, got 0")
+	assert.Contains(t, err.Error(), "outbox.max_backoff must not be less than outbox.min_backoff")
+	assert.Contains(t, err.Error(), "webhooks.max_attempts must be positive, got 0")
+	assert.Contains(t, err.Error(), "webhooks.lease must be positive, got 0")
+
+	c = config.Config{}
+	c.Webhooks.API = true
+	err = c.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "webhooks.token is required")
+
+	c = config.Config{}
+	c.Database.Driver, c.Context.Timeout = "memory", 2
+	c.Stream = config.StreamConfig{Log: "outbox", Settle: 2}
+	err = c.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stream.log outbox needs a SQL database.driver")
+	assert.Contains(t, err.Error(), "stream.settle must be greater than context.timeout, got 2")
+	assert.Contains(t, err.Error(), "stream.buffer must be positive, got 0")
+
+	c = config.Config{}
+	c.Jobs = config.JobsConfig{API: true, Queues: []string{"default", "exports:0"}, MinBackoff: 10, MaxBackoff: 5}
+	err = c.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "jobs.token is required")
+	assert.Contains(t, err.Error(), `jobs.queues must be given as name:concurrency, got "default"`)
+	assert.Contains(t, err.Error(), `jobs.queues must have a positive concurrency, got "exports:0"`)
+	assert.Contains(t, err.Error(), "jobs.max_backoff must not be less than jobs.min_backoff")
+	assert.Contains(t, err.Error(), "jobs.export_dir is required")
+}
+
+func TestWorkerConfig(t *testing.T) {
+	path, cleanup := writeConfig(t, map[string]string{"config.json": baseConfig})
+	defer cleanup()
+	defer setenv(t, "APP_JOBS_QUEUES", "default:4, exports:1")()
+
+	c, err := config.Load(path, "")
+
+	require.NoError(t, err)
+	workerConfig := c.WorkerConfig()
+	assert.Equal(t, map[string]int{"default": 4, "exports": 1}, workerConfig.Queues)
+	assert.Equal(t, 300*time.Second, workerConfig.Timeout)
+}
+
+func TestServerConfigTLS(t *testing.T) {
+	path, cleanup := writeConfig(t, map[string]string{"config.json": baseConfig})
+	defer cleanup()
+	c, err := config.Load(path, "")
+	require.NoError(t, err)
+	assert.Nil(t, c.ServerConfig().TLS, "plain HTTP without a certificate")
+
+	defer setenv(t, "APP_SERVER_TLS_CERT_FILE", "cert.pem")()
+	defer setenv(t, "APP_SERVER_TLS_KEY_FILE", "key.pem")()
+	defer setenv(t, "APP_SERVER_TLS_CIPHER_SUITES", "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256")()
+	c, err = config.Load(path, "")
+
+	require.NoError(t, err)
+	tlsConfig := c.ServerConfig().TLS
+	require.NotNil(t, tlsConfig)
+	assert.Equal(t, "1.2", tlsConfig.MinVersion)
+	assert.Equal(t, 10*time.Second, tlsConfig.ReloadInterval)
+	assert.Equal(t, []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"}, tlsConfig.CipherSuites)
+}
+
+func TestRedacted(t *testing.T) {
+	var c config.Config
+	c.Database.Pass = "secret"
+	c.Admin.Token = "secret"
+	c.Webhooks.Token = "secret"
+	c.Jobs.Token = "secret"
+	c.Database.Replicas = []string{
+		"host=replica port=5432 user=postgres password=secret dbname=article",
+		"user:secret@tcp(replica:3306)/article",
+	}
+
+	r := c.Redacted()
+
+	assert.Equal(t, "*****", r.Database.Pass)
+	assert.Equal(t, "*****", r.Admin.Token)
+	assert.Equal(t, "*****", r.Webhooks.Token)
+	assert.Equal(t, "*****", r.Jobs.Token)
+	assert.Equal(t, "host=replica port=5432 user=postgres password=***** dbname=article", r.Database.Replicas[0])
+	assert.Equal(t, "user:*****@tcp(replica:3306)/article", r.Database.Replicas[1])
+	assert.NotContains(t, c.String(), "secret")
+	// the original is untouched
+	assert.Equal(t, "secret", c.Database.Pass)
+}
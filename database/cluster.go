@@ -0,0 +1,221 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+// DefaultReplicaCheckInterval is used when Config.ReplicaCheckInterval is not set
+const DefaultReplicaCheckInterval = 5 * time.Second
+
+// Cluster is a primary database with optional read replicas
+type Cluster struct {
+	// Primary receives the writes and the reads that must see them
+	Primary models.Gormw
+	// Replica spreads queries over the healthy replicas, falling back to
+	// Primary when none is healthy. It is Primary when there are no replicas.
+	Replica models.Gormw
+
+	replicas *replicaSet
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// OpenCluster opens the primary like Open does and connects the replicas listed in c.Replicas.
+// Replicas that can not be reached are kept out of rotation until their health check passes.
+func OpenCluster(c Config) (*Cluster, error) {
+	primary, err := Open(c)
+	if err != nil {
+		return nil, err
+	}
+
+	replicas := make([]*sql.DB, 0, len(c.Replicas))
+	for _, dsn := range c.Replicas {
+		replica, err := sql.Open(c.Driver, dsn)
+		if err != nil {
+			for _, r := range replicas {
+				r.Close()
+			}
+			primary.Close()
+			return nil, err
+		}
+		replicas = append(replicas, replica)
+	}
+
+	interval := c.ReplicaCheckInterval
+	if interval <= 0 {
+		interval = DefaultReplicaCheckInterval
+	}
+	return NewCluster(primary, replicas, interval)
+}
+
+// NewCluster routes reads over replicas, checking their health every checkInterval
+func NewCluster(primary models.Gormw, replicas []*sql.DB, checkInterval time.Duration) (*Cluster, error) {
+	c := &Cluster{Primary: primary, Replica: primary, stop: make(chan struct{})}
+	if len(replicas) == 0 {
+		return c, nil
+	}
+
+	c.replicas = &replicaSet{primary: primary.DB()}
+	for _, db := range replicas {
+		c.replicas.members = append(c.replicas.members, &replica{db: db})
+	}
+	replica, err := models.Openw(primary.Dialect().GetName(), c.replicas)
+	if err != nil {
+		return nil, err
+	}
+	c.Replica = replica
+
+	c.replicas.check(checkInterval)
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.replicas.check(checkInterval)
+			case <-c.stop:
+				return
+			}
+		}
+	}()
+	return c, nil
+}
+
+// Ping checks that the primary answers
+func (c *Cluster) Ping(ctx context.Context) error {
+	return c.Primary.DB().PingContext(ctx)
+}
+
+// HealthyReplicas returns how many replicas are currently in rotation
+func (c *Cluster) HealthyReplicas() int {
+	if c.replicas == nil {
+		return 0
+	}
+	n := 0
+	for _, r := range c.replicas.members {
+		if r.isHealthy() {
+			n++
+		}
+	}
+	return n
+}
+
+// ReplicasCheckedAt returns when the last health check of the replicas
+// finished, the zero time when there are no replicas
+func (c *Cluster) ReplicasCheckedAt() time.Time {
+	if c.replicas == nil {
+		return time.Time{}
+	}
+	return time.Unix(0, atomic.LoadInt64(&c.replicas.checked))
+}
+
+// Close stops the health checks and closes every connection
+func (c *Cluster) Close() error {
+	close(c.stop)
+	c.wg.Wait()
+	if c.replicas != nil {
+		for _, r := range c.replicas.members {
+			r.db.Close()
+		}
+	}
+	return c.Primary.Close()
+}
+
+type replica struct {
+	db      *sql.DB
+	healthy int32
+}
+
+func (r *replica) isHealthy() bool {
+	return atomic.LoadInt32(&r.healthy) == 1
+}
+
+// replicaSet is a gorm.SQLCommon sending each statement to the next healthy replica
+type replicaSet struct {
+	primary *sql.DB
+	members []*replica
+	next    uint32
+	// checked is when check last finished, in Unix nanoseconds
+	checked int64
+}
+
+// check pings every replica and updates which of them are in rotation
+func (s *replicaSet) check(timeout time.Duration) {
+	var wg sync.WaitGroup
+	for _, r := range s.members {
+		wg.Add(1)
+		go func(r *replica) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			healthy := int32(1)
+			err := r.db.PingContext(ctx)
+			if err != nil {
+				healthy = 0
+			}
+			if old := atomic.SwapInt32(&r.healthy, healthy); old != healthy {
+				if err != nil {
+					logrus.Warn("database replica taken out of rotation: ", err)
+				} else {
+					logrus.Info("database replica back in rotation")
+				}
+			}
+		}(r)
+	}
+	wg.Wait()
+	atomic.StoreInt64(&s.checked, time.Now().UnixNano())
+}
+
+func (s *replicaSet) pick() *sql.DB {
+	n := uint32(len(s.members))
+	start := atomic.AddUint32(&s.next, 1)
+	for i := uint32(0); i < n; i++ {
+		if r := s.members[(start+i)%n]; r.isHealthy() {
+			return r.db
+		}
+	}
+	return s.primary
+}
+
+func (s *replicaSet) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return s.pick().Exec(query, args...)
+}
+
+func (s *replicaSet) Prepare(query string) (*sql.Stmt, error) {
+	return s.pick().Prepare(query)
+}
+
+func (s *replicaSet) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return s.pick().Query(query, args...)
+}
+
+func (s *replicaSet) QueryRow(query string, args ...interface{}) *sql.Row {
+	return s.pick().QueryRow(query, args...)
+}
+
+func (s *replicaSet) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return s.pick().ExecContext(ctx, query, args...)
+}
+
+func (s *replicaSet) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return s.pick().PrepareContext(ctx, query)
+}
+
+func (s *replicaSet) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return s.pick().QueryContext(ctx, query, args...)
+}
+
+func (s *replicaSet) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return s.pick().QueryRowContext(ctx, query, args...)
+}
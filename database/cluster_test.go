@@ -0,0 +1,81 @@
+package database_test
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/database"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+func TestClusterRoutesToHealthyReplicas(t *testing.T) {
+	primaryDB, primaryMock, err := sqlmock.New()
+	require.NoError(t, err)
+	primary, err := models.Openw(database.Postgres, primaryDB)
+	require.NoError(t, err)
+
+	healthy, healthyMock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	down, downMock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	healthyMock.ExpectPing()
+	downMock.ExpectPing().WillReturnError(errors.New("connection refused"))
+
+	before := time.Now()
+	cluster, err := database.NewCluster(primary, []*sql.DB{healthy, down}, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, cluster.HealthyReplicas())
+	assert.False(t, cluster.ReplicasCheckedAt().Before(before))
+
+	// every read lands on the healthy replica
+	for i := 0; i < 3; i++ {
+		healthyMock.ExpectExec("SELECT 1").WillReturnResult(sqlmock.NewResult(0, 0))
+		require.NoError(t, cluster.Replica.Exec("SELECT 1").Error())
+	}
+
+	healthyMock.ExpectClose()
+	downMock.ExpectClose()
+	primaryMock.ExpectClose()
+	require.NoError(t, cluster.Close())
+
+	assert.NoError(t, primaryMock.ExpectationsWereMet())
+	assert.NoError(t, healthyMock.ExpectationsWereMet())
+	assert.NoError(t, downMock.ExpectationsWereMet())
+}
+
+func TestClusterFallsBackToPrimary(t *testing.T) {
+	primaryDB, primaryMock, err := sqlmock.New()
+	require.NoError(t, err)
+	primary, err := models.Openw(database.Postgres, primaryDB)
+	require.NoError(t, err)
+
+	down, downMock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	downMock.ExpectPing().WillReturnError(errors.New("connection refused"))
+
+	cluster, err := database.NewCluster(primary, []*sql.DB{down}, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 0, cluster.HealthyReplicas())
+
+	primaryMock.ExpectExec("SELECT 1").WillReturnResult(sqlmock.NewResult(0, 0))
+	require.NoError(t, cluster.Replica.Exec("SELECT 1").Error())
+	assert.NoError(t, primaryMock.ExpectationsWereMet())
+}
+
+func TestClusterWithoutReplicas(t *testing.T) {
+	primaryDB, _, err := sqlmock.New()
+	require.NoError(t, err)
+	primary, err := models.Openw(database.Postgres, primaryDB)
+	require.NoError(t, err)
+
+	cluster, err := database.NewCluster(primary, nil, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, cluster.Primary, cluster.Replica)
+	assert.True(t, cluster.ReplicasCheckedAt().IsZero())
+}
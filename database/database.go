@@ -0,0 +1,65 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	// register the gorm dialects selectable through Config.Driver
+	_ "github.com/jinzhu/gorm/dialects/mysql"
+	_ "github.com/jinzhu/gorm/dialects/postgres"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+// Supported values of Config.Driver
+const (
+	Postgres = "postgres"
+	Mysql    = "mysql"
+	Sqlite   = "sqlite3"
+	Memory   = "memory"
+)
+
+// Config represent the connection settings of the database
+type Config struct {
+	Driver  string
+	Host    string
+	Port    string
+	User    string
+	Pass    string
+	Name    string
+	SSLMode string
+	// Path is the data file used by the sqlite3 driver
+	Path string
+	// Replicas are the DSNs of read replicas of the primary
+	Replicas             []string
+	ReplicaCheckInterval time.Duration
+}
+
+// DSN returns the data source name understood by the driver
+func (c Config) DSN() (string, error) {
+	switch c.Driver {
+	case Postgres:
+		return fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s", c.Host, c.Port, c.User, c.Name, c.Pass, c.SSLMode), nil
+	case Mysql:
+		return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=true&loc=UTC", c.User, c.Pass, c.Host, c.Port, c.Name), nil
+	case Sqlite:
+		// WAL lets readers run alongside the single writer. Immediate
+		// transactions wait on the busy timeout instead of failing with
+		// SQLITE_BUSY when two of them try to write.
+		return fmt.Sprintf("file:%s?_journal_mode=WAL&_busy_timeout=5000&_txlock=immediate&_foreign_keys=on", c.Path), nil
+	default:
+		return "", fmt.Errorf("unsupported database driver %q", c.Driver)
+	}
+}
+
+// Open connects to the configured database.
+// Its schema is managed by Migrator.
+func Open(c Config) (models.Gormw, error) {
+	dsn, err := c.DSN()
+	if err != nil {
+		return nil, err
+	}
+
+	return models.Openw(c.Driver, dsn)
+}
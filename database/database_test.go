@@ -0,0 +1,35 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/naveenpatilm/go-clean-arch/database"
+)
+
+func TestDSN(t *testing.T) {
+	c := database.Config{
+		Host:    "localhost",
+		Port:    "5432",
+		User:    "user",
+		Pass:    "password",
+		Name:    "article",
+		SSLMode: "disable",
+	}
+
+	c.Driver = database.Postgres
+	dsn, err := c.DSN()
+	assert.NoError(t, err)
+	assert.Equal(t, "host=localhost port=5432 user=user dbname=article password=password sslmode=disable", dsn)
+
+	c.Driver = database.Mysql
+	c.Port = "3306"
+	dsn, err = c.DSN()
+	assert.NoError(t, err)
+	assert.Equal(t, "user:password@tcp(localhost:3306)/article?charset=utf8mb4&parseTime=true&loc=UTC", dsn)
+
+	c.Driver = "oracle"
+	_, err = c.DSN()
+	assert.Error(t, err)
+}
@@ -0,0 +1,305 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// schemaLockKey identifies the postgres advisory lock held while migrating
+const schemaLockKey = 4201812051
+
+// mysqlLockTimeout is how long, in seconds, mysql waits for another migration to finish
+const mysqlLockTimeout = 60
+
+// ErrNoMigration is returned by Down when no migration is applied
+var ErrNoMigration = errors.New("no migration to roll back")
+
+// SchemaBehindError is returned by CheckCurrent when migrations are pending
+type SchemaBehindError struct {
+	Current int64
+	Latest  int64
+}
+
+func (e *SchemaBehindError) Error() string {
+	return fmt.Sprintf("database schema is at version %d but %d is required, run the migrate command", e.Current, e.Latest)
+}
+
+// MigrationStatus tells whether a migration is applied
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Migrator applies the versioned migrations of a driver and records them in
+// the schema_migrations table. Every change holds a database lock, so
+// instances started together never apply the same migration twice.
+type Migrator struct {
+	db         *sql.DB
+	driver     string
+	migrations []Migration
+}
+
+// NewMigrator will create a Migrator for the schema of driver on db
+func NewMigrator(db *sql.DB, driver string) (*Migrator, error) {
+	list, ok := migrations[driver]
+	if !ok {
+		return nil, fmt.Errorf("no migrations for database driver %q", driver)
+	}
+	return &Migrator{db: db, driver: driver, migrations: list}, nil
+}
+
+// Latest returns the version the binary expects the schema to be at
+func (m *Migrator) Latest() int64 {
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Status lists every migration with the time it was applied, if it was
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Migration: migration}
+		if at, ok := applied[migration.Version]; ok {
+			at := at
+			status.AppliedAt = &at
+		}
+		res = append(res, status)
+	}
+	return res, nil
+}
+
+// CheckCurrent returns a *SchemaBehindError when a migration is not applied
+func (m *Migrator) CheckCurrent(ctx context.Context) error {
+	applied, err := m.applied(ctx, m.db)
+	if err != nil {
+		return err
+	}
+
+	current := int64(0)
+	behind := false
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			current = migration.Version
+		} else {
+			behind = true
+		}
+	}
+	if behind {
+		return &SchemaBehindError{Current: current, Latest: m.Latest()}
+	}
+	return nil
+}
+
+// Up applies every pending migration
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.Latest())
+}
+
+// Down reverts the most recent applied migration
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.locked(ctx, func(conn *sql.Conn, applied map[int64]time.Time) error {
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			if _, ok := applied[m.migrations[i].Version]; ok {
+				return m.apply(ctx, conn, m.migrations[i], false)
+			}
+		}
+		return ErrNoMigration
+	})
+}
+
+// To applies or reverts migrations until exactly those up to version are applied.
+// Version 0 reverts every migration.
+func (m *Migrator) To(ctx context.Context, version int64) error {
+	known := version == 0
+	for _, migration := range m.migrations {
+		known = known || migration.Version == version
+	}
+	if !known {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+
+	return m.locked(ctx, func(conn *sql.Conn, applied map[int64]time.Time) error {
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; ok && migration.Version > version {
+				if err := m.apply(ctx, conn, migration, false); err != nil {
+					return err
+				}
+			}
+		}
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; !ok && migration.Version <= version {
+				if err := m.apply(ctx, conn, migration, true); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// locked runs fn on a single connection holding the migration lock
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn, applied map[int64]time.Time) error) (err error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	switch m.driver {
+	case Postgres:
+		if _, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", schemaLockKey); err != nil {
+			return err
+		}
+		defer func() {
+			if _, unlockErr := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", schemaLockKey); err == nil {
+				err = unlockErr
+			}
+		}()
+	case Mysql:
+		var acquired sql.NullInt64
+		if err = conn.QueryRowContext(ctx, "SELECT GET_LOCK('schema_migrations', ?)", mysqlLockTimeout).Scan(&acquired); err != nil {
+			return err
+		}
+		if acquired.Int64 != 1 {
+			return errors.New("timed out waiting for another migration to finish")
+		}
+		defer func() {
+			if _, unlockErr := conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK('schema_migrations')"); err == nil {
+				err = unlockErr
+			}
+		}()
+	}
+	// sqlite has no such lock, so applied may be outdated by the time a
+	// migration runs: apply checks it again in its immediate transaction.
+
+	if _, err = conn.ExecContext(ctx, m.createTable()); err != nil {
+		return err
+	}
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return err
+	}
+	return fn(conn, applied)
+}
+
+// apply runs the up or down statements of migration and records the change
+// in one transaction, unless the change was recorded meanwhile. Mysql commits
+// DDL implicitly, so a failed migration may be left half applied there.
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration, up bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	var recorded int
+	err = tx.QueryRowContext(ctx, m.bind("SELECT count(*) FROM schema_migrations WHERE version = ?"), migration.Version).Scan(&recorded)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if (recorded > 0) == up {
+		return tx.Rollback()
+	}
+
+	statements := migration.Down
+	record, args := m.bind("DELETE FROM schema_migrations WHERE version = ?"), []interface{}{migration.Version}
+	if up {
+		statements = migration.Up
+		record = m.bind("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)")
+		args = append(args, migration.Name, time.Now().UTC())
+	}
+
+	for _, statement := range statements {
+		if _, err = tx.ExecContext(ctx, statement); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		_, err = tx.ExecContext(ctx, record, args...)
+	}
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("migration %d %s: %v", migration.Version, migration.Name, err)
+	}
+	return tx.Commit()
+}
+
+// queryer is implemented by both *sql.DB and *sql.Conn
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// applied returns the applied versions, or none when schema_migrations does not exist yet
+func (m *Migrator) applied(ctx context.Context, db queryer) (map[int64]time.Time, error) {
+	applied := map[int64]time.Time{}
+	exists, err := m.tableExists(ctx, db)
+	if err != nil || !exists {
+		return applied, err
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var version int64
+		var at time.Time
+		if err = rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		applied[version] = at
+	}
+	return applied, rows.Err()
+}
+
+func (m *Migrator) tableExists(ctx context.Context, db queryer) (bool, error) {
+	var query string
+	switch m.driver {
+	case Postgres:
+		query = "SELECT 1 FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = 'schema_migrations'"
+	case Mysql:
+		query = "SELECT 1 FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = 'schema_migrations'"
+	case Sqlite:
+		query = "SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'"
+	}
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	return rows.Next(), rows.Err()
+}
+
+func (m *Migrator) createTable() string {
+	timestamp := "datetime"
+	if m.driver == Postgres {
+		timestamp = "timestamp with time zone"
+	}
+	return "CREATE TABLE IF NOT EXISTS schema_migrations (version bigint NOT NULL PRIMARY KEY, name varchar(255) NOT NULL, applied_at " + timestamp + " NOT NULL)"
+}
+
+// bind rewrites ? placeholders into the $n form postgres expects
+func (m *Migrator) bind(query string) string {
+	if m.driver != Postgres {
+		return query
+	}
+	res := make([]byte, 0, len(query))
+	n := 0
+	for i := 0; i < len(query); i++ {
+		if query[i] != '?' {
+			res = append(res, query[i])
+			continue
+		}
+		n++
+		res = append(res, fmt.Sprintf("$%d", n)...)
+	}
+	return string(res)
+}
@@ -0,0 +1,158 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/database"
+)
+
+func openSqlite(t *testing.T) (*sql.DB, func()) {
+	dir, err := ioutil.TempDir("", "migrate")
+	require.NoError(t, err)
+	db, err := database.Open(database.Config{Driver: database.Sqlite, Path: filepath.Join(dir, "article.db")})
+	require.NoError(t, err)
+	return db.DB(), func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	rows, err := db.Query("SELECT 1 FROM sqlite_master WHERE name = ?", name)
+	require.NoError(t, err)
+	defer rows.Close()
+	return rows.Next()
+}
+
+func columnExists(t *testing.T, db *sql.DB, table, column string) bool {
+	rows, err := db.Query("SELECT 1 FROM pragma_table_info(?) WHERE name = ?", table, column)
+	require.NoError(t, err)
+	defer rows.Close()
+	return rows.Next()
+}
+
+func TestMigrateUpAndDown(t *testing.T) {
+	db, cleanup := openSqlite(t)
+	defer cleanup()
+	migrator, err := database.NewMigrator(db, database.Sqlite)
+	require.NoError(t, err)
+	ctx := context.TODO()
+
+	err = migrator.CheckCurrent(ctx)
+	assert.Equal(t, &database.SchemaBehindError{Current: 0, Latest: migrator.Latest()}, err)
+
+	require.NoError(t, migrator.Up(ctx))
+	assert.NoError(t, migrator.CheckCurrent(ctx))
+	assert.True(t, tableExists(t, db, "articles"))
+	assert.True(t, tableExists(t, db, "outbox_messages"))
+	assert.True(t, tableExists(t, db, "webhook_deliveries"))
+	assert.True(t, tableExists(t, db, "jobs"))
+	assert.True(t, columnExists(t, db, "outbox_messages", "locked_until"))
+	assert.True(t, columnExists(t, db, "webhook_deliveries", "locked_until"))
+	// applying twice is a no-op
+	require.NoError(t, migrator.Up(ctx))
+
+	require.NoError(t, migrator.Down(ctx))
+	assert.True(t, tableExists(t, db, "jobs"))
+	assert.True(t, columnExists(t, db, "outbox_messages", "locked_until"))
+	assert.False(t, columnExists(t, db, "webhook_deliveries", "locked_until"))
+	err = migrator.CheckCurrent(ctx)
+	assert.Equal(t, &database.SchemaBehindError{Current: migrator.Latest() - 1, Latest: migrator.Latest()}, err)
+
+	status, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, status, int(migrator.Latest()))
+	assert.NotNil(t, status[0].AppliedAt)
+	assert.Nil(t, status[len(status)-1].AppliedAt)
+
+	require.NoError(t, migrator.To(ctx, 0))
+	assert.False(t, tableExists(t, db, "articles"))
+	assert.Equal(t, database.ErrNoMigration, migrator.Down(ctx))
+	assert.Error(t, migrator.To(ctx, 999))
+}
+
+func TestMigrateConcurrentInstances(t *testing.T) {
+	db, cleanup := openSqlite(t)
+	defer cleanup()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			migrator, err := database.NewMigrator(db, database.Sqlite)
+			if err == nil {
+				err = migrator.Up(context.TODO())
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	var applied int
+	require.NoError(t, db.QueryRow("SELECT count(*) FROM schema_migrations").Scan(&applied))
+	migrator, err := database.NewMigrator(db, database.Sqlite)
+	require.NoError(t, err)
+	assert.Equal(t, int(migrator.Latest()), applied)
+}
+
+func TestMigratePostgresHoldsAdvisoryLock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	migrator, err := database.NewMigrator(db, database.Postgres)
+	require.NoError(t, err)
+
+	mock.ExpectExec(`SELECT pg_advisory_lock\(\$1\)`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("information_schema.tables").WillReturnRows(sqlmock.NewRows([]string{"?column?"}).AddRow(1))
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT count\(\*\) FROM schema_migrations WHERE version = \$1`).WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS authors").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS articles").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO schema_migrations \(version, name, applied_at\) VALUES \(\$1, \$2, \$3\)`).
+		WithArgs(1, "create_authors_and_articles", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(`SELECT pg_advisory_unlock\(\$1\)`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.NoError(t, migrator.To(context.TODO(), 1))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrateSkipsVersionsAppliedMeanwhile(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	migrator, err := database.NewMigrator(db, database.Sqlite)
+	require.NoError(t, err)
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("sqlite_master").WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}))
+	// another instance applied the migration once applied was read
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT count\(\*\) FROM schema_migrations WHERE version = \?`).WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+
+	require.NoError(t, migrator.To(context.TODO(), 1))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,540 @@
+package database
+
+// Migration is one versioned change of the schema.
+// Up applies it and Down reverts it, one statement per element.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      []string
+	Down    []string
+}
+
+// migrations are the schema changes of every driver, ordered by version.
+// Applied migrations must never be edited: add a new version instead.
+var migrations = map[string][]Migration{
+	Postgres: {
+		{
+			Version: 1,
+			Name:    "create_authors_and_articles",
+			Up: []string{
+				`CREATE TABLE IF NOT EXISTS authors (
+					id bigserial PRIMARY KEY,
+					created_at timestamp with time zone,
+					updated_at timestamp with time zone,
+					deleted_at timestamp with time zone,
+					name text
+				)`,
+				`CREATE TABLE IF NOT EXISTS articles (
+					id bigserial PRIMARY KEY,
+					created_at timestamp with time zone,
+					updated_at timestamp with time zone,
+					deleted_at timestamp with time zone,
+					title text,
+					content text,
+					author_id bigint
+				)`,
+			},
+			Down: []string{
+				`DROP TABLE articles`,
+				`DROP TABLE authors`,
+			},
+		},
+		{
+			Version: 2,
+			Name:    "unique_live_article_title",
+			// title uniqueness is case-insensitive and ignores soft deleted articles
+			Up: []string{
+				`CREATE UNIQUE INDEX IF NOT EXISTS idx_articles_title_lower ON articles (lower(title)) WHERE deleted_at IS NULL`,
+			},
+			Down: []string{
+				`DROP INDEX idx_articles_title_lower`,
+			},
+		},
+		{
+			Version: 3,
+			Name:    "index_articles_created_at_id",
+			// keyset pagination walks articles in (created_at, id) order
+			Up: []string{
+				`CREATE INDEX IF NOT EXISTS idx_articles_created_at_id ON articles (created_at, id)`,
+			},
+			Down: []string{
+				`DROP INDEX idx_articles_created_at_id`,
+			},
+		},
+		{
+			Version: 4,
+			Name:    "create_outbox_messages",
+			Up: []string{
+				`CREATE TABLE IF NOT EXISTS outbox_messages (
+					id bigserial PRIMARY KEY,
+					created_at timestamp with time zone NOT NULL,
+					article_id bigint NOT NULL,
+					event_name text NOT NULL,
+					payload text NOT NULL,
+					attempts integer NOT NULL DEFAULT 0,
+					next_attempt_at timestamp with time zone NOT NULL,
+					last_error text NOT NULL DEFAULT '',
+					dispatched_at timestamp with time zone
+				)`,
+				// the relay looks for the oldest pending message of each article
+				`CREATE INDEX IF NOT EXISTS idx_outbox_messages_pending ON outbox_messages (article_id, id) WHERE dispatched_at IS NULL`,
+			},
+			Down: []string{
+				`DROP TABLE outbox_messages`,
+			},
+		},
+		{
+			Version: 5,
+			Name:    "create_webhooks",
+			Up: []string{
+				`CREATE TABLE IF NOT EXISTS webhooks (
+					id bigserial PRIMARY KEY,
+					created_at timestamp with time zone NOT NULL,
+					updated_at timestamp with time zone NOT NULL,
+					url text NOT NULL,
+					events text NOT NULL,
+					secret text NOT NULL,
+					paused boolean NOT NULL DEFAULT false
+				)`,
+				`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+					id bigserial PRIMARY KEY,
+					created_at timestamp with time zone NOT NULL,
+					webhook_id bigint NOT NULL,
+					article_id bigint NOT NULL,
+					event_name text NOT NULL,
+					payload text NOT NULL,
+					state text NOT NULL,
+					attempts integer NOT NULL DEFAULT 0,
+					next_attempt_at timestamp with time zone NOT NULL,
+					response_code integer NOT NULL DEFAULT 0,
+					last_error text NOT NULL DEFAULT '',
+					delivered_at timestamp with time zone
+				)`,
+				`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id, id)`,
+				// the dispatcher looks for the oldest pending delivery of each article to each webhook
+				`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON webhook_deliveries (webhook_id, article_id, id) WHERE state = 'pending'`,
+				`CREATE TABLE IF NOT EXISTS webhook_attempts (
+					id bigserial PRIMARY KEY,
+					created_at timestamp with time zone NOT NULL,
+					delivery_id bigint NOT NULL,
+					response_code integer NOT NULL DEFAULT 0,
+					error text NOT NULL DEFAULT '',
+					duration_ms bigint NOT NULL DEFAULT 0
+				)`,
+				`CREATE INDEX IF NOT EXISTS idx_webhook_attempts_delivery_id ON webhook_attempts (delivery_id, id)`,
+			},
+			Down: []string{
+				`DROP TABLE webhook_attempts`,
+				`DROP TABLE webhook_deliveries`,
+				`DROP TABLE webhooks`,
+			},
+		},
+		{
+			Version: 6,
+			Name:    "create_jobs",
+			Up: []string{
+				`CREATE TABLE IF NOT EXISTS jobs (
+					id bigserial PRIMARY KEY,
+					created_at timestamp with time zone NOT NULL,
+					updated_at timestamp with time zone NOT NULL,
+					kind text NOT NULL,
+					queue text NOT NULL,
+					payload text NOT NULL,
+					state text NOT NULL,
+					attempts integer NOT NULL DEFAULT 0,
+					max_attempts integer NOT NULL,
+					run_at timestamp with time zone NOT NULL,
+					unique_key text NOT NULL DEFAULT '',
+					active_key text,
+					locked_by text NOT NULL DEFAULT '',
+					locked_until timestamp with time zone,
+					last_error text NOT NULL DEFAULT '',
+					finished_at timestamp with time zone
+				)`,
+				// the workers look for the due jobs of their queue
+				`CREATE INDEX IF NOT EXISTS idx_jobs_pending ON jobs (queue, run_at, id) WHERE state = 'pending'`,
+				`CREATE INDEX IF NOT EXISTS idx_jobs_running ON jobs (locked_until) WHERE state = 'running'`,
+				// only the pending and running jobs hold their unique key
+				`CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_active_key ON jobs (active_key)`,
+			},
+			Down: []string{
+				`DROP TABLE jobs`,
+			},
+		},
+		{
+			Version: 7,
+			Name:    "add_outbox_message_leases",
+			Up: []string{
+				// a relay leases the messages it claims instead of keeping them locked while it sends them
+				`ALTER TABLE outbox_messages ADD COLUMN locked_by text NOT NULL DEFAULT ''`,
+				`ALTER TABLE outbox_messages ADD COLUMN locked_until timestamp with time zone`,
+			},
+			Down: []string{
+				`ALTER TABLE outbox_messages DROP COLUMN locked_until`,
+				`ALTER TABLE outbox_messages DROP COLUMN locked_by`,
+			},
+		},
+		{
+			Version: 8,
+			Name:    "add_webhook_delivery_leases",
+			Up: []string{
+				// the requests of a dispatcher are sent outside the transaction claiming their deliveries
+				`ALTER TABLE webhook_deliveries ADD COLUMN locked_by text NOT NULL DEFAULT ''`,
+				`ALTER TABLE webhook_deliveries ADD COLUMN locked_until timestamp with time zone`,
+			},
+			Down: []string{
+				`ALTER TABLE webhook_deliveries DROP COLUMN locked_until`,
+				`ALTER TABLE webhook_deliveries DROP COLUMN locked_by`,
+			},
+		},
+	},
+	Mysql: {
+		{
+			Version: 1,
+			Name:    "create_authors_and_articles",
+			Up: []string{
+				`CREATE TABLE IF NOT EXISTS authors (
+					id bigint NOT NULL AUTO_INCREMENT PRIMARY KEY,
+					created_at datetime NULL,
+					updated_at datetime NULL,
+					deleted_at datetime NULL,
+					name varchar(255)
+				) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+				`CREATE TABLE IF NOT EXISTS articles (
+					id bigint NOT NULL AUTO_INCREMENT PRIMARY KEY,
+					created_at datetime NULL,
+					updated_at datetime NULL,
+					deleted_at datetime NULL,
+					title varchar(255),
+					content longtext,
+					author_id bigint
+				) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+			},
+			Down: []string{
+				`DROP TABLE articles`,
+				`DROP TABLE authors`,
+			},
+		},
+		{
+			Version: 2,
+			Name:    "unique_live_article_title",
+			// mysql has no partial indexes: the unique index covers a generated
+			// column that is NULL for soft deleted articles. The column collation
+			// makes the comparison case-insensitive.
+			Up: []string{
+				`ALTER TABLE articles ADD COLUMN live_title varchar(255) COLLATE utf8mb4_unicode_ci AS (IF(deleted_at IS NULL, title, NULL)) VIRTUAL`,
+				`CREATE UNIQUE INDEX idx_articles_live_title ON articles (live_title)`,
+			},
+			Down: []string{
+				`DROP INDEX idx_articles_live_title ON articles`,
+				`ALTER TABLE articles DROP COLUMN live_title`,
+			},
+		},
+		{
+			Version: 3,
+			Name:    "index_articles_created_at_id",
+			Up: []string{
+				`CREATE INDEX idx_articles_created_at_id ON articles (created_at, id)`,
+			},
+			Down: []string{
+				`DROP INDEX idx_articles_created_at_id ON articles`,
+			},
+		},
+		{
+			Version: 4,
+			Name:    "create_outbox_messages",
+			// FOR UPDATE SKIP LOCKED, used by the relay, needs mysql 8
+			Up: []string{
+				`CREATE TABLE IF NOT EXISTS outbox_messages (
+					id bigint NOT NULL AUTO_INCREMENT PRIMARY KEY,
+					created_at datetime(6) NOT NULL,
+					article_id bigint NOT NULL,
+					event_name varchar(64) NOT NULL,
+					payload longtext NOT NULL,
+					attempts int NOT NULL DEFAULT 0,
+					next_attempt_at datetime(6) NOT NULL,
+					last_error text NOT NULL,
+					dispatched_at datetime(6) NULL
+				) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+				`CREATE INDEX idx_outbox_messages_pending ON outbox_messages (dispatched_at, article_id, id)`,
+			},
+			Down: []string{
+				`DROP TABLE outbox_messages`,
+			},
+		},
+		{
+			Version: 5,
+			Name:    "create_webhooks",
+			Up: []string{
+				`CREATE TABLE IF NOT EXISTS webhooks (
+					id bigint NOT NULL AUTO_INCREMENT PRIMARY KEY,
+					created_at datetime(6) NOT NULL,
+					updated_at datetime(6) NOT NULL,
+					url varchar(2048) NOT NULL,
+					events varchar(255) NOT NULL,
+					secret varchar(255) NOT NULL,
+					paused boolean NOT NULL DEFAULT false
+				) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+				`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+					id bigint NOT NULL AUTO_INCREMENT PRIMARY KEY,
+					created_at datetime(6) NOT NULL,
+					webhook_id bigint NOT NULL,
+					article_id bigint NOT NULL,
+					event_name varchar(64) NOT NULL,
+					payload longtext NOT NULL,
+					state varchar(16) NOT NULL,
+					attempts int NOT NULL DEFAULT 0,
+					next_attempt_at datetime(6) NOT NULL,
+					response_code int NOT NULL DEFAULT 0,
+					last_error text NOT NULL,
+					delivered_at datetime(6) NULL
+				) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+				`CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id, id)`,
+				`CREATE INDEX idx_webhook_deliveries_pending ON webhook_deliveries (state, webhook_id, article_id, id)`,
+				`CREATE TABLE IF NOT EXISTS webhook_attempts (
+					id bigint NOT NULL AUTO_INCREMENT PRIMARY KEY,
+					created_at datetime(6) NOT NULL,
+					delivery_id bigint NOT NULL,
+					response_code int NOT NULL DEFAULT 0,
+					error text NOT NULL,
+					duration_ms bigint NOT NULL DEFAULT 0
+				) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+				`CREATE INDEX idx_webhook_attempts_delivery_id ON webhook_attempts (delivery_id, id)`,
+			},
+			Down: []string{
+				`DROP TABLE webhook_attempts`,
+				`DROP TABLE webhook_deliveries`,
+				`DROP TABLE webhooks`,
+			},
+		},
+		{
+			Version: 6,
+			Name:    "create_jobs",
+			Up: []string{
+				`CREATE TABLE IF NOT EXISTS jobs (
+					id bigint NOT NULL AUTO_INCREMENT PRIMARY KEY,
+					created_at datetime(6) NOT NULL,
+					updated_at datetime(6) NOT NULL,
+					kind varchar(255) NOT NULL,
+					queue varchar(255) NOT NULL,
+					payload longtext NOT NULL,
+					state varchar(16) NOT NULL,
+					attempts int NOT NULL DEFAULT 0,
+					max_attempts int NOT NULL,
+					run_at datetime(6) NOT NULL,
+					unique_key varchar(255) NOT NULL DEFAULT '',
+					active_key varchar(255) NULL,
+					locked_by varchar(255) NOT NULL DEFAULT '',
+					locked_until datetime(6) NULL,
+					last_error text NOT NULL,
+					finished_at datetime(6) NULL
+				) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+				`CREATE INDEX idx_jobs_pending ON jobs (queue, state, run_at, id)`,
+				`CREATE INDEX idx_jobs_running ON jobs (state, locked_until)`,
+				// only the pending and running jobs hold their unique key
+				`CREATE UNIQUE INDEX idx_jobs_active_key ON jobs (active_key)`,
+			},
+			Down: []string{
+				`DROP TABLE jobs`,
+			},
+		},
+		{
+			Version: 7,
+			Name:    "add_outbox_message_leases",
+			Up: []string{
+				// a relay leases the messages it claims instead of keeping them locked while it sends them
+				`ALTER TABLE outbox_messages ADD COLUMN locked_by varchar(255) NOT NULL DEFAULT ''`,
+				`ALTER TABLE outbox_messages ADD COLUMN locked_until datetime(6) NULL`,
+			},
+			Down: []string{
+				`ALTER TABLE outbox_messages DROP COLUMN locked_until`,
+				`ALTER TABLE outbox_messages DROP COLUMN locked_by`,
+			},
+		},
+		{
+			Version: 8,
+			Name:    "add_webhook_delivery_leases",
+			Up: []string{
+				// the requests of a dispatcher are sent outside the transaction claiming their deliveries
+				`ALTER TABLE webhook_deliveries ADD COLUMN locked_by varchar(255) NOT NULL DEFAULT ''`,
+				`ALTER TABLE webhook_deliveries ADD COLUMN locked_until datetime(6) NULL`,
+			},
+			Down: []string{
+				`ALTER TABLE webhook_deliveries DROP COLUMN locked_until`,
+				`ALTER TABLE webhook_deliveries DROP COLUMN locked_by`,
+			},
+		},
+	},
+	Sqlite: {
+		{
+			Version: 1,
+			Name:    "create_authors_and_articles",
+			Up: []string{
+				`CREATE TABLE IF NOT EXISTS authors (
+					id integer PRIMARY KEY AUTOINCREMENT,
+					created_at datetime,
+					updated_at datetime,
+					deleted_at datetime,
+					name varchar(255)
+				)`,
+				`CREATE TABLE IF NOT EXISTS articles (
+					id integer PRIMARY KEY AUTOINCREMENT,
+					created_at datetime,
+					updated_at datetime,
+					deleted_at datetime,
+					title varchar(255),
+					content text,
+					author_id bigint
+				)`,
+			},
+			Down: []string{
+				`DROP TABLE articles`,
+				`DROP TABLE authors`,
+			},
+		},
+		{
+			Version: 2,
+			Name:    "unique_live_article_title",
+			// NOCASE is the collation GetByTitle compares with
+			Up: []string{
+				`CREATE UNIQUE INDEX IF NOT EXISTS idx_articles_title_nocase ON articles (title COLLATE NOCASE) WHERE deleted_at IS NULL`,
+			},
+			Down: []string{
+				`DROP INDEX idx_articles_title_nocase`,
+			},
+		},
+		{
+			Version: 3,
+			Name:    "index_articles_created_at_id",
+			Up: []string{
+				`CREATE INDEX IF NOT EXISTS idx_articles_created_at_id ON articles (created_at, id)`,
+			},
+			Down: []string{
+				`DROP INDEX idx_articles_created_at_id`,
+			},
+		},
+		{
+			Version: 4,
+			Name:    "create_outbox_messages",
+			Up: []string{
+				`CREATE TABLE IF NOT EXISTS outbox_messages (
+					id integer PRIMARY KEY AUTOINCREMENT,
+					created_at datetime NOT NULL,
+					article_id bigint NOT NULL,
+					event_name varchar(64) NOT NULL,
+					payload text NOT NULL,
+					attempts integer NOT NULL DEFAULT 0,
+					next_attempt_at datetime NOT NULL,
+					last_error text NOT NULL DEFAULT '',
+					dispatched_at datetime
+				)`,
+				`CREATE INDEX IF NOT EXISTS idx_outbox_messages_pending ON outbox_messages (article_id, id) WHERE dispatched_at IS NULL`,
+			},
+			Down: []string{
+				`DROP TABLE outbox_messages`,
+			},
+		},
+		{
+			Version: 5,
+			Name:    "create_webhooks",
+			Up: []string{
+				`CREATE TABLE IF NOT EXISTS webhooks (
+					id integer PRIMARY KEY AUTOINCREMENT,
+					created_at datetime NOT NULL,
+					updated_at datetime NOT NULL,
+					url text NOT NULL,
+					events varchar(255) NOT NULL,
+					secret varchar(255) NOT NULL,
+					paused boolean NOT NULL DEFAULT false
+				)`,
+				`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+					id integer PRIMARY KEY AUTOINCREMENT,
+					created_at datetime NOT NULL,
+					webhook_id bigint NOT NULL,
+					article_id bigint NOT NULL,
+					event_name varchar(64) NOT NULL,
+					payload text NOT NULL,
+					state varchar(16) NOT NULL,
+					attempts integer NOT NULL DEFAULT 0,
+					next_attempt_at datetime NOT NULL,
+					response_code integer NOT NULL DEFAULT 0,
+					last_error text NOT NULL DEFAULT '',
+					delivered_at datetime
+				)`,
+				`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id, id)`,
+				`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON webhook_deliveries (webhook_id, article_id, id) WHERE state = 'pending'`,
+				`CREATE TABLE IF NOT EXISTS webhook_attempts (
+					id integer PRIMARY KEY AUTOINCREMENT,
+					created_at datetime NOT NULL,
+					delivery_id bigint NOT NULL,
+					response_code integer NOT NULL DEFAULT 0,
+					error text NOT NULL DEFAULT '',
+					duration_ms bigint NOT NULL DEFAULT 0
+				)`,
+				`CREATE INDEX IF NOT EXISTS idx_webhook_attempts_delivery_id ON webhook_attempts (delivery_id, id)`,
+			},
+			Down: []string{
+				`DROP TABLE webhook_attempts`,
+				`DROP TABLE webhook_deliveries`,
+				`DROP TABLE webhooks`,
+			},
+		},
+		{
+			Version: 6,
+			Name:    "create_jobs",
+			Up: []string{
+				`CREATE TABLE IF NOT EXISTS jobs (
+					id integer PRIMARY KEY AUTOINCREMENT,
+					created_at datetime NOT NULL,
+					updated_at datetime NOT NULL,
+					kind varchar(255) NOT NULL,
+					queue varchar(255) NOT NULL,
+					payload text NOT NULL,
+					state varchar(16) NOT NULL,
+					attempts integer NOT NULL DEFAULT 0,
+					max_attempts integer NOT NULL,
+					run_at datetime NOT NULL,
+					unique_key varchar(255) NOT NULL DEFAULT '',
+					active_key varchar(255),
+					locked_by varchar(255) NOT NULL DEFAULT '',
+					locked_until datetime,
+					last_error text NOT NULL DEFAULT '',
+					finished_at datetime
+				)`,
+				`CREATE INDEX IF NOT EXISTS idx_jobs_pending ON jobs (queue, run_at, id) WHERE state = 'pending'`,
+				`CREATE INDEX IF NOT EXISTS idx_jobs_running ON jobs (locked_until) WHERE state = 'running'`,
+				// only the pending and running jobs hold their unique key
+				`CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_active_key ON jobs (active_key)`,
+			},
+			Down: []string{
+				`DROP TABLE jobs`,
+			},
+		},
+		{
+			Version: 7,
+			Name:    "add_outbox_message_leases",
+			Up: []string{
+				// a relay leases the messages it claims instead of keeping them locked while it sends them
+				`ALTER TABLE outbox_messages ADD COLUMN locked_by varchar(255) NOT NULL DEFAULT ''`,
+				`ALTER TABLE outbox_messages ADD COLUMN locked_until datetime`,
+			},
+			Down: []string{
+				`ALTER TABLE outbox_messages DROP COLUMN locked_until`,
+				`ALTER TABLE outbox_messages DROP COLUMN locked_by`,
+			},
+		},
+		{
+			Version: 8,
+			Name:    "add_webhook_delivery_leases",
+			Up: []string{
+				// the requests of a dispatcher are sent outside the transaction claiming their deliveries
+				`ALTER TABLE webhook_deliveries ADD COLUMN locked_by varchar(255) NOT NULL DEFAULT ''`,
+				`ALTER TABLE webhook_deliveries ADD COLUMN locked_until datetime`,
+			},
+			Down: []string{
+				`ALTER TABLE webhook_deliveries DROP COLUMN locked_until`,
+				`ALTER TABLE webhook_deliveries DROP COLUMN locked_by`,
+			},
+		},
+	},
+}
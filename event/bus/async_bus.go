@@ -0,0 +1,110 @@
+package bus
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/naveenpatilm/go-clean-arch/event"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+// DefaultBuffer is used when NewAsyncBus is given no buffer
+const DefaultBuffer = 1024
+
+type queued struct {
+	ctx context.Context
+	e   models.Event
+}
+
+type asyncSubscriber struct {
+	subscription
+	queue chan queued
+}
+
+type asyncBus struct {
+	buffer int
+
+	// mu is held for writing while closing, so no event is sent on a closed queue
+	mu     sync.RWMutex
+	subs   []*asyncSubscriber
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewAsyncBus will create an event.Bus where each subscriber handles its events
+// in its own goroutine, in the order they were published. An event is dropped
+// for a subscriber whose buffer of pending events is full, so a slow
+// subscriber never blocks the publisher.
+func NewAsyncBus(buffer int) event.Bus {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	return &asyncBus{buffer: buffer}
+}
+
+func (b *asyncBus) Subscribe(h event.Handler, names ...string) {
+	s := &asyncSubscriber{subscription: newSubscription(h, names), queue: make(chan queued, b.buffer)}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.subs = append(b.subs, s)
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		for q := range s.queue {
+			s.handle(q.ctx, q.e)
+		}
+	}()
+}
+
+func (b *asyncBus) Publish(ctx context.Context, events ...models.Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return event.ErrClosed
+	}
+
+	ctx = detached{ctx}
+	for _, e := range events {
+		for _, s := range b.subs {
+			if !s.matches(e) {
+				continue
+			}
+			select {
+			case s.queue <- queued{ctx: ctx, e: e}:
+			default:
+				logEvent(e).WithField("buffer", b.buffer).Error("event dropped, the subscriber is too slow")
+			}
+		}
+	}
+	return nil
+}
+
+func (b *asyncBus) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		for _, s := range b.subs {
+			close(s.queue)
+		}
+	}
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		logrus.Warn("event bus closed with events still pending")
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,64 @@
+package bus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/naveenpatilm/go-clean-arch/event"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+type subscription struct {
+	handler event.Handler
+	// names is empty when every event is handled
+	names map[string]bool
+}
+
+func newSubscription(h event.Handler, names []string) subscription {
+	s := subscription{handler: h}
+	if len(names) > 0 {
+		s.names = map[string]bool{}
+		for _, name := range names {
+			s.names[name] = true
+		}
+	}
+	return s
+}
+
+func (s subscription) matches(e models.Event) bool {
+	return s.names == nil || s.names[e.EventName()]
+}
+
+// handle runs the handler, logging its error or panic so that it never
+// reaches the publisher nor the other subscribers
+func (s subscription) handle(ctx context.Context, e models.Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			logEvent(e).Errorf("event subscriber panicked: %v", r)
+		}
+	}()
+	if err := s.handler(ctx, e); err != nil {
+		logEvent(e).Error("event subscriber failed: ", err)
+	}
+}
+
+func logEvent(e models.Event) *logrus.Entry {
+	return logrus.WithFields(logrus.Fields{
+		"event":      e.EventName(),
+		"article_id": e.EventArticle().ID,
+	})
+}
+
+// detached keeps the values of a context, such as the trace, without its
+// deadline and cancellation, which end with the request
+type detached struct {
+	context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detached) Done() <-chan struct{}       { return nil }
+func (detached) Err() error                  { return nil }
+func (d detached) String() string            { return fmt.Sprintf("%v.Detached", d.Context) }
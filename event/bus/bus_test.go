@@ -0,0 +1,116 @@
+package bus_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/event"
+	"github.com/naveenpatilm/go-clean-arch/event/bus"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+func created(id int64) models.Event {
+	return models.ArticleCreated{Article: models.Article{ID: id}, OccurredAt: time.Now()}
+}
+
+func deleted(id int64) models.Event {
+	return models.ArticleDeleted{Article: models.Article{ID: id}, OccurredAt: time.Now()}
+}
+
+// recorder collects the article ids of the events it handles
+type recorder struct {
+	mu  sync.Mutex
+	ids []int64
+}
+
+func (r *recorder) handle(ctx context.Context, e models.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ids = append(r.ids, e.EventArticle().ID)
+	return nil
+}
+
+func (r *recorder) get() []int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int64(nil), r.ids...)
+}
+
+func TestBusIsolatesSubscribers(t *testing.T) {
+	for name, b := range map[string]event.Bus{"sync": bus.NewSyncBus(), "async": bus.NewAsyncBus(10)} {
+		t.Run(name, func(t *testing.T) {
+			all, onlyDeleted := &recorder{}, &recorder{}
+			b.Subscribe(func(ctx context.Context, e models.Event) error {
+				return errors.New("failed")
+			})
+			b.Subscribe(func(ctx context.Context, e models.Event) error {
+				panic("broken subscriber")
+			})
+			b.Subscribe(all.handle)
+			b.Subscribe(onlyDeleted.handle, models.ArticleDeletedEvent)
+
+			require.NoError(t, b.Publish(context.TODO(), created(1), deleted(1)))
+			require.NoError(t, b.Publish(context.TODO(), created(2)))
+			require.NoError(t, b.Close(context.TODO()))
+
+			assert.Equal(t, []int64{1, 1, 2}, all.get())
+			assert.Equal(t, []int64{1}, onlyDeleted.get())
+			assert.Equal(t, event.ErrClosed, b.Publish(context.TODO(), created(3)))
+		})
+	}
+}
+
+func TestSyncBusHandlesBeforeReturning(t *testing.T) {
+	b := bus.NewSyncBus()
+	r := &recorder{}
+	b.Subscribe(r.handle)
+
+	require.NoError(t, b.Publish(context.TODO(), created(1)))
+
+	assert.Equal(t, []int64{1}, r.get())
+}
+
+func TestAsyncBusDoesNotWaitForSlowSubscribers(t *testing.T) {
+	b := bus.NewAsyncBus(1)
+	release := make(chan struct{})
+	b.Subscribe(func(ctx context.Context, e models.Event) error {
+		<-release
+		return nil
+	})
+	r := &recorder{}
+	b.Subscribe(r.handle)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	// the first event is being handled, the second is buffered, the third is dropped
+	for id := int64(1); id <= 3; id++ {
+		require.NoError(t, b.Publish(ctx, created(id)))
+		time.Sleep(10 * time.Millisecond)
+	}
+	// subscribers do not depend on the request context
+	cancel()
+	close(release)
+	require.NoError(t, b.Close(context.TODO()))
+
+	assert.Equal(t, []int64{1, 2, 3}, r.get())
+}
+
+func TestAsyncBusCloseTimeout(t *testing.T) {
+	b := bus.NewAsyncBus(1)
+	block := make(chan struct{})
+	defer close(block)
+	b.Subscribe(func(ctx context.Context, e models.Event) error {
+		<-block
+		return nil
+	})
+	require.NoError(t, b.Publish(context.TODO(), created(1)))
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 20*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, b.Close(ctx))
+}
@@ -0,0 +1,52 @@
+package bus
+
+import (
+	"context"
+	"sync"
+
+	"github.com/naveenpatilm/go-clean-arch/event"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+type syncBus struct {
+	mu     sync.RWMutex
+	subs   []subscription
+	closed bool
+}
+
+// NewSyncBus will create an event.Bus calling the subscribers in the goroutine of
+// the publisher, in their order of subscription, before Publish returns
+func NewSyncBus() event.Bus {
+	return &syncBus{}
+}
+
+func (b *syncBus) Subscribe(h event.Handler, names ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs = append(b.subs, newSubscription(h, names))
+}
+
+func (b *syncBus) Publish(ctx context.Context, events ...models.Event) error {
+	b.mu.RLock()
+	subs, closed := b.subs, b.closed
+	b.mu.RUnlock()
+	if closed {
+		return event.ErrClosed
+	}
+
+	for _, e := range events {
+		for _, s := range subs {
+			if s.matches(e) {
+				s.handle(ctx, e)
+			}
+		}
+	}
+	return nil
+}
+
+func (b *syncBus) Close(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	return nil
+}
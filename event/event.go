@@ -0,0 +1,60 @@
+package event
+
+import (
+	"context"
+	"errors"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+// ErrClosed is returned when publishing on a closed bus
+var ErrClosed = errors.New("event bus is closed")
+
+// Publisher represent the contract the usecases emit domain events through
+type Publisher interface {
+	// Publish delivers events to the subscribers. A failing subscriber never
+	// makes Publish fail.
+	Publish(ctx context.Context, events ...models.Event) error
+}
+
+// Handler reacts to an event. Its error is logged by the bus.
+type Handler func(ctx context.Context, e models.Event) error
+
+// Bus represent an in-process Publisher that subscribers register on
+type Bus interface {
+	Publisher
+	// Subscribe registers h for the events called names, or every event when names is empty
+	Subscribe(h Handler, names ...string)
+	// Close stops accepting events and waits until the pending ones are handled or ctx is done
+	Close(ctx context.Context) error
+}
+
+// PublisherFunc adapts a function to a Publisher
+type PublisherFunc func(ctx context.Context, events ...models.Event) error
+
+func (f PublisherFunc) Publish(ctx context.Context, events ...models.Event) error {
+	return f(ctx, events...)
+}
+
+// Multi returns a Publisher publishing to each of publishers in turn.
+// It returns the first error, after publishing to every publisher.
+func Multi(publishers ...Publisher) Publisher {
+	return PublisherFunc(func(ctx context.Context, events ...models.Event) error {
+		var first error
+		for _, p := range publishers {
+			if err := p.Publish(ctx, events...); err != nil && first == nil {
+				first = err
+			}
+		}
+		return first
+	})
+}
+
+// Discard is a Publisher dropping every event
+var Discard Publisher = discard{}
+
+type discard struct{}
+
+func (discard) Publish(ctx context.Context, events ...models.Event) error {
+	return nil
+}
@@ -0,0 +1,45 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/naveenpatilm/go-clean-arch/health"
+)
+
+// HttpHealthHandler represent the httphandler for the liveness and readiness probes
+type HttpHealthHandler struct {
+	Health *health.Health
+}
+
+func NewHealthHttpHandler(r *mux.Router, h *health.Health) {
+	handler := &HttpHealthHandler{
+		Health: h,
+	}
+	r.HandleFunc("/healthz", handler.Liveness).Methods("GET")
+	r.HandleFunc("/readyz", handler.Readiness).Methods("GET")
+}
+
+// Liveness answers as long as the process can serve requests
+func (h *HttpHealthHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, health.Report{Status: health.StatusOK})
+}
+
+// Readiness answers 503 when a checker fails or the service is draining,
+// 200 when it is only degraded
+func (h *HttpHealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	report := h.Health.Ready(r.Context())
+	status := http.StatusOK
+	if report.Status != health.StatusOK && report.Status != health.StatusDegraded {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, report)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,66 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/health"
+	healthHttp "github.com/naveenpatilm/go-clean-arch/health/delivery/http"
+)
+
+func serve(h *health.Health, path string) *httptest.ResponseRecorder {
+	router := mux.NewRouter()
+	healthHttp.NewHealthHttpHandler(router, h)
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestLiveness(t *testing.T) {
+	h := health.New(time.Second)
+	h.Register("database", health.CheckerFunc(func(ctx context.Context) error {
+		return errors.New("down")
+	}))
+
+	// liveness does not depend on the checkers
+	rec := serve(h, "/healthz")
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestReadiness(t *testing.T) {
+	h := health.New(time.Second)
+	h.Register("database", health.CheckerFunc(func(ctx context.Context) error {
+		return nil
+	}))
+
+	rec := serve(h, "/readyz")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var report health.Report
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, health.StatusOK, report.Checks["database"].Status)
+
+	// the service still answers without its replicas
+	h.Register("replicas", health.CheckerFunc(func(ctx context.Context) error {
+		return health.Degraded(errors.New("every replica is down"))
+	}))
+	rec = serve(h, "/readyz")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, health.StatusDegraded, report.Status)
+
+	h.Drain()
+	rec = serve(h, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, health.StatusDraining, report.Status)
+}
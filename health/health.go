@@ -0,0 +1,168 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Status values of a Report and of each CheckResult
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+	StatusDraining    = "draining"
+	// StatusDegraded means the service works with less redundancy, see Degraded
+	StatusDegraded = "degraded"
+)
+
+// Checker reports whether a dependency of the service is usable
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts a function to a Checker
+type CheckerFunc func(ctx context.Context) error
+
+// Check calls f(ctx)
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// degradedError is a checker error that does not make the service unready
+type degradedError struct {
+	error
+}
+
+// Degraded wraps err so that its checker reports the service degraded
+// rather than unavailable
+func Degraded(err error) error {
+	return degradedError{err}
+}
+
+// Heartbeat is a Checker for a background loop, which calls Beat after each
+// successful poll. It fails once no beat came for longer than maxAge.
+type Heartbeat struct {
+	maxAge time.Duration
+	last   int64
+}
+
+// NewHeartbeat will create a Heartbeat giving its loop maxAge from now for its first beat
+func NewHeartbeat(maxAge time.Duration) *Heartbeat {
+	h := &Heartbeat{maxAge: maxAge}
+	h.Beat()
+	return h
+}
+
+// Beat records a successful poll
+func (h *Heartbeat) Beat() {
+	atomic.StoreInt64(&h.last, time.Now().UnixNano())
+}
+
+// Check fails when the last beat is older than maxAge
+func (h *Heartbeat) Check(ctx context.Context) error {
+	age := time.Since(time.Unix(0, atomic.LoadInt64(&h.last)))
+	if age > h.maxAge {
+		return fmt.Errorf("no successful poll for %s", age.Truncate(time.Second))
+	}
+	return nil
+}
+
+// CheckResult is the outcome of one checker
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the outcome of a readiness check
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type namedChecker struct {
+	name    string
+	checker Checker
+}
+
+// Health runs the registered readiness checkers
+type Health struct {
+	timeout  time.Duration
+	draining int32
+
+	mu       sync.RWMutex
+	checkers []namedChecker
+}
+
+// DefaultTimeout is used by New when no timeout is given
+const DefaultTimeout = 2 * time.Second
+
+// New will create a Health that gives each checker at most timeout to answer
+func New(timeout time.Duration) *Health {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Health{timeout: timeout}
+}
+
+// Register adds a readiness checker under name
+func (h *Health) Register(name string, c Checker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checkers = append(h.checkers, namedChecker{name: name, checker: c})
+}
+
+// Drain marks the service as shutting down: it is no longer ready whatever its checkers say
+func (h *Health) Drain() {
+	atomic.StoreInt32(&h.draining, 1)
+}
+
+// Ready runs every checker concurrently and reports on each of them.
+// The service is degraded when no checker failed but some reported Degraded.
+func (h *Health) Ready(ctx context.Context) Report {
+	h.mu.RLock()
+	checkers := h.checkers
+	h.mu.RUnlock()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(checkers))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range checkers {
+		wg.Add(1)
+		go func(c namedChecker) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, h.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := c.checker.Check(ctx)
+			result := CheckResult{
+				Status:    StatusOK,
+				LatencyMS: float64(time.Since(start)) / float64(time.Millisecond),
+			}
+			if err != nil {
+				result.Status = StatusUnavailable
+				if errors.As(err, new(degradedError)) {
+					result.Status = StatusDegraded
+				}
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[c.name] = result
+			if result.Status == StatusUnavailable || result.Status == StatusDegraded && report.Status == StatusOK {
+				report.Status = result.Status
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	if atomic.LoadInt32(&h.draining) == 1 {
+		report.Status = StatusDraining
+	}
+	return report
+}
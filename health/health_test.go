@@ -0,0 +1,80 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/health"
+)
+
+func TestReady(t *testing.T) {
+	h := health.New(50 * time.Millisecond)
+	h.Register("database", health.CheckerFunc(func(ctx context.Context) error {
+		return nil
+	}))
+	report := h.Ready(context.TODO())
+	assert.Equal(t, health.StatusOK, report.Status)
+	assert.Equal(t, health.StatusOK, report.Checks["database"].Status)
+
+	h.Register("migrations", health.CheckerFunc(func(ctx context.Context) error {
+		return errors.New("schema is behind")
+	}))
+	report = h.Ready(context.TODO())
+	assert.Equal(t, health.StatusUnavailable, report.Status)
+	assert.Equal(t, health.CheckResult{Status: health.StatusUnavailable, Error: "schema is behind", LatencyMS: report.Checks["migrations"].LatencyMS}, report.Checks["migrations"])
+}
+
+func TestReadyCheckTimeout(t *testing.T) {
+	h := health.New(20 * time.Millisecond)
+	h.Register("slow", health.CheckerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+
+	start := time.Now()
+	report := h.Ready(context.TODO())
+
+	assert.True(t, time.Since(start) < time.Second)
+	require.Contains(t, report.Checks, "slow")
+	assert.Equal(t, health.StatusUnavailable, report.Checks["slow"].Status)
+	assert.True(t, report.Checks["slow"].LatencyMS >= 20)
+}
+
+func TestReadyWhileDraining(t *testing.T) {
+	h := health.New(time.Second)
+	h.Drain()
+
+	assert.Equal(t, health.StatusDraining, h.Ready(context.TODO()).Status)
+}
+
+func TestReadyDegraded(t *testing.T) {
+	h := health.New(time.Second)
+	h.Register("replicas", health.CheckerFunc(func(ctx context.Context) error {
+		return health.Degraded(errors.New("every replica is down"))
+	}))
+	report := h.Ready(context.TODO())
+	assert.Equal(t, health.StatusDegraded, report.Status)
+	assert.Equal(t, health.StatusDegraded, report.Checks["replicas"].Status)
+	assert.Equal(t, "every replica is down", report.Checks["replicas"].Error)
+
+	h.Register("database", health.CheckerFunc(func(ctx context.Context) error {
+		return errors.New("down")
+	}))
+	assert.Equal(t, health.StatusUnavailable, h.Ready(context.TODO()).Status)
+}
+
+func TestHeartbeat(t *testing.T) {
+	beat := health.NewHeartbeat(30 * time.Millisecond)
+	assert.NoError(t, beat.Check(context.TODO()))
+
+	time.Sleep(50 * time.Millisecond)
+	assert.EqualError(t, beat.Check(context.TODO()), "no successful poll for 0s")
+
+	beat.Beat()
+	assert.NoError(t, beat.Check(context.TODO()))
+}
@@ -0,0 +1,91 @@
+// Package lease holds what the outbox relay, the webhook dispatcher and the
+// job worker share to claim rows for a while, retry them and save their outcome.
+package lease
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+// FinishTimeout bounds the saving of an outcome, see Finish
+const FinishTimeout = 5 * time.Second
+
+// NewID identifies the process in the leases it takes, for the operators
+func NewID() string {
+	host, _ := os.Hostname()
+	b := make([]byte, 4)
+	rand.Read(b)
+	return fmt.Sprintf("%s:%d:%s", host, os.Getpid(), hex.EncodeToString(b))
+}
+
+// Backoff is the wait before the next attempt once attempts failed: min,
+// doubled with every failed attempt after the first, up to max
+func Backoff(attempts int, min, max time.Duration) time.Duration {
+	wait := min
+	for i := 1; i < attempts && wait < max; i++ {
+		wait *= 2
+	}
+	if wait > max {
+		wait = max
+	}
+	return wait
+}
+
+// Finish calls save with a context of its own, bounded by FinishTimeout:
+// an outcome must be saved, and its lease ended, even when the worker stops
+func Finish(save func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), FinishTimeout)
+	defer cancel()
+	return save(ctx)
+}
+
+// Claim selects rows with query in a transaction on db, and runs update,
+// followed by the IDs of the rows, before committing: update leases the rows
+// while query, e.g. with FOR UPDATE SKIP LOCKED, keeps the other claims off them.
+func Claim[T any](ctx context.Context, db models.Gormw, query string, args []interface{}, id func(T) int64, update string, updateArgs ...interface{}) ([]T, error) {
+	tx := db.BeginTx(ctx, nil)
+	if err := tx.Error(); err != nil {
+		return nil, err
+	}
+
+	var rows []T
+	err := tx.WithContext(ctx).Raw(query, args...).Scan(&rows).Error()
+	if err = models.ContextError(ctx, err); err != nil {
+		Rollback(tx)
+		return nil, err
+	}
+	if len(rows) == 0 {
+		Rollback(tx)
+		return rows, nil
+	}
+
+	ids := make([]int64, len(rows))
+	for i, row := range rows {
+		ids[i] = id(row)
+	}
+	err = tx.WithContext(ctx).Exec(update, append(updateArgs, ids)...).Error()
+	if err = models.ContextError(ctx, err); err != nil {
+		Rollback(tx)
+		return nil, err
+	}
+	if err = tx.Commit().Error(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Rollback ends tx after a failure, only logging its own error since the
+// failure is the one to report
+func Rollback(tx models.Gormw) {
+	if err := tx.Rollback().Error(); err != nil {
+		logrus.Error(err)
+	}
+}
@@ -0,0 +1,36 @@
+package lease_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/naveenpatilm/go-clean-arch/internal/lease"
+)
+
+func TestBackoff(t *testing.T) {
+	for attempts, want := range map[int]time.Duration{
+		1: time.Second,
+		2: 2 * time.Second,
+		3: 4 * time.Second,
+		6: 10 * time.Second,
+	} {
+		assert.Equal(t, want, lease.Backoff(attempts, time.Second, 10*time.Second), "attempt %d", attempts)
+	}
+}
+
+func TestFinishHasItsOwnDeadline(t *testing.T) {
+	err := lease.Finish(func(ctx context.Context) error {
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(lease.FinishTimeout), deadline, time.Second)
+		return ctx.Err()
+	})
+	assert.NoError(t, err)
+}
+
+func TestNewIDIsUnique(t *testing.T) {
+	assert.NotEqual(t, lease.NewID(), lease.NewID())
+}
@@ -0,0 +1,145 @@
+package http
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"github.com/naveenpatilm/go-clean-arch/job"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+// ResponseError represent the reseponse error struct
+type ResponseError struct {
+	Message string `json:"message"`
+}
+
+// HttpJobHandler represent the httphandler for job
+type HttpJobHandler struct {
+	JUsecase job.Usecase
+}
+
+// NewJobHttpHandler registers the API inspecting and retrying the jobs on r.
+// When token is set, its requests need an "Authorization: Bearer <token>" header.
+func NewJobHttpHandler(r *mux.Router, us job.Usecase, token string) {
+	handler := &HttpJobHandler{
+		JUsecase: us,
+	}
+	s := r.PathPrefix("/jobs").Subrouter()
+	if token != "" {
+		s.Use(authorize(token))
+	}
+	s.HandleFunc("", handler.Fetch).Methods("GET")
+	s.HandleFunc("/{id}", handler.GetByID).Methods("GET")
+	s.HandleFunc("/{id}/retry", handler.Retry).Methods("POST")
+}
+
+func authorize(token string) mux.MiddlewareFunc {
+	expected := []byte("Bearer " + token)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="jobs"`)
+				writeJSON(w, http.StatusUnauthorized, ResponseError{Message: "a valid jobs token is required"})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Fetch lists the latest jobs, newest first, filtered by the queue, kind and
+// state query parameters, up to the num query parameter
+func (h *HttpJobHandler) Fetch(w http.ResponseWriter, req *http.Request) {
+	params := req.URL.Query()
+	f := models.JobFilter{Queue: params.Get("queue"), Kind: params.Get("kind"), State: params.Get("state")}
+	if param := params.Get("num"); param != "" {
+		n, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			writeError(w, models.ErrBadParamInput)
+			return
+		}
+		f.Num = n
+	}
+	jobs, err := h.JUsecase.Fetch(req.Context(), f)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+func (h *HttpJobHandler) GetByID(w http.ResponseWriter, req *http.Request) {
+	id, ok := pathID(w, req)
+	if !ok {
+		return
+	}
+	j, err := h.JUsecase.GetByID(req.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, j)
+}
+
+// Retry runs a dead job again and answers 202 with the pending job, or 409 when the job is not dead
+func (h *HttpJobHandler) Retry(w http.ResponseWriter, req *http.Request) {
+	id, ok := pathID(w, req)
+	if !ok {
+		return
+	}
+	j, err := h.JUsecase.Retry(req.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, j)
+}
+
+func pathID(w http.ResponseWriter, req *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(req)["id"], 10, 64)
+	if err != nil {
+		writeError(w, models.ErrBadParamInput)
+		return 0, false
+	}
+	return id, true
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := getStatusCode(err)
+	if status == http.StatusInternalServerError {
+		err = models.ErrInternalServerError
+	}
+	writeJSON(w, status, ResponseError{Message: err.Error()})
+}
+
+// statusClientClosedRequest is the nginx status of a request whose client went away
+const statusClientClosedRequest = 499
+
+func getStatusCode(err error) int {
+	switch err {
+	case models.ErrNotFound:
+		return http.StatusNotFound
+	case models.ErrConflict:
+		return http.StatusConflict
+	case models.ErrBadParamInput:
+		return http.StatusBadRequest
+	case models.ErrTimeout:
+		return http.StatusGatewayTimeout
+	case models.ErrCanceled:
+		return statusClientClosedRequest
+	default:
+		logrus.Error(err)
+		return http.StatusInternalServerError
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
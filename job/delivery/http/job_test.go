@@ -0,0 +1,70 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	jobHttp "github.com/naveenpatilm/go-clean-arch/job/delivery/http"
+	"github.com/naveenpatilm/go-clean-arch/job/mocks"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+func serve(t *testing.T, us *mocks.Usecase, token, method, path string, header http.Header) *httptest.ResponseRecorder {
+	router := mux.NewRouter()
+	jobHttp.NewJobHttpHandler(router, us, token)
+	req, err := http.NewRequest(method, path, nil)
+	require.NoError(t, err)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestFetch(t *testing.T) {
+	mockUCase := new(mocks.Usecase)
+	f := models.JobFilter{Queue: "exports", Kind: "articles.export", State: models.JobDead, Num: 5}
+	mockUCase.On("Fetch", mock.Anything, f).Return([]*models.Job{{ID: 2, State: models.JobDead}}, nil).Once()
+
+	rec := serve(t, mockUCase, "", "GET", "/jobs?queue=exports&kind=articles.export&state=dead&num=5", nil)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var jobs []models.Job
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &jobs))
+	require.Len(t, jobs, 1)
+	assert.Equal(t, int64(2), jobs[0].ID)
+	assert.Equal(t, http.StatusBadRequest, serve(t, mockUCase, "", "GET", "/jobs?num=many", nil).Code)
+	mockUCase.AssertExpectations(t)
+}
+
+func TestRetry(t *testing.T) {
+	mockUCase := new(mocks.Usecase)
+	mockUCase.On("Retry", mock.Anything, int64(2)).Return(&models.Job{ID: 2, State: models.JobPending}, nil).Once()
+	mockUCase.On("Retry", mock.Anything, int64(3)).Return(nil, models.ErrConflict).Once()
+	mockUCase.On("GetByID", mock.Anything, int64(4)).Return(nil, models.ErrNotFound).Once()
+
+	rec := serve(t, mockUCase, "", "POST", "/jobs/2/retry", nil)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"state":"pending"`)
+	assert.Equal(t, http.StatusConflict, serve(t, mockUCase, "", "POST", "/jobs/3/retry", nil).Code)
+	assert.Equal(t, http.StatusNotFound, serve(t, mockUCase, "", "GET", "/jobs/4", nil).Code)
+	mockUCase.AssertExpectations(t)
+}
+
+func TestToken(t *testing.T) {
+	mockUCase := new(mocks.Usecase)
+	mockUCase.On("GetByID", mock.Anything, int64(1)).Return(&models.Job{ID: 1}, nil).Once()
+
+	assert.Equal(t, http.StatusUnauthorized, serve(t, mockUCase, "s3cret", "GET", "/jobs/1", nil).Code)
+	rec := serve(t, mockUCase, "s3cret", "GET", "/jobs/1", http.Header{"Authorization": {"Bearer s3cret"}})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUCase.AssertExpectations(t)
+}
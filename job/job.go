@@ -0,0 +1,132 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+// DefaultQueue is the queue of the kinds registered without one
+const DefaultQueue = "default"
+
+// Handler runs the jobs of a kind. A job whose handler fails is retried,
+// unless the error is Permanent, so handlers must be idempotent.
+type Handler interface {
+	Handle(ctx context.Context, j *models.Job) error
+}
+
+// HandlerFunc adapts a function to a Handler
+type HandlerFunc func(ctx context.Context, j *models.Job) error
+
+func (f HandlerFunc) Handle(ctx context.Context, j *models.Job) error {
+	return f(ctx, j)
+}
+
+// Definition represent a kind of job and how its jobs run
+type Definition struct {
+	Kind    string
+	Handler Handler
+	// Queue defaults to DefaultQueue
+	Queue string
+	// MaxAttempts defaults to the setting of the usecase
+	MaxAttempts int
+	// Timeout bounds each attempt. It defaults to, and can not exceed, the timeout of the worker.
+	Timeout time.Duration
+}
+
+// Registry holds the kinds of job registered at startup
+type Registry interface {
+	// Register adds d, replacing the kind of the same name
+	Register(d Definition)
+	Lookup(kind string) (Definition, bool)
+	// Kinds returns the registered kinds, sorted
+	Kinds() []string
+}
+
+type registry struct {
+	mu          sync.RWMutex
+	definitions map[string]Definition
+}
+
+// NewRegistry will create an empty Registry
+func NewRegistry() Registry {
+	return &registry{definitions: map[string]Definition{}}
+}
+
+func (r *registry) Register(d Definition) {
+	if d.Queue == "" {
+		d.Queue = DefaultQueue
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.definitions[d.Kind] = d
+}
+
+func (r *registry) Lookup(kind string) (Definition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	d, ok := r.definitions[kind]
+	return d, ok
+}
+
+func (r *registry) Kinds() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	kinds := make([]string, 0, len(r.definitions))
+	for kind := range r.definitions {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// permanentError is a failure that retrying does not fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+// Permanent marks err so that the job dies at once instead of being retried
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was returned by Permanent
+func IsPermanent(err error) bool {
+	_, ok := err.(*permanentError)
+	return ok
+}
+
+// Decode unmarshals the payload of j into v. A payload that can not be
+// decoded never will, so its error is Permanent.
+func Decode(j *models.Job, v interface{}) error {
+	if err := json.Unmarshal([]byte(j.Payload), v); err != nil {
+		return Permanent(fmt.Errorf("invalid payload of %s job %d: %v", j.Kind, j.ID, err))
+	}
+	return nil
+}
+
+// EnqueueOptions override the defaults of the kind of an enqueued job
+type EnqueueOptions struct {
+	Queue string
+	// RunAt schedules the job, which runs at once when it is zero
+	RunAt       time.Time
+	UniqueKey   string
+	MaxAttempts int
+}
+
+// Worker represent the pools running the jobs of their queues
+type Worker interface {
+	// RunOnce runs one batch of the due jobs of each queue and returns how many were claimed
+	RunOnce(ctx context.Context) (int, error)
+	// Run runs the jobs until ctx is done, then releases the jobs it did not finish
+	Run(ctx context.Context) error
+}
@@ -0,0 +1,41 @@
+package job_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/naveenpatilm/go-clean-arch/job"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+func TestRegistry(t *testing.T) {
+	r := job.NewRegistry()
+	noop := job.HandlerFunc(func(ctx context.Context, j *models.Job) error { return nil })
+	r.Register(job.Definition{Kind: "b", Handler: noop})
+	r.Register(job.Definition{Kind: "a", Handler: noop, Queue: "exports"})
+
+	d, ok := r.Lookup("b")
+	assert.True(t, ok)
+	assert.Equal(t, job.DefaultQueue, d.Queue)
+	d, _ = r.Lookup("a")
+	assert.Equal(t, "exports", d.Queue)
+	_, ok = r.Lookup("c")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"a", "b"}, r.Kinds())
+}
+
+func TestDecode(t *testing.T) {
+	var payload struct {
+		Name string `json:"name"`
+	}
+	assert.NoError(t, job.Decode(&models.Job{Payload: `{"name": "all"}`}, &payload))
+	assert.Equal(t, "all", payload.Name)
+
+	err := job.Decode(&models.Job{Kind: "export", ID: 3, Payload: `{"name": 3}`}, &payload)
+	assert.Error(t, err)
+	assert.True(t, job.IsPermanent(err))
+	assert.False(t, job.IsPermanent(errors.New("timeout")))
+}
@@ -0,0 +1,32 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+// Repository represent the job's repository contract
+type Repository interface {
+	// Store saves a new job. It returns models.ErrConflict when a pending or
+	// running job has the same ActiveKey.
+	Store(ctx context.Context, j *models.Job) error
+	// Fetch returns the latest jobs matching f, newest first
+	Fetch(ctx context.Context, f models.JobFilter) ([]*models.Job, error)
+	GetByID(ctx context.Context, id int64) (*models.Job, error)
+	// Claim marks up to limit due pending jobs of queue as running, locked by
+	// lockedBy until lease elapsed, and returns them oldest first with their
+	// attempt counted. Jobs claimed by another worker are skipped.
+	Claim(ctx context.Context, queue string, limit int, lockedBy string, lease time.Duration) ([]*models.Job, error)
+	// Finish saves the outcome of a claimed job, j.LockedBy being its claim.
+	// It returns models.ErrNotFound when the job was rescued meanwhile.
+	Finish(ctx context.Context, j *models.Job) error
+	// Rescue makes the running jobs whose lock expired pending again, or dead
+	// when they used their attempts, and returns how many were rescued
+	Rescue(ctx context.Context) (int64, error)
+	// Retry makes a dead job pending again, now, with a fresh number of attempts.
+	// It returns models.ErrConflict when the job is not dead, or when another
+	// job holds its unique key.
+	Retry(ctx context.Context, id int64) (*models.Job, error)
+}
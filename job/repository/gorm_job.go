@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+
+	_lease "github.com/naveenpatilm/go-clean-arch/internal/lease"
+	"github.com/naveenpatilm/go-clean-arch/job"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+// claimQuery selects the due pending jobs of a queue, oldest first
+const claimQuery = `SELECT * FROM jobs
+	WHERE queue = ? AND state = ? AND run_at <= ?
+	ORDER BY run_at, id LIMIT ?`
+
+// errStopped is the error of the jobs rescued from a worker that stopped
+const errStopped = "the worker stopped before the job finished"
+
+const (
+	// activeKeyIndex makes the unique key of a job held by one active job at most
+	activeKeyIndex      = "idx_jobs_active_key"
+	pqUniqueViolation   = "23505" // postgres error code for unique_violation
+	mysqlDuplicateEntry = 1062    // mysql error number for ER_DUP_ENTRY
+)
+
+// gormJobRepository is the job.Repository shared by the SQL databases
+type gormJobRepository struct {
+	DB models.Gormw
+	// lock is appended to claimQuery to lock the claimed rows
+	lock string
+	// isActiveKeyViolation reports whether err was raised by activeKeyIndex
+	isActiveKeyViolation func(err error) bool
+}
+
+// NewPostgresJobRepository will create an object that represent the job.Repository interface on postgres
+func NewPostgresJobRepository(DB models.Gormw) job.Repository {
+	return &gormJobRepository{DB: DB, lock: " FOR UPDATE SKIP LOCKED", isActiveKeyViolation: isPostgresActiveKeyViolation}
+}
+
+// NewMysqlJobRepository will create an object that represent the job.Repository interface on mysql 8
+func NewMysqlJobRepository(DB models.Gormw) job.Repository {
+	return &gormJobRepository{DB: DB, lock: " FOR UPDATE SKIP LOCKED", isActiveKeyViolation: isMysqlActiveKeyViolation}
+}
+
+// NewSqliteJobRepository will create an object that represent the job.Repository interface on sqlite,
+// whose serialized transactions need no row locks
+func NewSqliteJobRepository(DB models.Gormw) job.Repository {
+	return &gormJobRepository{DB: DB, isActiveKeyViolation: isSqliteActiveKeyViolation}
+}
+
+func isPostgresActiveKeyViolation(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == pqUniqueViolation && pqErr.Constraint == activeKeyIndex
+}
+
+// isMysqlActiveKeyViolation matches ER_DUP_ENTRY, which names the index in its message only
+func isMysqlActiveKeyViolation(err error) bool {
+	myErr, ok := err.(*mysql.MySQLError)
+	return ok && myErr.Number == mysqlDuplicateEntry && strings.Contains(myErr.Message, activeKeyIndex)
+}
+
+// isSqliteActiveKeyViolation matches the column of the index, which sqlite names instead
+func isSqliteActiveKeyViolation(err error) bool {
+	sqliteErr, ok := err.(sqlite3.Error)
+	return ok && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique && strings.Contains(sqliteErr.Error(), "jobs.active_key")
+}
+
+func (m *gormJobRepository) Store(ctx context.Context, j *models.Job) error {
+	err := models.ContextError(ctx, m.DB.WithContext(ctx).Create(j).Error())
+	if m.isActiveKeyViolation(err) {
+		return models.ErrConflict
+	}
+	return err
+}
+
+// active reports whether a pending or running job holds key
+func (m *gormJobRepository) active(ctx context.Context, db models.Gormw, key string) (bool, error) {
+	var count int
+	err := db.WithContext(ctx).Model(&models.Job{}).Where("active_key = ?", key).Count(&count).Error()
+	if err = models.ContextError(ctx, err); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (m *gormJobRepository) Fetch(ctx context.Context, f models.JobFilter) ([]*models.Job, error) {
+	query := m.DB.WithContext(ctx)
+	if f.Queue != "" {
+		query = query.Where("queue = ?", f.Queue)
+	}
+	if f.Kind != "" {
+		query = query.Where("kind = ?", f.Kind)
+	}
+	if f.State != "" {
+		query = query.Where("state = ?", f.State)
+	}
+	jobs := []*models.Job{}
+	err := query.Order("id DESC").Limit(int(f.Num)).Find(&jobs).Error()
+	if err = models.ContextError(ctx, err); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (m *gormJobRepository) GetByID(ctx context.Context, id int64) (*models.Job, error) {
+	var j models.Job
+	err := models.ContextError(ctx, m.DB.WithContext(ctx).First(&j, id).Error())
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+func (m *gormJobRepository) Claim(ctx context.Context, queue string, limit int, lockedBy string, lease time.Duration) ([]*models.Job, error) {
+	now := time.Now().UTC()
+	lockedUntil := now.Add(lease)
+	jobs, err := _lease.Claim(ctx, m.DB, claimQuery+m.lock, []interface{}{queue, models.JobPending, now, limit},
+		func(j *models.Job) int64 { return j.ID },
+		`UPDATE jobs
+		SET state = ?, attempts = attempts + 1, locked_by = ?, locked_until = ?, updated_at = ?
+		WHERE id IN (?)`, models.JobRunning, lockedBy, lockedUntil, now)
+	if err != nil {
+		return nil, err
+	}
+	for _, j := range jobs {
+		j.State, j.Attempts, j.LockedBy, j.LockedUntil, j.UpdatedAt = models.JobRunning, j.Attempts+1, lockedBy, &lockedUntil, now
+	}
+	return jobs, nil
+}
+
+func (m *gormJobRepository) Finish(ctx context.Context, j *models.Job) error {
+	res := m.DB.WithContext(ctx).Model(&models.Job{}).
+		Where("id = ? AND state = ? AND locked_by = ?", j.ID, models.JobRunning, j.LockedBy).
+		Updates(map[string]interface{}{
+			"state":        j.State,
+			"attempts":     j.Attempts,
+			"run_at":       j.RunAt,
+			"active_key":   j.ActiveKey,
+			"locked_by":    "",
+			"locked_until": nil,
+			"last_error":   j.LastError,
+			"finished_at":  j.FinishedAt,
+		})
+	if err := models.ContextError(ctx, res.Error()); err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	j.LockedBy, j.LockedUntil = "", nil
+	return nil
+}
+
+func (m *gormJobRepository) Rescue(ctx context.Context) (int64, error) {
+	now := time.Now().UTC()
+	expired := func() models.Gormw {
+		return m.DB.WithContext(ctx).Model(&models.Job{}).Where("state = ? AND locked_until < ?", models.JobRunning, now)
+	}
+	dead := expired().Where("attempts >= max_attempts").Updates(map[string]interface{}{
+		"state":        models.JobDead,
+		"active_key":   nil,
+		"locked_by":    "",
+		"locked_until": nil,
+		"last_error":   errStopped,
+		"finished_at":  now,
+	})
+	if err := models.ContextError(ctx, dead.Error()); err != nil {
+		return 0, err
+	}
+	pending := expired().Updates(map[string]interface{}{
+		"state":        models.JobPending,
+		"run_at":       now,
+		"locked_by":    "",
+		"locked_until": nil,
+		"last_error":   errStopped,
+	})
+	if err := models.ContextError(ctx, pending.Error()); err != nil {
+		return 0, err
+	}
+	return dead.RowsAffected() + pending.RowsAffected(), nil
+}
+
+func (m *gormJobRepository) Retry(ctx context.Context, id int64) (*models.Job, error) {
+	tx := m.DB.BeginTx(ctx, nil)
+	if err := tx.Error(); err != nil {
+		return nil, err
+	}
+
+	var j models.Job
+	err := models.ContextError(ctx, tx.WithContext(ctx).First(&j, id).Error())
+	if gorm.IsRecordNotFoundError(err) {
+		err = models.ErrNotFound
+	}
+	if err == nil && j.State != models.JobDead {
+		err = models.ErrConflict
+	}
+	if err == nil && j.UniqueKey != "" {
+		var active bool
+		if active, err = m.active(ctx, tx, j.UniqueKey); err == nil && active {
+			err = models.ErrConflict
+		}
+		key := j.UniqueKey
+		j.ActiveKey = &key
+	}
+	if err != nil {
+		_lease.Rollback(tx)
+		return nil, err
+	}
+
+	j.State, j.Attempts, j.RunAt, j.FinishedAt = models.JobPending, 0, time.Now().UTC(), nil
+	err = tx.WithContext(ctx).Model(&j).Updates(map[string]interface{}{
+		"state":       j.State,
+		"attempts":    j.Attempts,
+		"run_at":      j.RunAt,
+		"active_key":  j.ActiveKey,
+		"finished_at": nil,
+	}).Error()
+	if err = models.ContextError(ctx, err); err != nil {
+		_lease.Rollback(tx)
+		if m.isActiveKeyViolation(err) {
+			// a job holding the key was stored meanwhile
+			err = models.ErrConflict
+		}
+		return nil, err
+	}
+	if err = tx.Commit().Error(); err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
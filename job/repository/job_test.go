@@ -0,0 +1,196 @@
+package repository_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/database"
+	"github.com/naveenpatilm/go-clean-arch/job"
+	_jobRepo "github.com/naveenpatilm/go-clean-arch/job/repository"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+// forEachRepo runs the same behaviour test against sqlite and the memory repository
+func forEachRepo(t *testing.T, test func(t *testing.T, repo job.Repository)) {
+	dir, err := ioutil.TempDir("", "job")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	db, err := database.Open(database.Config{Driver: database.Sqlite, Path: filepath.Join(dir, "article.db")})
+	require.NoError(t, err)
+	defer db.Close()
+	migrator, err := database.NewMigrator(db.DB(), database.Sqlite)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Up(context.TODO()))
+
+	for name, repo := range map[string]job.Repository{"sqlite": _jobRepo.NewSqliteJobRepository(db), "memory": _jobRepo.NewMemoryJobRepository()} {
+		t.Run(name, func(t *testing.T) {
+			test(t, repo)
+		})
+	}
+}
+
+func newJob(queue, key string, runAt time.Time) *models.Job {
+	j := &models.Job{Kind: "test", Queue: queue, Payload: "{}", State: models.JobPending, MaxAttempts: 2, RunAt: runAt.UTC(), UniqueKey: key}
+	if key != "" {
+		j.ActiveKey = &key
+	}
+	return j
+}
+
+func store(t *testing.T, repo job.Repository, j *models.Job) *models.Job {
+	require.NoError(t, repo.Store(context.TODO(), j))
+	return j
+}
+
+func claim(t *testing.T, repo job.Repository, queue string, lease time.Duration) []int64 {
+	jobs, err := repo.Claim(context.TODO(), queue, 10, "worker-1", lease)
+	require.NoError(t, err)
+	ids := []int64{}
+	for _, j := range jobs {
+		assert.Equal(t, models.JobRunning, j.State)
+		assert.Equal(t, "worker-1", j.LockedBy)
+		ids = append(ids, j.ID)
+	}
+	return ids
+}
+
+func TestStoreAndFetch(t *testing.T) {
+	forEachRepo(t, func(t *testing.T, repo job.Repository) {
+		ctx := context.TODO()
+		first := store(t, repo, newJob("default", "export", time.Now()))
+		assert.Equal(t, models.ErrConflict, repo.Store(ctx, newJob("default", "export", time.Now())))
+		second := store(t, repo, newJob("other", "", time.Now()))
+
+		jobs, err := repo.Fetch(ctx, models.JobFilter{Num: 10})
+		require.NoError(t, err)
+		require.Len(t, jobs, 2)
+		assert.Equal(t, second.ID, jobs[0].ID)
+		jobs, err = repo.Fetch(ctx, models.JobFilter{Queue: "default", State: models.JobPending, Num: 10})
+		require.NoError(t, err)
+		require.Len(t, jobs, 1)
+		assert.Equal(t, "export", jobs[0].UniqueKey)
+
+		j, err := repo.GetByID(ctx, first.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "test", j.Kind)
+		_, err = repo.GetByID(ctx, 999)
+		assert.Equal(t, models.ErrNotFound, err)
+	})
+}
+
+func TestClaimAndFinish(t *testing.T) {
+	forEachRepo(t, func(t *testing.T, repo job.Repository) {
+		ctx := context.TODO()
+		late := store(t, repo, newJob("default", "", time.Now().Add(-time.Minute)))
+		early := store(t, repo, newJob("default", "export", time.Now().Add(-time.Hour)))
+		store(t, repo, newJob("default", "", time.Now().Add(time.Hour)))
+		other := store(t, repo, newJob("other", "", time.Now()))
+
+		// due jobs only, the oldest run_at first
+		assert.Equal(t, []int64{early.ID, late.ID}, claim(t, repo, "default", time.Minute))
+		assert.Empty(t, claim(t, repo, "default", time.Minute))
+		assert.Equal(t, []int64{other.ID}, claim(t, repo, "other", time.Minute))
+
+		j, err := repo.GetByID(ctx, early.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, j.Attempts)
+		j.LockedBy = "worker-2"
+		assert.Equal(t, models.ErrNotFound, repo.Finish(ctx, j))
+
+		now := time.Now().UTC()
+		j.LockedBy, j.State, j.FinishedAt, j.ActiveKey = "worker-1", models.JobSucceeded, &now, nil
+		require.NoError(t, repo.Finish(ctx, j))
+		j, err = repo.GetByID(ctx, early.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.JobSucceeded, j.State)
+		assert.Nil(t, j.LockedUntil)
+		// a finished job frees its unique key
+		store(t, repo, newJob("default", "export", time.Now()))
+	})
+}
+
+func TestRescueAndRetry(t *testing.T) {
+	forEachRepo(t, func(t *testing.T, repo job.Repository) {
+		ctx := context.TODO()
+		last := newJob("default", "export", time.Now())
+		last.MaxAttempts = 1
+		store(t, repo, last)
+		retried := store(t, repo, newJob("default", "", time.Now()))
+		// the lease of a worker that stopped at once has already expired
+		claim(t, repo, "default", -time.Second)
+
+		rescued, err := repo.Rescue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), rescued)
+		j, err := repo.GetByID(ctx, retried.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.JobPending, j.State)
+		assert.Equal(t, 1, j.Attempts)
+		assert.NotEmpty(t, j.LastError)
+		j, err = repo.GetByID(ctx, last.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.JobDead, j.State)
+
+		_, err = repo.Retry(ctx, retried.ID)
+		assert.Equal(t, models.ErrConflict, err, "only dead jobs are retried")
+		_, err = repo.Retry(ctx, 999)
+		assert.Equal(t, models.ErrNotFound, err)
+
+		blocking := store(t, repo, newJob("other", "export", time.Now()))
+		_, err = repo.Retry(ctx, last.ID)
+		assert.Equal(t, models.ErrConflict, err, "another job holds the unique key")
+		claim(t, repo, "other", time.Minute)
+		blocking.LockedBy, blocking.State, blocking.ActiveKey = "worker-1", models.JobSucceeded, nil
+		require.NoError(t, repo.Finish(ctx, blocking))
+
+		j, err = repo.Retry(ctx, last.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.JobPending, j.State)
+		assert.Equal(t, 0, j.Attempts)
+		assert.Equal(t, models.ErrConflict, repo.Store(ctx, newJob("default", "export", time.Now())))
+	})
+}
+
+func TestStoreMapsTheActiveKeyViolation(t *testing.T) {
+	for name, tc := range map[string]struct {
+		newRepo func(models.Gormw) job.Repository
+		expect  func(mock sqlmock.Sqlmock)
+	}{
+		database.Postgres: {
+			newRepo: _jobRepo.NewPostgresJobRepository,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO "jobs"`).
+					WillReturnError(&pq.Error{Code: "23505", Constraint: "idx_jobs_active_key"})
+			},
+		},
+		database.Mysql: {
+			newRepo: _jobRepo.NewMysqlJobRepository,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("INSERT INTO `jobs`").
+					WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'export' for key 'jobs.idx_jobs_active_key'"})
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			gormDB, err := models.Openw(name, db)
+			require.NoError(t, err)
+			tc.expect(mock)
+
+			err = tc.newRepo(gormDB).Store(context.TODO(), newJob("default", "export", time.Now()))
+			assert.Equal(t, models.ErrConflict, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
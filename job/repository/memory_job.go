@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/naveenpatilm/go-clean-arch/job"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+type memoryJobRepository struct {
+	mu     sync.Mutex
+	lastID int64
+	jobs   map[int64]models.Job
+}
+
+// NewMemoryJobRepository will create an object that represent the job.Repository interface in memory
+func NewMemoryJobRepository() job.Repository {
+	return &memoryJobRepository{jobs: map[int64]models.Job{}}
+}
+
+// active reports whether a pending or running job holds key, m.mu being held
+func (m *memoryJobRepository) active(key string) bool {
+	for _, j := range m.jobs {
+		if j.ActiveKey != nil && *j.ActiveKey == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *memoryJobRepository) Store(ctx context.Context, j *models.Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if j.ActiveKey != nil && m.active(*j.ActiveKey) {
+		return models.ErrConflict
+	}
+	m.lastID++
+	now := time.Now().UTC()
+	j.ID, j.CreatedAt, j.UpdatedAt = m.lastID, now, now
+	m.jobs[j.ID] = *j
+	return nil
+}
+
+func (m *memoryJobRepository) Fetch(ctx context.Context, f models.JobFilter) ([]*models.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	jobs := []*models.Job{}
+	for _, j := range m.jobs {
+		if (f.Queue != "" && j.Queue != f.Queue) || (f.Kind != "" && j.Kind != f.Kind) || (f.State != "" && j.State != f.State) {
+			continue
+		}
+		j := j
+		jobs = append(jobs, &j)
+	}
+	sort.Slice(jobs, func(a, b int) bool { return jobs[a].ID > jobs[b].ID })
+	if int64(len(jobs)) > f.Num {
+		jobs = jobs[:f.Num]
+	}
+	return jobs, nil
+}
+
+func (m *memoryJobRepository) GetByID(ctx context.Context, id int64) (*models.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return &j, nil
+}
+
+func (m *memoryJobRepository) Claim(ctx context.Context, queue string, limit int, lockedBy string, lease time.Duration) ([]*models.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now().UTC()
+	jobs := []*models.Job{}
+	for _, j := range m.jobs {
+		if j.Queue == queue && j.State == models.JobPending && !j.RunAt.After(now) {
+			j := j
+			jobs = append(jobs, &j)
+		}
+	}
+	sort.Slice(jobs, func(a, b int) bool {
+		if !jobs[a].RunAt.Equal(jobs[b].RunAt) {
+			return jobs[a].RunAt.Before(jobs[b].RunAt)
+		}
+		return jobs[a].ID < jobs[b].ID
+	})
+	if len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	lockedUntil := now.Add(lease)
+	for _, j := range jobs {
+		j.State, j.Attempts, j.LockedBy, j.LockedUntil, j.UpdatedAt = models.JobRunning, j.Attempts+1, lockedBy, &lockedUntil, now
+		m.jobs[j.ID] = *j
+	}
+	return jobs, nil
+}
+
+func (m *memoryJobRepository) Finish(ctx context.Context, j *models.Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	current, ok := m.jobs[j.ID]
+	if !ok || current.State != models.JobRunning || current.LockedBy != j.LockedBy {
+		return models.ErrNotFound
+	}
+	j.LockedBy, j.LockedUntil, j.UpdatedAt = "", nil, time.Now().UTC()
+	m.jobs[j.ID] = *j
+	return nil
+}
+
+func (m *memoryJobRepository) Rescue(ctx context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now().UTC()
+	var rescued int64
+	for id, j := range m.jobs {
+		if j.State != models.JobRunning || j.LockedUntil == nil || !j.LockedUntil.Before(now) {
+			continue
+		}
+		j.LockedBy, j.LockedUntil, j.LastError, j.UpdatedAt = "", nil, errStopped, now
+		if j.Attempts >= j.MaxAttempts {
+			j.State, j.ActiveKey, j.FinishedAt = models.JobDead, nil, &now
+		} else {
+			j.State, j.RunAt = models.JobPending, now
+		}
+		m.jobs[id] = j
+		rescued++
+	}
+	return rescued, nil
+}
+
+func (m *memoryJobRepository) Retry(ctx context.Context, id int64) (*models.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	if j.State != models.JobDead {
+		return nil, models.ErrConflict
+	}
+	if j.UniqueKey != "" {
+		if m.active(j.UniqueKey) {
+			return nil, models.ErrConflict
+		}
+		key := j.UniqueKey
+		j.ActiveKey = &key
+	}
+	now := time.Now().UTC()
+	j.State, j.Attempts, j.RunAt, j.FinishedAt, j.UpdatedAt = models.JobPending, 0, now, nil, now
+	m.jobs[id] = j
+	return &j, nil
+}
@@ -0,0 +1,18 @@
+package job
+
+import (
+	"context"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+// Usecase represent the job's usecases
+type Usecase interface {
+	// Enqueue saves a job of a registered kind, payload being encoded as JSON.
+	// It returns models.ErrConflict when a job with the same unique key is pending or running.
+	Enqueue(ctx context.Context, kind string, payload interface{}, opts EnqueueOptions) (*models.Job, error)
+	Fetch(ctx context.Context, f models.JobFilter) ([]*models.Job, error)
+	GetByID(ctx context.Context, id int64) (*models.Job, error)
+	// Retry runs a dead job again
+	Retry(ctx context.Context, id int64) (*models.Job, error)
+}
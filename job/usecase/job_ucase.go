@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/naveenpatilm/go-clean-arch/job"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+const defaultJobs = 20
+
+type jobUsecase struct {
+	repo     job.Repository
+	registry job.Registry
+	// maxAttempts is the default of the kinds registered without one
+	maxAttempts    int
+	contextTimeout time.Duration
+}
+
+// NewJobUsecase will create new a jobUsecase object representation of job.Usecase interface.
+// Only the kinds of registry can be enqueued.
+func NewJobUsecase(repo job.Repository, registry job.Registry, maxAttempts int, timeout time.Duration) job.Usecase {
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+	return &jobUsecase{repo: repo, registry: registry, maxAttempts: maxAttempts, contextTimeout: timeout}
+}
+
+func (u *jobUsecase) Enqueue(c context.Context, kind string, payload interface{}, opts job.EnqueueOptions) (*models.Job, error) {
+	def, ok := u.registry.Lookup(kind)
+	if !ok || opts.MaxAttempts < 0 {
+		return nil, models.ErrBadParamInput
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, models.ErrBadParamInput
+	}
+
+	j := &models.Job{
+		Kind:        kind,
+		Queue:       def.Queue,
+		Payload:     string(data),
+		State:       models.JobPending,
+		MaxAttempts: def.MaxAttempts,
+		RunAt:       opts.RunAt.UTC(),
+		UniqueKey:   opts.UniqueKey,
+	}
+	if opts.Queue != "" {
+		j.Queue = opts.Queue
+	}
+	if opts.MaxAttempts > 0 {
+		j.MaxAttempts = opts.MaxAttempts
+	}
+	if j.MaxAttempts <= 0 {
+		j.MaxAttempts = u.maxAttempts
+	}
+	if opts.RunAt.IsZero() {
+		j.RunAt = time.Now().UTC()
+	}
+	if opts.UniqueKey != "" {
+		key := opts.UniqueKey
+		j.ActiveKey = &key
+	}
+
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+	if err = u.repo.Store(ctx, j); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func (u *jobUsecase) Fetch(c context.Context, f models.JobFilter) ([]*models.Job, error) {
+	if f.Num <= 0 {
+		f.Num = defaultJobs
+	}
+	switch f.State {
+	case "", models.JobPending, models.JobRunning, models.JobSucceeded, models.JobDead:
+	default:
+		return nil, models.ErrBadParamInput
+	}
+
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+	return u.repo.Fetch(ctx, f)
+}
+
+func (u *jobUsecase) GetByID(c context.Context, id int64) (*models.Job, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+	return u.repo.GetByID(ctx, id)
+}
+
+func (u *jobUsecase) Retry(c context.Context, id int64) (*models.Job, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+	return u.repo.Retry(ctx, id)
+}
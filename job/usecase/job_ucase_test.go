@@ -0,0 +1,76 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/job"
+	"github.com/naveenpatilm/go-clean-arch/job/mocks"
+	ucase "github.com/naveenpatilm/go-clean-arch/job/usecase"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+func newRegistry() job.Registry {
+	r := job.NewRegistry()
+	noop := job.HandlerFunc(func(ctx context.Context, j *models.Job) error { return nil })
+	r.Register(job.Definition{Kind: "articles.export", Handler: noop, Queue: "exports", MaxAttempts: 3})
+	r.Register(job.Definition{Kind: "notify", Handler: noop})
+	return r
+}
+
+func TestEnqueue(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockRepo.On("Store", mock.Anything, mock.AnythingOfType("*models.Job")).Return(nil).Twice()
+	u := ucase.NewJobUsecase(mockRepo, newRegistry(), 10, time.Second)
+
+	j, err := u.Enqueue(context.TODO(), "articles.export", map[string]string{"name": "all.jsonl"}, job.EnqueueOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "exports", j.Queue)
+	assert.Equal(t, models.JobPending, j.State)
+	assert.Equal(t, 3, j.MaxAttempts)
+	assert.JSONEq(t, `{"name": "all.jsonl"}`, j.Payload)
+	assert.WithinDuration(t, time.Now(), j.RunAt, time.Second)
+	assert.Nil(t, j.ActiveKey)
+
+	runAt := time.Now().Add(time.Hour)
+	j, err = u.Enqueue(context.TODO(), "notify", nil, job.EnqueueOptions{Queue: "mail", RunAt: runAt, UniqueKey: "author-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "mail", j.Queue)
+	assert.Equal(t, 10, j.MaxAttempts, "the default of the usecase")
+	assert.True(t, runAt.Equal(j.RunAt))
+	require.NotNil(t, j.ActiveKey)
+	assert.Equal(t, "author-1", *j.ActiveKey)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEnqueueInvalid(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockRepo.On("Store", mock.Anything, mock.Anything).Return(models.ErrConflict).Once()
+	u := ucase.NewJobUsecase(mockRepo, newRegistry(), 10, time.Second)
+
+	_, err := u.Enqueue(context.TODO(), "unknown", nil, job.EnqueueOptions{})
+	assert.Equal(t, models.ErrBadParamInput, err)
+	_, err = u.Enqueue(context.TODO(), "notify", make(chan int), job.EnqueueOptions{})
+	assert.Equal(t, models.ErrBadParamInput, err)
+	_, err = u.Enqueue(context.TODO(), "notify", nil, job.EnqueueOptions{UniqueKey: "author-1"})
+	assert.Equal(t, models.ErrConflict, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFetch(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockRepo.On("Fetch", mock.Anything, models.JobFilter{State: models.JobDead, Num: 20}).Return([]*models.Job{{ID: 1}}, nil).Once()
+	u := ucase.NewJobUsecase(mockRepo, newRegistry(), 10, time.Second)
+
+	jobs, err := u.Fetch(context.TODO(), models.JobFilter{State: models.JobDead})
+	require.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	_, err = u.Fetch(context.TODO(), models.JobFilter{State: "lost"})
+	assert.Equal(t, models.ErrBadParamInput, err)
+	mockRepo.AssertExpectations(t)
+}
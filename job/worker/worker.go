@@ -0,0 +1,237 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/naveenpatilm/go-clean-arch/internal/lease"
+	"github.com/naveenpatilm/go-clean-arch/job"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+// Config represent the pools of the worker and how their jobs are retried
+type Config struct {
+	// Queues is how many jobs of each queue run at once
+	Queues map[string]int
+	// PollInterval is the wait between two polls of a queue with no due job
+	PollInterval time.Duration
+	// Timeout bounds each attempt of a job
+	Timeout time.Duration
+	// MinBackoff is the wait before the first retry of a job. It doubles with
+	// every failed attempt, up to MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// RescueInterval is the wait between two rescues of the jobs of stopped workers
+	RescueInterval time.Duration
+	// Polled, when set, is called by the pool of each queue after a claim
+	// without error, or when the pool is full and needs no claim
+	Polled func()
+}
+
+// leaseMargin is how long after its timeout a job is known to be abandoned
+const leaseMargin = time.Minute
+
+type worker struct {
+	repo     job.Repository
+	registry job.Registry
+	c        Config
+	// id locks the jobs claimed by this worker
+	id string
+}
+
+// NewWorker will create an implementation of job.Worker running the jobs of repo with the handlers of registry
+func NewWorker(repo job.Repository, registry job.Registry, c Config) job.Worker {
+	if len(c.Queues) == 0 {
+		c.Queues = map[string]int{job.DefaultQueue: 1}
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = time.Second
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 5 * time.Minute
+	}
+	if c.MinBackoff <= 0 {
+		c.MinBackoff = time.Second
+	}
+	if c.MaxBackoff < c.MinBackoff {
+		c.MaxBackoff = c.MinBackoff
+	}
+	if c.RescueInterval <= 0 {
+		c.RescueInterval = time.Minute
+	}
+	return &worker{repo: repo, registry: registry, c: c, id: lease.NewID()}
+}
+
+func (w *worker) RunOnce(ctx context.Context) (int, error) {
+	if _, err := w.repo.Rescue(ctx); err != nil {
+		return 0, err
+	}
+	claimed := 0
+	var wg sync.WaitGroup
+	for _, queue := range w.queues() {
+		jobs, err := w.repo.Claim(ctx, queue, w.c.Queues[queue], w.id, w.c.Timeout+leaseMargin)
+		if err != nil {
+			wg.Wait()
+			return claimed, err
+		}
+		claimed += len(jobs)
+		for _, j := range jobs {
+			wg.Add(1)
+			go func(j *models.Job) {
+				defer wg.Done()
+				w.run(ctx, j)
+			}(j)
+		}
+	}
+	wg.Wait()
+	return claimed, nil
+}
+
+func (w *worker) queues() []string {
+	queues := make([]string, 0, len(w.c.Queues))
+	for queue := range w.c.Queues {
+		queues = append(queues, queue)
+	}
+	sort.Strings(queues)
+	return queues
+}
+
+func (w *worker) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, queue := range w.queues() {
+		wg.Add(1)
+		go func(queue string) {
+			defer wg.Done()
+			w.runQueue(ctx, queue, w.c.Queues[queue])
+		}(queue)
+	}
+	for {
+		if _, err := w.repo.Rescue(ctx); err != nil && ctx.Err() == nil {
+			logrus.Error("job worker: ", err)
+		}
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return nil
+		case <-time.After(w.c.RescueInterval):
+		}
+	}
+}
+
+// runQueue runs up to concurrency jobs of queue at once until ctx is done,
+// then waits for the running jobs
+func (w *worker) runQueue(ctx context.Context, queue string, concurrency int) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	running := 0
+	// finished receives a value whenever a job of the pool finishes
+	finished := make(chan struct{}, concurrency)
+	for {
+		free := concurrency - running
+		wait := w.c.PollInterval
+		if free > 0 {
+			jobs, err := w.repo.Claim(ctx, queue, free, w.id, w.c.Timeout+leaseMargin)
+			if err != nil && ctx.Err() == nil {
+				logrus.WithField("queue", queue).Error("job worker: ", err)
+			}
+			for _, j := range jobs {
+				running++
+				wg.Add(1)
+				go func(j *models.Job) {
+					defer wg.Done()
+					w.run(ctx, j)
+					finished <- struct{}{}
+				}(j)
+			}
+			// filling the pool means more jobs are likely due
+			if err == nil && len(jobs) == free {
+				wait = 0
+			}
+			if err == nil {
+				w.polled()
+			}
+		} else {
+			w.polled()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-finished:
+			running--
+		case <-time.After(wait):
+		}
+		// collect every job that finished meanwhile
+		for drained := false; !drained; {
+			select {
+			case <-finished:
+				running--
+			default:
+				drained = true
+			}
+		}
+	}
+}
+
+func (w *worker) polled() {
+	if w.c.Polled != nil {
+		w.c.Polled()
+	}
+}
+
+// run makes one attempt of j and saves its outcome
+func (w *worker) run(ctx context.Context, j *models.Job) {
+	fields := logrus.Fields{"job_id": j.ID, "kind": j.Kind, "queue": j.Queue, "attempts": j.Attempts}
+	err := w.handle(ctx, j)
+
+	now := time.Now().UTC()
+	switch {
+	case err == nil:
+		j.State, j.FinishedAt, j.ActiveKey, j.LastError = models.JobSucceeded, &now, nil, ""
+	case ctx.Err() != nil:
+		// the worker stops: the attempt did not fail, it runs again at once
+		j.State, j.Attempts, j.RunAt, j.LastError = models.JobPending, j.Attempts-1, now, err.Error()
+		logrus.WithFields(fields).Info("job released: ", err)
+	case job.IsPermanent(err) || j.Attempts >= j.MaxAttempts:
+		j.State, j.FinishedAt, j.ActiveKey, j.LastError = models.JobDead, &now, nil, err.Error()
+		logrus.WithFields(fields).Error("job failed: ", err)
+	default:
+		j.State, j.RunAt, j.LastError = models.JobPending, now.Add(lease.Backoff(j.Attempts, w.c.MinBackoff, w.c.MaxBackoff)), err.Error()
+		logrus.WithFields(fields).WithField("retry_at", j.RunAt).Warn("job attempt failed: ", err)
+	}
+
+	err = lease.Finish(func(ctx context.Context) error {
+		return w.repo.Finish(ctx, j)
+	})
+	if err != nil {
+		if err == models.ErrNotFound {
+			err = fmt.Errorf("job %d was rescued from this worker, its outcome is lost", j.ID)
+		}
+		logrus.WithFields(fields).Error(err)
+	}
+}
+
+// handle calls the handler of j, turning its panics into errors
+func (w *worker) handle(ctx context.Context, j *models.Job) (err error) {
+	def, ok := w.registry.Lookup(j.Kind)
+	if !ok {
+		return job.Permanent(fmt.Errorf("no handler is registered for the kind %q", j.Kind))
+	}
+	timeout := w.c.Timeout
+	if def.Timeout > 0 && def.Timeout < timeout {
+		timeout = def.Timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return def.Handler.Handle(ctx, j)
+}
@@ -0,0 +1,149 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/job"
+	_jobRepo "github.com/naveenpatilm/go-clean-arch/job/repository"
+	"github.com/naveenpatilm/go-clean-arch/job/worker"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+func newJob(t *testing.T, repo job.Repository, kind string, maxAttempts int) *models.Job {
+	j := &models.Job{Kind: kind, Queue: job.DefaultQueue, Payload: "{}", State: models.JobPending, MaxAttempts: maxAttempts, RunAt: time.Now().UTC()}
+	require.NoError(t, repo.Store(context.TODO(), j))
+	return j
+}
+
+func get(t *testing.T, repo job.Repository, id int64) *models.Job {
+	j, err := repo.GetByID(context.TODO(), id)
+	require.NoError(t, err)
+	return j
+}
+
+func TestRunOnce(t *testing.T) {
+	repo := _jobRepo.NewMemoryJobRepository()
+	registry := job.NewRegistry()
+	register := func(kind string, err error) {
+		registry.Register(job.Definition{Kind: kind, Handler: job.HandlerFunc(func(ctx context.Context, j *models.Job) error {
+			return err
+		})})
+	}
+	register("ok", nil)
+	register("flaky", errors.New("timeout"))
+	register("broken", job.Permanent(errors.New("invalid payload")))
+	registry.Register(job.Definition{Kind: "panics", Handler: job.HandlerFunc(func(ctx context.Context, j *models.Job) error {
+		panic("nil map")
+	})})
+	ok := newJob(t, repo, "ok", 3)
+	flaky := newJob(t, repo, "flaky", 2)
+	broken := newJob(t, repo, "broken", 3)
+	panics := newJob(t, repo, "panics", 3)
+	unknown := newJob(t, repo, "unknown", 3)
+	w := worker.NewWorker(repo, registry, worker.Config{
+		Queues:     map[string]int{job.DefaultQueue: 10},
+		MinBackoff: time.Millisecond,
+		MaxBackoff: time.Millisecond,
+	})
+
+	claimed, err := w.RunOnce(context.TODO())
+
+	require.NoError(t, err)
+	assert.Equal(t, 5, claimed)
+	j := get(t, repo, ok.ID)
+	assert.Equal(t, models.JobSucceeded, j.State)
+	assert.NotNil(t, j.FinishedAt)
+	j = get(t, repo, flaky.ID)
+	assert.Equal(t, models.JobPending, j.State)
+	assert.Equal(t, 1, j.Attempts)
+	assert.Equal(t, "timeout", j.LastError)
+	assert.Equal(t, models.JobDead, get(t, repo, broken.ID).State, "a permanent error is not retried")
+	assert.Equal(t, models.JobDead, get(t, repo, unknown.ID).State)
+	j = get(t, repo, panics.ID)
+	assert.Equal(t, models.JobPending, j.State)
+	assert.Equal(t, "panic: nil map", j.LastError)
+
+	// the second attempt of flaky is its last
+	time.Sleep(5 * time.Millisecond)
+	_, err = w.RunOnce(context.TODO())
+	require.NoError(t, err)
+	j = get(t, repo, flaky.ID)
+	assert.Equal(t, models.JobDead, j.State)
+	assert.Equal(t, 2, j.Attempts)
+}
+
+func TestRunConcurrency(t *testing.T) {
+	repo := _jobRepo.NewMemoryJobRepository()
+	registry := job.NewRegistry()
+	var mu sync.Mutex
+	running, max := 0, 0
+	registry.Register(job.Definition{Kind: "slow", Handler: job.HandlerFunc(func(ctx context.Context, j *models.Job) error {
+		mu.Lock()
+		running++
+		if running > max {
+			max = running
+		}
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	})})
+	for i := 0; i < 6; i++ {
+		newJob(t, repo, "slow", 1)
+	}
+	ctx, stop := context.WithCancel(context.TODO())
+	done := make(chan error)
+	go func() {
+		done <- worker.NewWorker(repo, registry, worker.Config{
+			Queues:       map[string]int{job.DefaultQueue: 2},
+			PollInterval: time.Millisecond,
+		}).Run(ctx)
+	}()
+
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		jobs, err := repo.Fetch(context.TODO(), models.JobFilter{State: models.JobSucceeded, Num: 10})
+		require.NoError(t, err)
+		if len(jobs) == 6 {
+			break
+		}
+		require.True(t, time.Now().Before(deadline), "%d jobs succeeded", len(jobs))
+	}
+	stop()
+	require.NoError(t, <-done)
+	assert.Equal(t, 2, max)
+}
+
+func TestRunReleasesOnStop(t *testing.T) {
+	repo := _jobRepo.NewMemoryJobRepository()
+	registry := job.NewRegistry()
+	started := make(chan struct{})
+	registry.Register(job.Definition{Kind: "long", Handler: job.HandlerFunc(func(ctx context.Context, j *models.Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})})
+	long := newJob(t, repo, "long", 1)
+	ctx, stop := context.WithCancel(context.TODO())
+	done := make(chan error)
+	go func() {
+		done <- worker.NewWorker(repo, registry, worker.Config{PollInterval: time.Millisecond}).Run(ctx)
+	}()
+
+	<-started
+	stop()
+	require.NoError(t, <-done)
+
+	// the interrupted attempt is not counted
+	j := get(t, repo, long.ID)
+	assert.Equal(t, models.JobPending, j.State)
+	assert.Equal(t, 0, j.Attempts)
+}
@@ -9,5 +9,11 @@ type Article struct {
 	DeletedAt *time.Time
 	Title     string `json:"title" validate:"required"`
 	Content   string `json:"content" validate:"required"`
-	Author    Author `json:"author"`
+	AuthorID  int64  `json:"-"`
+	Author    Author `json:"author" gorm:"association_autocreate:false;association_autoupdate:false"`
+}
+
+// AfterFind restores the author reference that is persisted as author_id
+func (a *Article) AfterFind() {
+	a.Author.ID = a.AuthorID
 }
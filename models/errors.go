@@ -8,4 +8,6 @@ var (
 	ErrConflict            = errors.New("Your Item already exist")
 	ErrBadParamInput       = errors.New("Given Param is not valid")
 	ErrUnprocessableEntity = errors.New("invalid request")
+	ErrTimeout             = errors.New("Request timed out")
+	ErrCanceled            = errors.New("Request canceled")
 )
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Names of the article events
+const (
+	ArticleCreatedEvent = "article.created"
+	ArticleUpdatedEvent = "article.updated"
+	ArticleDeletedEvent = "article.deleted"
+)
+
+// Event represent a change of an article that already happened
+type Event interface {
+	// EventName is one of the article event names, e.g. article.created
+	EventName() string
+	// EventArticle is the article after the change, or as it was before its deletion
+	EventArticle() Article
+	EventTime() time.Time
+}
+
+// ArticleCreated is emitted once an article is stored
+type ArticleCreated struct {
+	Article    Article   `json:"article"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (e ArticleCreated) EventName() string     { return ArticleCreatedEvent }
+func (e ArticleCreated) EventArticle() Article { return e.Article }
+func (e ArticleCreated) EventTime() time.Time  { return e.OccurredAt }
+
+// ArticleUpdated is emitted once an article is updated
+type ArticleUpdated struct {
+	Article    Article   `json:"article"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (e ArticleUpdated) EventName() string     { return ArticleUpdatedEvent }
+func (e ArticleUpdated) EventArticle() Article { return e.Article }
+func (e ArticleUpdated) EventTime() time.Time  { return e.OccurredAt }
+
+// ArticleDeleted is emitted once an article is deleted
+type ArticleDeleted struct {
+	Article    Article   `json:"article"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (e ArticleDeleted) EventName() string     { return ArticleDeletedEvent }
+func (e ArticleDeleted) EventArticle() Article { return e.Article }
+func (e ArticleDeleted) EventTime() time.Time  { return e.OccurredAt }
@@ -0,0 +1,158 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jinzhu/gorm"
+)
+
+// sqlContextCommon is implemented by both *sql.DB and *sql.Tx
+type sqlContextCommon interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// StatementHook is called before each statement run by a handle of WithContext,
+// e.g. to trace it. It returns the context the statement runs with and a function
+// called with its outcome; the result is nil for queries.
+type StatementHook func(ctx context.Context, dialect, query string) (context.Context, func(sql.Result, error))
+
+const statementHookKey = "models:statement_hook"
+
+// WithStatementHook returns a handle on db calling hook around its statements
+// run with a context, see WithContext
+func WithStatementHook(db Gormw, hook StatementHook) Gormw {
+	return db.Set(statementHookKey, hook)
+}
+
+// contextSQLCommon is a gorm.SQLCommon running every statement with ctx,
+// around the statement hook when there is one
+type contextSQLCommon struct {
+	ctx     context.Context
+	db      sqlContextCommon
+	dialect string
+	hook    StatementHook
+}
+
+func (c *contextSQLCommon) Exec(query string, args ...interface{}) (sql.Result, error) {
+	ctx, done := c.start(query)
+	res, err := c.db.ExecContext(ctx, query, args...)
+	done(res, err)
+	return res, err
+}
+
+func (c *contextSQLCommon) Prepare(query string) (*sql.Stmt, error) {
+	ctx, done := c.start(query)
+	stmt, err := c.db.PrepareContext(ctx, query)
+	done(nil, err)
+	return stmt, err
+}
+
+func (c *contextSQLCommon) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, done := c.start(query)
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	done(nil, err)
+	return rows, err
+}
+
+func (c *contextSQLCommon) QueryRow(query string, args ...interface{}) *sql.Row {
+	ctx, done := c.start(query)
+	row := c.db.QueryRowContext(ctx, query, args...)
+	done(nil, nil)
+	return row
+}
+
+func (c *contextSQLCommon) start(query string) (context.Context, func(sql.Result, error)) {
+	if c.hook == nil {
+		return c.ctx, func(sql.Result, error) {}
+	}
+	return c.hook(c.ctx, c.dialect, query)
+}
+
+// WithContext returns a new handle on the connection (or transaction) of db,
+// without its search conditions, running its statements with ctx so cancelling
+// ctx aborts in-flight SQL. gorm v1 has no context support of its own. The
+// statement hook of db is kept; its other settings, e.g. its logger, are not,
+// since gorm v1 cannot read them back.
+func WithContext(ctx context.Context, db *gorm.DB) *gorm.DB {
+	var common sqlContextCommon
+	switch c := db.CommonDB().(type) {
+	case *contextSQLCommon:
+		common = c.db
+	case sqlContextCommon:
+		common = c
+	default:
+		return db
+	}
+
+	h := statementHook(db)
+	dialect := db.Dialect().GetName()
+	return open(db, dialect, &contextSQLCommon{ctx: ctx, db: common, dialect: dialect, hook: h}, h)
+}
+
+// BeginTx begins a transaction on db, with ctx, also when db is a handle of
+// WithContext: contextSQLCommon is no gorm transaction starter, otherwise
+// gorm would run its creates and updates in transactions of its own, outside
+// ctx. The statement hook of db is kept.
+func BeginTx(ctx context.Context, db *gorm.DB, opts *sql.TxOptions) *gorm.DB {
+	c, ok := db.CommonDB().(*contextSQLCommon)
+	if !ok {
+		return db.BeginTx(ctx, opts)
+	}
+	conn, ok := c.db.(*sql.DB)
+	if !ok {
+		db = db.New()
+		db.AddError(gorm.ErrCantStartTransaction)
+		return db
+	}
+	tx, err := conn.BeginTx(ctx, opts)
+	if err != nil {
+		db = db.New()
+		db.AddError(err)
+		return db
+	}
+	txDB := open(db, c.dialect, tx, statementHook(db))
+	if txDB.Error != nil {
+		tx.Rollback()
+	}
+	return txDB
+}
+
+func statementHook(db *gorm.DB) StatementHook {
+	hook, _ := db.Get(statementHookKey)
+	h, _ := hook.(StatementHook)
+	return h
+}
+
+// open returns a new handle of dialect on common, keeping hook; a
+// gorm.SQLCommon opens without a ping, so no statement runs here
+func open(db *gorm.DB, dialect string, common gorm.SQLCommon, hook StatementHook) *gorm.DB {
+	clone, err := gorm.Open(dialect, common)
+	if err != nil {
+		db = db.New()
+		db.AddError(err)
+		return db
+	}
+	if hook != nil {
+		clone = clone.Set(statementHookKey, hook)
+	}
+	return clone
+}
+
+// ContextError translates a failed query error caused by ctx: ErrTimeout
+// when its deadline passed, ErrCanceled when the caller went away.
+func ContextError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return ErrTimeout
+	case context.Canceled:
+		return ErrCanceled
+	}
+	return err
+}
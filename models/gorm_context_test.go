@@ -0,0 +1,83 @@
+package models_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+func TestWithContextKeepsTheConnection(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db, err := gorm.Open("common", sqlDB)
+	require.NoError(t, err)
+	mock.ExpectExec("DELETE FROM articles").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctxDB := models.WithContext(context.TODO(), db.Where("id = ?", 1))
+
+	_, isSQLDB := ctxDB.CommonDB().(*sql.DB)
+	assert.False(t, isSQLDB, "the statements must run with the context")
+	assert.Equal(t, "common", ctxDB.Dialect().GetName())
+	require.NoError(t, ctxDB.Exec("DELETE FROM articles").Error)
+	assert.Equal(t, sqlDB, db.CommonDB(), "the original handle must not change")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithContextBeginsTransactions(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db, err := models.Openw("common", sqlDB)
+	require.NoError(t, err)
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM articles").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	tx := db.WithContext(context.TODO()).BeginTx(context.TODO(), nil)
+	require.NoError(t, tx.Error())
+	require.NoError(t, tx.WithContext(context.TODO()).Exec("DELETE FROM articles").Error())
+	require.NoError(t, tx.Commit().Error())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithContextCancel(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db, err := gorm.Open("common", sqlDB)
+	require.NoError(t, err)
+	mock.ExpectExec("DELETE FROM articles").WillDelayFor(5 * time.Second).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	err = models.WithContext(ctx, db).Exec("DELETE FROM articles").Error
+
+	assert.Equal(t, models.ErrCanceled, models.ContextError(ctx, err))
+}
+
+func TestWithContextCallsTheStatementHook(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db, err := models.Openw("common", sqlDB)
+	require.NoError(t, err)
+	mock.ExpectExec("DELETE FROM articles").WillReturnResult(sqlmock.NewResult(0, 2))
+
+	var queries []string
+	var affected int64
+	db = models.WithStatementHook(db, func(ctx context.Context, dialect, query string) (context.Context, func(sql.Result, error)) {
+		queries = append(queries, dialect+": "+query)
+		return ctx, func(res sql.Result, err error) {
+			affected, _ = res.RowsAffected()
+		}
+	})
+
+	require.NoError(t, db.WithContext(context.TODO()).Exec("DELETE FROM articles").Error())
+	assert.Equal(t, []string{"common: DELETE FROM articles"}, queries)
+	assert.Equal(t, int64(2), affected)
+}
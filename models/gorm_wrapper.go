@@ -1,5 +1,6 @@
 package models
 
+import "context"
 import "database/sql"
 import "github.com/jinzhu/gorm"
 
@@ -81,6 +82,9 @@ type Gormw interface {
 	// extra
 	Error() error
 	RowsAffected() int64
+	Dialect() gorm.Dialect
+	BeginTx(ctx context.Context, opts *sql.TxOptions) Gormw
+	WithContext(ctx context.Context) Gormw
 }
 
 type gormw struct {
@@ -393,3 +397,16 @@ func (it *gormw) RowsAffected() int64 {
 func (it *gormw) Error() error {
 	return it.w.Error
 }
+
+func (it *gormw) Dialect() gorm.Dialect {
+	return it.w.Dialect()
+}
+
+func (it *gormw) BeginTx(ctx context.Context, opts *sql.TxOptions) Gormw {
+	return Wrap(BeginTx(ctx, it.w, opts))
+}
+
+// WithContext runs the statements of the returned Gormw with ctx, see WithContext
+func (it *gormw) WithContext(ctx context.Context) Gormw {
+	return Wrap(WithContext(ctx, it.w))
+}
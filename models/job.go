@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// States of a job
+const (
+	// JobPending jobs run once their RunAt is due, including the failed jobs waiting for a retry
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	// JobDead jobs failed for good and wait for a manual retry
+	JobDead = "dead"
+)
+
+// Job is a unit of background work run by a worker outside of the requests
+type Job struct {
+	ID        int64     `gorm:"primary_key" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Kind selects the handler of the job, e.g. articles.export
+	Kind  string `json:"kind"`
+	Queue string `json:"queue"`
+	// Payload is the argument of the handler encoded as JSON
+	Payload     string    `json:"payload"`
+	State       string    `json:"state"`
+	Attempts    int       `json:"attempts"`
+	MaxAttempts int       `json:"max_attempts"`
+	RunAt       time.Time `json:"run_at"`
+	// UniqueKey, when set, allows a single pending or running job with that key
+	UniqueKey string `json:"unique_key,omitempty"`
+	// ActiveKey is UniqueKey until the job succeeds or dies. It holds the unique index.
+	ActiveKey *string `json:"-"`
+	// LockedBy identifies the worker running the job
+	LockedBy string `json:"-"`
+	// LockedUntil is when a running job whose worker stopped is run again
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+	LastError   string     `json:"last_error"`
+	FinishedAt  *time.Time `json:"finished_at"`
+}
+
+// JobFilter selects the jobs to list, every field being optional but Num
+type JobFilter struct {
+	Queue string
+	Kind  string
+	State string
+	Num   int64
+}
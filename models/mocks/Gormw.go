@@ -2,6 +2,7 @@
 
 package mocks
 
+import context "context"
 import gorm "github.com/jinzhu/gorm"
 import mock "github.com/stretchr/testify/mock"
 import models "github.com/naveenpatilm/go-clean-arch/models"
@@ -174,6 +175,22 @@ func (_m *Gormw) Begin() models.Gormw {
 	return r0
 }
 
+// BeginTx provides a mock function with given fields: ctx, opts
+func (_m *Gormw) BeginTx(ctx context.Context, opts *sql.TxOptions) models.Gormw {
+	ret := _m.Called(ctx, opts)
+
+	var r0 models.Gormw
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.TxOptions) models.Gormw); ok {
+		r0 = rf(ctx, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(models.Gormw)
+		}
+	}
+
+	return r0
+}
+
 // Callback provides a mock function with given fields:
 func (_m *Gormw) Callback() *gorm.Callback {
 	ret := _m.Called()
@@ -337,6 +354,22 @@ func (_m *Gormw) Delete(value interface{}, where ...interface{}) models.Gormw {
 	return r0
 }
 
+// Dialect provides a mock function with given fields:
+func (_m *Gormw) Dialect() gorm.Dialect {
+	ret := _m.Called()
+
+	var r0 gorm.Dialect
+	if rf, ok := ret.Get(0).(func() gorm.Dialect); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(gorm.Dialect)
+		}
+	}
+
+	return r0
+}
+
 // DropColumn provides a mock function with given fields: column
 func (_m *Gormw) DropColumn(column string) models.Gormw {
 	ret := _m.Called(column)
@@ -1268,3 +1301,19 @@ func (_m *Gormw) Where(query interface{}, args ...interface{}) models.Gormw {
 
 	return r0
 }
+
+// WithContext provides a mock function with given fields: ctx
+func (_m *Gormw) WithContext(ctx context.Context) models.Gormw {
+	ret := _m.Called(ctx)
+
+	var r0 models.Gormw
+	if rf, ok := ret.Get(0).(func(context.Context) models.Gormw); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(models.Gormw)
+		}
+	}
+
+	return r0
+}
@@ -0,0 +1,8 @@
+package models
+
+// Page represent the cursors around a fetched page of results.
+// An empty cursor means there is nothing more in that direction.
+type Page struct {
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "context"
+
+type readYourWritesKey struct{}
+
+// ReadYourWrites marks ctx so that repository reads made with it go to the
+// primary database instead of a replica that may lag behind.
+func ReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readYourWritesKey{}, true)
+}
+
+// IsReadYourWrites reports whether ctx was marked by ReadYourWrites
+func IsReadYourWrites(ctx context.Context) bool {
+	marked, _ := ctx.Value(readYourWritesKey{}).(bool)
+	return marked
+}
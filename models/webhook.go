@@ -0,0 +1,103 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Webhook is the subscription of a partner endpoint to article events
+type Webhook struct {
+	ID        int64     `gorm:"primary_key" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	URL       string    `json:"url" validate:"required,url"`
+	// Events are the names of the events delivered, e.g. article.created
+	Events EventNames `json:"events" validate:"required,min=1"`
+	// Secret signs the deliveries. It is only returned when the webhook is created.
+	Secret string `json:"secret,omitempty"`
+	// Paused webhooks receive no new deliveries
+	Paused bool `json:"paused"`
+}
+
+// Subscribes reports whether the events called name are delivered to w
+func (w *Webhook) Subscribes(name string) bool {
+	if w.Paused {
+		return false
+	}
+	for _, e := range w.Events {
+		if e == name {
+			return true
+		}
+	}
+	return false
+}
+
+// EventNames is a list of event names stored as a comma separated column
+type EventNames []string
+
+func (n EventNames) Value() (driver.Value, error) {
+	return strings.Join(n, ","), nil
+}
+
+func (n *EventNames) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case nil:
+	default:
+		return fmt.Errorf("cannot scan %T into EventNames", src)
+	}
+	*n = nil
+	if s != "" {
+		*n = strings.Split(s, ",")
+	}
+	return nil
+}
+
+// States of a webhook delivery
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	// DeliveryFailed is a delivery given up after its last attempt
+	DeliveryFailed = "failed"
+)
+
+// WebhookDelivery is the delivery of an event to a webhook, retried until it succeeds or fails
+type WebhookDelivery struct {
+	ID        int64     `gorm:"primary_key" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	WebhookID int64     `json:"webhook_id"`
+	ArticleID int64     `json:"article_id"`
+	EventName string    `json:"event"`
+	// Payload is the body posted to the webhook
+	Payload       string    `json:"-"`
+	State         string    `json:"state"`
+	Attempts      int       `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	// ResponseCode is the status of the last response, 0 when none was received
+	ResponseCode int        `json:"response_code"`
+	LastError    string     `json:"last_error"`
+	DeliveredAt  *time.Time `json:"delivered_at"`
+	// LockedBy identifies the dispatcher sending the delivery
+	LockedBy string `json:"-"`
+	// LockedUntil is when another dispatcher may claim the delivery, if its dispatcher stopped
+	LockedUntil *time.Time `json:"-"`
+	// History holds the attempts, oldest first. It is only loaded for a single delivery.
+	History []*WebhookAttempt `json:"history,omitempty" gorm:"-"`
+}
+
+// WebhookAttempt is one request of a delivery
+type WebhookAttempt struct {
+	ID         int64     `gorm:"primary_key" json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+	DeliveryID int64     `json:"-"`
+	// ResponseCode is 0 when no response was received, e.g. on a timeout
+	ResponseCode int    `json:"response_code"`
+	Error        string `json:"error,omitempty"`
+	DurationMs   int64  `json:"duration_ms"`
+}
@@ -0,0 +1,80 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+// Message is an article event saved with the change it describes, waiting to be delivered
+type Message struct {
+	ID        int64     `gorm:"primary_key" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	ArticleID int64     `json:"article_id"`
+	EventName string    `json:"event"`
+	// Payload is the event encoded as JSON
+	Payload       string     `json:"payload"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     string     `json:"last_error"`
+	DispatchedAt  *time.Time `json:"dispatched_at"`
+	// LockedBy identifies the relay delivering the message
+	LockedBy string `json:"-"`
+	// LockedUntil is when another relay may claim the message, if its relay stopped
+	LockedUntil *time.Time `json:"-"`
+}
+
+// TableName is the table of the messages
+func (Message) TableName() string {
+	return "outbox_messages"
+}
+
+// NewMessage will create the pending message of e
+func NewMessage(e models.Event) (*Message, error) {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	return &Message{
+		CreatedAt:     now,
+		ArticleID:     e.EventArticle().ID,
+		EventName:     e.EventName(),
+		Payload:       string(payload),
+		NextAttemptAt: now,
+	}, nil
+}
+
+// Repository represent the outbox's repository contract
+type Repository interface {
+	// Store saves the message of e. Bound to a transaction, see transaction.Repositories,
+	// it is committed or rolled back with the article change.
+	Store(ctx context.Context, e models.Event) error
+	// Claim leases up to limit pending messages that are due to lockedBy for lease and
+	// returns them, oldest first. Only the oldest pending message of an article is
+	// claimed, and messages leased by another relay are skipped until their lease
+	// expires, so the messages of an article are delivered one at a time, in order.
+	Claim(ctx context.Context, limit int, lockedBy string, lease time.Duration) ([]*Message, error)
+	// Finish saves the outcome of a claimed message and ends its lease. It returns
+	// models.ErrNotFound when the lease expired and another relay claimed it.
+	Finish(ctx context.Context, m *Message) error
+	// Purge deletes the messages dispatched before t and returns how many were deleted
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Sink represent where the relay delivers the messages
+type Sink interface {
+	// Send delivers m. A message may be sent more than once, e.g. when the
+	// relay stops before saving that it was dispatched.
+	Send(ctx context.Context, m *Message) error
+}
+
+// Relay represent the worker delivering the pending messages to a Sink
+type Relay interface {
+	// RunOnce delivers one batch of messages and returns how many were claimed
+	RunOnce(ctx context.Context) (int, error)
+	// Run delivers the messages until ctx is done
+	Run(ctx context.Context) error
+}
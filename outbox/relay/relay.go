@@ -0,0 +1,158 @@
+package relay
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/naveenpatilm/go-clean-arch/internal/lease"
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/outbox"
+)
+
+// Config represent how the relay polls and retries
+type Config struct {
+	// PollInterval is the wait between two polls once every due message was claimed
+	PollInterval time.Duration
+	// BatchSize is how many messages are claimed at once
+	BatchSize int
+	// Lease is how long the relay may take to deliver a batch. Messages it did
+	// not deliver in time are left to the other relays.
+	Lease time.Duration
+	// MinBackoff is the wait before the first retry of a message. It doubles
+	// with every failed attempt, up to MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Retention is how long dispatched messages are kept. Zero keeps them forever.
+	Retention time.Duration
+	// Polled, when set, is called after each poll that claimed without error
+	Polled func()
+}
+
+// purgeInterval is how often the dispatched messages older than the retention are deleted
+const purgeInterval = time.Hour
+
+type relay struct {
+	repo outbox.Repository
+	sink outbox.Sink
+	c    Config
+	// id leases the messages claimed by this relay
+	id string
+}
+
+// NewRelay will create an implementation of outbox.Relay delivering the messages of repo to sink.
+// A message failing to be delivered is retried forever, since the later messages of its article
+// wait for it.
+func NewRelay(repo outbox.Repository, sink outbox.Sink, c Config) outbox.Relay {
+	if c.PollInterval <= 0 {
+		c.PollInterval = time.Second
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 100
+	}
+	if c.Lease <= 0 {
+		c.Lease = 5 * time.Minute
+	}
+	if c.MinBackoff <= 0 {
+		c.MinBackoff = time.Second
+	}
+	if c.MaxBackoff < c.MinBackoff {
+		c.MaxBackoff = c.MinBackoff
+	}
+	return &relay{repo: repo, sink: sink, c: c, id: lease.NewID()}
+}
+
+func (r *relay) RunOnce(ctx context.Context) (int, error) {
+	messages, err := r.repo.Claim(ctx, r.c.BatchSize, r.id, r.c.Lease)
+	if err != nil {
+		return 0, err
+	}
+	for _, m := range messages {
+		// once the lease expired, another relay may be sending the message
+		if ctx.Err() == nil && time.Now().Before(*m.LockedUntil) {
+			r.send(ctx, m)
+		}
+		r.finish(m)
+	}
+	return len(messages), nil
+}
+
+// finish saves the outcome of m, or only ends its lease when it was not sent
+func (r *relay) finish(m *outbox.Message) {
+	err := lease.Finish(func(ctx context.Context) error {
+		return r.repo.Finish(ctx, m)
+	})
+	if err != nil {
+		if err == models.ErrNotFound {
+			err = fmt.Errorf("the lease of message %d expired, another relay claimed it", m.ID)
+		}
+		logrus.WithField("message_id", m.ID).Error("outbox relay: ", err)
+	}
+}
+
+// send delivers m and records the outcome on it
+func (r *relay) send(ctx context.Context, m *outbox.Message) {
+	err := r.sink.Send(ctx, m)
+	now := time.Now().UTC()
+	if err != nil && ctx.Err() != nil {
+		// the relay stops: the attempt did not fail, the message is sent again at once
+		return
+	}
+	m.Attempts++
+	if err != nil {
+		m.LastError = err.Error()
+		m.NextAttemptAt = now.Add(lease.Backoff(m.Attempts, r.c.MinBackoff, r.c.MaxBackoff))
+		logrus.WithFields(logrus.Fields{
+			"message_id": m.ID,
+			"article_id": m.ArticleID,
+			"attempts":   m.Attempts,
+			"retry_at":   m.NextAttemptAt,
+		}).Warn("outbox delivery failed: ", err)
+		return
+	}
+	m.DispatchedAt, m.LastError = &now, ""
+}
+
+func (r *relay) Run(ctx context.Context) error {
+	purge := time.NewTicker(purgeInterval)
+	defer purge.Stop()
+	r.purge(ctx)
+	for {
+		claimed, err := r.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			logrus.Error("outbox relay: ", err)
+		}
+		if err == nil && r.c.Polled != nil {
+			r.c.Polled()
+		}
+		// a full batch means more messages are likely due
+		wait := r.c.PollInterval
+		if err == nil && claimed == r.c.BatchSize {
+			wait = 0
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-purge.C:
+			r.purge(ctx)
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (r *relay) purge(ctx context.Context) {
+	if r.c.Retention <= 0 {
+		return
+	}
+	deleted, err := r.repo.Purge(ctx, time.Now().Add(-r.c.Retention))
+	if err != nil {
+		logrus.Error("outbox purge: ", err)
+		return
+	}
+	if deleted > 0 {
+		logrus.Infof("outbox purge deleted %d dispatched messages", deleted)
+	}
+}
@@ -0,0 +1,173 @@
+package relay_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/outbox"
+	"github.com/naveenpatilm/go-clean-arch/outbox/relay"
+	_outboxRepo "github.com/naveenpatilm/go-clean-arch/outbox/repository"
+)
+
+// flakySink fails the first attempts of every message
+type flakySink struct {
+	mu       sync.Mutex
+	failures int
+	attempts map[int64]int
+	sent     []int64
+}
+
+func (s *flakySink) Send(ctx context.Context, m *outbox.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts[m.ID]++
+	if s.attempts[m.ID] <= s.failures {
+		return errors.New("unavailable")
+	}
+	s.sent = append(s.sent, m.ID)
+	return nil
+}
+
+func (s *flakySink) get() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int64(nil), s.sent...)
+}
+
+func store(t *testing.T, repo outbox.Repository, articleIDs ...int64) {
+	for _, id := range articleIDs {
+		require.NoError(t, repo.Store(context.TODO(), models.ArticleCreated{Article: models.Article{ID: id}}))
+	}
+}
+
+func TestRelayRetriesWithBackoff(t *testing.T) {
+	repo := _outboxRepo.NewMemoryOutboxRepository()
+	store(t, repo, 1, 1, 2)
+	sink := &flakySink{failures: 2, attempts: map[int64]int{}}
+	r := relay.NewRelay(repo, sink, relay.Config{MinBackoff: 20 * time.Millisecond, MaxBackoff: 30 * time.Millisecond})
+
+	claimed, err := r.RunOnce(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, 2, claimed, "the heads of articles 1 and 2")
+	claimed, err = r.RunOnce(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, 0, claimed, "the failed messages wait for their backoff")
+
+	messages, err := repo.Claim(context.TODO(), 10, "test", time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, messages, "not due yet")
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(sink.get()) < 3 {
+		require.True(t, time.Now().Before(deadline), "the messages were not delivered")
+		_, err = r.RunOnce(context.TODO())
+		require.NoError(t, err)
+		time.Sleep(5 * time.Millisecond)
+	}
+	sent := sink.get()
+	// message 2 follows message 1 of the same article
+	assert.Equal(t, []int64{1, 3, 2}, sent)
+	assert.Equal(t, 3, sink.attempts[1])
+}
+
+// blockingSink blocks until the relay stops
+type blockingSink struct {
+	sending chan struct{}
+}
+
+func (s *blockingSink) Send(ctx context.Context, m *outbox.Message) error {
+	s.sending <- struct{}{}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestStoppedRelayReleasesItsMessages(t *testing.T) {
+	repo := _outboxRepo.NewMemoryOutboxRepository()
+	store(t, repo, 1, 2)
+	sink := &blockingSink{sending: make(chan struct{}, 2)}
+	r := relay.NewRelay(repo, sink, relay.Config{})
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	go func() {
+		<-sink.sending
+		cancel()
+	}()
+	claimed, err := r.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, claimed)
+
+	messages, err := repo.Claim(context.TODO(), 10, "test", time.Minute)
+	require.NoError(t, err)
+	require.Len(t, messages, 2, "the messages are claimed again at once")
+	for _, m := range messages {
+		assert.Equal(t, 0, m.Attempts)
+		assert.Empty(t, m.LastError)
+	}
+}
+
+func TestConcurrentRelaysKeepArticleOrder(t *testing.T) {
+	repo := _outboxRepo.NewMemoryOutboxRepository()
+	for i := 0; i < 20; i++ {
+		store(t, repo, 1, 2, 3)
+	}
+	sink := &flakySink{attempts: map[int64]int{}}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			relay.NewRelay(repo, sink, relay.Config{PollInterval: time.Millisecond, BatchSize: 2}).Run(ctx)
+		}()
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(sink.get()) < 60 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	wg.Wait()
+
+	sent := sink.get()
+	require.Len(t, sent, 60)
+	// messages are numbered in the order they were stored: article i has the ids i, i+3, i+6...
+	last := map[int64]int64{}
+	for _, id := range sent {
+		article := (id-1)%3 + 1
+		assert.True(t, id > last[article], "message %d of article %d was sent after %d", id, article, last[article])
+		last[article] = id
+	}
+}
+
+func TestRunReportsItsPolls(t *testing.T) {
+	repo := _outboxRepo.NewMemoryOutboxRepository()
+	polled := make(chan struct{}, 1)
+	r := relay.NewRelay(repo, &flakySink{attempts: map[int64]int{}}, relay.Config{
+		PollInterval: time.Millisecond,
+		Polled: func() {
+			select {
+			case polled <- struct{}{}:
+			default:
+			}
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	done := make(chan error)
+	go func() { done <- r.Run(ctx) }()
+	select {
+	case <-polled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the relay did not report a poll")
+	}
+	cancel()
+	assert.NoError(t, <-done)
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	_lease "github.com/naveenpatilm/go-clean-arch/internal/lease"
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/outbox"
+)
+
+// claimQuery selects the due messages that are the oldest pending one of their
+// article and whose lease, if any, expired
+const claimQuery = `SELECT * FROM outbox_messages o
+	WHERE o.dispatched_at IS NULL AND o.next_attempt_at <= ?
+	AND (o.locked_until IS NULL OR o.locked_until < ?)
+	AND NOT EXISTS (
+		SELECT 1 FROM outbox_messages p
+		WHERE p.article_id = o.article_id AND p.dispatched_at IS NULL AND p.id < o.id
+	)
+	ORDER BY o.id LIMIT ?`
+
+type gormOutboxRepository struct {
+	DB models.Gormw
+	// lock is appended to claimQuery to lock the claimed rows
+	lock string
+}
+
+// NewPostgresOutboxRepository will create an object that represent the outbox.Repository interface on postgres
+func NewPostgresOutboxRepository(DB models.Gormw) outbox.Repository {
+	return &gormOutboxRepository{DB: DB, lock: " FOR UPDATE SKIP LOCKED"}
+}
+
+// NewMysqlOutboxRepository will create an object that represent the outbox.Repository interface on mysql 8
+func NewMysqlOutboxRepository(DB models.Gormw) outbox.Repository {
+	return &gormOutboxRepository{DB: DB, lock: " FOR UPDATE SKIP LOCKED"}
+}
+
+// NewSqliteOutboxRepository will create an object that represent the outbox.Repository interface on sqlite.
+// Sqlite has no row locks: its transactions are serialized, so concurrent relays still claim distinct messages.
+func NewSqliteOutboxRepository(DB models.Gormw) outbox.Repository {
+	return &gormOutboxRepository{DB: DB}
+}
+
+func (m *gormOutboxRepository) Store(ctx context.Context, e models.Event) error {
+	message, err := outbox.NewMessage(e)
+	if err != nil {
+		return err
+	}
+	return models.ContextError(ctx, m.DB.WithContext(ctx).Create(message).Error())
+}
+
+func (m *gormOutboxRepository) Claim(ctx context.Context, limit int, lockedBy string, lease time.Duration) ([]*outbox.Message, error) {
+	now := time.Now().UTC()
+	lockedUntil := now.Add(lease)
+	messages, err := _lease.Claim(ctx, m.DB, claimQuery+m.lock, []interface{}{now, now, limit},
+		func(message *outbox.Message) int64 { return message.ID },
+		`UPDATE outbox_messages SET locked_by = ?, locked_until = ? WHERE id IN (?)`, lockedBy, lockedUntil)
+	if err != nil {
+		return nil, err
+	}
+	for _, message := range messages {
+		message.LockedBy, message.LockedUntil = lockedBy, &lockedUntil
+	}
+	return messages, nil
+}
+
+func (m *gormOutboxRepository) Finish(ctx context.Context, message *outbox.Message) error {
+	res := m.DB.WithContext(ctx).Model(&outbox.Message{}).
+		Where("id = ? AND locked_by = ?", message.ID, message.LockedBy).
+		Updates(map[string]interface{}{
+			"attempts":        message.Attempts,
+			"next_attempt_at": message.NextAttemptAt,
+			"last_error":      message.LastError,
+			"dispatched_at":   message.DispatchedAt,
+			"locked_by":       "",
+			"locked_until":    nil,
+		})
+	if err := models.ContextError(ctx, res.Error()); err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	message.LockedBy, message.LockedUntil = "", nil
+	return nil
+}
+
+func (m *gormOutboxRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	res := m.DB.WithContext(ctx).Where("dispatched_at IS NOT NULL AND dispatched_at < ?", before.UTC()).Delete(outbox.Message{})
+	if err := models.ContextError(ctx, res.Error()); err != nil {
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
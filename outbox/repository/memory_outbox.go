@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/outbox"
+	"github.com/naveenpatilm/go-clean-arch/transaction"
+)
+
+type memoryOutboxRepository struct {
+	mu       sync.Mutex
+	lastID   int64
+	messages map[int64]outbox.Message
+}
+
+// NewMemoryOutboxRepository will create an object that represent the outbox.Repository interface in memory
+func NewMemoryOutboxRepository() outbox.Repository {
+	return &memoryOutboxRepository{messages: map[int64]outbox.Message{}}
+}
+
+func (m *memoryOutboxRepository) Store(ctx context.Context, e models.Event) error {
+	message, err := outbox.NewMessage(e)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastID++
+	message.ID = m.lastID
+	m.messages[message.ID] = *message
+	id := message.ID
+	transaction.RecordUndo(ctx, func() {
+		m.mu.Lock()
+		delete(m.messages, id)
+		m.mu.Unlock()
+	})
+	return nil
+}
+
+func (m *memoryOutboxRepository) Claim(ctx context.Context, limit int, lockedBy string, lease time.Duration) ([]*outbox.Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	pending := make([]outbox.Message, 0, len(m.messages))
+	for _, message := range m.messages {
+		if message.DispatchedAt == nil {
+			pending = append(pending, message)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].ID < pending[j].ID })
+
+	now := time.Now().UTC()
+	lockedUntil := now.Add(lease)
+	messages := []*outbox.Message{}
+	seen := map[int64]bool{}
+	for i := range pending {
+		message := pending[i]
+		// only the oldest pending message of an article may be claimed, even when it is leased
+		head := !seen[message.ArticleID]
+		seen[message.ArticleID] = true
+		free := message.LockedUntil == nil || message.LockedUntil.Before(now)
+		if head && free && !message.NextAttemptAt.After(now) && len(messages) < limit {
+			message.LockedBy, message.LockedUntil = lockedBy, &lockedUntil
+			m.messages[message.ID] = message
+			messages = append(messages, &message)
+		}
+	}
+	return messages, nil
+}
+
+func (m *memoryOutboxRepository) Finish(ctx context.Context, message *outbox.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	current, ok := m.messages[message.ID]
+	if !ok || current.DispatchedAt != nil || current.LockedBy != message.LockedBy {
+		return models.ErrNotFound
+	}
+	message.LockedBy, message.LockedUntil = "", nil
+	m.messages[message.ID] = *message
+	return nil
+}
+
+func (m *memoryOutboxRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var deleted int64
+	for id, message := range m.messages {
+		if message.DispatchedAt != nil && message.DispatchedAt.Before(before) {
+			delete(m.messages, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
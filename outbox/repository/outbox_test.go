@@ -0,0 +1,154 @@
+package repository_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/database"
+	"github.com/naveenpatilm/go-clean-arch/models"
+	_gormMock "github.com/naveenpatilm/go-clean-arch/models/mocks"
+	"github.com/naveenpatilm/go-clean-arch/outbox"
+	_outboxRepo "github.com/naveenpatilm/go-clean-arch/outbox/repository"
+)
+
+func newSqliteRepo(t *testing.T) (outbox.Repository, func()) {
+	dir, err := ioutil.TempDir("", "outbox")
+	require.NoError(t, err)
+	db, err := database.Open(database.Config{Driver: database.Sqlite, Path: filepath.Join(dir, "article.db")})
+	require.NoError(t, err)
+	migrator, err := database.NewMigrator(db.DB(), database.Sqlite)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Up(context.TODO()))
+	return _outboxRepo.NewSqliteOutboxRepository(db), func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func updated(id int64, title string) models.Event {
+	return models.ArticleUpdated{Article: models.Article{ID: id, Title: title}, OccurredAt: time.Now()}
+}
+
+// claim returns the titles of the claimed messages and finishes each of them once mark applied
+func claim(t *testing.T, repo outbox.Repository, mark func(m *outbox.Message)) []string {
+	messages, err := repo.Claim(context.TODO(), 10, "relay", time.Minute)
+	require.NoError(t, err)
+	var titles []string
+	for _, m := range messages {
+		var e models.ArticleUpdated
+		require.NoError(t, json.Unmarshal([]byte(m.Payload), &e))
+		titles = append(titles, e.Article.Title)
+		mark(m)
+		require.NoError(t, repo.Finish(context.TODO(), m))
+	}
+	return titles
+}
+
+func TestClaimOrderPerArticle(t *testing.T) {
+	sqliteRepo, cleanup := newSqliteRepo(t)
+	defer cleanup()
+
+	for name, repo := range map[string]outbox.Repository{"sqlite": sqliteRepo, "memory": _outboxRepo.NewMemoryOutboxRepository()} {
+		t.Run(name, func(t *testing.T) {
+			for _, e := range []models.Event{updated(1, "a1"), updated(2, "b1"), updated(1, "a2"), updated(3, "c1")} {
+				require.NoError(t, repo.Store(context.TODO(), e))
+			}
+			now := time.Now().UTC()
+			dispatch := func(m *outbox.Message) { m.DispatchedAt = &now }
+
+			// only the oldest pending message of each article is claimed
+			assert.Equal(t, []string{"a1", "b1", "c1"}, claim(t, repo, func(m *outbox.Message) {
+				if m.ArticleID == 2 {
+					m.Attempts, m.LastError, m.NextAttemptAt = 1, "timeout", now.Add(time.Hour)
+					return
+				}
+				dispatch(m)
+			}))
+			// b1 waits for its retry
+			assert.Equal(t, []string{"a2"}, claim(t, repo, dispatch))
+			assert.Empty(t, claim(t, repo, dispatch))
+
+			deleted, err := repo.Purge(context.TODO(), time.Now().Add(time.Minute))
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), deleted)
+		})
+	}
+}
+
+func TestClaimLeasesTheMessages(t *testing.T) {
+	sqliteRepo, cleanup := newSqliteRepo(t)
+	defer cleanup()
+
+	for name, repo := range map[string]outbox.Repository{"sqlite": sqliteRepo, "memory": _outboxRepo.NewMemoryOutboxRepository()} {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, repo.Store(context.TODO(), updated(1, "a1")))
+			require.NoError(t, repo.Store(context.TODO(), updated(1, "a2")))
+
+			leased, err := repo.Claim(context.TODO(), 10, "first", time.Minute)
+			require.NoError(t, err)
+			require.Len(t, leased, 1)
+			assert.Equal(t, "first", leased[0].LockedBy)
+			require.NotNil(t, leased[0].LockedUntil)
+			// neither the leased message nor the next one of its article may be claimed
+			assert.Empty(t, claim(t, repo, func(m *outbox.Message) {}))
+
+			expiring, err := repo.Claim(context.TODO(), 10, "second", -time.Second)
+			require.NoError(t, err)
+			assert.Empty(t, expiring)
+			released := *leased[0]
+			require.NoError(t, repo.Finish(context.TODO(), &released), "releasing keeps the message pending")
+
+			expired, err := repo.Claim(context.TODO(), 10, "second", -time.Second)
+			require.NoError(t, err)
+			require.Len(t, expired, 1)
+			// the lease of second expired at once: third takes the message over
+			taken, err := repo.Claim(context.TODO(), 10, "third", time.Minute)
+			require.NoError(t, err)
+			require.Len(t, taken, 1)
+			assert.Equal(t, expired[0].ID, taken[0].ID)
+
+			now := time.Now().UTC()
+			expired[0].DispatchedAt = &now
+			assert.Equal(t, models.ErrNotFound, repo.Finish(context.TODO(), expired[0]))
+			taken[0].DispatchedAt = &now
+			require.NoError(t, repo.Finish(context.TODO(), taken[0]))
+			assert.Equal(t, []string{"a2"}, claim(t, repo, func(m *outbox.Message) {}))
+		})
+	}
+}
+
+func TestPostgresClaimSkipsLockedRows(t *testing.T) {
+	db := new(_gormMock.Gormw)
+	db.On("BeginTx", mock.Anything, mock.Anything).Return(db).Once()
+	db.On("WithContext", mock.Anything).Return(db)
+	db.On("Raw", mock.MatchedBy(func(query string) bool {
+		return strings.HasSuffix(query, "LIMIT ? FOR UPDATE SKIP LOCKED")
+	}), mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), 10).Return(db).Once()
+	db.On("Scan", mock.AnythingOfType("*[]*outbox.Message")).Run(func(args mock.Arguments) {
+		*args.Get(0).(*[]*outbox.Message) = []*outbox.Message{{ID: 4, ArticleID: 1}}
+	}).Return(db).Once()
+	db.On("Exec", mock.MatchedBy(func(query string) bool {
+		return strings.HasPrefix(query, "UPDATE outbox_messages SET locked_by")
+	}), "relay", mock.AnythingOfType("time.Time"), []int64{4}).Return(db).Once()
+	db.On("Commit").Return(db).Once()
+	db.On("Error").Return(nil)
+	repo := _outboxRepo.NewPostgresOutboxRepository(db)
+
+	messages, err := repo.Claim(context.TODO(), 10, "relay", time.Minute)
+
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, int64(4), messages[0].ID)
+	assert.Equal(t, "relay", messages[0].LockedBy)
+	db.AssertExpectations(t)
+}
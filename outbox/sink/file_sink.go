@@ -0,0 +1,44 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/naveenpatilm/go-clean-arch/outbox"
+)
+
+type fileSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileSink will create an outbox.Sink appending each message to path as a line of JSON
+func NewFileSink(path string) outbox.Sink {
+	return &fileSink{path: path}
+}
+
+func (s *fileSink) Send(ctx context.Context, m *outbox.Message) error {
+	line, err := json.Marshal(NewEnvelope(m))
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	// the message is only marked as dispatched once it is on disk
+	if err = f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
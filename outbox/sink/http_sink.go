@@ -0,0 +1,54 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/naveenpatilm/go-clean-arch/outbox"
+)
+
+type httpSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPSink will create an outbox.Sink posting each message to url as JSON.
+// Any status but 2xx is a failed delivery. The X-Outbox-Message-Id header
+// lets the receiver ignore duplicates.
+func NewHTTPSink(url string, timeout time.Duration) outbox.Sink {
+	return &httpSink{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (s *httpSink) Send(ctx context.Context, m *outbox.Message) error {
+	body, err := json.Marshal(NewEnvelope(m))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Outbox-Message-Id", strconv.FormatInt(m.ID, 10))
+	req.Header.Set("X-Outbox-Event", m.EventName)
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	// drain the body so the connection is reused
+	io.Copy(ioutil.Discard, io.LimitReader(res.Body, 64<<10))
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("%s answered %s", s.url, res.Status)
+	}
+	return nil
+}
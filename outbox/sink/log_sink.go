@@ -0,0 +1,25 @@
+package sink
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/naveenpatilm/go-clean-arch/outbox"
+)
+
+type logSink struct{}
+
+// NewLogSink will create an outbox.Sink writing each message to the logs
+func NewLogSink() outbox.Sink {
+	return logSink{}
+}
+
+func (logSink) Send(ctx context.Context, m *outbox.Message) error {
+	logrus.WithFields(logrus.Fields{
+		"message_id": m.ID,
+		"event":      m.EventName,
+		"article_id": m.ArticleID,
+	}).Info(m.Payload)
+	return nil
+}
@@ -0,0 +1,61 @@
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/naveenpatilm/go-clean-arch/outbox"
+)
+
+// Envelope is how the file and HTTP sinks encode a message.
+// Consumers should ignore an ID they already handled: a message may be delivered more than once.
+type Envelope struct {
+	ID        int64           `json:"id"`
+	Event     string          `json:"event"`
+	ArticleID int64           `json:"article_id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// NewEnvelope will create the envelope of m
+func NewEnvelope(m *outbox.Message) Envelope {
+	return Envelope{
+		ID:        m.ID,
+		Event:     m.EventName,
+		ArticleID: m.ArticleID,
+		CreatedAt: m.CreatedAt,
+		Payload:   json.RawMessage(m.Payload),
+	}
+}
+
+// Kinds of sinks
+const (
+	Log  = "log"
+	File = "file"
+	HTTP = "http"
+)
+
+// Config selects and configures a sink
+type Config struct {
+	// Kind is Log, File or HTTP
+	Kind string
+	// Path is the file of a File sink
+	Path string
+	// URL and Timeout configure an HTTP sink
+	URL     string
+	Timeout time.Duration
+}
+
+// New will create the sink selected by c
+func New(c Config) (outbox.Sink, error) {
+	switch c.Kind {
+	case Log:
+		return NewLogSink(), nil
+	case File:
+		return NewFileSink(c.Path), nil
+	case HTTP:
+		return NewHTTPSink(c.URL, c.Timeout), nil
+	}
+	return nil, fmt.Errorf("unknown outbox sink %q", c.Kind)
+}
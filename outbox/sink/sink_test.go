@@ -0,0 +1,69 @@
+package sink_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/outbox"
+	"github.com/naveenpatilm/go-clean-arch/outbox/sink"
+)
+
+func newMessage() *outbox.Message {
+	return &outbox.Message{ID: 7, ArticleID: 3, EventName: "article.created", Payload: `{"article":{"title":"Hello"}}`}
+}
+
+func TestFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sink")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "outbox.jsonl")
+	s, err := sink.New(sink.Config{Kind: sink.File, Path: path})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Send(context.TODO(), newMessage()))
+	require.NoError(t, s.Send(context.TODO(), newMessage()))
+
+	content, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 2)
+	var envelope sink.Envelope
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &envelope))
+	assert.Equal(t, int64(7), envelope.ID)
+	assert.JSONEq(t, `{"article":{"title":"Hello"}}`, string(envelope.Payload))
+}
+
+func TestHTTPSink(t *testing.T) {
+	status := http.StatusAccepted
+	var received *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+	s := sink.NewHTTPSink(srv.URL, time.Second)
+
+	require.NoError(t, s.Send(context.TODO(), newMessage()))
+	assert.Equal(t, "7", received.Header.Get("X-Outbox-Message-Id"))
+	assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+	assert.Contains(t, string(body), `"event":"article.created"`)
+
+	status = http.StatusServiceUnavailable
+	assert.Error(t, s.Send(context.TODO(), newMessage()))
+
+	_, err := sink.New(sink.Config{Kind: "kafka"})
+	assert.Error(t, err)
+}
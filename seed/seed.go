@@ -0,0 +1,173 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bxcodec/faker"
+
+	"github.com/naveenpatilm/go-clean-arch/article"
+	"github.com/naveenpatilm/go-clean-arch/author"
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+// DefaultUntil is the time of the most recent generated article when
+// Options.Until is not set. It is fixed so a seed always gives the same data.
+var DefaultUntil = time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Options configures the generated data
+type Options struct {
+	Authors  int
+	Articles int
+	// Seed selects the data: the same seed always generates the same data
+	Seed int64
+	// Until is the time of the most recent article, DefaultUntil when zero
+	Until time.Time
+	// Days is how far before Until the oldest articles are created
+	Days int
+}
+
+// Result is what Run stored
+type Result struct {
+	Authors  int `json:"authors"`
+	Articles int `json:"articles"`
+}
+
+// fakerMu serializes the runs, which share the source of faker
+var fakerMu sync.Mutex
+
+// Run generates the authors and articles described by opts and stores them
+// through the repositories, authors first.
+//
+// Articles get timestamps that are denser close to Until, content of one to
+// a few dozen paragraphs, and authors following a Zipf law: a few authors
+// write most of the articles.
+func Run(ctx context.Context, authorRepo author.Repository, articleRepo article.Repository, opts Options) (Result, error) {
+	var res Result
+	if opts.Authors < 1 || opts.Articles < 0 || opts.Days < 1 {
+		return res, models.ErrBadParamInput
+	}
+	until := opts.Until
+	if until.IsZero() {
+		until = DefaultUntil
+	}
+	span := time.Duration(opts.Days) * 24 * time.Hour
+
+	// faker draws from the global source of math/rand
+	fakerMu.Lock()
+	defer fakerMu.Unlock()
+	rand.Seed(opts.Seed)
+	rnd := rand.New(rand.NewSource(opts.Seed))
+
+	createdAt := make([]time.Time, opts.Articles)
+	for i := range createdAt {
+		// squaring pushes most articles towards until
+		ago := time.Duration(math.Pow(rnd.Float64(), 2) * float64(span))
+		createdAt[i] = until.Add(-ago).Truncate(time.Second)
+	}
+	sort.Slice(createdAt, func(i, j int) bool { return createdAt[i].Before(createdAt[j]) })
+
+	writers := rand.NewZipf(rnd, 1.2, 1, uint64(opts.Authors-1))
+	byAuthor := make([]int, len(createdAt))
+	first := make([]time.Time, opts.Authors)
+	for i, at := range createdAt {
+		byAuthor[i] = int(writers.Uint64())
+		if first[byAuthor[i]].IsZero() {
+			first[byAuthor[i]] = at
+		}
+	}
+
+	authors := make([]*models.Author, opts.Authors)
+	for i := range authors {
+		joined := first[i]
+		if joined.IsZero() {
+			joined = until.Add(-time.Duration(rnd.Int63n(int64(span))))
+		}
+		// authors sign up some time before their first article
+		joined = joined.Add(-time.Duration(rnd.Int63n(int64(30 * 24 * time.Hour)))).Truncate(time.Second)
+		authors[i] = &models.Author{
+			Name:      fakeString(faker.GetPerson().FirstName) + " " + fakeString(faker.GetPerson().LastName),
+			CreatedAt: joined,
+			UpdatedAt: joined,
+		}
+		if err := authorRepo.Store(ctx, authors[i]); err != nil {
+			return res, err
+		}
+		res.Authors++
+	}
+
+	titles := map[string]bool{}
+	for i, at := range createdAt {
+		updatedAt := at
+		if rnd.Intn(4) == 0 {
+			// a quarter of the articles were edited within a week
+			updatedAt = at.Add(time.Duration(rnd.Int63n(int64(7 * 24 * time.Hour)))).Truncate(time.Second)
+		}
+		ar := &models.Article{
+			Title:     uniqueTitle(titles, sentence(rnd, 3, 8)),
+			Content:   content(rnd),
+			Author:    *authors[byAuthor[i]],
+			CreatedAt: at,
+			UpdatedAt: updatedAt,
+		}
+		if err := articleRepo.Store(ctx, ar); err != nil {
+			return res, err
+		}
+		res.Articles++
+	}
+	return res, nil
+}
+
+// content returns paragraphs whose number follows an exponential law,
+// so that most articles are short and a few are long
+func content(rnd *rand.Rand) string {
+	n := 1 + int(rnd.ExpFloat64()*3)
+	if n > 30 {
+		n = 30
+	}
+	paragraphs := make([]string, n)
+	for i := range paragraphs {
+		sentences := make([]string, 2+rnd.Intn(6))
+		for j := range sentences {
+			sentences[j] = sentence(rnd, 6, 18) + "."
+		}
+		paragraphs[i] = strings.Join(sentences, " ")
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// sentence returns between min and max faker words, the first one capitalized.
+// The sentences of faker itself only use six distinct words.
+func sentence(rnd *rand.Rand, min, max int) string {
+	words := make([]string, min+rnd.Intn(max-min+1))
+	for i := range words {
+		words[i] = fakeString(faker.GetLorem().Word)
+	}
+	words[0] = strings.Title(words[0])
+	return strings.Join(words, " ")
+}
+
+// uniqueTitle numbers title when it is already taken.
+// Titles are compared without case, like the repositories do.
+func uniqueTitle(taken map[string]bool, title string) string {
+	candidate := title
+	for n := 2; taken[strings.ToLower(candidate)]; n++ {
+		candidate = fmt.Sprintf("%s %d", title, n)
+	}
+	taken[strings.ToLower(candidate)] = true
+	return candidate
+}
+
+// fakeString calls a faker provider, which never fails for strings
+func fakeString(provider func(reflect.Value) (interface{}, error)) string {
+	v, _ := provider(reflect.Value{})
+	s, _ := v.(string)
+	return s
+}
@@ -0,0 +1,100 @@
+// faker draws from the global source of math/rand, which newer Go
+// releases only let rand.Seed reset with this setting.
+
+//go:debug randseednop=0
+
+package seed_test
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	articleRepo "github.com/naveenpatilm/go-clean-arch/article/repository"
+	authorRepo "github.com/naveenpatilm/go-clean-arch/author/repository"
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/seed"
+)
+
+// generate seeds memory repositories and reads back what was stored
+func generate(t *testing.T, opts seed.Options) ([]*models.Author, []*models.Article) {
+	authors := authorRepo.NewMemoryAuthorRepository()
+	articles := articleRepo.NewMemoryArticleRepository()
+	res, err := seed.Run(context.TODO(), authors, articles, opts)
+	require.NoError(t, err)
+	require.Equal(t, seed.Result{Authors: opts.Authors, Articles: opts.Articles}, res)
+
+	var authorList []*models.Author
+	for id := int64(1); id <= int64(opts.Authors); id++ {
+		a, err := authors.GetByID(context.TODO(), id)
+		require.NoError(t, err)
+		authorList = append(authorList, a)
+	}
+	var articleList []*models.Article
+	for id := int64(1); id <= int64(opts.Articles); id++ {
+		a, err := articles.GetByID(context.TODO(), id)
+		require.NoError(t, err)
+		articleList = append(articleList, a)
+	}
+	return authorList, articleList
+}
+
+func TestRunIsDeterministic(t *testing.T) {
+	opts := seed.Options{Authors: 5, Articles: 40, Seed: 42, Days: 90}
+	authors, articles := generate(t, opts)
+	// other users of the global source do not change the data
+	rand.Int()
+	againAuthors, againArticles := generate(t, opts)
+	assert.Equal(t, authors, againAuthors)
+	assert.Equal(t, articles, againArticles)
+
+	opts.Seed = 43
+	_, otherArticles := generate(t, opts)
+	assert.NotEqual(t, articles[0].Title, otherArticles[0].Title)
+}
+
+func TestRunDistributions(t *testing.T) {
+	until := time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC)
+	authors, articles := generate(t, seed.Options{Authors: 10, Articles: 300, Seed: 7, Until: until, Days: 100})
+
+	titles := map[string]bool{}
+	perAuthor := map[int64]int{}
+	recent := 0
+	for i, ar := range articles {
+		assert.False(t, titles[strings.ToLower(ar.Title)], "duplicated title %q", ar.Title)
+		titles[strings.ToLower(ar.Title)] = true
+		assert.NotEmpty(t, ar.Content)
+
+		assert.False(t, ar.CreatedAt.After(until))
+		assert.False(t, ar.CreatedAt.Before(until.AddDate(0, 0, -100)))
+		assert.False(t, ar.UpdatedAt.Before(ar.CreatedAt))
+		if i > 0 {
+			assert.False(t, ar.CreatedAt.Before(articles[i-1].CreatedAt), "ids follow creation time")
+		}
+		if ar.CreatedAt.After(until.AddDate(0, 0, -50)) {
+			recent++
+		}
+
+		require.True(t, ar.Author.ID >= 1 && ar.Author.ID <= int64(len(authors)))
+		author := authors[ar.Author.ID-1]
+		assert.False(t, author.CreatedAt.After(ar.CreatedAt), "authors join before they write")
+		perAuthor[ar.Author.ID]++
+	}
+
+	// most articles are recent and written by the first authors
+	assert.True(t, recent > len(articles)/2, "recent: %d", recent)
+	assert.True(t, perAuthor[1] > len(articles)/4, "first author: %d", perAuthor[1])
+	for _, a := range authors {
+		assert.Contains(t, a.Name, " ")
+	}
+}
+
+func TestRunInvalidOptions(t *testing.T) {
+	_, err := seed.Run(context.TODO(), authorRepo.NewMemoryAuthorRepository(), articleRepo.NewMemoryArticleRepository(), seed.Options{Authors: 0, Articles: 1, Days: 1})
+	assert.Equal(t, models.ErrBadParamInput, err)
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Config represent the timeouts of the http server
+type Config struct {
+	Address      string
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+	// DrainDelay is how long the server keeps accepting requests after a
+	// shutdown signal, so load balancers see the failing readiness first
+	DrainDelay time.Duration
+	// ShutdownGrace is how long in-flight requests may take to finish once shutdown starts
+	ShutdownGrace time.Duration
+	// TLS serves HTTPS when it is set
+	TLS *TLSConfig
+	// RedirectAddress is where plain HTTP requests are redirected to HTTPS, when set
+	RedirectAddress string
+}
+
+// New will create an http.Server serving handler with the timeouts of c
+func New(c Config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:         c.Address,
+		Handler:      handler,
+		ReadTimeout:  c.ReadTimeout,
+		WriteTimeout: c.WriteTimeout,
+		IdleTimeout:  c.IdleTimeout,
+	}
+}
+
+// WithSignals returns a copy of ctx that is cancelled on SIGINT or SIGTERM
+func WithSignals(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-signals:
+			logrus.Infof("received %s, shutting down", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(signals)
+	}()
+	return ctx, cancel
+}
+
+// WithDrainDelay returns a context that is done delay after ctx.
+// onDrain is called as soon as ctx is done.
+func WithDrainDelay(ctx context.Context, delay time.Duration, onDrain func()) (context.Context, context.CancelFunc) {
+	drained, cancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-drained.Done():
+			return
+		}
+		onDrain()
+		select {
+		case <-time.After(delay):
+		case <-drained.Done():
+		}
+		cancel()
+	}()
+	return drained, cancel
+}
+
+// ListenAndServe listens on srv.Addr and calls Serve
+func ListenAndServe(ctx context.Context, srv *http.Server, grace time.Duration) error {
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+	return Serve(ctx, srv, ln, grace)
+}
+
+// Serve handles requests on ln until ctx is done. It then stops accepting
+// connections and waits up to grace for in-flight requests to complete.
+// It returns nil only when every request was drained in time.
+// Requests are served over TLS, with HTTP/2, when srv.TLSConfig is set.
+func Serve(ctx context.Context, srv *http.Server, ln net.Listener, grace time.Duration) error {
+	errs := make(chan error, 1)
+	go func() {
+		if srv.TLSConfig != nil {
+			errs <- srv.ServeTLS(ln, "", "")
+			return
+		}
+		errs <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		srv.Close()
+		return fmt.Errorf("requests still in flight after the %s grace period: %v", grace, err)
+	}
+	if err := <-errs; err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package server_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/server"
+)
+
+// slowServer answers after delay and reports on started when a request arrives
+func slowServer(delay time.Duration, started chan<- struct{}) (*http.Server, net.Listener, error) {
+	srv := server.New(server.Config{ReadTimeout: time.Second, WriteTimeout: 5 * time.Second}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		time.Sleep(delay)
+		w.Write([]byte("done"))
+	}))
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	return srv, ln, err
+}
+
+func TestServeDrainsInFlightRequests(t *testing.T) {
+	started := make(chan struct{}, 1)
+	srv, ln, err := slowServer(100*time.Millisecond, started)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.TODO())
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(ctx, srv, ln, time.Second)
+	}()
+
+	body := make(chan string, 1)
+	go func() {
+		res, err := http.Get("http://" + ln.Addr().String())
+		if err != nil {
+			body <- err.Error()
+			return
+		}
+		defer res.Body.Close()
+		b, _ := ioutil.ReadAll(res.Body)
+		body <- string(b)
+	}()
+
+	<-started
+	cancel()
+	assert.Equal(t, "done", <-body)
+	assert.NoError(t, <-served)
+
+	// the listener is closed
+	_, err = net.Dial("tcp", ln.Addr().String())
+	assert.Error(t, err)
+}
+
+func TestServeGracePeriodExceeded(t *testing.T) {
+	started := make(chan struct{}, 1)
+	srv, ln, err := slowServer(time.Second, started)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.TODO())
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(ctx, srv, ln, 50*time.Millisecond)
+	}()
+
+	go http.Get("http://" + ln.Addr().String())
+	<-started
+	cancel()
+
+	assert.Error(t, <-served)
+}
+
+func TestWithDrainDelay(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	drained := make(chan struct{})
+	serving, stop := server.WithDrainDelay(ctx, 50*time.Millisecond, func() { close(drained) })
+	defer stop()
+
+	cancel()
+	<-drained
+	// requests are still served during the delay
+	assert.NoError(t, serving.Err())
+	<-serving.Done()
+}
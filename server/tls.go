@@ -0,0 +1,197 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultReloadInterval is used when TLSConfig.ReloadInterval is not set
+const DefaultReloadInterval = 10 * time.Second
+
+// TLSConfig represent the certificate and protocol settings of the server
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
+	// MinVersion is 1.0, 1.1, 1.2 or 1.3. 1.2 is used when it is empty.
+	MinVersion string
+	// CipherSuites restricts the TLS 1.2 cipher suites, by their Go names.
+	// TLS 1.3 suites are not configurable.
+	CipherSuites []string
+	// ReloadInterval is how often the certificate files are checked for changes
+	ReloadInterval time.Duration
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLSVersions lists the accepted values of TLSConfig.MinVersion
+func TLSVersions() []string {
+	return []string{"1.0", "1.1", "1.2", "1.3"}
+}
+
+// CipherSuite returns the id of the cipher suite called name, false when it is unknown or insecure
+func CipherSuite(name string) (uint16, bool) {
+	for _, suite := range tls.CipherSuites() {
+		if suite.Name == name {
+			return suite.ID, true
+		}
+	}
+	return 0, false
+}
+
+// http2Suites are the TLS 1.2 suites HTTP/2 requires one of (RFC 7540, 9.2.2)
+var http2Suites = map[uint16]bool{
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256:   true,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256: true,
+}
+
+// NewTLSConfig will create a tls.Config serving the certificates of certs over HTTP/2 and HTTP/1.1
+func NewTLSConfig(c TLSConfig, certs *CertReloader) (*tls.Config, error) {
+	minVersion := uint16(tls.VersionTLS12)
+	if c.MinVersion != "" {
+		v, ok := tlsVersions[c.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unknown TLS version %q", c.MinVersion)
+		}
+		minVersion = v
+	}
+
+	var suites []uint16
+	http2 := len(c.CipherSuites) == 0
+	for _, name := range c.CipherSuites {
+		id, ok := CipherSuite(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure cipher suite %q", name)
+		}
+		suites = append(suites, id)
+		http2 = http2 || http2Suites[id]
+	}
+	if !http2 && minVersion < tls.VersionTLS13 {
+		return nil, fmt.Errorf("HTTP/2 requires TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 or TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256 in the cipher suites")
+	}
+
+	return &tls.Config{
+		GetCertificate: certs.GetCertificate,
+		MinVersion:     minVersion,
+		CipherSuites:   suites,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}, nil
+}
+
+// CertReloader serves a certificate loaded from disk and reloads it when its files change
+type CertReloader struct {
+	certFile, keyFile string
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+// NewCertReloader loads the key pair of certFile and keyFile
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate returns the current certificate. It is a tls.Config.GetCertificate.
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// Reload loads the key pair from disk. The current certificate is kept when it fails.
+func (r *CertReloader) Reload() error {
+	modTime, err := r.lastModified()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.cert, r.modTime = &cert, modTime
+	r.mu.Unlock()
+	return nil
+}
+
+// Watch reloads the key pair every interval when one of its files changed, until ctx is done
+func (r *CertReloader) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultReloadInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+
+		modTime, err := r.lastModified()
+		r.mu.RLock()
+		changed := err == nil && !modTime.Equal(r.modTime)
+		r.mu.RUnlock()
+		if !changed {
+			continue
+		}
+		// both files may not be written yet, the next tick retries
+		if err = r.Reload(); err != nil {
+			logrus.Warn("keeping the current TLS certificate: ", err)
+			continue
+		}
+		logrus.Info("TLS certificate reloaded from ", r.certFile)
+	}
+}
+
+// lastModified returns the latest modification time of the key pair files
+func (r *CertReloader) lastModified() (time.Time, error) {
+	var latest time.Time
+	for _, path := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return latest, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// NewRedirect will create an http.Server on address redirecting every request
+// to the same URL over HTTPS on the port of tlsAddress
+func NewRedirect(c Config, address, tlsAddress string) *http.Server {
+	_, port, _ := net.SplitHostPort(tlsAddress)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+		target := "https://" + host + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
+	})
+	c.Address = address
+	return New(c, handler)
+}
@@ -0,0 +1,190 @@
+package server_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/server"
+)
+
+// writeCert writes a self-signed certificate for 127.0.0.1 named commonName,
+// modified at modTime, and returns the certificate to trust
+func writeCert(t *testing.T, dir, commonName string, modTime time.Time) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+// serveTLS serves the certificates of dir on a random port and returns its address
+func serveTLS(t *testing.T, ctx context.Context, dir string, c server.TLSConfig) string {
+	certs, err := server.NewCertReloader(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"))
+	require.NoError(t, err)
+	go certs.Watch(ctx, 10*time.Millisecond)
+
+	srv := server.New(server.Config{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	}))
+	srv.TLSConfig, err = server.NewTLSConfig(c, certs)
+	require.NoError(t, err)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Serve(ctx, srv, ln, time.Second)
+	return ln.Addr().String()
+}
+
+// get requests address over a new connection trusting roots
+func get(t *testing.T, address string, roots ...*x509.Certificate) (*http.Response, string) {
+	pool := x509.NewCertPool()
+	for _, cert := range roots {
+		pool.AddCert(cert)
+	}
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: pool},
+		ForceAttemptHTTP2: true,
+		DisableKeepAlives: true,
+	}}
+	res, err := client.Get("https://" + address)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	return res, string(body)
+}
+
+func TestServeTLSWithHTTP2(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	cert := writeCert(t, dir, "first", time.Now())
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	address := serveTLS(t, ctx, dir, server.TLSConfig{MinVersion: "1.2"})
+
+	res, body := get(t, address, cert)
+	assert.Equal(t, "HTTP/2.0", body)
+	assert.Equal(t, "first", res.TLS.PeerCertificates[0].Subject.CommonName)
+}
+
+func TestCertificateIsReloaded(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	first := writeCert(t, dir, "first", time.Now().Add(-time.Minute))
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	address := serveTLS(t, ctx, dir, server.TLSConfig{})
+
+	second := writeCert(t, dir, "second", time.Now())
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		res, _ := get(t, address, first, second)
+		if res.TLS.PeerCertificates[0].Subject.CommonName == "second" {
+			break
+		}
+		require.True(t, time.Now().Before(deadline), "the certificate was not reloaded")
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCertReloaderKeepsCertificateOnError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	writeCert(t, dir, "first", time.Now())
+	certs, err := server.NewCertReloader(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"))
+	require.NoError(t, err)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "key.pem"), []byte("truncated"), 0600))
+
+	assert.Error(t, certs.Reload())
+	cert, err := certs.GetCertificate(nil)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	assert.Equal(t, "first", leaf.Subject.CommonName)
+}
+
+func TestNewTLSConfigErrors(t *testing.T) {
+	for name, c := range map[string]server.TLSConfig{
+		"version":        {MinVersion: "1.4"},
+		"unknown-suite":  {CipherSuites: []string{"TLS_NULL"}},
+		"insecure-suite": {CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}},
+		"no-http2-suite": {CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := server.NewTLSConfig(c, nil)
+			assert.Error(t, err)
+		})
+	}
+
+	c, err := server.NewTLSConfig(server.TLSConfig{MinVersion: "1.3", CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), c.MinVersion)
+}
+
+func TestRedirect(t *testing.T) {
+	srv := server.NewRedirect(server.Config{}, ":8080", ":8443")
+	for _, tc := range []struct{ host, target string }{
+		{"example.com:8080", "https://example.com:8443/articles?num=2"},
+		{"example.com", "https://example.com:8443/articles?num=2"},
+	} {
+		req, err := http.NewRequest("POST", "http://"+tc.host+"/articles?num=2", nil)
+		require.NoError(t, err)
+		rec := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusPermanentRedirect, rec.Code)
+		assert.Equal(t, tc.target, rec.Header().Get("Location"))
+	}
+
+	srv = server.NewRedirect(server.Config{}, ":80", ":443")
+	req, err := http.NewRequest("GET", "http://example.com/", nil)
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(rec, req)
+	assert.Equal(t, "https://example.com/", rec.Header().Get("Location"))
+}
@@ -0,0 +1,209 @@
+package broker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/naveenpatilm/go-clean-arch/stream"
+)
+
+// Config represent how the broker polls its log and buffers the entries of each subscriber
+type Config struct {
+	// PollInterval is the wait between two polls of a log that does not notify its appends
+	PollInterval time.Duration
+	// BatchSize is how many entries are read at once
+	BatchSize int
+	// Buffer is how many entries may wait for a subscriber before it is dropped
+	Buffer int
+	// Polled, when set, is called whenever the log was read successfully
+	Polled func()
+}
+
+// notifier is implemented by the logs waking the broker up when entries are appended
+type notifier interface {
+	Notify() <-chan struct{}
+}
+
+type broker struct {
+	log stream.Log
+	c   Config
+	// ready is closed once the cursor is initialized
+	ready chan struct{}
+
+	mu      sync.Mutex
+	subs    map[*subscription]struct{}
+	stopped bool
+}
+
+// NewBroker will create an implementation of stream.Broker for the entries of log
+func NewBroker(log stream.Log, c Config) stream.Broker {
+	if c.PollInterval <= 0 {
+		c.PollInterval = time.Second
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 500
+	}
+	if c.Buffer <= 0 {
+		c.Buffer = 64
+	}
+	return &broker{log: log, c: c, ready: make(chan struct{}), subs: map[*subscription]struct{}{}}
+}
+
+// Subscribe waits until the broker runs, so that no entry falls between a
+// replay of the log and the entries received
+func (b *broker) Subscribe(ctx context.Context) (stream.Subscription, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-b.ready:
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stopped {
+		return nil, stream.ErrClosed
+	}
+	s := &subscription{b: b, entries: make(chan stream.Entry, b.c.Buffer)}
+	b.subs[s] = struct{}{}
+	return s, nil
+}
+
+func (b *broker) Run(ctx context.Context) error {
+	defer b.stop()
+	cursor, err := b.last(ctx)
+	if err != nil {
+		return nil
+	}
+	close(b.ready)
+
+	var notify <-chan struct{}
+	if n, ok := b.log.(notifier); ok {
+		notify = n.Notify()
+	}
+	for {
+		entries, err := b.log.After(ctx, cursor, b.c.BatchSize)
+		switch {
+		case err == stream.ErrGone:
+			// the log dropped entries before they were read: every subscriber missed them
+			logrus.Warn("stream broker fell behind its log")
+			b.dropAll()
+			if cursor, err = b.last(ctx); err != nil {
+				return nil
+			}
+		case err != nil:
+			if ctx.Err() == nil {
+				logrus.Error("stream broker: ", err)
+			}
+		case len(entries) > 0:
+			b.publish(entries)
+			cursor = entries[len(entries)-1].ID
+		}
+		if err == nil && b.c.Polled != nil {
+			b.c.Polled()
+		}
+		if err == nil && len(entries) == b.c.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-notify:
+		case <-time.After(b.c.PollInterval):
+		}
+	}
+}
+
+// last reads the newest entry of the log, retrying until ctx is done
+func (b *broker) last(ctx context.Context) (int64, error) {
+	for {
+		last, err := b.log.Last(ctx)
+		if err == nil {
+			return last, nil
+		}
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		logrus.Error("stream broker: ", err)
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(b.c.PollInterval):
+		}
+	}
+}
+
+// publish hands entries to every subscriber without waiting, dropping the subscribers whose buffer is full
+func (b *broker) publish(entries []stream.Entry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for s := range b.subs {
+		for _, e := range entries {
+			select {
+			case s.entries <- e:
+				continue
+			default:
+			}
+			s.end(stream.ErrSlow)
+			break
+		}
+	}
+}
+
+func (b *broker) dropAll() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for s := range b.subs {
+		s.end(stream.ErrSlow)
+	}
+}
+
+func (b *broker) stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stopped = true
+	for s := range b.subs {
+		s.end(nil)
+	}
+	select {
+	case <-b.ready:
+	default:
+		close(b.ready)
+	}
+}
+
+type subscription struct {
+	b       *broker
+	entries chan stream.Entry
+	// err is set when the subscription ends, b.mu being held
+	err   error
+	ended bool
+}
+
+func (s *subscription) Entries() <-chan stream.Entry {
+	return s.entries
+}
+
+func (s *subscription) Err() error {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	return s.err
+}
+
+func (s *subscription) Close() {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	s.end(nil)
+}
+
+// end unregisters s and closes its entries, b.mu being held
+func (s *subscription) end(err error) {
+	if s.ended {
+		return
+	}
+	s.ended, s.err = true, err
+	delete(s.b.subs, s)
+	close(s.entries)
+}
@@ -0,0 +1,86 @@
+package broker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/stream"
+	"github.com/naveenpatilm/go-clean-arch/stream/broker"
+	_streamRepo "github.com/naveenpatilm/go-clean-arch/stream/repository"
+)
+
+func created(id int64) models.Event {
+	return models.ArticleCreated{Article: models.Article{ID: id}, OccurredAt: time.Now()}
+}
+
+func receive(t *testing.T, sub stream.Subscription) stream.Entry {
+	select {
+	case e, ok := <-sub.Entries():
+		require.True(t, ok, "the subscription ended: %v", sub.Err())
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("no entry received")
+	}
+	return stream.Entry{}
+}
+
+func TestFanOut(t *testing.T) {
+	log := _streamRepo.NewMemoryLog(10)
+	ctx := context.TODO()
+	require.NoError(t, log.Append(ctx, created(1)))
+	b := broker.NewBroker(log, broker.Config{PollInterval: time.Hour, Buffer: 2})
+	runCtx, stop := context.WithCancel(ctx)
+	done := make(chan error)
+	go func() { done <- b.Run(runCtx) }()
+
+	first, err := b.Subscribe(ctx)
+	require.NoError(t, err)
+	second, err := b.Subscribe(ctx)
+	require.NoError(t, err)
+	defer second.Close()
+
+	// the entries appended before Run are not published again
+	require.NoError(t, log.Append(ctx, created(2)))
+	assert.Equal(t, int64(2), receive(t, first).ID)
+	assert.Equal(t, int64(2), receive(t, second).ID)
+	first.Close()
+	require.NoError(t, log.Append(ctx, created(3)))
+	assert.Equal(t, int64(3), receive(t, second).ArticleID)
+
+	stop()
+	require.NoError(t, <-done)
+	_, ok := <-second.Entries()
+	assert.False(t, ok)
+	assert.NoError(t, second.Err())
+	_, err = b.Subscribe(ctx)
+	assert.Equal(t, stream.ErrClosed, err)
+}
+
+func TestSlowSubscriber(t *testing.T) {
+	log := _streamRepo.NewMemoryLog(10)
+	ctx, stop := context.WithCancel(context.TODO())
+	defer stop()
+	b := broker.NewBroker(log, broker.Config{PollInterval: time.Hour, Buffer: 2})
+	go b.Run(ctx)
+	slow, err := b.Subscribe(ctx)
+	require.NoError(t, err)
+	fast, err := b.Subscribe(ctx)
+	require.NoError(t, err)
+
+	for i := int64(1); i <= 3; i++ {
+		require.NoError(t, log.Append(ctx, created(i)))
+		assert.Equal(t, i, receive(t, fast).ID)
+	}
+
+	// slow received what its buffer holds, then was dropped
+	assert.Equal(t, int64(1), receive(t, slow).ID)
+	assert.Equal(t, int64(2), receive(t, slow).ID)
+	_, ok := <-slow.Entries()
+	assert.False(t, ok)
+	assert.Equal(t, stream.ErrSlow, slow.Err())
+}
@@ -0,0 +1,204 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"github.com/naveenpatilm/go-clean-arch/stream"
+)
+
+// ResponseError represent the reseponse error struct
+type ResponseError struct {
+	Message string `json:"message"`
+}
+
+// Config represent how often the streams are kept alive
+type Config struct {
+	// Heartbeat is the wait between two comments sent on an idle stream, 0 disables them
+	Heartbeat time.Duration
+}
+
+// reconnectDelay is the retry sent to the clients, in milliseconds
+const reconnectDelay = 3000
+
+// errClientGone ends a stream whose client disconnected
+var errClientGone = errors.New("the client disconnected")
+
+// backlogSize is how many entries of the log are read at once when a client resumes
+const backlogSize = 500
+
+// HttpStreamHandler represent the httphandler for the article stream
+type HttpStreamHandler struct {
+	Log    stream.Log
+	Broker stream.Broker
+	c      Config
+}
+
+// NewStreamHttpHandler registers the Server-Sent Events stream of the article changes on r
+func NewStreamHttpHandler(r *mux.Router, log stream.Log, broker stream.Broker, c Config) {
+	handler := &HttpStreamHandler{
+		Log:    log,
+		Broker: broker,
+		c:      c,
+	}
+	r.HandleFunc("/articles/stream", handler.Stream).Methods("GET")
+}
+
+// Stream sends the article events as they happen. A client sending Last-Event-ID,
+// as a header or the last_event_id parameter, first receives the events it missed,
+// or a reset event when the log no longer holds them. author_id filters the
+// events to the articles of an author. The stream is exempt from the write
+// timeout of the server, and lasts until the client disconnects.
+func (h *HttpStreamHandler) Stream(w http.ResponseWriter, req *http.Request) {
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		logrus.Warn("the stream may be cut by the write timeout: ", err)
+	}
+	params := req.URL.Query()
+	var authorID int64
+	if v := params.Get("author_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			writeJSON(w, http.StatusBadRequest, ResponseError{Message: "author_id must be a positive integer"})
+			return
+		}
+		authorID = id
+	}
+	lastEventID := req.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = params.Get("last_event_id")
+	}
+	var last int64
+	if lastEventID != "" {
+		id, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || id < 0 {
+			writeJSON(w, http.StatusBadRequest, ResponseError{Message: "Last-Event-ID must be a non negative integer"})
+			return
+		}
+		last = id
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSON(w, http.StatusInternalServerError, ResponseError{Message: "streaming is not supported"})
+		return
+	}
+
+	ctx := req.Context()
+	// subscribe before reading the log: the entries appended meanwhile are
+	// received twice rather than missed
+	sub, err := h.Broker.Subscribe(ctx)
+	if err != nil {
+		if err == stream.ErrClosed {
+			writeJSON(w, http.StatusServiceUnavailable, ResponseError{Message: err.Error()})
+		}
+		return
+	}
+	defer sub.Close()
+
+	if lastEventID == "" {
+		if last, err = h.Log.Last(ctx); err != nil {
+			logrus.Error(err)
+			writeJSON(w, http.StatusInternalServerError, ResponseError{Message: "Internal Server Error"})
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// keeps proxies such as nginx from buffering the events
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", reconnectDelay)
+	if lastEventID != "" {
+		if last, err = h.replay(w, req, last, authorID); err != nil {
+			if err != errClientGone {
+				logrus.Error(err)
+			}
+			return
+		}
+	}
+	flusher.Flush()
+
+	var heartbeat <-chan time.Time
+	if h.c.Heartbeat > 0 {
+		ticker := time.NewTicker(h.c.Heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case e, ok := <-sub.Entries():
+			if !ok {
+				if sub.Err() != nil {
+					logrus.WithField("remote_addr", req.RemoteAddr).Warn(sub.Err())
+				}
+				return
+			}
+			if e.ID <= last {
+				continue
+			}
+			if !h.write(w, e, authorID) {
+				return
+			}
+			last = e.ID
+		}
+		flusher.Flush()
+	}
+}
+
+// replay sends the entries of the log following last, or a reset event when
+// the log no longer holds them, and returns the ID of the last entry sent
+func (h *HttpStreamHandler) replay(w http.ResponseWriter, req *http.Request, last int64, authorID int64) (int64, error) {
+	flusher := w.(http.Flusher)
+	for {
+		entries, err := h.Log.After(req.Context(), last, backlogSize)
+		if err == stream.ErrGone {
+			if last, err = h.Log.Last(req.Context()); err != nil {
+				return 0, err
+			}
+			data, _ := json.Marshal(ResponseError{Message: stream.ErrGone.Error()})
+			_, err = fmt.Fprintf(w, "id: %d\nevent: reset\ndata: %s\n\n", last, data)
+			return last, err
+		}
+		if err != nil {
+			return 0, err
+		}
+		for _, e := range entries {
+			if !h.write(w, e, authorID) {
+				return 0, errClientGone
+			}
+			last = e.ID
+		}
+		if len(entries) < backlogSize {
+			return last, nil
+		}
+		flusher.Flush()
+	}
+}
+
+// write sends e unless it is filtered out by authorID, and reports whether the client is still connected
+func (h *HttpStreamHandler) write(w http.ResponseWriter, e stream.Entry, authorID int64) bool {
+	if authorID != 0 && e.AuthorID != authorID {
+		return true
+	}
+	_, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Event, e.Data)
+	return err == nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,157 @@
+package http_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/stream/broker"
+	streamHttp "github.com/naveenpatilm/go-clean-arch/stream/delivery/http"
+	_streamRepo "github.com/naveenpatilm/go-clean-arch/stream/repository"
+)
+
+func created(id, authorID int64) models.Event {
+	return models.ArticleCreated{Article: models.Article{ID: id, Title: "title", Author: models.Author{ID: authorID}}}
+}
+
+// newServer serves the stream of log and returns it with a function stopping the broker and the server
+func newServer(t *testing.T, log _streamRepo.MemoryLog, c streamHttp.Config) (*httptest.Server, func()) {
+	return newTimedServer(t, log, c, 0)
+}
+
+// newTimedServer is newServer with the write timeout of the server
+func newTimedServer(t *testing.T, log _streamRepo.MemoryLog, c streamHttp.Config, writeTimeout time.Duration) (*httptest.Server, func()) {
+	ctx, stop := context.WithCancel(context.TODO())
+	b := broker.NewBroker(log, broker.Config{PollInterval: time.Hour})
+	go b.Run(ctx)
+	r := mux.NewRouter()
+	streamHttp.NewStreamHttpHandler(r, log, b, c)
+	srv := httptest.NewUnstartedServer(r)
+	srv.Config.WriteTimeout = writeTimeout
+	srv.Start()
+	return srv, func() {
+		stop()
+		srv.Close()
+	}
+}
+
+type client struct {
+	t    *testing.T
+	resp *http.Response
+	r    *bufio.Reader
+}
+
+func connect(t *testing.T, srv *httptest.Server, query, lastEventID string) *client {
+	req, err := http.NewRequest("GET", srv.URL+"/articles/stream"+query, nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	c := &client{t: t, resp: resp, r: bufio.NewReader(resp.Body)}
+	assert.Equal(t, "retry: 3000", c.next())
+	return c
+}
+
+// next returns the next event or comment, its lines joined by "|"
+func (c *client) next() string {
+	var lines []string
+	for {
+		line, err := c.r.ReadString('\n')
+		require.NoError(c.t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return strings.Join(lines, "|")
+		}
+		lines = append(lines, line)
+	}
+}
+
+// event returns the next event, skipping the heartbeats
+func (c *client) event() string {
+	for {
+		if e := c.next(); !strings.HasPrefix(e, ":") {
+			return e
+		}
+	}
+}
+
+func TestStreamLive(t *testing.T) {
+	log := _streamRepo.NewMemoryLog(10)
+	ctx := context.TODO()
+	require.NoError(t, log.Append(ctx, created(1, 7)))
+	srv, stop := newServer(t, log, streamHttp.Config{})
+	defer stop()
+
+	c := connect(t, srv, "?author_id=7", "")
+	defer c.resp.Body.Close()
+	require.NoError(t, log.Append(ctx, created(2, 8)))
+	require.NoError(t, log.Append(ctx, created(3, 7)))
+
+	// 1 was appended before the client connected, and 2 is of another author
+	e := c.event()
+	assert.True(t, strings.HasPrefix(e, "id: 3|event: article.created|data: {"), e)
+	assert.Contains(t, e, `"ID":3`)
+}
+
+func TestStreamResume(t *testing.T) {
+	log := _streamRepo.NewMemoryLog(3)
+	ctx := context.TODO()
+	for i := int64(1); i <= 3; i++ {
+		require.NoError(t, log.Append(ctx, created(i, 7)))
+	}
+	srv, stop := newServer(t, log, streamHttp.Config{})
+	defer stop()
+
+	c := connect(t, srv, "", "1")
+	assert.True(t, strings.HasPrefix(c.event(), "id: 2|"))
+	assert.True(t, strings.HasPrefix(c.event(), "id: 3|"))
+	require.NoError(t, log.Append(ctx, created(4, 7)))
+	assert.True(t, strings.HasPrefix(c.event(), "id: 4|"))
+	c.resp.Body.Close()
+
+	// 2 was dropped from the log
+	require.NoError(t, log.Append(ctx, created(5, 7)))
+	c = connect(t, srv, "?last_event_id=1", "")
+	defer c.resp.Body.Close()
+	assert.True(t, strings.HasPrefix(c.event(), "id: 5|event: reset|data: "))
+	require.NoError(t, log.Append(ctx, created(6, 7)))
+	assert.True(t, strings.HasPrefix(c.event(), "id: 6|"))
+}
+
+func TestStreamOutlivesTheWriteTimeout(t *testing.T) {
+	log := _streamRepo.NewMemoryLog(10)
+	srv, stop := newTimedServer(t, log, streamHttp.Config{Heartbeat: 150 * time.Millisecond}, 50*time.Millisecond)
+	defer stop()
+
+	c := connect(t, srv, "", "")
+	defer c.resp.Body.Close()
+	// the first heartbeat comes after the write timeout
+	assert.Equal(t, ": heartbeat", c.next())
+	require.NoError(t, log.Append(context.TODO(), created(1, 7)))
+	assert.True(t, strings.HasPrefix(c.event(), "id: 1|"))
+}
+
+func TestStreamBadRequest(t *testing.T) {
+	srv, stop := newServer(t, _streamRepo.NewMemoryLog(10), streamHttp.Config{})
+	defer stop()
+
+	for _, query := range []string{"?author_id=abc", "?last_event_id=-1"} {
+		resp, err := http.Get(srv.URL + "/articles/stream" + query)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+	}
+}
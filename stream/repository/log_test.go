@@ -0,0 +1,121 @@
+package repository_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/database"
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/outbox"
+	"github.com/naveenpatilm/go-clean-arch/stream"
+	_streamRepo "github.com/naveenpatilm/go-clean-arch/stream/repository"
+)
+
+func created(id, authorID int64) models.Event {
+	return models.ArticleCreated{Article: models.Article{ID: id, Author: models.Author{ID: authorID}}, OccurredAt: time.Now()}
+}
+
+func ids(entries []stream.Entry) []int64 {
+	ids := []int64{}
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestMemoryLog(t *testing.T) {
+	log := _streamRepo.NewMemoryLog(3)
+	ctx := context.TODO()
+
+	entries, err := log.After(ctx, 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	for i := int64(1); i <= 5; i++ {
+		require.NoError(t, log.Append(ctx, created(i*10, 7)))
+	}
+	select {
+	case <-log.Notify():
+	default:
+		t.Fatal("Append must notify")
+	}
+
+	last, err := log.Last(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), last)
+	entries, err = log.After(ctx, 2, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3, 4, 5}, ids(entries))
+	assert.Equal(t, models.ArticleCreatedEvent, entries[0].Event)
+	assert.Equal(t, int64(30), entries[0].ArticleID)
+	assert.Equal(t, int64(7), entries[0].AuthorID)
+	entries, err = log.After(ctx, 3, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{4}, ids(entries))
+
+	// 2 was dropped, and 6 does not exist yet
+	_, err = log.After(ctx, 1, 10)
+	assert.Equal(t, stream.ErrGone, err)
+	_, err = log.After(ctx, 6, 10)
+	assert.Equal(t, stream.ErrGone, err)
+}
+
+func TestOutboxLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stream")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	db, err := database.Open(database.Config{Driver: database.Sqlite, Path: filepath.Join(dir, "article.db")})
+	require.NoError(t, err)
+	defer db.Close()
+	migrator, err := database.NewMigrator(db.DB(), database.Sqlite)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Up(context.TODO()))
+
+	ctx := context.TODO()
+	log := _streamRepo.NewOutboxLog(db, time.Minute)
+	last, err := log.Last(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), last)
+
+	store := func(e models.Event, age time.Duration) {
+		m, err := outbox.NewMessage(e)
+		require.NoError(t, err)
+		m.CreatedAt = m.CreatedAt.Add(-age)
+		require.NoError(t, db.Create(m).Error())
+	}
+	store(created(1, 7), time.Hour)
+	store(created(2, 8), time.Hour)
+	store(created(3, 7), time.Hour)
+	// 4 may still be committed before 5, which is not streamed yet
+	store(created(4, 7), 0)
+	store(created(5, 7), time.Hour)
+
+	last, err = log.Last(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), last)
+	entries, err := log.After(ctx, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, ids(entries))
+	assert.Equal(t, int64(8), entries[1].AuthorID)
+	assert.Equal(t, int64(2), entries[1].ArticleID)
+	assert.Equal(t, models.ArticleCreatedEvent, entries[1].Event)
+	entries, err = log.After(ctx, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2}, ids(entries))
+
+	// the messages dispatched long ago are purged
+	require.NoError(t, db.Exec("DELETE FROM outbox_messages WHERE id <= 2").Error())
+	entries, err = log.After(ctx, 2, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3}, ids(entries))
+	_, err = log.After(ctx, 1, 10)
+	assert.Equal(t, stream.ErrGone, err)
+	_, err = log.After(ctx, 6, 10)
+	assert.Equal(t, stream.ErrGone, err)
+}
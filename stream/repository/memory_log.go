@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/stream"
+)
+
+// MemoryLog is a stream.Log keeping the last entries in memory. It only holds
+// the events of this process and starts empty, so clients resuming after a
+// restart are told that their entries are gone.
+type MemoryLog interface {
+	stream.Log
+	// Append adds e to the log, dropping the oldest entry when it is full. It is an event.Handler.
+	Append(ctx context.Context, e models.Event) error
+	// Notify receives a value after entries were appended, to wake the broker up
+	Notify() <-chan struct{}
+}
+
+type memoryLog struct {
+	mu sync.Mutex
+	// entries is a ring buffer, entries[(id-1)%len] holds the entry id
+	entries []stream.Entry
+	last    int64
+	notify  chan struct{}
+}
+
+// NewMemoryLog will create a MemoryLog holding up to size entries.
+// Subscribe its Append to the event bus to fill it.
+func NewMemoryLog(size int) MemoryLog {
+	if size <= 0 {
+		size = 1
+	}
+	return &memoryLog{entries: make([]stream.Entry, size), notify: make(chan struct{}, 1)}
+}
+
+func (m *memoryLog) Append(ctx context.Context, e models.Event) error {
+	m.mu.Lock()
+	entry, err := stream.NewEntry(m.last+1, e)
+	if err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	m.last = entry.ID
+	m.entries[(entry.ID-1)%int64(len(m.entries))] = entry
+	m.mu.Unlock()
+
+	select {
+	case m.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (m *memoryLog) Notify() <-chan struct{} {
+	return m.notify
+}
+
+func (m *memoryLog) After(ctx context.Context, id int64, limit int) ([]stream.Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	size := int64(len(m.entries))
+	oldest := m.last - size + 1
+	if oldest < 1 {
+		oldest = 1
+	}
+	if id > m.last || id < oldest-1 {
+		return nil, stream.ErrGone
+	}
+	entries := []stream.Entry{}
+	for next := id + 1; next <= m.last && len(entries) < limit; next++ {
+		entries = append(entries, m.entries[(next-1)%size])
+	}
+	return entries, nil
+}
+
+func (m *memoryLog) Last(ctx context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.last, nil
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/outbox"
+	"github.com/naveenpatilm/go-clean-arch/stream"
+)
+
+type outboxLog struct {
+	DB     models.Gormw
+	settle time.Duration
+}
+
+// NewOutboxLog will create a stream.Log reading the messages of the outbox, so
+// that every instance streams the changes made through any of them and clients
+// resume across restarts, for as long as the outbox retains the messages.
+//
+// The outbox IDs are not committed in order: a message is only read once it is
+// older than settle, which must exceed the longest article transaction, and the
+// log stops at the first message that is not.
+func NewOutboxLog(DB models.Gormw, settle time.Duration) stream.Log {
+	return &outboxLog{DB: DB, settle: settle}
+}
+
+func (m *outboxLog) cutoff() time.Time {
+	return time.Now().UTC().Add(-m.settle)
+}
+
+func (m *outboxLog) After(ctx context.Context, id int64, limit int) ([]stream.Entry, error) {
+	var messages []*outbox.Message
+	err := m.DB.WithContext(ctx).Where("id > ?", id).Order("id").Limit(limit).Find(&messages).Error()
+	if err = models.ContextError(ctx, err); err != nil {
+		return nil, err
+	}
+	if id > 0 && (len(messages) == 0 || messages[0].ID != id+1) {
+		if err = m.checkKept(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	cutoff := m.cutoff()
+	entries := []stream.Entry{}
+	for _, message := range messages {
+		if !message.CreatedAt.Before(cutoff) {
+			break
+		}
+		entries = append(entries, newEntry(message))
+	}
+	return entries, nil
+}
+
+// checkKept returns ErrGone when the messages following id may have been purged
+func (m *outboxLog) checkKept(ctx context.Context, id int64) error {
+	last, err := m.Last(ctx)
+	if err != nil {
+		return err
+	}
+	if id > last {
+		return stream.ErrGone
+	}
+	var oldest sql.NullInt64
+	err = m.DB.WithContext(ctx).Raw("SELECT MIN(id) FROM outbox_messages").Row().Scan(&oldest)
+	if err = models.ContextError(ctx, err); err != nil {
+		return err
+	}
+	// IDs of rolled back transactions are never used, so a missing id only
+	// means that it was purged when no older message remains
+	if oldest.Valid && id < oldest.Int64-1 {
+		return stream.ErrGone
+	}
+	return nil
+}
+
+// Last is the message before the oldest one that is not settled yet, or the newest message
+func (m *outboxLog) Last(ctx context.Context) (int64, error) {
+	var id sql.NullInt64
+	err := m.DB.WithContext(ctx).Raw("SELECT MIN(id) FROM outbox_messages WHERE created_at >= ?", m.cutoff()).Row().Scan(&id)
+	if err = models.ContextError(ctx, err); err != nil {
+		return 0, err
+	}
+	if id.Valid {
+		return id.Int64 - 1, nil
+	}
+	err = m.DB.WithContext(ctx).Raw("SELECT MAX(id) FROM outbox_messages").Row().Scan(&id)
+	if err = models.ContextError(ctx, err); err != nil {
+		return 0, err
+	}
+	return id.Int64, nil
+}
+
+// newEntry reads the author of the article from the payload of message
+func newEntry(message *outbox.Message) stream.Entry {
+	var payload struct {
+		Article models.Article `json:"article"`
+	}
+	json.Unmarshal([]byte(message.Payload), &payload)
+	return stream.Entry{
+		ID:        message.ID,
+		Event:     message.EventName,
+		ArticleID: message.ArticleID,
+		AuthorID:  payload.Article.Author.ID,
+		Data:      message.Payload,
+	}
+}
@@ -0,0 +1,72 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+// Kinds of the log the stream resumes from
+const (
+	LogMemory = "memory"
+	LogOutbox = "outbox"
+)
+
+var (
+	// ErrGone is returned when the entries following an ID are no longer in the log
+	ErrGone = errors.New("the stream log no longer holds the requested entries")
+	// ErrSlow ends the subscription of a subscriber that fell behind
+	ErrSlow = errors.New("the subscriber fell behind the stream")
+	// ErrClosed is returned when subscribing to a stopped broker
+	ErrClosed = errors.New("the stream broker is stopped")
+)
+
+// Entry is an article event of the stream log
+type Entry struct {
+	// ID orders the entries. A client resumes after the last ID it received.
+	ID        int64
+	Event     string
+	ArticleID int64
+	AuthorID  int64
+	// Data is the event encoded as JSON
+	Data string
+}
+
+// NewEntry will create the entry id of e
+func NewEntry(id int64, e models.Event) (Entry, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return Entry{}, err
+	}
+	ar := e.EventArticle()
+	return Entry{ID: id, Event: e.EventName(), ArticleID: ar.ID, AuthorID: ar.Author.ID, Data: string(data)}, nil
+}
+
+// Log represent the bounded history of article events the stream resumes from
+type Log interface {
+	// After returns up to limit entries following the entry id, oldest first.
+	// It returns ErrGone when some of them were dropped, or when id is newer than the log.
+	After(ctx context.Context, id int64, limit int) ([]Entry, error)
+	// Last returns the ID of the newest entry, 0 when the log is empty
+	Last(ctx context.Context) (int64, error)
+}
+
+// Subscription receives the entries appended to the log of a Broker
+type Subscription interface {
+	// Entries is closed when the subscriber fell behind or the broker stopped
+	Entries() <-chan Entry
+	// Err is ErrSlow once Entries is closed because the subscriber fell behind
+	Err() error
+	Close()
+}
+
+// Broker represent the fan-out of the new entries of a Log to the subscribers.
+// It never waits for a subscriber: one whose buffer is full is dropped with ErrSlow.
+type Broker interface {
+	// Subscribe registers a subscriber receiving the entries appended from now on
+	Subscribe(ctx context.Context) (Subscription, error)
+	// Run polls the log until ctx is done, then closes the subscriptions
+	Run(ctx context.Context) error
+}
@@ -0,0 +1,244 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strconv"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// otlpFileExporter writes each batch of spans as one line of OTLP JSON, the
+// format read by the otlpjsonfile receiver of the OpenTelemetry collector
+type otlpFileExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewOTLPFileExporter will create a SpanExporter writing OTLP JSON lines to w
+func NewOTLPFileExporter(w io.Writer) sdktrace.SpanExporter {
+	return &otlpFileExporter{w: w}
+}
+
+func (e *otlpFileExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	line, err := json.Marshal(otlpTraces(spans))
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err = e.w.Write(append(line, '\n'))
+	return err
+}
+
+func (e *otlpFileExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// The types below follow the JSON mapping of the OTLP protobuf messages:
+// ids are hex strings, 64 bits integers are decimal strings.
+
+type otlpTracesData struct {
+	ResourceSpans []*otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource      `json:"resource"`
+	ScopeSpans []*otlpScopeSpans `json:"scopeSpans"`
+	SchemaURL  string            `json:"schemaUrl,omitempty"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope     otlpScope  `json:"scope"`
+	Spans     []otlpSpan `json:"spans"`
+	SchemaURL string     `json:"schemaUrl,omitempty"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	TraceState        string         `json:"traceState,omitempty"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Events            []otlpEvent    `json:"events,omitempty"`
+	Links             []otlpLink     `json:"links,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpEvent struct {
+	TimeUnixNano string         `json:"timeUnixNano"`
+	Name         string         `json:"name"`
+	Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpLink struct {
+	TraceID    string         `json:"traceId"`
+	SpanID     string         `json:"spanId"`
+	Attributes []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+// otlpStatus codes are 0 unset, 1 ok and 2 error
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string         `json:"stringValue,omitempty"`
+	BoolValue   *bool           `json:"boolValue,omitempty"`
+	IntValue    *string         `json:"intValue,omitempty"`
+	DoubleValue *float64        `json:"doubleValue,omitempty"`
+	ArrayValue  *otlpArrayValue `json:"arrayValue,omitempty"`
+}
+
+type otlpArrayValue struct {
+	Values []otlpAnyValue `json:"values"`
+}
+
+// otlpTraces groups spans by resource and instrumentation scope
+func otlpTraces(spans []sdktrace.ReadOnlySpan) otlpTracesData {
+	var data otlpTracesData
+	resources := map[*resource.Resource]*otlpResourceSpans{}
+	scopes := map[*resource.Resource]map[instrumentation.Scope]*otlpScopeSpans{}
+
+	for _, s := range spans {
+		res := s.Resource()
+		rs, ok := resources[res]
+		if !ok {
+			rs = &otlpResourceSpans{Resource: otlpResource{Attributes: otlpAttributes(res.Attributes())}, SchemaURL: res.SchemaURL()}
+			resources[res] = rs
+			scopes[res] = map[instrumentation.Scope]*otlpScopeSpans{}
+			data.ResourceSpans = append(data.ResourceSpans, rs)
+		}
+		scope := s.InstrumentationScope()
+		ss, ok := scopes[res][scope]
+		if !ok {
+			ss = &otlpScopeSpans{Scope: otlpScope{Name: scope.Name, Version: scope.Version}, SchemaURL: scope.SchemaURL}
+			scopes[res][scope] = ss
+			rs.ScopeSpans = append(rs.ScopeSpans, ss)
+		}
+		ss.Spans = append(ss.Spans, otlpSpanOf(s))
+	}
+	return data
+}
+
+func otlpSpanOf(s sdktrace.ReadOnlySpan) otlpSpan {
+	sc := s.SpanContext()
+	span := otlpSpan{
+		TraceID:           sc.TraceID().String(),
+		SpanID:            sc.SpanID().String(),
+		TraceState:        sc.TraceState().String(),
+		Name:              s.Name(),
+		Kind:              int(s.SpanKind()),
+		StartTimeUnixNano: strconv.FormatInt(s.StartTime().UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.EndTime().UnixNano(), 10),
+		Attributes:        otlpAttributes(s.Attributes()),
+	}
+	if parent := s.Parent(); parent.HasSpanID() {
+		span.ParentSpanID = parent.SpanID().String()
+	}
+	for _, e := range s.Events() {
+		span.Events = append(span.Events, otlpEvent{
+			TimeUnixNano: strconv.FormatInt(e.Time.UnixNano(), 10),
+			Name:         e.Name,
+			Attributes:   otlpAttributes(e.Attributes),
+		})
+	}
+	for _, l := range s.Links() {
+		span.Links = append(span.Links, otlpLink{
+			TraceID:    l.SpanContext.TraceID().String(),
+			SpanID:     l.SpanContext.SpanID().String(),
+			Attributes: otlpAttributes(l.Attributes),
+		})
+	}
+	switch s.Status().Code {
+	case codes.Ok:
+		span.Status.Code = 1
+	case codes.Error:
+		span.Status = otlpStatus{Code: 2, Message: s.Status().Description}
+	}
+	return span
+}
+
+func otlpAttributes(attrs []attribute.KeyValue) []otlpKeyValue {
+	res := make([]otlpKeyValue, 0, len(attrs))
+	for _, kv := range attrs {
+		res = append(res, otlpKeyValue{Key: string(kv.Key), Value: otlpValue(kv.Value)})
+	}
+	return res
+}
+
+func otlpValue(v attribute.Value) otlpAnyValue {
+	var res otlpAnyValue
+	switch v.Type() {
+	case attribute.BOOL:
+		b := v.AsBool()
+		res.BoolValue = &b
+	case attribute.INT64:
+		i := strconv.FormatInt(v.AsInt64(), 10)
+		res.IntValue = &i
+	case attribute.FLOAT64:
+		f := v.AsFloat64()
+		res.DoubleValue = &f
+	case attribute.BOOLSLICE, attribute.INT64SLICE, attribute.FLOAT64SLICE, attribute.STRINGSLICE:
+		res.ArrayValue = &otlpArrayValue{}
+		for _, item := range sliceValues(v) {
+			res.ArrayValue.Values = append(res.ArrayValue.Values, otlpValue(item))
+		}
+	default:
+		s := v.Emit()
+		res.StringValue = &s
+	}
+	return res
+}
+
+func sliceValues(v attribute.Value) []attribute.Value {
+	var res []attribute.Value
+	switch v.Type() {
+	case attribute.BOOLSLICE:
+		for _, b := range v.AsBoolSlice() {
+			res = append(res, attribute.BoolValue(b))
+		}
+	case attribute.INT64SLICE:
+		for _, i := range v.AsInt64Slice() {
+			res = append(res, attribute.Int64Value(i))
+		}
+	case attribute.FLOAT64SLICE:
+		for _, f := range v.AsFloat64Slice() {
+			res = append(res, attribute.Float64Value(f))
+		}
+	case attribute.STRINGSLICE:
+		for _, s := range v.AsStringSlice() {
+			res = append(res, attribute.StringValue(s))
+		}
+	}
+	return res
+}
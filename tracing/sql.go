@@ -0,0 +1,46 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// dbSystems maps the gorm dialects to the db.system of the semantic conventions
+var dbSystems = map[string]attribute.KeyValue{
+	"postgres": semconv.DBSystemPostgreSQL,
+	"mysql":    semconv.DBSystemMySQL,
+	"sqlite3":  semconv.DBSystemSqlite,
+}
+
+// SQLStatement starts the span of a statement of dialect and returns the
+// function ending it. It is the models.StatementHook tracing the SQL.
+// Arguments are left out of the span, as they may hold personal data.
+func SQLStatement(ctx context.Context, dialect, query string) (context.Context, func(sql.Result, error)) {
+	operation := query
+	if i := strings.IndexAny(query, " \n\t"); i > 0 {
+		operation = query[:i]
+	}
+	operation = strings.ToUpper(operation)
+
+	attrs := []attribute.KeyValue{semconv.DBQueryText(query), semconv.DBOperationName(operation)}
+	if system, ok := dbSystems[dialect]; ok {
+		attrs = append(attrs, system)
+	}
+	ctx, span := Tracer().Start(ctx, operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	return ctx, func(res sql.Result, err error) {
+		if res != nil && err == nil {
+			if affected, affectedErr := res.RowsAffected(); affectedErr == nil {
+				span.SetAttributes(attribute.Int64("db.rows_affected", affected))
+			}
+		}
+		if err == sql.ErrNoRows {
+			err = nil
+		}
+		End(span, err)
+	}
+}
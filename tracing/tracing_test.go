@@ -0,0 +1,93 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/naveenpatilm/go-clean-arch/tracing"
+)
+
+func TestOTLPFileExporter(t *testing.T) {
+	var buf bytes.Buffer
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(tracing.NewOTLPFileExporter(&buf)))
+	tracer := provider.Tracer("test")
+
+	ctx, parent := tracer.Start(context.TODO(), "GET /articles", trace.WithSpanKind(trace.SpanKindServer))
+	_, child := tracer.Start(ctx, "SELECT", trace.WithAttributes(
+		attribute.String("db.query.text", "SELECT * FROM articles"),
+		attribute.Int64("db.rows_affected", 3),
+		attribute.Int64Slice("author.ids", []int64{1, 2}),
+	))
+	child.SetStatus(codes.Error, "connection reset")
+	child.End()
+	parent.End()
+	require.NoError(t, provider.Shutdown(context.TODO()))
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2, "one line per exported batch")
+
+	var data struct {
+		ResourceSpans []struct {
+			ScopeSpans []struct {
+				Scope struct {
+					Name string `json:"name"`
+				} `json:"scope"`
+				Spans []map[string]interface{} `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	require.NoError(t, json.Unmarshal(lines[0], &data))
+	span := data.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	assert.Equal(t, "test", data.ResourceSpans[0].ScopeSpans[0].Scope.Name)
+	assert.Equal(t, "SELECT", span["name"])
+	assert.Equal(t, parent.SpanContext().TraceID().String(), span["traceId"])
+	assert.Equal(t, parent.SpanContext().SpanID().String(), span["parentSpanId"])
+	assert.Equal(t, float64(1), span["kind"])
+	assert.Equal(t, map[string]interface{}{"code": float64(2), "message": "connection reset"}, span["status"])
+	assert.IsType(t, "", span["startTimeUnixNano"])
+	assert.Contains(t, span["attributes"], map[string]interface{}{
+		"key": "db.rows_affected", "value": map[string]interface{}{"intValue": "3"},
+	})
+	assert.Contains(t, span["attributes"], map[string]interface{}{
+		"key": "author.ids", "value": map[string]interface{}{"arrayValue": map[string]interface{}{"values": []interface{}{
+			map[string]interface{}{"intValue": "1"}, map[string]interface{}{"intValue": "2"},
+		}}},
+	})
+}
+
+func TestSetup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tracing")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "traces.jsonl")
+	previous := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(previous)
+
+	shutdown, err := tracing.Setup(tracing.Config{Exporter: tracing.ExporterOTLPFile, Path: path, ServiceName: "articles", SampleRatio: 1})
+	require.NoError(t, err)
+	_, span := tracing.Tracer().Start(context.TODO(), "work")
+	tracing.End(span, errors.New("failed"))
+	require.NoError(t, shutdown(context.TODO()))
+
+	content, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"name":"work"`)
+	assert.Contains(t, string(content), `{"key":"service.name","value":{"stringValue":"articles"}}`)
+
+	_, err = tracing.Setup(tracing.Config{Exporter: "zipkin"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/naveenpatilm/go-clean-arch/article"
+	"github.com/naveenpatilm/go-clean-arch/author"
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/outbox"
+	"github.com/naveenpatilm/go-clean-arch/transaction"
+)
+
+type gormTransactionManager struct {
+	DB            models.Gormw
+	articleRepoFn func(models.Gormw) article.Repository
+	authorRepoFn  func(models.Gormw) author.Repository
+	outboxRepoFn  func(models.Gormw) outbox.Repository
+}
+
+// NewGormTransactionManager will create an implementation of transaction.Manager.
+// The given constructors are used to build the repositories bound to each transaction.
+func NewGormTransactionManager(db models.Gormw, articleRepoFn func(models.Gormw) article.Repository, authorRepoFn func(models.Gormw) author.Repository, outboxRepoFn func(models.Gormw) outbox.Repository) transaction.Manager {
+
+	return &gormTransactionManager{
+		DB:            db,
+		articleRepoFn: articleRepoFn,
+		authorRepoFn:  authorRepoFn,
+		outboxRepoFn:  outboxRepoFn,
+	}
+}
+
+func (m *gormTransactionManager) Do(ctx context.Context, fn func(ctx context.Context, repos transaction.Repositories) error) error {
+	tx := m.DB.BeginTx(ctx, nil)
+	if err := tx.Error(); err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			rollback(tx)
+			panic(p)
+		}
+	}()
+
+	repos := transaction.Repositories{
+		Article: m.articleRepoFn(tx),
+		Author:  m.authorRepoFn(tx),
+		Outbox:  m.outboxRepoFn(tx),
+	}
+	if err := fn(ctx, repos); err != nil {
+		rollback(tx)
+		return err
+	}
+
+	return tx.Commit().Error()
+}
+
+func rollback(tx models.Gormw) {
+	if err := tx.Rollback().Error(); err != nil {
+		logrus.Error(err)
+	}
+}
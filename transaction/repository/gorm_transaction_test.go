@@ -0,0 +1,107 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	_ "github.com/jinzhu/gorm/dialects/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/article"
+	_articleMock "github.com/naveenpatilm/go-clean-arch/article/mocks"
+	"github.com/naveenpatilm/go-clean-arch/author"
+	_authorMock "github.com/naveenpatilm/go-clean-arch/author/mocks"
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/outbox"
+	_outboxMock "github.com/naveenpatilm/go-clean-arch/outbox/mocks"
+	"github.com/naveenpatilm/go-clean-arch/transaction"
+	_transactionRepo "github.com/naveenpatilm/go-clean-arch/transaction/repository"
+)
+
+func newManager(t *testing.T) (transaction.Manager, sqlmock.Sqlmock, *[]models.Gormw) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	gormDB, err := models.Openw("postgres", db)
+	require.NoError(t, err)
+
+	var bound []models.Gormw
+	tm := _transactionRepo.NewGormTransactionManager(gormDB,
+		func(tx models.Gormw) article.Repository {
+			bound = append(bound, tx)
+			return new(_articleMock.Repository)
+		},
+		func(tx models.Gormw) author.Repository {
+			bound = append(bound, tx)
+			return new(_authorMock.Repository)
+		},
+		func(tx models.Gormw) outbox.Repository {
+			bound = append(bound, tx)
+			return new(_outboxMock.Repository)
+		},
+	)
+	return tm, mock, &bound
+}
+
+func TestDoCommit(t *testing.T) {
+	tm, mock, bound := newManager(t)
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	err := tm.Do(context.TODO(), func(ctx context.Context, repos transaction.Repositories) error {
+		assert.NotNil(t, repos.Article)
+		assert.NotNil(t, repos.Author)
+		assert.NotNil(t, repos.Outbox)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	require.Len(t, *bound, 3)
+	assert.Equal(t, (*bound)[0], (*bound)[1])
+	assert.Equal(t, (*bound)[0], (*bound)[2])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDoRollbackOnError(t *testing.T) {
+	tm, mock, _ := newManager(t)
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	expected := errors.New("Unexpected Error")
+	err := tm.Do(context.TODO(), func(ctx context.Context, repos transaction.Repositories) error {
+		return expected
+	})
+
+	assert.Equal(t, expected, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDoRollbackOnPanic(t *testing.T) {
+	tm, mock, _ := newManager(t)
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	assert.PanicsWithValue(t, "boom", func() {
+		tm.Do(context.TODO(), func(ctx context.Context, repos transaction.Repositories) error {
+			panic("boom")
+		})
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDoBeginError(t *testing.T) {
+	tm, mock, _ := newManager(t)
+	mock.ExpectBegin().WillReturnError(errors.New("connection refused"))
+
+	called := false
+	err := tm.Do(context.TODO(), func(ctx context.Context, repos transaction.Repositories) error {
+		called = true
+		return nil
+	})
+
+	assert.Error(t, err)
+	assert.False(t, called)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/naveenpatilm/go-clean-arch/article"
+	"github.com/naveenpatilm/go-clean-arch/author"
+	"github.com/naveenpatilm/go-clean-arch/outbox"
+	"github.com/naveenpatilm/go-clean-arch/transaction"
+)
+
+type memoryTransactionManager struct {
+	mu          sync.Mutex
+	articleRepo article.Repository
+	authorRepo  author.Repository
+	outboxRepo  outbox.Repository
+}
+
+// NewMemoryTransactionManager will create an implementation of transaction.Manager for the
+// in-memory repositories. Transactions run one at a time and roll back by undoing their own
+// writes, recorded in a transaction.UndoLog; writes made outside a transaction are kept.
+func NewMemoryTransactionManager(articleRepo article.Repository, authorRepo author.Repository, outboxRepo outbox.Repository) transaction.Manager {
+
+	return &memoryTransactionManager{
+		articleRepo: articleRepo,
+		authorRepo:  authorRepo,
+		outboxRepo:  outboxRepo,
+	}
+}
+
+func (m *memoryTransactionManager) Do(ctx context.Context, fn func(ctx context.Context, repos transaction.Repositories) error) (err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	undo := &transaction.UndoLog{}
+	ctx = transaction.WithUndoLog(ctx, undo)
+
+	defer func() {
+		if p := recover(); p != nil {
+			undo.Undo()
+			panic(p)
+		}
+	}()
+
+	err = fn(ctx, transaction.Repositories{
+		Article: m.articleRepo,
+		Author:  m.authorRepo,
+		Outbox:  m.outboxRepo,
+	})
+	if err != nil {
+		undo.Undo()
+	}
+	return err
+}
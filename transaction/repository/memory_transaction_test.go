@@ -0,0 +1,99 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_articleRepo "github.com/naveenpatilm/go-clean-arch/article/repository"
+	_authorRepo "github.com/naveenpatilm/go-clean-arch/author/repository"
+	"github.com/naveenpatilm/go-clean-arch/models"
+	_outboxRepo "github.com/naveenpatilm/go-clean-arch/outbox/repository"
+	"github.com/naveenpatilm/go-clean-arch/transaction"
+	_transactionRepo "github.com/naveenpatilm/go-clean-arch/transaction/repository"
+)
+
+func TestMemoryDoCommit(t *testing.T) {
+	ar := _articleRepo.NewMemoryArticleRepository()
+	au := _authorRepo.NewMemoryAuthorRepository()
+	tm := _transactionRepo.NewMemoryTransactionManager(ar, au, _outboxRepo.NewMemoryOutboxRepository())
+
+	author := &models.Author{Name: "Iman Tumorang"}
+	err := tm.Do(context.TODO(), func(ctx context.Context, repos transaction.Repositories) error {
+		if err := repos.Author.Store(ctx, author); err != nil {
+			return err
+		}
+		return repos.Article.Store(ctx, &models.Article{Title: "Hello", Content: "Content", Author: *author})
+	})
+
+	require.NoError(t, err)
+	_, err = au.GetByID(context.TODO(), author.ID)
+	assert.NoError(t, err)
+	_, err = ar.GetByTitle(context.TODO(), "Hello")
+	assert.NoError(t, err)
+}
+
+func TestMemoryDoRollback(t *testing.T) {
+	ar := _articleRepo.NewMemoryArticleRepository()
+	au := _authorRepo.NewMemoryAuthorRepository()
+	ob := _outboxRepo.NewMemoryOutboxRepository()
+	tm := _transactionRepo.NewMemoryTransactionManager(ar, au, ob)
+	require.NoError(t, ar.Store(context.TODO(), &models.Article{Title: "Hello", Content: "Content"}))
+
+	author := &models.Author{Name: "Iman Tumorang"}
+	err := tm.Do(context.TODO(), func(ctx context.Context, repos transaction.Repositories) error {
+		if err := repos.Author.Store(ctx, author); err != nil {
+			return err
+		}
+		if err := repos.Outbox.Store(ctx, models.ArticleCreated{Article: models.Article{ID: 2}}); err != nil {
+			return err
+		}
+		return repos.Article.Store(ctx, &models.Article{Title: "hello", Content: "Content", Author: *author})
+	})
+
+	assert.Equal(t, models.ErrConflict, err)
+	_, err = au.GetByID(context.TODO(), author.ID)
+	assert.Equal(t, models.ErrNotFound, err)
+	messages, err := ob.Claim(context.TODO(), 10, "test", time.Minute)
+	assert.NoError(t, err)
+	assert.Empty(t, messages, "the outbox message was not rolled back")
+
+	assert.Panics(t, func() {
+		tm.Do(context.TODO(), func(ctx context.Context, repos transaction.Repositories) error {
+			repos.Author.Store(ctx, &models.Author{Name: "Other"})
+			panic("boom")
+		})
+	})
+	_, err = au.GetByID(context.TODO(), 1)
+	assert.Equal(t, models.ErrNotFound, err)
+}
+
+func TestMemoryRollbackKeepsWritesOutsideTheTransaction(t *testing.T) {
+	ar := _articleRepo.NewMemoryArticleRepository()
+	tm := _transactionRepo.NewMemoryTransactionManager(ar, _authorRepo.NewMemoryAuthorRepository(), _outboxRepo.NewMemoryOutboxRepository())
+	existing := &models.Article{Title: "Hello", Content: "Content"}
+	require.NoError(t, ar.Store(context.TODO(), existing))
+
+	err := tm.Do(context.TODO(), func(ctx context.Context, repos transaction.Repositories) error {
+		updated := *existing
+		updated.Title = "Updated"
+		require.NoError(t, repos.Article.Update(ctx, &updated))
+		require.NoError(t, repos.Article.Store(ctx, &models.Article{Title: "Inside", Content: "Content"}))
+		// e.g. seed, writing while the transaction runs
+		require.NoError(t, ar.Store(context.TODO(), &models.Article{Title: "Outside", Content: "Content"}))
+		return errors.New("rolled back")
+	})
+
+	require.Error(t, err)
+	_, err = ar.GetByTitle(context.TODO(), "Outside")
+	assert.NoError(t, err)
+	_, err = ar.GetByTitle(context.TODO(), "Inside")
+	assert.Equal(t, models.ErrNotFound, err)
+	got, err := ar.GetByID(context.TODO(), existing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", got.Title)
+}
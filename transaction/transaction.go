@@ -0,0 +1,24 @@
+package transaction
+
+import (
+	"context"
+
+	"github.com/naveenpatilm/go-clean-arch/article"
+	"github.com/naveenpatilm/go-clean-arch/author"
+	"github.com/naveenpatilm/go-clean-arch/outbox"
+)
+
+// Repositories represent the repositories bound to a single transaction
+type Repositories struct {
+	Article article.Repository
+	Author  author.Repository
+	Outbox  outbox.Repository
+}
+
+// Manager represent the unit of work contract used by the usecases
+type Manager interface {
+	// Do runs fn with repositories bound to one transaction. The transaction
+	// is committed when fn returns nil and rolled back when fn returns an
+	// error or panics.
+	Do(ctx context.Context, fn func(ctx context.Context, repos Repositories) error) error
+}
@@ -0,0 +1,44 @@
+package transaction
+
+import (
+	"context"
+	"sync"
+)
+
+// UndoLog records how to undo the writes made in a transaction by the
+// in-memory repositories, which have no transaction of their own
+type UndoLog struct {
+	mu    sync.Mutex
+	undos []func()
+}
+
+type undoLogKey struct{}
+
+// WithUndoLog returns a copy of ctx in which the in-memory repositories record
+// the undo of their writes in log
+func WithUndoLog(ctx context.Context, log *UndoLog) context.Context {
+	return context.WithValue(ctx, undoLogKey{}, log)
+}
+
+// RecordUndo records undo in the undo log of ctx. Writes made with a context
+// without undo log are not part of a transaction and are never undone.
+func RecordUndo(ctx context.Context, undo func()) {
+	log, ok := ctx.Value(undoLogKey{}).(*UndoLog)
+	if !ok {
+		return
+	}
+	log.mu.Lock()
+	log.undos = append(log.undos, undo)
+	log.mu.Unlock()
+}
+
+// Undo undoes the recorded writes, the latest first
+func (l *UndoLog) Undo() {
+	l.mu.Lock()
+	undos := l.undos
+	l.undos = nil
+	l.mu.Unlock()
+	for i := len(undos) - 1; i >= 0; i-- {
+		undos[i]()
+	}
+}
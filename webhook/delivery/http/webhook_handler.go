@@ -0,0 +1,244 @@
+package http
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	validator "gopkg.in/go-playground/validator.v9"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/webhook"
+)
+
+// ResponseError represent the reseponse error struct
+type ResponseError struct {
+	Message string `json:"message"`
+}
+
+// HttpWebhookHandler represent the httphandler for webhook
+type HttpWebhookHandler struct {
+	WUsecase webhook.Usecase
+}
+
+// NewWebhookHttpHandler registers the webhook API on r. When token is set,
+// its requests need an "Authorization: Bearer <token>" header.
+func NewWebhookHttpHandler(r *mux.Router, us webhook.Usecase, token string) {
+	handler := &HttpWebhookHandler{
+		WUsecase: us,
+	}
+	s := r.PathPrefix("/webhooks").Subrouter()
+	if token != "" {
+		s.Use(authorize(token))
+	}
+	s.HandleFunc("", handler.Fetch).Methods("GET")
+	s.HandleFunc("", handler.Store).Methods("POST")
+	s.HandleFunc("/{id}", handler.GetByID).Methods("GET")
+	s.HandleFunc("/{id}", handler.Update).Methods("PUT")
+	s.HandleFunc("/{id}", handler.Delete).Methods("DELETE")
+	s.HandleFunc("/{id}/deliveries", handler.Deliveries).Methods("GET")
+	s.HandleFunc("/{id}/deliveries/{delivery_id}", handler.GetDelivery).Methods("GET")
+	s.HandleFunc("/{id}/deliveries/{delivery_id}/redeliver", handler.Redeliver).Methods("POST")
+}
+
+func authorize(token string) mux.MiddlewareFunc {
+	expected := []byte("Bearer " + token)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="webhooks"`)
+				writeJSON(w, http.StatusUnauthorized, ResponseError{Message: "a valid webhooks token is required"})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (h *HttpWebhookHandler) Fetch(w http.ResponseWriter, req *http.Request) {
+	webhooks, err := h.WUsecase.Fetch(req.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, webhooks)
+}
+
+func (h *HttpWebhookHandler) GetByID(w http.ResponseWriter, req *http.Request) {
+	id, ok := pathID(w, req, "id")
+	if !ok {
+		return
+	}
+	hook, err := h.WUsecase.GetByID(req.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, hook)
+}
+
+// Store answers the created webhook with its secret, which is never returned again
+func (h *HttpWebhookHandler) Store(w http.ResponseWriter, req *http.Request) {
+	hook, ok := decode(w, req)
+	if !ok {
+		return
+	}
+	if err := h.WUsecase.Store(req.Context(), hook); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, hook)
+}
+
+// Update replaces the URL, events and pause of a webhook. Its secret is kept unless one is given.
+func (h *HttpWebhookHandler) Update(w http.ResponseWriter, req *http.Request) {
+	id, ok := pathID(w, req, "id")
+	if !ok {
+		return
+	}
+	hook, ok := decode(w, req)
+	if !ok {
+		return
+	}
+	hook.ID = id
+	if err := h.WUsecase.Update(req.Context(), hook); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, hook)
+}
+
+func (h *HttpWebhookHandler) Delete(w http.ResponseWriter, req *http.Request) {
+	id, ok := pathID(w, req, "id")
+	if !ok {
+		return
+	}
+	if err := h.WUsecase.Delete(req.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Deliveries lists the latest deliveries of a webhook, newest first, up to the num query parameter
+func (h *HttpWebhookHandler) Deliveries(w http.ResponseWriter, req *http.Request) {
+	id, ok := pathID(w, req, "id")
+	if !ok {
+		return
+	}
+	var num int64
+	if param := req.URL.Query().Get("num"); param != "" {
+		n, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			writeError(w, models.ErrBadParamInput)
+			return
+		}
+		num = n
+	}
+	deliveries, err := h.WUsecase.Deliveries(req.Context(), id, num)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, deliveries)
+}
+
+// GetDelivery answers a delivery with the history of its attempts
+func (h *HttpWebhookHandler) GetDelivery(w http.ResponseWriter, req *http.Request) {
+	id, ok := pathID(w, req, "id")
+	if !ok {
+		return
+	}
+	deliveryID, ok := pathID(w, req, "delivery_id")
+	if !ok {
+		return
+	}
+	d, err := h.WUsecase.GetDelivery(req.Context(), id, deliveryID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, d)
+}
+
+// Redeliver schedules a delivery again and answers 202 with the pending delivery
+func (h *HttpWebhookHandler) Redeliver(w http.ResponseWriter, req *http.Request) {
+	id, ok := pathID(w, req, "id")
+	if !ok {
+		return
+	}
+	deliveryID, ok := pathID(w, req, "delivery_id")
+	if !ok {
+		return
+	}
+	d, err := h.WUsecase.Redeliver(req.Context(), id, deliveryID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, d)
+}
+
+// decode reads and validates the webhook of the request body, answering 422 or 400 when it cannot
+func decode(w http.ResponseWriter, req *http.Request) (*models.Webhook, bool) {
+	var hook models.Webhook
+	if err := json.NewDecoder(req.Body).Decode(&hook); err != nil {
+		logrus.Error(err)
+		writeError(w, models.ErrUnprocessableEntity)
+		return nil, false
+	}
+	if err := validator.New().Struct(&hook); err != nil {
+		writeJSON(w, http.StatusBadRequest, ResponseError{Message: err.Error()})
+		return nil, false
+	}
+	return &hook, true
+}
+
+func pathID(w http.ResponseWriter, req *http.Request, name string) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(req)[name], 10, 64)
+	if err != nil {
+		writeError(w, models.ErrBadParamInput)
+		return 0, false
+	}
+	return id, true
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := getStatusCode(err)
+	if status == http.StatusInternalServerError {
+		err = models.ErrInternalServerError
+	}
+	writeJSON(w, status, ResponseError{Message: err.Error()})
+}
+
+// statusClientClosedRequest is the nginx status of a request whose client went away
+const statusClientClosedRequest = 499
+
+func getStatusCode(err error) int {
+	switch err {
+	case models.ErrNotFound:
+		return http.StatusNotFound
+	case models.ErrConflict:
+		return http.StatusConflict
+	case models.ErrBadParamInput:
+		return http.StatusBadRequest
+	case models.ErrTimeout:
+		return http.StatusGatewayTimeout
+	case models.ErrCanceled:
+		return statusClientClosedRequest
+	case models.ErrUnprocessableEntity:
+		return http.StatusUnprocessableEntity
+	default:
+		logrus.Error(err)
+		return http.StatusInternalServerError
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
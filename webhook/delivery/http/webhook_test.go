@@ -0,0 +1,98 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+	webhookHttp "github.com/naveenpatilm/go-clean-arch/webhook/delivery/http"
+	"github.com/naveenpatilm/go-clean-arch/webhook/mocks"
+)
+
+func serve(t *testing.T, us *mocks.Usecase, token, method, path, body string, header http.Header) *httptest.ResponseRecorder {
+	router := mux.NewRouter()
+	webhookHttp.NewWebhookHttpHandler(router, us, token)
+	req, err := http.NewRequest(method, path, strings.NewReader(body))
+	require.NoError(t, err)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestStore(t *testing.T) {
+	mockUCase := new(mocks.Usecase)
+	mockUCase.On("Store", mock.Anything, mock.AnythingOfType("*models.Webhook")).Run(func(args mock.Arguments) {
+		w := args.Get(1).(*models.Webhook)
+		w.ID, w.Secret = 1, "s3cret"
+	}).Return(nil).Once()
+
+	rec := serve(t, mockUCase, "", "POST", "/webhooks", `{"url": "https://example.com/hook", "events": ["article.created"]}`, nil)
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var w models.Webhook
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &w))
+	assert.Equal(t, "s3cret", w.Secret)
+	assert.Equal(t, models.EventNames{"article.created"}, w.Events)
+	mockUCase.AssertExpectations(t)
+}
+
+func TestStoreInvalid(t *testing.T) {
+	mockUCase := new(mocks.Usecase)
+	mockUCase.On("Store", mock.Anything, mock.Anything).Return(models.ErrBadParamInput).Once()
+
+	rec := serve(t, mockUCase, "", "POST", "/webhooks", `{"url": "not a url", "events": ["article.created"]}`, nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = serve(t, mockUCase, "", "POST", "/webhooks", `{"url": "http://example.com", "events": ["nope"]}`, nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{"message": "Given Param is not valid"}`, rec.Body.String())
+	mockUCase.AssertExpectations(t)
+}
+
+func TestToken(t *testing.T) {
+	mockUCase := new(mocks.Usecase)
+	mockUCase.On("Fetch", mock.Anything).Return([]*models.Webhook{}, nil).Once()
+
+	rec := serve(t, mockUCase, "s3cret", "GET", "/webhooks", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	rec = serve(t, mockUCase, "s3cret", "GET", "/webhooks", "", http.Header{"Authorization": {"Bearer s3cret"}})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `[]`, rec.Body.String())
+	mockUCase.AssertExpectations(t)
+}
+
+func TestDeliveries(t *testing.T) {
+	mockUCase := new(mocks.Usecase)
+	mockUCase.On("Deliveries", mock.Anything, int64(1), int64(5)).
+		Return([]*models.WebhookDelivery{{ID: 9, WebhookID: 1, State: models.DeliveryFailed, ResponseCode: 500}}, nil).Once()
+	mockUCase.On("GetDelivery", mock.Anything, int64(1), int64(8)).Return(nil, models.ErrNotFound).Once()
+	mockUCase.On("Redeliver", mock.Anything, int64(1), int64(9)).
+		Return(&models.WebhookDelivery{ID: 9, WebhookID: 1, State: models.DeliveryPending}, nil).Once()
+
+	rec := serve(t, mockUCase, "", "GET", "/webhooks/1/deliveries?num=5", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var deliveries []models.WebhookDelivery
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &deliveries))
+	assert.Equal(t, 500, deliveries[0].ResponseCode)
+
+	rec = serve(t, mockUCase, "", "GET", "/webhooks/1/deliveries/8", "", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serve(t, mockUCase, "", "POST", "/webhooks/1/deliveries/9/redeliver", "", nil)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"state":"pending"`)
+
+	rec = serve(t, mockUCase, "", "GET", "/webhooks/x/deliveries", "", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockUCase.AssertExpectations(t)
+}
@@ -0,0 +1,264 @@
+package dispatcher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/naveenpatilm/go-clean-arch/internal/lease"
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/webhook"
+)
+
+// Config represent how the dispatcher polls, sends and retries
+type Config struct {
+	// PollInterval is the wait between two polls once every due delivery was claimed
+	PollInterval time.Duration
+	// BatchSize is how many deliveries are claimed at once
+	BatchSize int
+	// Concurrency is how many deliveries of a batch are sent at once
+	Concurrency int
+	// Timeout bounds each request
+	Timeout time.Duration
+	// Lease is how long the dispatcher may take to send a batch. Deliveries it
+	// did not send in time are left to the other dispatchers.
+	Lease time.Duration
+	// MinBackoff is the wait before the first retry of a delivery. It doubles
+	// with every failed attempt, up to MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// MaxAttempts is the number of attempts before a delivery fails
+	MaxAttempts int
+	// AllowPrivate lets the requests reach the internal network, see webhook.PublicIP
+	AllowPrivate bool
+	// Polled, when set, is called once a batch was claimed and handled without error
+	Polled func()
+}
+
+// UserAgent is sent with every delivery
+const UserAgent = "go-clean-arch-webhooks/1.0"
+
+type dispatcher struct {
+	repo   webhook.Repository
+	client *http.Client
+	c      Config
+	// id leases the deliveries claimed by this dispatcher
+	id string
+}
+
+// NewDispatcher will create an implementation of webhook.Dispatcher sending the deliveries of repo.
+// Any status but 2xx is a failed attempt.
+func NewDispatcher(repo webhook.Repository, c Config) webhook.Dispatcher {
+	if c.PollInterval <= 0 {
+		c.PollInterval = time.Second
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 50
+	}
+	if c.Concurrency <= 0 {
+		c.Concurrency = 1
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+	if c.Lease <= 0 {
+		c.Lease = 5 * time.Minute
+	}
+	if c.MinBackoff <= 0 {
+		c.MinBackoff = time.Second
+	}
+	if c.MaxBackoff < c.MinBackoff {
+		c.MaxBackoff = c.MinBackoff
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 1
+	}
+	client := &http.Client{Timeout: c.Timeout}
+	if !c.AllowPrivate {
+		client.Transport = publicTransport()
+	}
+	return &dispatcher{repo: repo, client: client, c: c, id: lease.NewID()}
+}
+
+// publicTransport only connects to the public addresses. The address is checked
+// when dialing, as a host may resolve to another address than when the webhook
+// was saved, and the redirects are checked as well.
+func publicTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !webhook.PublicIP(ip) {
+				return fmt.Errorf("%s is not a public address", host)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would be dialed instead of the webhook
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+func (d *dispatcher) RunOnce(ctx context.Context) (int, error) {
+	deliveries, err := d.repo.Claim(ctx, d.c.BatchSize, d.id, d.c.Lease)
+	if err != nil {
+		return 0, err
+	}
+	webhooks := map[int64]*models.Webhook{}
+	for _, delivery := range deliveries {
+		if _, ok := webhooks[delivery.WebhookID]; ok {
+			continue
+		}
+		w, err := d.repo.GetByID(ctx, delivery.WebhookID)
+		if err != nil && err != models.ErrNotFound {
+			for _, delivery := range deliveries {
+				d.finish(delivery)
+			}
+			return len(deliveries), err
+		}
+		webhooks[delivery.WebhookID] = w
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, d.c.Concurrency)
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(delivery *models.WebhookDelivery) {
+			defer func() { <-slots; wg.Done() }()
+			// once the lease expired, another dispatcher may be sending the delivery
+			if ctx.Err() == nil && time.Now().Before(*delivery.LockedUntil) {
+				d.send(ctx, webhooks[delivery.WebhookID], delivery)
+			}
+			d.finish(delivery)
+		}(delivery)
+	}
+	wg.Wait()
+	return len(deliveries), nil
+}
+
+// finish saves the outcome of delivery, or only ends its lease when it was not sent
+func (d *dispatcher) finish(delivery *models.WebhookDelivery) {
+	err := lease.Finish(func(ctx context.Context) error {
+		return d.repo.Finish(ctx, delivery)
+	})
+	if err != nil {
+		if err == models.ErrNotFound {
+			err = fmt.Errorf("the lease of delivery %d expired, another dispatcher claimed it", delivery.ID)
+		}
+		logrus.WithFields(logrus.Fields{
+			"webhook_id":  delivery.WebhookID,
+			"delivery_id": delivery.ID,
+		}).Error("webhook dispatcher: ", err)
+	}
+}
+
+// send makes one attempt of delivery to w and records its outcome on delivery
+func (d *dispatcher) send(ctx context.Context, w *models.Webhook, delivery *models.WebhookDelivery) {
+	delivery.Attempts++
+	start := time.Now()
+	attempt := &models.WebhookAttempt{CreatedAt: start.UTC()}
+	var err error
+	if w == nil {
+		err = fmt.Errorf("webhook %d was deleted", delivery.WebhookID)
+		delivery.Attempts = d.c.MaxAttempts
+	} else {
+		attempt.ResponseCode, err = d.post(ctx, w, delivery)
+		if err != nil && ctx.Err() != nil {
+			// the dispatcher stops: the attempt did not fail, the delivery is sent again at once
+			delivery.Attempts--
+			return
+		}
+	}
+	attempt.DurationMs = int64(time.Since(start) / time.Millisecond)
+	delivery.History = append(delivery.History, attempt)
+	delivery.ResponseCode = attempt.ResponseCode
+
+	now := time.Now().UTC()
+	if err == nil {
+		delivery.State, delivery.DeliveredAt, delivery.LastError = models.DeliverySucceeded, &now, ""
+		return
+	}
+	attempt.Error, delivery.LastError = err.Error(), err.Error()
+	fields := logrus.Fields{
+		"webhook_id":  delivery.WebhookID,
+		"delivery_id": delivery.ID,
+		"attempts":    delivery.Attempts,
+	}
+	if delivery.Attempts >= d.c.MaxAttempts {
+		delivery.State = models.DeliveryFailed
+		logrus.WithFields(fields).Error("webhook delivery failed: ", err)
+		return
+	}
+	delivery.NextAttemptAt = now.Add(lease.Backoff(delivery.Attempts, d.c.MinBackoff, d.c.MaxBackoff))
+	logrus.WithFields(fields).WithField("retry_at", delivery.NextAttemptAt).Warn("webhook delivery attempt failed: ", err)
+}
+
+// post sends the signed payload of delivery to w and returns the response status
+func (d *dispatcher) post(ctx context.Context, w *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req = req.WithContext(ctx)
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set(webhook.HeaderWebhook, strconv.FormatInt(w.ID, 10))
+	req.Header.Set(webhook.HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(webhook.HeaderEvent, delivery.EventName)
+	req.Header.Set(webhook.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(webhook.HeaderSignature, webhook.Sign(w.Secret, timestamp, body))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	// drain the body so the connection is reused
+	io.Copy(ioutil.Discard, io.LimitReader(res.Body, 64<<10))
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("%s answered %s", w.URL, res.Status)
+	}
+	return res.StatusCode, nil
+}
+
+func (d *dispatcher) Run(ctx context.Context) error {
+	for {
+		claimed, err := d.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			logrus.Error("webhook dispatcher: ", err)
+		}
+		if err == nil && d.c.Polled != nil {
+			d.c.Polled()
+		}
+		// a full batch means more deliveries are likely due
+		wait := d.c.PollInterval
+		if err == nil && claimed == d.c.BatchSize {
+			wait = 0
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(wait):
+		}
+	}
+}
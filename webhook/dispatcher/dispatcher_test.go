@@ -0,0 +1,229 @@
+package dispatcher_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+	_outboxRepo "github.com/naveenpatilm/go-clean-arch/outbox/repository"
+	"github.com/naveenpatilm/go-clean-arch/webhook"
+	"github.com/naveenpatilm/go-clean-arch/webhook/dispatcher"
+	_webhookRepo "github.com/naveenpatilm/go-clean-arch/webhook/repository"
+	_webhookUcase "github.com/naveenpatilm/go-clean-arch/webhook/usecase"
+)
+
+// receiver is a webhook endpoint answering the given statuses in turn, then 204
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	bodies   []webhook.Body
+	verified []bool
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	body, _ := ioutil.ReadAll(req.Body)
+	timestamp, _ := strconv.ParseInt(req.Header.Get(webhook.HeaderTimestamp), 10, 64)
+	r.verified = append(r.verified, webhook.Verify("s3cret", timestamp, body, req.Header.Get(webhook.HeaderSignature)))
+	var b webhook.Body
+	json.Unmarshal(body, &b)
+	r.bodies = append(r.bodies, b)
+	status := http.StatusNoContent
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func setup(t *testing.T, r *receiver, events ...models.Event) (webhook.Repository, *models.Webhook, func()) {
+	srv := httptest.NewServer(r)
+	repo := _webhookRepo.NewMemoryWebhookRepository()
+	w := &models.Webhook{URL: srv.URL, Events: models.EventNames{models.ArticleCreatedEvent, models.ArticleUpdatedEvent}, Secret: "s3cret"}
+	require.NoError(t, repo.Store(context.TODO(), w))
+	outboxRepo := _webhookRepo.NewEnqueuingOutboxRepository(_outboxRepo.NewMemoryOutboxRepository(), repo)
+	for _, e := range events {
+		require.NoError(t, outboxRepo.Store(context.TODO(), e))
+	}
+	return repo, w, srv.Close
+}
+
+func created(id int64) models.Event {
+	return models.ArticleCreated{Article: models.Article{ID: id, Title: "Hello"}, OccurredAt: time.Now()}
+}
+
+// runUntil runs the dispatcher until done or a second passed
+func runUntil(t *testing.T, d webhook.Dispatcher, done func() bool) {
+	deadline := time.Now().Add(time.Second)
+	for !done() {
+		require.True(t, time.Now().Before(deadline), "timed out")
+		_, err := d.RunOnce(context.TODO())
+		require.NoError(t, err)
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRetriesWithBackoff(t *testing.T) {
+	r := &receiver{statuses: []int{http.StatusInternalServerError, http.StatusBadGateway}}
+	repo, w, cleanup := setup(t, r, created(1))
+	defer cleanup()
+	d := dispatcher.NewDispatcher(repo, dispatcher.Config{MinBackoff: 20 * time.Millisecond, MaxBackoff: time.Second, MaxAttempts: 5, AllowPrivate: true})
+
+	claimed, err := d.RunOnce(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, 1, claimed)
+	claimed, err = d.RunOnce(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, 0, claimed, "the delivery waits for its backoff")
+
+	deliveries, err := repo.Deliveries(context.TODO(), w.ID, 10)
+	require.NoError(t, err)
+	runUntil(t, d, func() bool {
+		delivery, err := repo.GetDelivery(context.TODO(), deliveries[0].ID)
+		require.NoError(t, err)
+		return delivery.State == models.DeliverySucceeded
+	})
+
+	delivery, err := repo.GetDelivery(context.TODO(), deliveries[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Equal(t, http.StatusNoContent, delivery.ResponseCode)
+	assert.NotNil(t, delivery.DeliveredAt)
+	require.Len(t, delivery.History, 3)
+	assert.Equal(t, http.StatusInternalServerError, delivery.History[0].ResponseCode)
+	assert.Equal(t, http.StatusBadGateway, delivery.History[1].ResponseCode)
+	assert.Empty(t, delivery.History[2].Error)
+	// the second retry waited twice as long as the first one
+	assert.True(t, delivery.History[2].CreatedAt.Sub(delivery.History[1].CreatedAt) >= 40*time.Millisecond)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	assert.Equal(t, []bool{true, true, true}, r.verified)
+	assert.Equal(t, models.ArticleCreatedEvent, r.bodies[2].Event)
+}
+
+func TestFailsAfterMaxAttemptsAndRedelivers(t *testing.T) {
+	r := &receiver{statuses: []int{http.StatusGone, http.StatusGone}}
+	repo, w, cleanup := setup(t, r, created(1), created(2))
+	defer cleanup()
+	d := dispatcher.NewDispatcher(repo, dispatcher.Config{MinBackoff: time.Millisecond, MaxAttempts: 1, Concurrency: 2, AllowPrivate: true})
+
+	_, err := d.RunOnce(context.TODO())
+	require.NoError(t, err)
+	deliveries, err := repo.Deliveries(context.TODO(), w.ID, 10)
+	require.NoError(t, err)
+	for _, delivery := range deliveries {
+		assert.Equal(t, models.DeliveryFailed, delivery.State)
+		assert.Equal(t, http.StatusGone, delivery.ResponseCode)
+	}
+
+	u := _webhookUcase.NewWebhookUsecase(repo, nil, true, time.Second)
+	_, err = u.Redeliver(context.TODO(), w.ID, deliveries[0].ID)
+	require.NoError(t, err)
+	claimed, err := d.RunOnce(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, 1, claimed)
+
+	delivery, err := u.GetDelivery(context.TODO(), w.ID, deliveries[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DeliverySucceeded, delivery.State)
+	assert.Len(t, delivery.History, 2)
+}
+
+func TestDeletedWebhookFails(t *testing.T) {
+	r := &receiver{}
+	repo, w, cleanup := setup(t, r, created(1))
+	defer cleanup()
+	deliveries, err := repo.Deliveries(context.TODO(), w.ID, 10)
+	require.NoError(t, err)
+	// a webhook deleted while its delivery is claimed
+	repo = &deletingRepo{Repository: repo}
+	d := dispatcher.NewDispatcher(repo, dispatcher.Config{MaxAttempts: 5})
+
+	_, err = d.RunOnce(context.TODO())
+	require.NoError(t, err)
+
+	assert.Equal(t, models.DeliveryFailed, repo.(*deletingRepo).saved[deliveries[0].ID].State)
+	assert.Empty(t, r.bodies)
+}
+
+type deletingRepo struct {
+	webhook.Repository
+	saved map[int64]models.WebhookDelivery
+}
+
+func (r *deletingRepo) GetByID(ctx context.Context, id int64) (*models.Webhook, error) {
+	return nil, models.ErrNotFound
+}
+
+func (r *deletingRepo) Finish(ctx context.Context, d *models.WebhookDelivery) error {
+	if r.saved == nil {
+		r.saved = map[int64]models.WebhookDelivery{}
+	}
+	r.saved[d.ID] = *d
+	return r.Repository.Finish(ctx, d)
+}
+
+func TestStoppedDispatcherReleasesItsDeliveries(t *testing.T) {
+	sending, stopped := make(chan struct{}, 2), make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ioutil.ReadAll(req.Body)
+		sending <- struct{}{}
+		select {
+		case <-req.Context().Done():
+		case <-stopped:
+		}
+	}))
+	defer srv.Close()
+	defer close(stopped)
+	repo, w, cleanup := setup(t, &receiver{}, created(1), created(2))
+	defer cleanup()
+	w.URL = srv.URL
+	require.NoError(t, repo.Update(context.TODO(), w))
+	d := dispatcher.NewDispatcher(repo, dispatcher.Config{Concurrency: 2, MaxAttempts: 5, AllowPrivate: true})
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	go func() {
+		<-sending
+		cancel()
+	}()
+	claimed, err := d.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, claimed)
+
+	deliveries, err := repo.Claim(context.TODO(), 10, "test", time.Minute)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2, "the deliveries are claimed again at once")
+	for _, delivery := range deliveries {
+		assert.Equal(t, 0, delivery.Attempts)
+		res, err := repo.GetDelivery(context.TODO(), delivery.ID)
+		require.NoError(t, err)
+		assert.Empty(t, res.History)
+	}
+}
+
+func TestRefusesInternalAddresses(t *testing.T) {
+	r := &receiver{}
+	repo, w, cleanup := setup(t, r, created(1))
+	defer cleanup()
+	d := dispatcher.NewDispatcher(repo, dispatcher.Config{MaxAttempts: 1})
+
+	_, err := d.RunOnce(context.TODO())
+	require.NoError(t, err)
+
+	deliveries, err := repo.Deliveries(context.TODO(), w.ID, 10)
+	require.NoError(t, err)
+	assert.Equal(t, models.DeliveryFailed, deliveries[0].State)
+	assert.Contains(t, deliveries[0].LastError, "127.0.0.1 is not a public address")
+	assert.Empty(t, r.bodies)
+}
@@ -0,0 +1,35 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+// Repository represent the webhook's repository contract
+type Repository interface {
+	Fetch(ctx context.Context) ([]*models.Webhook, error)
+	GetByID(ctx context.Context, id int64) (*models.Webhook, error)
+	Store(ctx context.Context, w *models.Webhook) error
+	Update(ctx context.Context, w *models.Webhook) error
+	// Delete deletes the webhook with its deliveries
+	Delete(ctx context.Context, id int64) error
+
+	// Enqueue stores a new pending delivery
+	Enqueue(ctx context.Context, d *models.WebhookDelivery) error
+	// Claim leases up to limit pending deliveries that are due to lockedBy for lease
+	// and returns them, oldest first. Only the oldest pending delivery of an article
+	// to a webhook is claimed, and deliveries leased by another dispatcher are
+	// skipped until their lease expires.
+	Claim(ctx context.Context, limit int, lockedBy string, lease time.Duration) ([]*models.WebhookDelivery, error)
+	// Finish saves the outcome of a claimed delivery with the attempts appended to
+	// its History, and ends its lease. It returns models.ErrNotFound when the lease
+	// expired and another dispatcher claimed it.
+	Finish(ctx context.Context, d *models.WebhookDelivery) error
+	// Deliveries returns the num latest deliveries of a webhook, newest first
+	Deliveries(ctx context.Context, webhookID int64, num int64) ([]*models.WebhookDelivery, error)
+	// GetDelivery returns a delivery with its History
+	GetDelivery(ctx context.Context, id int64) (*models.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, d *models.WebhookDelivery) error
+}
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jinzhu/gorm"
+
+	_lease "github.com/naveenpatilm/go-clean-arch/internal/lease"
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/webhook"
+)
+
+// claimQuery selects the due deliveries that are the oldest pending one of their
+// article to their webhook and whose lease, if any, expired
+const claimQuery = `SELECT * FROM webhook_deliveries d
+	WHERE d.state = ? AND d.next_attempt_at <= ?
+	AND (d.locked_until IS NULL OR d.locked_until < ?)
+	AND NOT EXISTS (
+		SELECT 1 FROM webhook_deliveries p
+		WHERE p.webhook_id = d.webhook_id AND p.article_id = d.article_id AND p.state = ? AND p.id < d.id
+	)
+	ORDER BY d.id LIMIT ?`
+
+// gormWebhookRepository is the webhook.Repository shared by the SQL databases
+type gormWebhookRepository struct {
+	DB models.Gormw
+	// lock is appended to claimQuery to lock the claimed rows
+	lock string
+}
+
+// NewPostgresWebhookRepository will create an object that represent the webhook.Repository interface on postgres
+func NewPostgresWebhookRepository(DB models.Gormw) webhook.Repository {
+	return &gormWebhookRepository{DB: DB, lock: " FOR UPDATE SKIP LOCKED"}
+}
+
+// NewMysqlWebhookRepository will create an object that represent the webhook.Repository interface on mysql 8
+func NewMysqlWebhookRepository(DB models.Gormw) webhook.Repository {
+	return &gormWebhookRepository{DB: DB, lock: " FOR UPDATE SKIP LOCKED"}
+}
+
+// NewSqliteWebhookRepository will create an object that represent the webhook.Repository interface on sqlite,
+// whose serialized transactions need no row locks
+func NewSqliteWebhookRepository(DB models.Gormw) webhook.Repository {
+	return &gormWebhookRepository{DB: DB}
+}
+
+func (m *gormWebhookRepository) Fetch(ctx context.Context) ([]*models.Webhook, error) {
+	webhooks := []*models.Webhook{}
+	err := m.DB.WithContext(ctx).Order("id").Find(&webhooks).Error()
+	if err = models.ContextError(ctx, err); err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (m *gormWebhookRepository) GetByID(ctx context.Context, id int64) (*models.Webhook, error) {
+	var w models.Webhook
+	err := models.ContextError(ctx, m.DB.WithContext(ctx).First(&w, id).Error())
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (m *gormWebhookRepository) Store(ctx context.Context, w *models.Webhook) error {
+	return models.ContextError(ctx, m.DB.WithContext(ctx).Create(w).Error())
+}
+
+func (m *gormWebhookRepository) Update(ctx context.Context, w *models.Webhook) error {
+	res := m.DB.WithContext(ctx).Model(w).Updates(map[string]interface{}{
+		"url":    w.URL,
+		"events": w.Events,
+		"secret": w.Secret,
+		"paused": w.Paused,
+	})
+	return models.ContextError(ctx, res.Error())
+}
+
+func (m *gormWebhookRepository) Delete(ctx context.Context, id int64) error {
+	tx := m.DB.BeginTx(ctx, nil)
+	if err := tx.Error(); err != nil {
+		return err
+	}
+	statements := []func() models.Gormw{
+		func() models.Gormw {
+			return tx.WithContext(ctx).Exec(`DELETE FROM webhook_attempts
+				WHERE delivery_id IN (SELECT id FROM webhook_deliveries WHERE webhook_id = ?)`, id)
+		},
+		func() models.Gormw {
+			return tx.WithContext(ctx).Where("webhook_id = ?", id).Delete(models.WebhookDelivery{})
+		},
+		func() models.Gormw {
+			return tx.WithContext(ctx).Where("id = ?", id).Delete(models.Webhook{})
+		},
+	}
+	var res models.Gormw
+	for _, statement := range statements {
+		res = statement()
+		if err := models.ContextError(ctx, res.Error()); err != nil {
+			_lease.Rollback(tx)
+			return err
+		}
+	}
+	if res.RowsAffected() == 0 {
+		_lease.Rollback(tx)
+		return models.ErrNotFound
+	}
+	return tx.Commit().Error()
+}
+
+func (m *gormWebhookRepository) Enqueue(ctx context.Context, d *models.WebhookDelivery) error {
+	return models.ContextError(ctx, m.DB.WithContext(ctx).Create(d).Error())
+}
+
+func (m *gormWebhookRepository) Claim(ctx context.Context, limit int, lockedBy string, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	now := time.Now().UTC()
+	lockedUntil := now.Add(lease)
+	deliveries, err := _lease.Claim(ctx, m.DB, claimQuery+m.lock,
+		[]interface{}{models.DeliveryPending, now, now, models.DeliveryPending, limit},
+		func(d *models.WebhookDelivery) int64 { return d.ID },
+		`UPDATE webhook_deliveries SET locked_by = ?, locked_until = ? WHERE id IN (?)`, lockedBy, lockedUntil)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range deliveries {
+		d.LockedBy, d.LockedUntil = lockedBy, &lockedUntil
+	}
+	return deliveries, nil
+}
+
+func (m *gormWebhookRepository) Finish(ctx context.Context, d *models.WebhookDelivery) error {
+	tx := m.DB.BeginTx(ctx, nil)
+	if err := tx.Error(); err != nil {
+		return err
+	}
+	res := tx.WithContext(ctx).Model(&models.WebhookDelivery{}).
+		Where("id = ? AND state = ? AND locked_by = ?", d.ID, models.DeliveryPending, d.LockedBy).
+		Updates(map[string]interface{}{
+			"state":           d.State,
+			"attempts":        d.Attempts,
+			"next_attempt_at": d.NextAttemptAt,
+			"response_code":   d.ResponseCode,
+			"last_error":      d.LastError,
+			"delivered_at":    d.DeliveredAt,
+			"locked_by":       "",
+			"locked_until":    nil,
+		})
+	if err := models.ContextError(ctx, res.Error()); err != nil {
+		_lease.Rollback(tx)
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		_lease.Rollback(tx)
+		return models.ErrNotFound
+	}
+	if err := m.createAttempts(ctx, tx, d); err != nil {
+		_lease.Rollback(tx)
+		return err
+	}
+	if err := tx.Commit().Error(); err != nil {
+		return err
+	}
+	d.LockedBy, d.LockedUntil = "", nil
+	return nil
+}
+
+// createAttempts creates the attempts of d that are not saved yet
+func (m *gormWebhookRepository) createAttempts(ctx context.Context, db models.Gormw, d *models.WebhookDelivery) error {
+	for _, attempt := range d.History {
+		if attempt.ID != 0 {
+			continue
+		}
+		attempt.DeliveryID = d.ID
+		if err := models.ContextError(ctx, db.WithContext(ctx).Create(attempt).Error()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *gormWebhookRepository) Deliveries(ctx context.Context, webhookID int64, num int64) ([]*models.WebhookDelivery, error) {
+	deliveries := []*models.WebhookDelivery{}
+	err := m.DB.WithContext(ctx).Where("webhook_id = ?", webhookID).Order("id DESC").Limit(int(num)).Find(&deliveries).Error()
+	if err = models.ContextError(ctx, err); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (m *gormWebhookRepository) GetDelivery(ctx context.Context, id int64) (*models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	err := models.ContextError(ctx, m.DB.WithContext(ctx).First(&d, id).Error())
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = m.DB.WithContext(ctx).Where("delivery_id = ?", id).Order("id").Find(&d.History).Error()
+	if err = models.ContextError(ctx, err); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (m *gormWebhookRepository) UpdateDelivery(ctx context.Context, d *models.WebhookDelivery) error {
+	if err := models.ContextError(ctx, m.DB.WithContext(ctx).Save(d).Error()); err != nil {
+		return err
+	}
+	return m.createAttempts(ctx, m.DB, d)
+}
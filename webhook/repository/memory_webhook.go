@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/transaction"
+	"github.com/naveenpatilm/go-clean-arch/webhook"
+)
+
+type memoryWebhookRepository struct {
+	mu         sync.Mutex
+	lastID     int64
+	webhooks   map[int64]models.Webhook
+	deliveries map[int64]models.WebhookDelivery
+	attempts   map[int64][]models.WebhookAttempt
+}
+
+// NewMemoryWebhookRepository will create an object that represent the webhook.Repository interface in memory
+func NewMemoryWebhookRepository() webhook.Repository {
+	return &memoryWebhookRepository{
+		webhooks:   map[int64]models.Webhook{},
+		deliveries: map[int64]models.WebhookDelivery{},
+		attempts:   map[int64][]models.WebhookAttempt{},
+	}
+}
+
+func (m *memoryWebhookRepository) Fetch(ctx context.Context) ([]*models.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	webhooks := make([]*models.Webhook, 0, len(m.webhooks))
+	for _, w := range m.webhooks {
+		w := w
+		webhooks = append(webhooks, &w)
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
+	return webhooks, nil
+}
+
+func (m *memoryWebhookRepository) GetByID(ctx context.Context, id int64) (*models.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w, ok := m.webhooks[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return &w, nil
+}
+
+func (m *memoryWebhookRepository) Store(ctx context.Context, w *models.Webhook) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastID++
+	now := time.Now()
+	w.ID, w.CreatedAt, w.UpdatedAt = m.lastID, now, now
+	m.webhooks[w.ID] = *w
+	return nil
+}
+
+func (m *memoryWebhookRepository) Update(ctx context.Context, w *models.Webhook) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.webhooks[w.ID]; !ok {
+		return models.ErrNotFound
+	}
+	w.UpdatedAt = time.Now()
+	m.webhooks[w.ID] = *w
+	return nil
+}
+
+func (m *memoryWebhookRepository) Delete(ctx context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.webhooks[id]; !ok {
+		return models.ErrNotFound
+	}
+	delete(m.webhooks, id)
+	for deliveryID, d := range m.deliveries {
+		if d.WebhookID == id {
+			delete(m.deliveries, deliveryID)
+			delete(m.attempts, deliveryID)
+		}
+	}
+	return nil
+}
+
+func (m *memoryWebhookRepository) Enqueue(ctx context.Context, d *models.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastID++
+	d.ID = m.lastID
+	if d.CreatedAt.IsZero() {
+		d.CreatedAt = time.Now()
+	}
+	m.deliveries[d.ID] = *d
+	id := d.ID
+	transaction.RecordUndo(ctx, func() {
+		m.mu.Lock()
+		delete(m.deliveries, id)
+		m.mu.Unlock()
+	})
+	return nil
+}
+
+func (m *memoryWebhookRepository) Claim(ctx context.Context, limit int, lockedBy string, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	pending := make([]models.WebhookDelivery, 0, len(m.deliveries))
+	for _, d := range m.deliveries {
+		if d.State == models.DeliveryPending {
+			pending = append(pending, d)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].ID < pending[j].ID })
+
+	type head struct{ webhookID, articleID int64 }
+	now := time.Now().UTC()
+	lockedUntil := now.Add(lease)
+	deliveries := []*models.WebhookDelivery{}
+	seen := map[head]bool{}
+	for i := range pending {
+		d := pending[i]
+		// only the oldest pending delivery of an article to a webhook may be claimed, even when it is leased
+		key := head{d.WebhookID, d.ArticleID}
+		first := !seen[key]
+		seen[key] = true
+		free := d.LockedUntil == nil || d.LockedUntil.Before(now)
+		if first && free && !d.NextAttemptAt.After(now) && len(deliveries) < limit {
+			d.LockedBy, d.LockedUntil = lockedBy, &lockedUntil
+			m.deliveries[d.ID] = d
+			deliveries = append(deliveries, &d)
+		}
+	}
+	return deliveries, nil
+}
+
+func (m *memoryWebhookRepository) Finish(ctx context.Context, d *models.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	current, ok := m.deliveries[d.ID]
+	if !ok || current.State != models.DeliveryPending || current.LockedBy != d.LockedBy {
+		return models.ErrNotFound
+	}
+	d.LockedBy, d.LockedUntil = "", nil
+	m.save(d)
+	return nil
+}
+
+// save stores d and its new attempts, m.mu being held
+func (m *memoryWebhookRepository) save(d *models.WebhookDelivery) {
+	for _, attempt := range d.History {
+		if attempt.ID != 0 {
+			continue
+		}
+		m.lastID++
+		attempt.ID, attempt.DeliveryID = m.lastID, d.ID
+		m.attempts[d.ID] = append(m.attempts[d.ID], *attempt)
+	}
+	stored := *d
+	stored.History = nil
+	m.deliveries[d.ID] = stored
+}
+
+func (m *memoryWebhookRepository) Deliveries(ctx context.Context, webhookID int64, num int64) ([]*models.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	deliveries := []*models.WebhookDelivery{}
+	for _, d := range m.deliveries {
+		if d.WebhookID == webhookID {
+			d := d
+			deliveries = append(deliveries, &d)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID > deliveries[j].ID })
+	if int64(len(deliveries)) > num {
+		deliveries = deliveries[:num]
+	}
+	return deliveries, nil
+}
+
+func (m *memoryWebhookRepository) GetDelivery(ctx context.Context, id int64) (*models.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	d, ok := m.deliveries[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	for _, attempt := range m.attempts[id] {
+		attempt := attempt
+		d.History = append(d.History, &attempt)
+	}
+	return &d, nil
+}
+
+func (m *memoryWebhookRepository) UpdateDelivery(ctx context.Context, d *models.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.deliveries[d.ID]; !ok {
+		return models.ErrNotFound
+	}
+	m.save(d)
+	return nil
+}
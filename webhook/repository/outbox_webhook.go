@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/outbox"
+	"github.com/naveenpatilm/go-clean-arch/webhook"
+)
+
+type enqueuingOutboxRepository struct {
+	outbox.Repository
+	webhooks webhook.Repository
+}
+
+// NewEnqueuingOutboxRepository will create an outbox.Repository that also enqueues a delivery
+// of each stored event to the webhooks subscribed to it. Bound to the same transaction,
+// outboxRepo and webhookRepo commit the deliveries with the article change.
+func NewEnqueuingOutboxRepository(outboxRepo outbox.Repository, webhookRepo webhook.Repository) outbox.Repository {
+	return &enqueuingOutboxRepository{Repository: outboxRepo, webhooks: webhookRepo}
+}
+
+func (m *enqueuingOutboxRepository) Store(ctx context.Context, e models.Event) error {
+	if err := m.Repository.Store(ctx, e); err != nil {
+		return err
+	}
+	webhooks, err := m.webhooks.Fetch(ctx)
+	if err != nil {
+		return err
+	}
+	var body []byte
+	for _, w := range webhooks {
+		if !w.Subscribes(e.EventName()) {
+			continue
+		}
+		if body == nil {
+			if body, err = webhook.NewBody(e); err != nil {
+				return err
+			}
+		}
+		now := time.Now().UTC()
+		err = m.webhooks.Enqueue(ctx, &models.WebhookDelivery{
+			CreatedAt:     now,
+			WebhookID:     w.ID,
+			ArticleID:     e.EventArticle().ID,
+			EventName:     e.EventName(),
+			Payload:       string(body),
+			State:         models.DeliveryPending,
+			NextAttemptAt: now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
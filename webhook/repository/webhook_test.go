@@ -0,0 +1,211 @@
+package repository_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/database"
+	"github.com/naveenpatilm/go-clean-arch/models"
+	_outboxRepo "github.com/naveenpatilm/go-clean-arch/outbox/repository"
+	"github.com/naveenpatilm/go-clean-arch/webhook"
+	_webhookRepo "github.com/naveenpatilm/go-clean-arch/webhook/repository"
+)
+
+func newSqliteRepo(t *testing.T) (webhook.Repository, func()) {
+	dir, err := ioutil.TempDir("", "webhook")
+	require.NoError(t, err)
+	db, err := database.Open(database.Config{Driver: database.Sqlite, Path: filepath.Join(dir, "article.db")})
+	require.NoError(t, err)
+	migrator, err := database.NewMigrator(db.DB(), database.Sqlite)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Up(context.TODO()))
+	return _webhookRepo.NewSqliteWebhookRepository(db), func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// forEachRepo runs the same behaviour test against sqlite and the memory repository
+func forEachRepo(t *testing.T, test func(t *testing.T, repo webhook.Repository)) {
+	sqliteRepo, cleanup := newSqliteRepo(t)
+	defer cleanup()
+	for name, repo := range map[string]webhook.Repository{"sqlite": sqliteRepo, "memory": _webhookRepo.NewMemoryWebhookRepository()} {
+		t.Run(name, func(t *testing.T) {
+			test(t, repo)
+		})
+	}
+}
+
+func newWebhook(t *testing.T, repo webhook.Repository, paused bool, events ...string) *models.Webhook {
+	w := &models.Webhook{URL: "http://localhost/hook", Events: events, Secret: "s3cret", Paused: paused}
+	require.NoError(t, repo.Store(context.TODO(), w))
+	return w
+}
+
+func TestWebhookCRUD(t *testing.T) {
+	forEachRepo(t, func(t *testing.T, repo webhook.Repository) {
+		w := newWebhook(t, repo, false, models.ArticleCreatedEvent, models.ArticleDeletedEvent)
+
+		w.URL, w.Events, w.Paused = "https://example.com/hook", models.EventNames{models.ArticleUpdatedEvent}, true
+		require.NoError(t, repo.Update(context.TODO(), w))
+		res, err := repo.GetByID(context.TODO(), w.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com/hook", res.URL)
+		assert.Equal(t, models.EventNames{models.ArticleUpdatedEvent}, res.Events)
+		assert.True(t, res.Paused)
+		assert.Equal(t, "s3cret", res.Secret)
+
+		list, err := repo.Fetch(context.TODO())
+		require.NoError(t, err)
+		assert.Len(t, list, 1)
+
+		require.NoError(t, repo.Delete(context.TODO(), w.ID))
+		_, err = repo.GetByID(context.TODO(), w.ID)
+		assert.Equal(t, models.ErrNotFound, err)
+		assert.Equal(t, models.ErrNotFound, repo.Delete(context.TODO(), w.ID))
+	})
+}
+
+func TestEnqueuingOutbox(t *testing.T) {
+	forEachRepo(t, func(t *testing.T, repo webhook.Repository) {
+		created := newWebhook(t, repo, false, models.ArticleCreatedEvent)
+		all := newWebhook(t, repo, false, models.ArticleCreatedEvent, models.ArticleUpdatedEvent)
+		paused := newWebhook(t, repo, true, models.ArticleCreatedEvent)
+		outboxRepo := _webhookRepo.NewEnqueuingOutboxRepository(_outboxRepo.NewMemoryOutboxRepository(), repo)
+
+		require.NoError(t, outboxRepo.Store(context.TODO(), models.ArticleCreated{Article: models.Article{ID: 1}}))
+		require.NoError(t, outboxRepo.Store(context.TODO(), models.ArticleUpdated{Article: models.Article{ID: 1}}))
+
+		count := func(w *models.Webhook) int {
+			deliveries, err := repo.Deliveries(context.TODO(), w.ID, 10)
+			require.NoError(t, err)
+			return len(deliveries)
+		}
+		assert.Equal(t, 1, count(created))
+		assert.Equal(t, 2, count(all))
+		assert.Equal(t, 0, count(paused))
+
+		deliveries, err := repo.Deliveries(context.TODO(), all.ID, 1)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, models.ArticleUpdatedEvent, deliveries[0].EventName, "newest first")
+		assert.Equal(t, models.DeliveryPending, deliveries[0].State)
+		assert.Contains(t, deliveries[0].Payload, `"event":"article.updated"`)
+	})
+}
+
+// enqueue stores a pending delivery of an event of article to w
+func enqueue(t *testing.T, repo webhook.Repository, w *models.Webhook, article int64) *models.WebhookDelivery {
+	d := &models.WebhookDelivery{
+		CreatedAt:     time.Now().UTC(),
+		WebhookID:     w.ID,
+		ArticleID:     article,
+		EventName:     models.ArticleUpdatedEvent,
+		Payload:       "{}",
+		State:         models.DeliveryPending,
+		NextAttemptAt: time.Now().UTC(),
+	}
+	require.NoError(t, repo.Enqueue(context.TODO(), d))
+	return d
+}
+
+// claim returns the ids of the claimed deliveries and finishes each of them once mark applied
+func claim(t *testing.T, repo webhook.Repository, mark func(d *models.WebhookDelivery)) []int64 {
+	deliveries, err := repo.Claim(context.TODO(), 10, "dispatcher", time.Minute)
+	require.NoError(t, err)
+	var ids []int64
+	for _, d := range deliveries {
+		ids = append(ids, d.ID)
+		mark(d)
+		require.NoError(t, repo.Finish(context.TODO(), d))
+	}
+	return ids
+}
+
+func TestClaimAndHistory(t *testing.T) {
+	forEachRepo(t, func(t *testing.T, repo webhook.Repository) {
+		w1 := newWebhook(t, repo, false, models.ArticleUpdatedEvent)
+		w2 := newWebhook(t, repo, false, models.ArticleUpdatedEvent)
+		first := enqueue(t, repo, w1, 1)
+		second := enqueue(t, repo, w1, 1)
+		other := enqueue(t, repo, w2, 1)
+
+		// the second delivery of article 1 to w1 waits for the first one
+		ids := claim(t, repo, func(d *models.WebhookDelivery) {
+			d.Attempts = 1
+			d.History = append(d.History, &models.WebhookAttempt{CreatedAt: time.Now().UTC(), ResponseCode: 500, Error: "boom"})
+			if d.ID == first.ID {
+				d.ResponseCode, d.NextAttemptAt = 500, time.Now().Add(time.Hour).UTC()
+				return
+			}
+			d.State, d.ResponseCode = models.DeliverySucceeded, 200
+		})
+		assert.Equal(t, []int64{first.ID, other.ID}, ids)
+		assert.Empty(t, claim(t, repo, func(d *models.WebhookDelivery) {}))
+
+		res, err := repo.GetDelivery(context.TODO(), first.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 500, res.ResponseCode)
+		require.Len(t, res.History, 1)
+		assert.Equal(t, "boom", res.History[0].Error)
+
+		// a failed delivery no longer blocks the next one
+		res.State = models.DeliveryFailed
+		res.History = append(res.History, &models.WebhookAttempt{CreatedAt: time.Now().UTC(), ResponseCode: 502})
+		require.NoError(t, repo.UpdateDelivery(context.TODO(), res))
+		res, err = repo.GetDelivery(context.TODO(), first.ID)
+		require.NoError(t, err)
+		assert.Len(t, res.History, 2)
+		assert.Equal(t, []int64{second.ID}, claim(t, repo, func(d *models.WebhookDelivery) {}))
+
+		require.NoError(t, repo.Delete(context.TODO(), w1.ID))
+		_, err = repo.GetDelivery(context.TODO(), first.ID)
+		assert.Equal(t, models.ErrNotFound, err)
+	})
+}
+
+func TestClaimLeasesTheDeliveries(t *testing.T) {
+	forEachRepo(t, func(t *testing.T, repo webhook.Repository) {
+		w := newWebhook(t, repo, false, models.ArticleUpdatedEvent)
+		first := enqueue(t, repo, w, 1)
+		second := enqueue(t, repo, w, 1)
+
+		leased, err := repo.Claim(context.TODO(), 10, "first", time.Minute)
+		require.NoError(t, err)
+		require.Len(t, leased, 1)
+		assert.Equal(t, "first", leased[0].LockedBy)
+		// neither the leased delivery nor the next one of its article may be claimed
+		assert.Empty(t, claim(t, repo, func(d *models.WebhookDelivery) {}))
+		released := *leased[0]
+		require.NoError(t, repo.Finish(context.TODO(), &released), "releasing keeps the delivery pending")
+
+		expired, err := repo.Claim(context.TODO(), 10, "second", -time.Second)
+		require.NoError(t, err)
+		require.Len(t, expired, 1)
+		// the lease of second expired at once: third takes the delivery over
+		taken, err := repo.Claim(context.TODO(), 10, "third", time.Minute)
+		require.NoError(t, err)
+		require.Len(t, taken, 1)
+		assert.Equal(t, first.ID, taken[0].ID)
+
+		attempt := &models.WebhookAttempt{CreatedAt: time.Now().UTC(), ResponseCode: 200}
+		expired[0].State = models.DeliverySucceeded
+		expired[0].History = append(expired[0].History, attempt)
+		assert.Equal(t, models.ErrNotFound, repo.Finish(context.TODO(), expired[0]))
+		res, err := repo.GetDelivery(context.TODO(), first.ID)
+		require.NoError(t, err)
+		assert.Empty(t, res.History, "the attempt of a lost lease is not saved")
+
+		taken[0].State = models.DeliverySucceeded
+		taken[0].History = append(taken[0].History, &models.WebhookAttempt{CreatedAt: time.Now().UTC(), ResponseCode: 200})
+		require.NoError(t, repo.Finish(context.TODO(), taken[0]))
+		assert.Equal(t, []int64{second.ID}, claim(t, repo, func(d *models.WebhookDelivery) {}))
+	})
+}
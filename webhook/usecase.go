@@ -0,0 +1,22 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+// Usecase represent the webhook's usecases
+type Usecase interface {
+	Fetch(ctx context.Context) ([]*models.Webhook, error)
+	GetByID(ctx context.Context, id int64) (*models.Webhook, error)
+	// Store creates w, with a random secret unless it has one
+	Store(ctx context.Context, w *models.Webhook) error
+	// Update changes the URL, events and pause of a webhook, and its secret when w has one
+	Update(ctx context.Context, w *models.Webhook) error
+	Delete(ctx context.Context, id int64) error
+	Deliveries(ctx context.Context, webhookID int64, num int64) ([]*models.WebhookDelivery, error)
+	GetDelivery(ctx context.Context, webhookID, deliveryID int64) (*models.WebhookDelivery, error)
+	// Redeliver schedules a delivery again, now, with a fresh number of attempts
+	Redeliver(ctx context.Context, webhookID, deliveryID int64) (*models.WebhookDelivery, error)
+}
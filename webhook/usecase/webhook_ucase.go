@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/webhook"
+)
+
+// events are the names a webhook may subscribe to
+var events = map[string]bool{
+	models.ArticleCreatedEvent: true,
+	models.ArticleUpdatedEvent: true,
+	models.ArticleDeletedEvent: true,
+}
+
+const defaultDeliveries = 20
+
+type webhookUsecase struct {
+	repo     webhook.Repository
+	resolver webhook.Resolver
+	// allowPrivate accepts the URLs of the internal network, see webhook.PublicIP
+	allowPrivate   bool
+	contextTimeout time.Duration
+}
+
+// NewWebhookUsecase will create new a webhookUsecase object representation of webhook.Usecase interface.
+// The secrets of the webhooks are only returned by Store. The hosts of the URLs are looked up with
+// resolver and refused when one of their addresses is internal, unless allowPrivate is set.
+func NewWebhookUsecase(repo webhook.Repository, resolver webhook.Resolver, allowPrivate bool, timeout time.Duration) webhook.Usecase {
+	return &webhookUsecase{repo: repo, resolver: resolver, allowPrivate: allowPrivate, contextTimeout: timeout}
+}
+
+func (u *webhookUsecase) Fetch(c context.Context) ([]*models.Webhook, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	webhooks, err := u.repo.Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range webhooks {
+		w.Secret = ""
+	}
+	return webhooks, nil
+}
+
+func (u *webhookUsecase) GetByID(c context.Context, id int64) (*models.Webhook, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	w, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	w.Secret = ""
+	return w, nil
+}
+
+func (u *webhookUsecase) Store(c context.Context, w *models.Webhook) error {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if err := u.validate(ctx, w); err != nil {
+		return err
+	}
+	if w.Secret == "" {
+		secret, err := newSecret()
+		if err != nil {
+			return err
+		}
+		w.Secret = secret
+	}
+	return u.repo.Store(ctx, w)
+}
+
+func (u *webhookUsecase) Update(c context.Context, w *models.Webhook) error {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if err := u.validate(ctx, w); err != nil {
+		return err
+	}
+	existing, err := u.repo.GetByID(ctx, w.ID)
+	if err != nil {
+		return err
+	}
+	secret := w.Secret
+	if secret == "" {
+		secret = existing.Secret
+	}
+	w.CreatedAt, w.Secret = existing.CreatedAt, secret
+	if err = u.repo.Update(ctx, w); err != nil {
+		return err
+	}
+	w.Secret = ""
+	return nil
+}
+
+func (u *webhookUsecase) Delete(c context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+	return u.repo.Delete(ctx, id)
+}
+
+func (u *webhookUsecase) Deliveries(c context.Context, webhookID int64, num int64) ([]*models.WebhookDelivery, error) {
+	if num <= 0 {
+		num = defaultDeliveries
+	}
+
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if _, err := u.repo.GetByID(ctx, webhookID); err != nil {
+		return nil, err
+	}
+	return u.repo.Deliveries(ctx, webhookID, num)
+}
+
+func (u *webhookUsecase) GetDelivery(c context.Context, webhookID, deliveryID int64) (*models.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+	return u.getDelivery(ctx, webhookID, deliveryID)
+}
+
+// getDelivery returns the delivery unless it belongs to another webhook
+func (u *webhookUsecase) getDelivery(ctx context.Context, webhookID, deliveryID int64) (*models.WebhookDelivery, error) {
+	d, err := u.repo.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if d.WebhookID != webhookID {
+		return nil, models.ErrNotFound
+	}
+	return d, nil
+}
+
+func (u *webhookUsecase) Redeliver(c context.Context, webhookID, deliveryID int64) (*models.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	d, err := u.getDelivery(ctx, webhookID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	d.State, d.Attempts, d.NextAttemptAt, d.DeliveredAt = models.DeliveryPending, 0, time.Now().UTC(), nil
+	if err = u.repo.UpdateDelivery(ctx, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// validate checks the URL and the events of w, and removes the duplicated events
+func (u *webhookUsecase) validate(ctx context.Context, w *models.Webhook) error {
+	target, err := url.Parse(w.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Hostname() == "" {
+		return models.ErrBadParamInput
+	}
+	if err = u.checkHost(ctx, target.Hostname()); err != nil {
+		return err
+	}
+	if len(w.Events) == 0 {
+		return models.ErrBadParamInput
+	}
+	seen := map[string]bool{}
+	names := w.Events[:0]
+	for _, name := range w.Events {
+		if !events[name] {
+			return models.ErrBadParamInput
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	w.Events = names
+	return nil
+}
+
+// checkHost refuses host when one of its addresses is internal
+func (u *webhookUsecase) checkHost(ctx context.Context, host string) error {
+	if u.allowPrivate {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if !webhook.PublicIP(ip) {
+			return models.ErrBadParamInput
+		}
+		return nil
+	}
+	addrs, err := u.resolver.LookupIPAddr(ctx, host)
+	if err = models.ContextError(ctx, err); err == models.ErrTimeout || err == models.ErrCanceled {
+		return err
+	}
+	// a host that cannot be looked up cannot be checked
+	if err != nil || len(addrs) == 0 {
+		return models.ErrBadParamInput
+	}
+	for _, addr := range addrs {
+		if !webhook.PublicIP(addr.IP) {
+			return models.ErrBadParamInput
+		}
+	}
+	return nil
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
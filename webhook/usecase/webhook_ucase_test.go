@@ -0,0 +1,139 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/webhook/mocks"
+	ucase "github.com/naveenpatilm/go-clean-arch/webhook/usecase"
+)
+
+// staticResolver looks the hosts up in a map
+type staticResolver map[string][]string
+
+func (r staticResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	ips, ok := r[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	var addrs []net.IPAddr
+	for _, ip := range ips {
+		addrs = append(addrs, net.IPAddr{IP: net.ParseIP(ip)})
+	}
+	return addrs, nil
+}
+
+var resolver = staticResolver{
+	"example.com":          {"93.184.215.14"},
+	"internal.example.com": {"93.184.215.14", "10.0.0.7"},
+}
+
+func TestStore(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockRepo.On("Store", mock.Anything, mock.AnythingOfType("*models.Webhook")).Return(nil).Once()
+	u := ucase.NewWebhookUsecase(mockRepo, resolver, false, time.Second)
+
+	w := &models.Webhook{URL: "https://example.com/hook", Events: models.EventNames{"article.created", "article.created"}}
+	err := u.Store(context.TODO(), w)
+
+	require.NoError(t, err)
+	assert.Len(t, w.Secret, 64)
+	assert.Equal(t, models.EventNames{"article.created"}, w.Events)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestStoreInvalid(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	u := ucase.NewWebhookUsecase(mockRepo, resolver, false, time.Second)
+
+	for _, w := range []*models.Webhook{
+		{URL: "ftp://example.com", Events: models.EventNames{"article.created"}},
+		{URL: "https://", Events: models.EventNames{"article.created"}},
+		{URL: "https://example.com/hook", Events: models.EventNames{"author.created"}},
+		{URL: "https://example.com/hook"},
+	} {
+		assert.Equal(t, models.ErrBadParamInput, u.Store(context.TODO(), w), w.URL)
+	}
+	mockRepo.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+}
+
+func TestStoreRefusesInternalTargets(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	u := ucase.NewWebhookUsecase(mockRepo, resolver, false, time.Second)
+
+	for _, url := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://[::1]/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"https://10.1.2.3/hook",
+		"https://100.64.0.1/hook",
+		"https://internal.example.com/hook",
+		"https://unknown.example.com/hook",
+	} {
+		w := &models.Webhook{URL: url, Events: models.EventNames{"article.created"}}
+		assert.Equal(t, models.ErrBadParamInput, u.Store(context.TODO(), w), url)
+	}
+	mockRepo.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+
+	mockRepo.On("Store", mock.Anything, mock.AnythingOfType("*models.Webhook")).Return(nil).Once()
+	u = ucase.NewWebhookUsecase(mockRepo, resolver, true, time.Second)
+	require.NoError(t, u.Store(context.TODO(), &models.Webhook{URL: "http://127.0.0.1:8080/hook", Events: models.EventNames{"article.created"}}))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSecretIsHidden(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	mockRepo.On("Fetch", mock.Anything).Return([]*models.Webhook{{ID: 1, Secret: "s3cret"}}, nil).Once()
+	mockRepo.On("GetByID", mock.Anything, int64(1)).Return(func(ctx context.Context, id int64) *models.Webhook {
+		return &models.Webhook{ID: id, Secret: "s3cret"}
+	}, nil)
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(w *models.Webhook) bool {
+		return w.Secret == "s3cret"
+	})).Return(nil).Once()
+	u := ucase.NewWebhookUsecase(mockRepo, resolver, false, time.Second)
+
+	list, err := u.Fetch(context.TODO())
+	require.NoError(t, err)
+	assert.Empty(t, list[0].Secret)
+	w, err := u.GetByID(context.TODO(), 1)
+	require.NoError(t, err)
+	assert.Empty(t, w.Secret)
+
+	// the secret is kept when none is given
+	w = &models.Webhook{ID: 1, URL: "https://example.com/hook", Events: models.EventNames{"article.deleted"}}
+	require.NoError(t, u.Update(context.TODO(), w))
+	assert.Empty(t, w.Secret)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRedeliver(t *testing.T) {
+	now := time.Now()
+	failed := func() *models.WebhookDelivery {
+		return &models.WebhookDelivery{ID: 7, WebhookID: 1, State: models.DeliveryFailed, Attempts: 10, DeliveredAt: &now}
+	}
+	mockRepo := new(mocks.Repository)
+	mockRepo.On("GetDelivery", mock.Anything, int64(7)).Return(failed(), nil)
+	mockRepo.On("UpdateDelivery", mock.Anything, mock.AnythingOfType("*models.WebhookDelivery")).Return(nil).Once()
+	u := ucase.NewWebhookUsecase(mockRepo, resolver, false, time.Second)
+
+	d, err := u.Redeliver(context.TODO(), 1, 7)
+
+	require.NoError(t, err)
+	assert.Equal(t, models.DeliveryPending, d.State)
+	assert.Equal(t, 0, d.Attempts)
+	assert.Nil(t, d.DeliveredAt)
+	assert.WithinDuration(t, time.Now(), d.NextAttemptAt, time.Second)
+
+	// the delivery belongs to webhook 1
+	_, err = u.Redeliver(context.TODO(), 2, 7)
+	assert.Equal(t, models.ErrNotFound, err)
+	mockRepo.AssertExpectations(t)
+}
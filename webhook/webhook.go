@@ -0,0 +1,103 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+)
+
+// Body is the JSON posted by a delivery
+type Body struct {
+	Event string `json:"event"`
+	// Data is the event, e.g. a models.ArticleCreated
+	Data json.RawMessage `json:"data"`
+}
+
+// NewBody will create the body delivering e
+func NewBody(e models.Event) ([]byte, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(Body{Event: e.EventName(), Data: data})
+}
+
+// Headers of a delivery request
+const (
+	HeaderWebhook = "X-Webhook-Id"
+	// HeaderDelivery identifies the delivery, it is the same on every attempt
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	// HeaderSignature is "sha256=" followed by the hex HMAC-SHA256 of the
+	// timestamp, a dot and the body, keyed with the secret of the webhook
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Sign returns the HeaderSignature value of body sent at timestamp, in Unix seconds
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the HeaderSignature of body sent at timestamp.
+// Receivers should also reject old timestamps to prevent replays.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, "sha256=") {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// Resolver looks up the addresses of a host, as a *net.Resolver does
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// internalNets are the internal ranges that the methods of net.IP do not report
+var internalNets = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),
+	// the shared address space of the carrier-grade NATs
+	mustParseCIDR("100.64.0.0/10"),
+}
+
+func mustParseCIDR(s string) *net.IPNet {
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// PublicIP reports whether ip may receive deliveries. Loopback, private and
+// link-local addresses, which hold the metadata endpoints of the clouds, are
+// refused so that a webhook cannot reach the internal network.
+func PublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified() || ip.IsMulticast() {
+		return false
+	}
+	for _, n := range internalNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// Dispatcher represent the worker sending the pending deliveries to the webhooks
+type Dispatcher interface {
+	// RunOnce sends one batch of deliveries and returns how many were claimed
+	RunOnce(ctx context.Context) (int, error)
+	// Run sends the deliveries until ctx is done
+	Run(ctx context.Context) error
+}
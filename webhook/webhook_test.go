@@ -0,0 +1,40 @@
+package webhook_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/naveenpatilm/go-clean-arch/models"
+	"github.com/naveenpatilm/go-clean-arch/webhook"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"event":"article.created"}`)
+	signature := webhook.Sign("s3cret", 1500000000, body)
+
+	assert.Equal(t, "sha256=", signature[:7])
+	assert.Len(t, signature, 7+64)
+	assert.True(t, webhook.Verify("s3cret", 1500000000, body, signature))
+	assert.False(t, webhook.Verify("other", 1500000000, body, signature))
+	assert.False(t, webhook.Verify("s3cret", 1500000001, body, signature))
+	assert.False(t, webhook.Verify("s3cret", 1500000000, []byte(`{}`), signature))
+	assert.False(t, webhook.Verify("s3cret", 1500000000, body, signature[7:]))
+}
+
+func TestNewBody(t *testing.T) {
+	e := models.ArticleDeleted{Article: models.Article{ID: 3, Title: "Hello"}, OccurredAt: time.Now()}
+
+	b, err := webhook.NewBody(e)
+
+	require.NoError(t, err)
+	var body webhook.Body
+	require.NoError(t, json.Unmarshal(b, &body))
+	assert.Equal(t, models.ArticleDeletedEvent, body.Event)
+	var data models.ArticleDeleted
+	require.NoError(t, json.Unmarshal(body.Data, &data))
+	assert.Equal(t, "Hello", data.Article.Title)
+}